@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Encoding é a codificação de caracteres de um arquivo de texto
+type Encoding int
+
+const (
+	EncodingUTF8        Encoding = iota
+	EncodingWindows1252          // Latin-1 estendido, comum em fontes AdvPL legados
+)
+
+// String retorna o nome da codificação
+func (e Encoding) String() string {
+	if e == EncodingWindows1252 {
+		return "Windows-1252"
+	}
+	return "UTF-8"
+}
+
+// LineEnding é a quebra de linha usada em um arquivo
+type LineEnding int
+
+const (
+	LineEndingLF LineEnding = iota
+	LineEndingCRLF
+)
+
+// String retorna o nome da quebra de linha
+func (l LineEnding) String() string {
+	if l == LineEndingCRLF {
+		return "CRLF"
+	}
+	return "LF"
+}
+
+// TextFormat descreve como o texto estava gravado, para ser salvo da mesma forma
+type TextFormat struct {
+	Encoding   Encoding
+	LineEnding LineEnding
+	BOM        bool // Arquivo UTF-8 com marca de ordem de bytes
+}
+
+// utf8BOM é a marca de ordem de bytes do UTF-8
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DecodeText converte o conteúdo do arquivo para texto com quebras "\n",
+// detectando a codificação (UTF-8 válido ou, senão, Windows-1252) e a quebra de
+// linha predominante
+func DecodeText(data []byte) (string, TextFormat) {
+	var format TextFormat
+
+	if bytes.HasPrefix(data, utf8BOM) {
+		format.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	var text string
+	if utf8.Valid(data) {
+		text = string(data)
+	} else {
+		format.Encoding = EncodingWindows1252
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			decoded = bytes.ToValidUTF8(data, []byte("�"))
+		}
+		text = string(decoded)
+	}
+
+	// A quebra predominante define como o arquivo será salvo
+	crlf := strings.Count(text, "\r\n")
+	if crlf > 0 && crlf >= strings.Count(text, "\n")-crlf {
+		format.LineEnding = LineEndingCRLF
+	}
+	return strings.ReplaceAll(text, "\r\n", "\n"), format
+}
+
+// EncodeText converte o texto (com quebras "\n") de volta para o formato do
+// arquivo. Retorna erro quando há caracteres que não existem na codificação.
+func EncodeText(text string, format TextFormat) ([]byte, error) {
+	if format.LineEnding == LineEndingCRLF {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+
+	if format.Encoding == EncodingWindows1252 {
+		data := make([]byte, 0, len(text))
+		line := 1
+		for _, r := range text {
+			if r == '\n' {
+				line++
+			}
+			b, ok := charmap.Windows1252.EncodeRune(r)
+			if !ok {
+				return nil, fmt.Errorf("o caractere %q (linha %d) não existe em %s", r, line, format.Encoding)
+			}
+			data = append(data, b)
+		}
+		return data, nil
+	}
+
+	if format.BOM {
+		return append(append([]byte{}, utf8BOM...), text...), nil
+	}
+	return []byte(text), nil
+}
@@ -0,0 +1,91 @@
+package editor
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CompilePattern compila a expressão regular da busca. Padrões sem letras
+// maiúsculas não diferenciam maiúsculas de minúsculas.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if !strings.ContainsFunc(pattern, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// FindMatch procura a próxima ocorrência a partir de from (ou a anterior, antes
+// de from), voltando ao início (ou ao fim) do texto quando não encontra.
+// Ocorrências vazias são ignoradas.
+func FindMatch(text string, re *regexp.Regexp, from int, backward bool) (start, end int, ok bool) {
+	from = max(0, min(from, len(text)))
+	var matches [][]int
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		if m[1] > m[0] {
+			matches = append(matches, m)
+		}
+	}
+	if len(matches) == 0 {
+		return 0, 0, false
+	}
+
+	if backward {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i][0] < from {
+				return matches[i][0], matches[i][1], true
+			}
+		}
+		last := matches[len(matches)-1]
+		return last[0], last[1], true
+	}
+
+	for _, m := range matches {
+		if m[0] >= from {
+			return m[0], m[1], true
+		}
+	}
+	return matches[0][0], matches[0][1], true
+}
+
+// ReplaceMatch retorna o texto que substitui a ocorrência em text[start:end],
+// expandindo $1, $2... Retorna false quando o trecho não é uma ocorrência.
+func ReplaceMatch(text string, re *regexp.Regexp, start, end int, replacement string) (string, bool) {
+	m := re.FindStringSubmatchIndex(text[start:])
+	if m == nil || m[0] != 0 || start+m[1] != end {
+		return "", false
+	}
+	return string(re.ExpandString(nil, replacement, text[start:], m)), true
+}
+
+// ReplaceAll substitui todas as ocorrências e retorna o novo texto e quantas foram trocadas
+func ReplaceAll(text string, re *regexp.Regexp, replacement string) (string, int) {
+	count := len(re.FindAllStringIndex(text, -1))
+	if count == 0 {
+		return text, 0
+	}
+	return re.ReplaceAllString(text, replacement), count
+}
+
+// LineOffset retorna a posição do início da linha (a partir de 1); linhas além
+// do fim levam ao início da última
+func LineOffset(text string, line int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	return offset
+}
+
+// Indentation retorna os espaços e tabulações no início da linha que contém pos,
+// até pos
+func Indentation(text string, pos int) string {
+	pos = max(0, min(pos, len(text)))
+	lineStart := strings.LastIndexByte(text[:pos], '\n') + 1
+	line := text[lineStart:pos]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
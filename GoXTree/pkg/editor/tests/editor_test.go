@@ -0,0 +1,102 @@
+package editor_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/editor"
+)
+
+func TestDecodeEncodeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		text   string
+		format editor.TextFormat
+	}{
+		{"utf8 lf", []byte("ação\nfim\n"), "ação\nfim\n", editor.TextFormat{}},
+		{"utf8 crlf", []byte("a\r\nb\r\n"), "a\nb\n", editor.TextFormat{LineEnding: editor.LineEndingCRLF}},
+		{"utf8 bom", []byte("\xEF\xBB\xBFx\n"), "x\n", editor.TextFormat{BOM: true}},
+		{"windows-1252", []byte("User Function A()\r\n// a\xE7\xE3o \x80\r\n"), "User Function A()\n// ação €\n",
+			editor.TextFormat{Encoding: editor.EncodingWindows1252, LineEnding: editor.LineEndingCRLF}},
+	}
+	for _, tt := range tests {
+		text, format := editor.DecodeText(tt.data)
+		if text != tt.text || format != tt.format {
+			t.Errorf("%s: DecodeText() = %q, %+v; want %q, %+v", tt.name, text, format, tt.text, tt.format)
+			continue
+		}
+		// Salvar sem alterações devolve os mesmos bytes
+		data, err := editor.EncodeText(text, format)
+		if err != nil || !bytes.Equal(data, tt.data) {
+			t.Errorf("%s: EncodeText() = %q, %v; want %q", tt.name, data, err, tt.data)
+		}
+	}
+
+	// Caracteres fora do Windows-1252 não podem ser salvos nessa codificação
+	if _, err := editor.EncodeText("ok\n日本", editor.TextFormat{Encoding: editor.EncodingWindows1252}); err == nil {
+		t.Error("EncodeText() sem erro para caractere fora do Windows-1252")
+	}
+}
+
+func TestFindAndReplace(t *testing.T) {
+	text := "Local nValor := 1\nlocal cNome := \"x\"\nReturn nValor\n"
+
+	// Sem maiúsculas no padrão, a busca não diferencia maiúsculas
+	re, err := editor.CompilePattern(`local (\w+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end, ok := editor.FindMatch(text, re, 1, false)
+	if !ok || text[start:end] != "local cNome" {
+		t.Fatalf("FindMatch() = %q, %v", text[start:end], ok)
+	}
+	// Depois da última, volta ao início
+	if start, _, _ := editor.FindMatch(text, re, end, false); start != 0 {
+		t.Errorf("FindMatch(circular) = %d; want 0", start)
+	}
+	if start, _, _ := editor.FindMatch(text, re, 0, true); text[start:start+11] != "local cNome" {
+		t.Errorf("FindMatch(para trás, circular) = %d", start)
+	}
+
+	replacement, ok := editor.ReplaceMatch(text, re, start, end, "Private $1")
+	if !ok || replacement != "Private cNome" {
+		t.Errorf("ReplaceMatch() = %q, %v", replacement, ok)
+	}
+	if _, ok := editor.ReplaceMatch(text, re, start, end-1, "x"); ok {
+		t.Error("ReplaceMatch() aceitou trecho que não é uma ocorrência")
+	}
+
+	replaced, count := editor.ReplaceAll(text, re, "Private $1")
+	if count != 2 || replaced != "Private nValor := 1\nPrivate cNome := \"x\"\nReturn nValor\n" {
+		t.Errorf("ReplaceAll() = %q, %d", replaced, count)
+	}
+
+	// Com maiúsculas, a busca diferencia
+	re, _ = editor.CompilePattern("Local")
+	if _, _, ok := editor.FindMatch("local", re, 0, false); ok {
+		t.Error("FindMatch() ignorou maiúsculas com padrão em maiúsculas")
+	}
+}
+
+func TestLineOffsetAndIndentation(t *testing.T) {
+	text := "a\n\tif x\n\t\ty := 1\nfim"
+	if got := editor.LineOffset(text, 3); text[got:got+3] != "\t\ty" {
+		t.Errorf("LineOffset(3) = %d", got)
+	}
+	if got := editor.LineOffset(text, 99); text[got:] != "fim" {
+		t.Errorf("LineOffset(99) = %d", got)
+	}
+	if got := editor.LineOffset(text, 1); got != 0 {
+		t.Errorf("LineOffset(1) = %d", got)
+	}
+
+	pos := editor.LineOffset(text, 3) + 3
+	if got := editor.Indentation(text, pos); got != "\t\t" {
+		t.Errorf("Indentation() = %q", got)
+	}
+	// Cursor no meio da indentação: só o que está antes dele
+	if got := editor.Indentation(text, editor.LineOffset(text, 3)+1); got != "\t" {
+		t.Errorf("Indentation(meio) = %q", got)
+	}
+}
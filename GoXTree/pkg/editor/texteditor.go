@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
@@ -11,37 +14,75 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-// TextEditor representa um editor de texto simples
+// maxFileSize é o tamanho máximo de arquivo aberto no editor
+const maxFileSize = 10 * 1024 * 1024
+
+// codeView desenha a área de texto com a coluna de números de linha à esquerda
+type codeView struct {
+	*tview.Box
+	editor *TextEditor
+}
+
+// TextEditor representa um editor de texto com desfazer/refazer, busca e
+// substituição por expressão regular, ir para linha, números de linha e
+// indentação automática. Preserva a codificação (UTF-8 ou Windows-1252) e a
+// quebra de linha (LF ou CRLF) do arquivo.
 type TextEditor struct {
 	app       *tview.Application
 	textArea  *tview.TextArea
+	code      *codeView
 	statusBar *tview.TextView
+	prompt    *tview.InputField
 	layout    *tview.Flex
+	root      *tview.Pages
 	filePath  string
 	fileInfo  os.FileInfo
+	format    TextFormat
 	modified  bool
-	onSave    func()
-	onClose   func()
+	loading   bool
+	lineCount int
+
+	lineNumbers bool
+	autoIndent  bool
+	pattern     *regexp.Regexp
+	patternText string
+	replacement string
+	promptMode  rune // 'f' busca, 'g' linha
+	message     string
+
+	onSave  func()
+	onClose func()
 }
 
 // NewTextEditor cria um novo editor de texto
 func NewTextEditor(app *tview.Application) *TextEditor {
 	te := &TextEditor{
-		app:       app,
-		textArea:  tview.NewTextArea(),
-		statusBar: tview.NewTextView(),
-		layout:    tview.NewFlex(),
-		modified:  false,
+		app:         app,
+		textArea:    tview.NewTextArea(),
+		statusBar:   tview.NewTextView(),
+		prompt:      tview.NewInputField(),
+		layout:      tview.NewFlex(),
+		root:        tview.NewPages(),
+		modified:    false,
+		lineCount:   1,
+		lineNumbers: true,
+		autoIndent:  true,
 	}
+	te.code = &codeView{Box: tview.NewBox(), editor: te}
 
-	// Configurar a área de texto
-	te.textArea.SetBorder(true)
-	te.textArea.SetTitle(" Editor de Texto ")
-	te.textArea.SetTitleAlign(tview.AlignLeft)
+	// Configurar a área de texto (sem quebra, para que cada linha ocupe uma linha da tela)
+	te.code.SetBorder(true)
+	te.code.SetTitle(" Editor de Texto ")
+	te.code.SetTitleAlign(tview.AlignLeft)
+	te.textArea.SetWrap(false)
 	te.textArea.SetChangedFunc(func() {
-		te.modified = true
+		te.lineCount = strings.Count(te.textArea.GetText(), "\n") + 1
+		if !te.loading {
+			te.modified = true
+		}
 		te.updateStatusBar()
 	})
+	te.textArea.SetMovedFunc(te.updateStatusBar)
 
 	// Configurar a barra de status
 	te.statusBar.SetTextColor(utils.ColorStatusText)
@@ -49,10 +90,27 @@ func NewTextEditor(app *tview.Application) *TextEditor {
 	te.statusBar.SetDynamicColors(true)
 	te.updateStatusBar()
 
+	// Campo de busca e de linha, exibido no lugar da barra de status
+	te.prompt.SetDoneFunc(func(key tcell.Key) {
+		text := te.prompt.GetText()
+		te.closePrompt()
+		if key != tcell.KeyEnter {
+			return
+		}
+		if te.promptMode == 'g' {
+			te.GotoLine(text)
+			return
+		}
+		if te.setPattern(text) {
+			te.FindNext(false)
+		}
+	})
+
 	// Configurar o layout
 	te.layout.SetDirection(tview.FlexRow).
-		AddItem(te.textArea, 0, 1, true).
+		AddItem(te.code, 0, 1, true).
 		AddItem(te.statusBar, 1, 1, false)
+	te.root.AddPage("editor", te.layout, true, true)
 
 	// Configurar manipuladores de eventos
 	te.textArea.SetInputCapture(te.handleKeyEvents)
@@ -74,7 +132,7 @@ func (te *TextEditor) LoadFile(filePath string) error {
 	}
 
 	// Verificar o tamanho do arquivo
-	if info.Size() > 10*1024*1024 { // 10MB
+	if info.Size() > maxFileSize {
 		return fmt.Errorf("arquivo muito grande para edição (> 10MB)")
 	}
 
@@ -87,14 +145,17 @@ func (te *TextEditor) LoadFile(filePath string) error {
 	// Armazenar informações do arquivo
 	te.filePath = filePath
 	te.fileInfo = info
-	te.modified = false
 
-	// Definir o texto
-	te.textArea.SetText(string(content), true)
+	// Definir o texto, guardando a codificação e a quebra de linha originais
+	text, format := DecodeText(content)
+	te.format = format
+	te.loading = true
+	te.textArea.SetText(text, false)
+	te.loading = false
+	te.modified = false
 
 	// Atualizar o título
-	fileName := filepath.Base(filePath)
-	te.textArea.SetTitle(fmt.Sprintf(" Editor de Texto - %s ", fileName))
+	te.setTitle()
 
 	// Atualizar a barra de status
 	te.updateStatusBar()
@@ -102,6 +163,25 @@ func (te *TextEditor) LoadFile(filePath string) error {
 	return nil
 }
 
+// NewFile prepara o editor para criar o arquivo ao salvar
+func (te *TextEditor) NewFile(filePath string) {
+	te.filePath = filePath
+	te.fileInfo = nil
+	te.format = TextFormat{}
+	te.setTitle()
+	te.updateStatusBar()
+}
+
+// setTitle exibe o nome do arquivo no título
+func (te *TextEditor) setTitle() {
+	te.code.SetTitle(fmt.Sprintf(" Editor de Texto - %s ", filepath.Base(te.filePath)))
+}
+
+// Format retorna a codificação e a quebra de linha usadas ao salvar
+func (te *TextEditor) Format() TextFormat {
+	return te.format
+}
+
 // SaveFile salva o arquivo
 func (te *TextEditor) SaveFile() error {
 	// Verificar se há um arquivo aberto
@@ -109,17 +189,27 @@ func (te *TextEditor) SaveFile() error {
 		return fmt.Errorf("nenhum arquivo aberto")
 	}
 
-	// Obter o texto
-	text := te.textArea.GetText()
-
-	// Salvar o arquivo
-	err := os.WriteFile(te.filePath, []byte(text), 0644)
+	// Converter para a codificação e a quebra de linha do arquivo
+	data, err := EncodeText(te.textArea.GetText(), te.format)
 	if err != nil {
 		return err
 	}
 
+	// Salvar o arquivo, mantendo as permissões de um arquivo existente
+	perm := os.FileMode(0644)
+	if te.fileInfo != nil {
+		perm = te.fileInfo.Mode().Perm()
+	}
+	if err := os.WriteFile(te.filePath, data, perm); err != nil {
+		return err
+	}
+	if info, err := os.Stat(te.filePath); err == nil {
+		te.fileInfo = info
+	}
+
 	// Atualizar o estado
 	te.modified = false
+	te.message = ""
 	te.updateStatusBar()
 
 	// Executar callback de salvamento
@@ -140,6 +230,280 @@ func (te *TextEditor) SetOnClose(callback func()) {
 	te.onClose = callback
 }
 
+// Draw desenha os números de linha e a área de texto
+func (c *codeView) Draw(screen tcell.Screen) {
+	c.DrawForSubclass(screen, c)
+	x, y, width, height := c.GetInnerRect()
+	te := c.editor
+
+	gutter := 0
+	if te.lineNumbers {
+		gutter = len(strconv.Itoa(te.lineCount)) + 1
+	}
+	te.textArea.SetRect(x+gutter, y, max(width-gutter, 0), height)
+	te.textArea.Draw(screen)
+	if gutter == 0 {
+		return
+	}
+
+	// O deslocamento só é conhecido depois que a área de texto foi desenhada
+	offset, _ := te.textArea.GetOffset()
+	_, _, cursor, _ := te.textArea.GetCursor()
+	for i := 0; i < height && offset+i < te.lineCount; i++ {
+		color := tcell.ColorGray
+		if offset+i == cursor {
+			color = tcell.ColorYellow
+		}
+		tview.Print(screen, strconv.Itoa(offset+i+1), x, y+i, gutter-1, tview.AlignRight, color)
+	}
+}
+
+// Focus repassa o foco para a área de texto
+func (c *codeView) Focus(delegate func(p tview.Primitive)) {
+	delegate(c.editor.textArea)
+}
+
+// HasFocus indica se a área de texto tem o foco
+func (c *codeView) HasFocus() bool {
+	return c.editor.textArea.HasFocus()
+}
+
+// InputHandler repassa as teclas para a área de texto
+func (c *codeView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return c.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if handler := c.editor.textArea.InputHandler(); handler != nil {
+			handler(event, setFocus)
+		}
+	})
+}
+
+// MouseHandler repassa o mouse para a área de texto
+func (c *codeView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+	return c.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+		return c.editor.textArea.MouseHandler()(action, event, setFocus)
+	})
+}
+
+// PasteHandler repassa o texto colado para a área de texto
+func (c *codeView) PasteHandler() func(text string, setFocus func(p tview.Primitive)) {
+	return c.WrapPasteHandler(func(text string, setFocus func(p tview.Primitive)) {
+		if handler := c.editor.textArea.PasteHandler(); handler != nil {
+			handler(text, setFocus)
+		}
+	})
+}
+
+// selectRange seleciona o trecho e rola o texto para que fique visível
+func (te *TextEditor) selectRange(start, end int) {
+	te.textArea.Select(start, end)
+	te.scrollToCursor(true)
+}
+
+// scrollToCursor rola o texto até o cursor, centralizando-o ou apenas o
+// necessário para que apareça
+func (te *TextEditor) scrollToCursor(center bool) {
+	_, _, width, height := te.textArea.GetInnerRect()
+	_, _, row, column := te.textArea.GetCursor()
+	rowOffset, columnOffset := te.textArea.GetOffset()
+	switch {
+	case row >= rowOffset && row < rowOffset+height:
+	case center:
+		rowOffset = max(row-height/2, 0)
+	case row < rowOffset:
+		rowOffset = row
+	default:
+		rowOffset = row - height + 1
+	}
+	if column < columnOffset || column >= columnOffset+width {
+		columnOffset = max(column-width/2, 0)
+	}
+	te.textArea.SetOffset(rowOffset, columnOffset)
+}
+
+// GotoLine posiciona o cursor no início da linha (a partir de 1)
+func (te *TextEditor) GotoLine(target string) {
+	line, err := strconv.Atoi(strings.TrimSpace(target))
+	if err != nil || line < 1 {
+		te.message = fmt.Sprintf("[red]Linha inválida: %s[-]", tview.Escape(target))
+		te.updateStatusBar()
+		return
+	}
+	offset := LineOffset(te.textArea.GetText(), line)
+	te.message = ""
+	te.selectRange(offset, offset)
+	te.updateStatusBar()
+}
+
+// setPattern compila a expressão buscada; retorna false em caso de erro
+func (te *TextEditor) setPattern(pattern string) bool {
+	if pattern == "" {
+		te.pattern, te.patternText = nil, ""
+		return false
+	}
+	re, err := CompilePattern(pattern)
+	if err != nil {
+		te.message = fmt.Sprintf("[red]Expressão inválida: %s[-]", tview.Escape(err.Error()))
+		te.updateStatusBar()
+		return false
+	}
+	te.pattern, te.patternText = re, pattern
+	return true
+}
+
+// FindNext seleciona a próxima (ou a anterior) ocorrência da busca
+func (te *TextEditor) FindNext(backward bool) bool {
+	if te.pattern == nil {
+		return false
+	}
+	text := te.textArea.GetText()
+	_, start, end := te.textArea.GetSelection()
+	from := end
+	if backward {
+		from = start
+	}
+
+	matchStart, matchEnd, ok := FindMatch(text, te.pattern, from, backward)
+	if !ok {
+		te.message = fmt.Sprintf("[red]%s não encontrado[-]", tview.Escape(strconv.Quote(te.patternText)))
+		te.updateStatusBar()
+		return false
+	}
+	te.message = ""
+	te.selectRange(matchStart, matchEnd)
+	te.updateStatusBar()
+	return true
+}
+
+// ReplaceNext substitui a ocorrência selecionada e seleciona a próxima
+func (te *TextEditor) ReplaceNext() {
+	if te.pattern == nil {
+		return
+	}
+	text := te.textArea.GetText()
+	_, start, end := te.textArea.GetSelection()
+	if replacement, ok := ReplaceMatch(text, te.pattern, start, end, te.replacement); ok {
+		te.textArea.Replace(start, end, replacement)
+	}
+	te.FindNext(false)
+}
+
+// ReplaceAll substitui todas as ocorrências (pode ser desfeito com Ctrl+Z)
+func (te *TextEditor) ReplaceAll() int {
+	if te.pattern == nil {
+		return 0
+	}
+	text := te.textArea.GetText()
+	replaced, count := ReplaceAll(text, te.pattern, te.replacement)
+	if count > 0 {
+		rowOffset, columnOffset := te.textArea.GetOffset()
+		te.textArea.Replace(0, len(text), replaced)
+		te.textArea.SetOffset(rowOffset, columnOffset)
+	}
+	te.message = fmt.Sprintf("%d substituições", count)
+	te.updateStatusBar()
+	return count
+}
+
+// insertNewline quebra a linha mantendo a indentação da linha atual
+func (te *TextEditor) insertNewline() {
+	text := te.textArea.GetText()
+	_, start, end := te.textArea.GetSelection()
+	te.textArea.Replace(start, end, "\n"+Indentation(text, start))
+	te.scrollToCursor(false)
+}
+
+// openPrompt exibe o campo de busca ou de linha no lugar da barra de status
+func (te *TextEditor) openPrompt(mode rune) {
+	te.promptMode = mode
+	if mode == 'g' {
+		te.prompt.SetLabel(" Ir para a linha: ").SetText("")
+	} else {
+		te.prompt.SetLabel(" Buscar (expressão regular): ").SetText(te.patternText)
+	}
+	te.layout.RemoveItem(te.statusBar)
+	te.layout.AddItem(te.prompt, 1, 1, true)
+	te.app.SetFocus(te.prompt)
+}
+
+// closePrompt volta a exibir a barra de status
+func (te *TextEditor) closePrompt() {
+	te.layout.RemoveItem(te.prompt)
+	te.layout.AddItem(te.statusBar, 1, 1, false)
+	te.app.SetFocus(te.textArea)
+}
+
+// showReplaceDialog exibe o diálogo de busca e substituição
+func (te *TextEditor) showReplaceDialog() {
+	form := tview.NewForm()
+	form.AddInputField("Buscar:", te.patternText, 50, nil, nil)
+	form.AddInputField("Substituir por:", te.replacement, 50, nil, nil)
+
+	apply := func() bool {
+		te.replacement = form.GetFormItem(1).(*tview.InputField).GetText()
+		return te.setPattern(form.GetFormItem(0).(*tview.InputField).GetText())
+	}
+	closeDialog := func() {
+		te.root.RemovePage("replace")
+		te.app.SetFocus(te.textArea)
+	}
+
+	form.AddButton("Substituir", func() {
+		if !apply() {
+			return
+		}
+		closeDialog()
+		// A primeira vez apenas seleciona a ocorrência; as seguintes substituem
+		if _, start, end := te.textArea.GetSelection(); start == end {
+			te.FindNext(false)
+			return
+		}
+		te.ReplaceNext()
+	})
+	form.AddButton("Substituir Todos", func() {
+		if apply() {
+			closeDialog()
+			te.ReplaceAll()
+		}
+	})
+	form.AddButton("Cancelar", closeDialog)
+	form.SetCancelFunc(closeDialog)
+	form.SetBorder(true).SetTitle(" Substituir (expressão regular, $1 no texto de substituição) ").SetTitleAlign(tview.AlignLeft)
+
+	te.root.AddPage("replace", centered(form, 72, 9), true, true)
+	te.app.SetFocus(form)
+}
+
+// showConfirmDialog exibe um diálogo de confirmação
+func (te *TextEditor) showConfirmDialog(message string, callback func(bool)) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"Sim", "Não", "Cancelar"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			te.root.RemovePage("confirm")
+			te.app.SetFocus(te.textArea)
+			if buttonLabel == "Sim" {
+				callback(true)
+			} else if buttonLabel == "Não" {
+				callback(false)
+			}
+			// Se for "Cancelar", não faz nada
+		})
+
+	te.root.AddPage("confirm", modal, true, true)
+	te.app.SetFocus(modal)
+}
+
+// centered centraliza o primitivo com o tamanho indicado
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
 // updateStatusBar atualiza a barra de status
 func (te *TextEditor) updateStatusBar() {
 	var status string
@@ -147,7 +511,7 @@ func (te *TextEditor) updateStatusBar() {
 	// Verificar se há um arquivo aberto
 	if te.filePath != "" {
 		fileName := filepath.Base(te.filePath)
-		status = fmt.Sprintf(" %s ", fileName)
+		status = fmt.Sprintf(" %s ", tview.Escape(fileName))
 
 		// Indicar se o arquivo foi modificado
 		if te.modified {
@@ -157,75 +521,94 @@ func (te *TextEditor) updateStatusBar() {
 		status = " Novo Arquivo "
 	}
 
+	// Posição do cursor, codificação e quebra de linha
+	_, _, row, column := te.textArea.GetCursor()
+	status += fmt.Sprintf("| Lin %d, Col %d | %s | %s ", row+1, column+1, te.format.Encoding, te.format.LineEnding)
+	if te.message != "" {
+		status += "| " + te.message + " "
+	}
+
 	// Adicionar instruções
-	status += " | [::b]Ctrl+S[-:-:-] Salvar | [::b]Ctrl+Q[-:-:-] Sair"
+	status += "| [::b]Ctrl+S[-:-:-] Salvar | [::b]Ctrl+Z/Y[-:-:-] Desfazer/Refazer | [::b]Ctrl+F[-:-:-] Buscar | [::b]Ctrl+N/P[-:-:-] Próxima/Anterior | [::b]Ctrl+R[-:-:-] Substituir | [::b]Ctrl+G[-:-:-] Linha | [::b]Alt+N[-:-:-] Números | [::b]Ctrl+Q[-:-:-] Sair"
 
 	// Atualizar a barra de status
 	te.statusBar.SetText(status)
 }
 
+// close fecha o editor, perguntando antes se há modificações não salvas
+func (te *TextEditor) close() {
+	if !te.modified {
+		if te.onClose != nil {
+			te.onClose()
+		}
+		return
+	}
+
+	te.showConfirmDialog("Arquivo modificado. Deseja salvar antes de sair?", func(save bool) {
+		if save {
+			// Salvar o arquivo
+			if err := te.SaveFile(); err != nil {
+				// Exibir mensagem de erro na barra de status
+				te.message = fmt.Sprintf("[red]Erro ao salvar: %s[-]", tview.Escape(err.Error()))
+				te.updateStatusBar()
+				return
+			}
+		}
+
+		// Fechar o editor
+		if te.onClose != nil {
+			te.onClose()
+		}
+	})
+}
+
 // handleKeyEvents manipula eventos de teclado
 func (te *TextEditor) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
-	// Verificar combinações de teclas
-	if event.Key() == tcell.KeyCtrlS {
+	te.message = ""
+
+	switch event.Key() {
+	case tcell.KeyCtrlS:
 		// Salvar o arquivo
-		err := te.SaveFile()
-		if err != nil {
+		if err := te.SaveFile(); err != nil {
 			// Exibir mensagem de erro na barra de status
-			te.statusBar.SetText(fmt.Sprintf(" Erro ao salvar: %s | [::b]Ctrl+S[-:-:-] Salvar | [::b]Ctrl+Q[-:-:-] Sair", err))
+			te.message = fmt.Sprintf("[red]Erro ao salvar: %s[-]", tview.Escape(err.Error()))
+			te.updateStatusBar()
 		}
 		return nil
-	} else if event.Key() == tcell.KeyCtrlQ || event.Key() == tcell.KeyEscape {
-		// Verificar se há modificações não salvas
-		if te.modified {
-			// Exibir diálogo de confirmação
-			te.showConfirmDialog("Arquivo modificado. Deseja salvar antes de sair?", func(save bool) {
-				if save {
-					// Salvar o arquivo
-					err := te.SaveFile()
-					if err != nil {
-						// Exibir mensagem de erro na barra de status
-						te.statusBar.SetText(fmt.Sprintf(" Erro ao salvar: %s | [::b]Ctrl+S[-:-:-] Salvar | [::b]Ctrl+Q[-:-:-] Sair", err))
-						return
-					}
-				}
-
-				// Fechar o editor
-				if te.onClose != nil {
-					te.onClose()
-				}
-			})
-		} else {
-			// Fechar o editor
-			if te.onClose != nil {
-				te.onClose()
-			}
-		}
+	case tcell.KeyCtrlQ, tcell.KeyEscape:
+		te.close()
+		return nil
+	case tcell.KeyCtrlF:
+		te.openPrompt('f')
+		return nil
+	case tcell.KeyCtrlN, tcell.KeyF3:
+		te.FindNext(false)
+		return nil
+	case tcell.KeyCtrlP:
+		te.FindNext(true)
 		return nil
+	case tcell.KeyCtrlR:
+		te.showReplaceDialog()
+		return nil
+	case tcell.KeyCtrlG:
+		te.openPrompt('g')
+		return nil
+	case tcell.KeyEnter:
+		if te.autoIndent {
+			te.insertNewline()
+			return nil
+		}
+	case tcell.KeyRune:
+		if event.Modifiers()&tcell.ModAlt != 0 && (event.Rune() == 'n' || event.Rune() == 'N') {
+			te.lineNumbers = !te.lineNumbers
+			return nil
+		}
 	}
 
 	return event
 }
 
-// showConfirmDialog exibe um diálogo de confirmação
-func (te *TextEditor) showConfirmDialog(message string, callback func(bool)) {
-	modal := tview.NewModal().
-		SetText(message).
-		AddButtons([]string{"Sim", "Não", "Cancelar"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Sim" {
-				callback(true)
-			} else if buttonLabel == "Não" {
-				callback(false)
-			}
-			// Se for "Cancelar", não faz nada
-			te.app.SetRoot(te.layout, true)
-		})
-
-	te.app.SetRoot(modal, true)
-}
-
 // Show exibe o editor
-func (te *TextEditor) Show() *tview.Flex {
-	return te.layout
+func (te *TextEditor) Show() *tview.Pages {
+	return te.root
 }
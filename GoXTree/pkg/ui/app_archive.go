@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// checkReadOnly avisa e retorna true se o diretório estiver dentro de um arquivo compactado
+func (a *App) checkReadOnly(dir string) bool {
+	if !utils.IsArchivePath(dir) {
+		return false
+	}
+	a.showError("Arquivos compactados são somente leitura. Use Alt+X para extrair.")
+	return true
+}
+
+// localFile retorna um caminho local para o arquivo: arquivos de outros sistemas de
+// arquivos (arquivos compactados, montagens) são copiados para o diretório temporário da aplicação
+func (a *App) localFile(path string) (string, error) {
+	if utils.IsLocalPath(path) {
+		return path, nil
+	}
+
+	if a.tempDir == "" {
+		dir, err := os.MkdirTemp("", "goxtree-")
+		if err != nil {
+			return "", err
+		}
+		a.tempDir = dir
+	}
+
+	dir, err := os.MkdirTemp(a.tempDir, "arquivo-")
+	if err != nil {
+		return "", err
+	}
+	localPath := filepath.Join(dir, filepath.Base(path))
+	if err := utils.CopyFileFS(utils.FSFor(path), path, utils.Local, localPath); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return localPath, nil
+}
+
+// removeTempDir remove os arquivos extraídos para visualização
+func (a *App) removeTempDir() {
+	if a.tempDir != "" {
+		os.RemoveAll(a.tempDir)
+		a.tempDir = ""
+	}
+}
+
+// extractSelected extrai os itens selecionados de um arquivo compactado (Alt+X).
+// Fora de um arquivo compactado, extrai todo o arquivo compactado sob o cursor.
+func (a *App) extractSelected() {
+	archivePath, _, inArchive := utils.SplitArchivePath(a.currentDir)
+
+	var names []string
+	if inArchive {
+		for path := range a.panelTargets() {
+			if _, inner, ok := utils.SplitArchivePath(path); ok && inner != "" {
+				names = append(names, inner)
+			}
+		}
+	} else {
+		archivePath = a.getSelectedFile()
+		if archivePath == "" || !utils.IsArchive(archivePath) {
+			a.showMessage("Selecione um arquivo compactado")
+			return
+		}
+		names = []string{""}
+	}
+
+	if len(names) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+	sort.Strings(names)
+
+	// Destino padrão: o outro painel ou o diretório do arquivo compactado
+	destDir := filepath.Dir(archivePath)
+	if other := a.otherPanelDir(); other != "" && !utils.IsArchivePath(other) {
+		destDir = other
+	}
+
+	a.showInputDialogWithValue("Extrair para", destDir, func(destDir string) {
+		if destDir == "" {
+			return
+		}
+		if !filepath.IsAbs(destDir) {
+			destDir = filepath.Join(filepath.Dir(archivePath), destDir)
+		}
+		if utils.IsArchivePath(destDir) {
+			a.showError("O destino não pode estar dentro de um arquivo compactado")
+			return
+		}
+
+		a.extractTo(archivePath, names, destDir)
+	})
+}
+
+// extractTo extrai as entradas de um arquivo compactado em segundo plano
+func (a *App) extractTo(archivePath string, names []string, destDir string) {
+	name := fmt.Sprintf("Extrair %s", filepath.Base(archivePath))
+	if len(names) == 1 && names[0] != "" {
+		name = fmt.Sprintf("Extrair %s de %s", filepath.Base(names[0]), filepath.Base(archivePath))
+	} else if len(names) > 1 {
+		name = fmt.Sprintf("Extrair %d itens de %s", len(names), filepath.Base(archivePath))
+	}
+
+	a.submitJob(name, func(jc *utils.JobContext) error {
+		archive, err := utils.OpenArchive(archivePath)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return err
+		}
+		return archive.Extract(jc, names, destDir)
+	}, nil)
+
+	a.selectedFiles = make(map[string]bool)
+	a.refreshFileView()
+}
+
+// extractFilesTo extrai arquivos de um arquivo compactado (copiar/colar a partir dele)
+func (a *App) extractFilesTo(files map[string]bool, destDir string) {
+	byArchive := make(map[string][]string)
+	for path := range files {
+		if archivePath, inner, ok := utils.SplitArchivePath(path); ok && inner != "" {
+			byArchive[archivePath] = append(byArchive[archivePath], inner)
+		}
+	}
+
+	for archivePath, names := range byArchive {
+		sort.Strings(names)
+		a.extractTo(archivePath, names, destDir)
+	}
+}
+
+// hasArchiveFiles indica se algum dos arquivos está dentro de um arquivo compactado
+func hasArchiveFiles(files map[string]bool) bool {
+	for path := range files {
+		if utils.IsArchivePath(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// showCreateArchiveDialog exibe o diálogo para compactar os itens selecionados (Alt+Z)
+func (a *App) showCreateArchiveDialog() {
+	files := a.panelTargets()
+	if len(files) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+	if hasArchiveFiles(files) {
+		a.showError("Extraia os itens do arquivo compactado antes de compactá-los novamente")
+		return
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Nome padrão: o item único ou o diretório atual
+	format := utils.ArchiveZip
+	destPath := filepath.Join(a.currentDir, filepath.Base(a.currentDir))
+	if len(paths) == 1 {
+		destPath = paths[0]
+	}
+	destPath += utils.ArchiveExtension(format)
+
+	var (
+		level            = 0
+		exclude          = ""
+		preservePerms    = true
+		preserveSymlinks = true
+		volumeMB         = "0"
+	)
+
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Criar Arquivo Compactado (%d item(ns)) ", len(paths))).
+		SetTitleAlign(tview.AlignLeft)
+
+	form.AddInputField("Arquivo:", destPath, 50, nil, func(text string) {
+		destPath = text
+	})
+	form.AddDropDown("Formato:", utils.WritableArchiveFormats, 0, func(option string, index int) {
+		if option == "" || option == format {
+			return
+		}
+
+		// Trocar a extensão do nome do arquivo junto com o formato
+		if strings.HasSuffix(destPath, utils.ArchiveExtension(format)) {
+			destPath = strings.TrimSuffix(destPath, utils.ArchiveExtension(format)) + utils.ArchiveExtension(option)
+			if field, ok := form.GetFormItemByLabel("Arquivo:").(*tview.InputField); ok {
+				field.SetText(destPath)
+			}
+		}
+		format = option
+	})
+	form.AddDropDown("Nível de compressão:", []string{"Padrão", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, 0, func(option string, index int) {
+		level = index
+	})
+	form.AddInputField("Excluir (ex: *.log; tmp/*):", "", 50, nil, func(text string) {
+		exclude = text
+	})
+	form.AddCheckbox("Preservar permissões", preservePerms, func(checked bool) {
+		preservePerms = checked
+	})
+	form.AddCheckbox("Manter links simbólicos", preserveSymlinks, func(checked bool) {
+		preserveSymlinks = checked
+	})
+	form.AddInputField("Dividir em volumes de (MB, 0 = não dividir):", volumeMB, 10, tview.InputFieldInteger, func(text string) {
+		volumeMB = text
+	})
+
+	form.AddButton("Criar", func() {
+		if destPath == "" {
+			return
+		}
+		if !filepath.IsAbs(destPath) {
+			destPath = filepath.Join(a.currentDir, destPath)
+		}
+		if utils.IsArchivePath(filepath.Dir(destPath)) {
+			a.showError("O destino não pode estar dentro de um arquivo compactado")
+			return
+		}
+
+		var volumeSize int64
+		if mb, err := strconv.ParseInt(volumeMB, 10, 64); err == nil && mb > 0 {
+			volumeSize = mb << 20
+		}
+
+		opts := utils.ArchiveOptions{
+			Format:              format,
+			Level:               level,
+			Exclude:             utils.ParseExcludePatterns(exclude),
+			PreservePermissions: preservePerms,
+			PreserveSymlinks:    preserveSymlinks,
+			VolumeSize:          volumeSize,
+		}
+
+		create := func() {
+			a.pages.RemovePage("createArchive")
+			a.app.SetFocus(a.fileView.fileList)
+			a.selectedFiles = make(map[string]bool)
+			a.refreshFileView()
+
+			a.submitJob(fmt.Sprintf("Compactar %s", filepath.Base(destPath)), func(jc *utils.JobContext) error {
+				return utils.CreateArchive(jc, paths, destPath, opts)
+			}, nil)
+		}
+
+		// O primeiro volume indica se um arquivo dividido já existe
+		existing := destPath
+		if volumeSize > 0 {
+			existing = utils.VolumePath(destPath, 1)
+		}
+		if _, err := os.Stat(existing); err == nil {
+			a.showConfirmDialog("Confirmar sobrescrita", fmt.Sprintf("'%s' já existe. Sobrescrever?", filepath.Base(existing)), func(confirmed bool) {
+				if confirmed {
+					create()
+				}
+			})
+			return
+		}
+		create()
+	})
+	form.AddButton("Cancelar", func() {
+		a.pages.RemovePage("createArchive")
+		a.app.SetFocus(a.fileView.fileList)
+	})
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("createArchive")
+			a.app.SetFocus(a.fileView.fileList)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("createArchive", a.modal(form, 80, 19), true, true)
+	a.app.SetFocus(form)
+}
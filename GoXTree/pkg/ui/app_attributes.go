@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// showAttributesDialog abre o diálogo de permissões e dono (chmod/chown) para os
+// itens selecionados ou o item atual (Alt+A)
+func (a *App) showAttributesDialog() {
+	files := a.panelTargets()
+	if len(files) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+	if hasArchiveFiles(files) {
+		a.showError("Itens dentro de arquivos compactados não podem ser alterados")
+		return
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Com um único item os campos começam com os valores atuais
+	var (
+		mode, owner, group string
+		current            string
+		hasDir             bool
+		remote             = !utils.IsLocalPath(paths[0])
+	)
+	for _, path := range paths {
+		info, err := utils.LstatFS(utils.FSFor(path), path)
+		if err != nil {
+			continue
+		}
+		hasDir = hasDir || info.IsDir()
+		if len(paths) == 1 {
+			mode = utils.FormatOctalMode(info.Mode())
+			owner, group = utils.FileOwner(info)
+			current = fmt.Sprintf("%s  %s:%s", utils.FormatMode(info.Mode()), owner, group)
+		}
+	}
+	initialMode, initialOwner, initialGroup := mode, owner, group
+	recursive := false
+
+	closeDialog := func() {
+		a.pages.RemovePage("attributes")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	title := fmt.Sprintf(" Permissões e Dono - %s ", filepath.Base(paths[0]))
+	if len(paths) > 1 {
+		title = fmt.Sprintf(" Permissões e Dono (%d itens) ", len(paths))
+	}
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignLeft)
+
+	if current != "" {
+		form.AddTextView("Atual:", tview.Escape(current), 40, 1, false, false)
+	}
+	form.AddInputField("Permissões (755, u+x,go-w, a+rX):", mode, 20, nil, func(text string) {
+		mode = text
+	})
+	form.AddInputField("Dono:", owner, 20, nil, func(text string) {
+		owner = text
+	})
+	form.AddInputField("Grupo:", group, 20, nil, func(text string) {
+		group = text
+	})
+	if hasDir {
+		form.AddCheckbox("Aplicar ao conteúdo dos diretórios", recursive, func(checked bool) {
+			recursive = checked
+		})
+	}
+
+	form.AddButton("Aplicar", func() {
+		// Campos vazios ou não alterados mantêm o valor de cada item; na aplicação
+		// recursiva os valores informados valem para todo o conteúdo
+		change := utils.AttrChange{UID: -1, GID: -1, Recursive: recursive}
+		if mode = strings.TrimSpace(mode); mode != "" && (mode != initialMode || recursive) {
+			if _, err := utils.ParseMode(mode, 0, false); err != nil {
+				a.showError(err.Error())
+				return
+			}
+			change.Mode = mode
+		}
+
+		var err error
+		if owner = strings.TrimSpace(owner); owner != "" && (owner != initialOwner || recursive) {
+			if change.UID, err = a.lookupOwnerID(owner, remote, utils.LookupUserID); err != nil {
+				a.showError(err.Error())
+				return
+			}
+		}
+		if group = strings.TrimSpace(group); group != "" && (group != initialGroup || recursive) {
+			if change.GID, err = a.lookupOwnerID(group, remote, utils.LookupGroupID); err != nil {
+				a.showError(err.Error())
+				return
+			}
+		}
+
+		closeDialog()
+		if change.Mode == "" && change.UID < 0 && change.GID < 0 {
+			return
+		}
+
+		name := fmt.Sprintf("Alterar atributos de %d item(ns)", len(paths))
+		if len(paths) == 1 {
+			name = fmt.Sprintf("Alterar atributos de %s", filepath.Base(paths[0]))
+		}
+		a.submitJob(name, func(jc *utils.JobContext) error {
+			return utils.ChangeAttributesJob(jc, paths, change)
+		}, func(s utils.JobSnapshot) {
+			if len(s.Errors) > 0 {
+				a.showError(fmt.Sprintf("%d item(ns) não puderam ser alterados: %v (Alt+J para detalhes)", len(s.Errors), s.Errors[0].Err))
+			}
+		})
+	})
+	form.AddButton("Cancelar", closeDialog)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeDialog()
+			return nil
+		}
+		return event
+	})
+
+	height := 13
+	if current == "" {
+		height -= 2
+	}
+	if !hasDir {
+		height -= 2
+	}
+	a.pages.AddPage("attributes", a.modal(form, 64, height), true, true)
+	a.app.SetFocus(form)
+}
+
+// lookupOwnerID converte o nome de usuário ou grupo em número. Em servidores
+// remotos os nomes locais não valem, então só números são aceitos.
+func (a *App) lookupOwnerID(name string, remote bool, lookup func(string) (int, error)) (int, error) {
+	if remote {
+		id, err := strconv.Atoi(name)
+		if err != nil || id < 0 {
+			return -1, fmt.Errorf("em servidores remotos informe o número (UID/GID), não o nome: %s", name)
+		}
+		return id, nil
+	}
+	return lookup(name)
+}
@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// frecencySaveInterval é o número de visitas entre gravações da base de diretórios
+const frecencySaveInterval = 20
+
+// handleBookmarkKeys trata as teclas de marcadores: m+letra grava o diretório
+// atual e '+letra volta a ele. Retorna nil quando a tecla foi consumida.
+func (a *App) handleBookmarkKeys(event *tcell.EventKey) *tcell.EventKey {
+	// Segunda tecla: a letra do marcador
+	if pending := a.pendingBookmarkKey; pending != 0 {
+		a.pendingBookmarkKey = 0
+		if event.Key() != tcell.KeyRune || !isBookmarkLetter(event.Rune()) {
+			a.statusBar.SetStatus("Marcador cancelado")
+			return nil
+		}
+		if pending == 'm' {
+			a.setBookmark(event.Rune())
+		} else {
+			a.jumpToBookmark(event.Rune())
+		}
+		return nil
+	}
+
+	// Primeira tecla
+	if event.Key() == tcell.KeyRune && event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0 {
+		switch event.Rune() {
+		case 'm':
+			a.pendingBookmarkKey = 'm'
+			a.statusBar.SetStatus("Marcar diretório: pressione uma letra (a–z)")
+			return nil
+		case '\'':
+			a.pendingBookmarkKey = '\''
+			a.statusBar.SetStatus("Ir para marcador: pressione uma letra (a–z)")
+			return nil
+		}
+	}
+	return event
+}
+
+// isBookmarkLetter indica se a tecla pode nomear um marcador
+func isBookmarkLetter(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+// setBookmark associa a letra ao diretório atual
+func (a *App) setBookmark(letter rune) {
+	a.bookmarks[string(letter)] = a.currentDir
+	if err := SaveBookmarks(a.bookmarks); err != nil {
+		a.showError(fmt.Sprintf("Erro ao salvar marcadores: %v", err))
+		return
+	}
+	a.statusBar.SetStatus(fmt.Sprintf("Marcador '%c → %s", letter, a.currentDir))
+}
+
+// jumpToBookmark navega para o diretório associado à letra
+func (a *App) jumpToBookmark(letter rune) {
+	dir, ok := a.bookmarks[string(letter)]
+	if !ok {
+		a.statusBar.SetStatus(fmt.Sprintf("Marcador '%c não definido (use m%c para criar)", letter, letter))
+		return
+	}
+	a.jumpToDirectory(dir)
+}
+
+// jumpToDirectory navega para um diretório de marcador ou da base de visitados,
+// verificando antes se ele ainda existe
+func (a *App) jumpToDirectory(dir string) bool {
+	info, err := utils.Stat(dir)
+	if err != nil || !info.IsDir() {
+		if a.frecency != nil && utils.IsLocalPath(dir) {
+			a.frecency.Remove(dir)
+		}
+		a.showError(fmt.Sprintf("Diretório não encontrado: %s", dir))
+		return false
+	}
+	a.navigateTo(dir)
+	return true
+}
+
+// recordVisit registra o diretório na base de visitados (só diretórios locais)
+func (a *App) recordVisit(dir string) {
+	if a.frecency == nil || !utils.IsLocalPath(dir) {
+		return
+	}
+	a.frecency.Visit(dir)
+	a.visitsSinceSave++
+	if a.visitsSinceSave >= frecencySaveInterval {
+		a.saveFrecency()
+	}
+}
+
+// saveFrecency grava a base de visitados; erros são apenas informados na barra de status
+func (a *App) saveFrecency() {
+	if a.frecency == nil {
+		return
+	}
+	a.visitsSinceSave = 0
+	if err := a.frecency.Save(); err != nil {
+		a.statusBar.SetStatus(fmt.Sprintf("Erro ao salvar diretórios visitados: %v", err))
+	}
+}
+
+// highlightMatches destaca as posições (em runas) encontradas pela busca aproximada
+func highlightMatches(text string, positions []int) string {
+	if len(positions) == 0 {
+		return tview.Escape(text)
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	inMatch := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if inMatch {
+			b.WriteString("[yellow::b]" + tview.Escape(string(run)) + "[-::-]")
+		} else {
+			b.WriteString(tview.Escape(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != inMatch {
+			flush()
+			inMatch = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
+
+// showJumpDialog abre a busca aproximada nos diretórios mais visitados (Alt+G)
+func (a *App) showJumpDialog() {
+	if a.frecency == nil || a.frecency.Len() == 0 {
+		a.showMessage("Nenhum diretório visitado ainda")
+		return
+	}
+
+	var matches []utils.FrecencyMatch
+
+	list := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true)
+	list.SetBorder(true).
+		SetTitle(" Ir para Diretório Frequente ").
+		SetTitleAlign(tview.AlignCenter)
+
+	input := tview.NewInputField().SetLabel("> ")
+	status := tview.NewTextView().SetDynamicColors(true)
+
+	update := func(pattern string) {
+		matches = a.frecency.Query(pattern, 200)
+		list.Clear()
+		for _, m := range matches {
+			list.AddItem(highlightMatches(m.Path, m.Positions), "", 0, nil)
+		}
+		status.SetText(fmt.Sprintf(" %d/%d  [::b]Enter[-:-:-] Ir  [::b]Ctrl+D[-:-:-] Esquecer  [::b]ESC[-:-:-] Fechar",
+			len(matches), a.frecency.Len()))
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("jump")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	input.SetChangedFunc(update)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		current := list.GetCurrentItem()
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyEnter:
+			if current < len(matches) {
+				closeDialog()
+				a.jumpToDirectory(matches[current].Path)
+			}
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			list.SetCurrentItem(max(current-1, 0))
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			list.SetCurrentItem(min(current+1, max(len(matches)-1, 0)))
+			return nil
+		case tcell.KeyPgUp:
+			list.SetCurrentItem(max(current-10, 0))
+			return nil
+		case tcell.KeyPgDn:
+			list.SetCurrentItem(min(current+10, max(len(matches)-1, 0)))
+			return nil
+		case tcell.KeyCtrlD:
+			if current < len(matches) {
+				a.frecency.Remove(matches[current].Path)
+				update(input.GetText())
+				list.SetCurrentItem(min(current, max(len(matches)-1, 0)))
+			}
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, false).
+		AddItem(input, 1, 0, true).
+		AddItem(status, 1, 0, false)
+
+	update("")
+	a.pages.AddPage("jump", a.modal(layout, 90, 24), true, true)
+	a.app.SetFocus(input)
+}
+
+// showBookmarks lista os marcadores; Enter ou a letra navega, Del remove
+func (a *App) showBookmarks() {
+	if len(a.bookmarks) == 0 {
+		a.showMessage("Nenhum marcador definido.\nUse m seguido de uma letra para marcar o diretório atual.")
+		return
+	}
+
+	letters := make([]string, 0, len(a.bookmarks))
+	for letter := range a.bookmarks {
+		letters = append(letters, letter)
+	}
+	sort.Strings(letters)
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(" Marcadores ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for i, letter := range letters {
+		table.SetCell(i, 0, tview.NewTableCell("'"+letter).SetTextColor(tcell.ColorYellow))
+		table.SetCell(i, 1, tview.NewTableCell(tview.Escape(a.bookmarks[letter])).SetExpansion(1))
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("bookmarks")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		closeDialog()
+		a.jumpToBookmark(rune(letters[row][0]))
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyDelete:
+			delete(a.bookmarks, letters[row])
+			if err := SaveBookmarks(a.bookmarks); err != nil {
+				a.showError(fmt.Sprintf("Erro ao salvar marcadores: %v", err))
+			}
+			closeDialog()
+			if len(a.bookmarks) > 0 {
+				a.showBookmarks()
+			}
+			return nil
+		case tcell.KeyRune:
+			if r := event.Rune(); isBookmarkLetter(r) {
+				if _, ok := a.bookmarks[string(r)]; ok {
+					closeDialog()
+					a.jumpToBookmark(r)
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	a.pages.AddPage("bookmarks", a.modal(table, 80, min(len(letters)+2, 28)), true, true)
+	a.app.SetFocus(table)
+}
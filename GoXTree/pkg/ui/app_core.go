@@ -5,11 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/peder1981/GoXTree/pkg/viewer"
 	"github.com/rivo/tview"
-	"github.com/sergi/go-diff/diffmatchpatch"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -30,6 +31,43 @@ type App struct {
 	selectedFiles  map[string]bool
 	clipboard      string
 	clipboardIsDir bool
+	theme          string // Nome do tema ativo ("retro", "modern", "dark", "light")
+	viewMode       ViewMode
+
+	// Painel duplo (estilo commander)
+	horizontalLayout *tview.Flex
+	panels           [2]*FileView
+	otherPanel       *panelState
+	dualPane         bool
+
+	// Observador de alterações no sistema de arquivos
+	watcher *utils.DirWatcher
+
+	// Operações de arquivo em segundo plano
+	jobs      *utils.JobManager
+	jobsPanel *jobsPanel
+
+	// Pilha de operações que podem ser desfeitas (Ctrl+Z)
+	undo *utils.UndoStack
+
+	// Diretório temporário para arquivos extraídos de arquivos compactados
+	tempDir string
+
+	// Marcadores (m+letra / '+letra) e diretórios visitados, salvos ao lado de config.json
+	bookmarks          Bookmarks
+	frecency           *utils.FrecencyDB
+	visitsSinceSave    int
+	pendingBookmarkKey rune
+
+	// Índice de arquivos do localizador aproximado (Ctrl+P)
+	fileIndex       *utils.FileIndex
+	fileIndexHidden bool
+
+	// Integração com o git: raiz do repositório de cada diretório consultado
+	// ("" fora de repositórios) e situação de cada repositório
+	gitRoots     map[string]string
+	gitStates    map[string]*gitState
+	gitRedrawing bool // Redesenho após uma leitura; não dispara novas leituras pelo prazo
 }
 
 // Clipboard representa a área de transferência
@@ -47,6 +85,39 @@ const (
 	ViewModeDetails
 )
 
+// viewModeNames são os nomes dos modos de visualização na configuração
+var viewModeNames = []string{"tree", "flat", "details"}
+
+// String retorna o nome do modo usado na configuração
+func (m ViewMode) String() string {
+	if m < 0 || int(m) >= len(viewModeNames) {
+		return viewModeNames[ViewModeTree]
+	}
+	return viewModeNames[m]
+}
+
+// Label retorna a descrição do modo exibida ao usuário
+func (m ViewMode) Label() string {
+	switch m {
+	case ViewModeFlat:
+		return "Ramo (todos os arquivos abaixo do diretório)"
+	case ViewModeDetails:
+		return "Detalhes (lista em tela cheia)"
+	default:
+		return "Árvore"
+	}
+}
+
+// ParseViewMode converte o nome da configuração; nomes desconhecidos viram ViewModeTree
+func ParseViewMode(name string) ViewMode {
+	for i, n := range viewModeNames {
+		if n == name {
+			return ViewMode(i)
+		}
+	}
+	return ViewModeTree
+}
+
 // Constantes para os tipos de foco
 const (
 	focusTree   = iota // Foco na árvore de diretórios
@@ -77,8 +148,14 @@ func NewApp() *App {
 	// Criar componentes
 	app.treeView = NewTreeView(app)
 	app.fileView = NewFileView(app)
+	app.panels = [2]*FileView{app.fileView, NewFileView(app)}
 	app.statusBar = NewStatusBar()
+	app.statusBar.SetTaggedCounter(func() int { return len(app.selectedFiles) })
+	app.statusBar.SetFilterSource(func() string { return app.fileView.Filter().String() })
+	app.statusBar.SetGitSource(app.gitSummary)
 	app.menuBar = NewMenuBar(app)
+	app.initJobs()
+	app.undo = utils.NewUndoStack(utils.DefaultUndoLimit)
 
 	// Carregar configuração
 	config, err := LoadConfig()
@@ -95,6 +172,16 @@ func NewApp() *App {
 		}
 		// Aplicar outras configurações
 		app.showHidden = config.ShowHidden
+		app.viewMode = ParseViewMode(config.ViewMode)
+		app.setColumns(config.Columns)
+	}
+
+	// Carregar marcadores e diretórios visitados
+	if app.bookmarks, err = LoadBookmarks(); err != nil {
+		app.showError(fmt.Sprintf("Erro ao carregar marcadores: %s", err))
+	}
+	if app.frecency, err = LoadFrecency(); err != nil {
+		app.showError(fmt.Sprintf("Erro ao carregar diretórios visitados: %s", err))
 	}
 
 	// Configurar layout principal como vertical (Row)
@@ -111,12 +198,15 @@ func NewApp() *App {
 	app.mainLayout.AddItem(app.menuBar.menuBar, 1, 0, false)
 
 	// Criar layout horizontal para árvore e lista de arquivos
-	horizontalLayout := tview.NewFlex().SetDirection(tview.FlexColumn)
-	horizontalLayout.AddItem(app.treeView.TreeView, 0, 1, true)
-	horizontalLayout.AddItem(app.fileView.fileList, 0, 2, false)
+	app.horizontalLayout = tview.NewFlex().SetDirection(tview.FlexColumn)
+	app.horizontalLayout.AddItem(app.treeView.TreeView, 0, 1, true)
+	app.horizontalLayout.AddItem(app.fileView.fileList, 0, 2, false)
+
+	// Modo de visualização salvo (no modo detalhes a árvore fica escondida)
+	app.setViewMode(app.viewMode)
 
 	// Adicionar o layout horizontal ao layout principal
-	app.mainLayout.AddItem(horizontalLayout, 0, 1, true)
+	app.mainLayout.AddItem(app.horizontalLayout, 0, 1, true)
 
 	// Adicionar barra de status
 	app.mainLayout.AddItem(app.statusBar.statusBar, 2, 0, false)
@@ -166,6 +256,13 @@ func (a *App) Run() error {
 	// Definir foco inicial
 	a.app.SetFocus(a.treeView.TreeView)
 
+	// Observar o sistema de arquivos enquanto a aplicação estiver em execução
+	a.startWatcher()
+	defer a.stopWatcher()
+	defer a.removeTempDir()
+	defer utils.CloseSFTPConnections()
+	defer a.saveFrecency()
+
 	// Iniciar aplicação
 	return a.app.SetRoot(a.pages, true).Run()
 }
@@ -173,6 +270,11 @@ func (a *App) Run() error {
 // SetupKeyHandlers configura os manipuladores de teclas
 func (a *App) SetupKeyHandlers() {
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Os visualizadores, o editor e a lista de marcados usam Tab, teclas de função e Ctrl+letra
+		if front, _ := a.pages.GetFrontPage(); front == "fileView" || front == "fileEdit" || front == "tagged" {
+			return event
+		}
+
 		// Verificar se alguma tecla de função foi pressionada
 		switch event.Key() {
 		case tcell.KeyF1:
@@ -187,11 +289,25 @@ func (a *App) SetupKeyHandlers() {
 		case tcell.KeyF4:
 			a.advancedSearch()
 			return nil
+		case tcell.KeyF5:
+			a.copyToOtherPanel()
+			return nil
+		case tcell.KeyF6:
+			a.moveToOtherPanel()
+			return nil
 		case tcell.KeyF7:
 			a.createDirectory()
 			return nil
 		case tcell.KeyF8:
-			a.deleteFile()
+			// Shift+F8 exclui definitivamente, sem passar pela lixeira
+			if event.Modifiers()&tcell.ModShift != 0 {
+				a.deleteFilePermanently()
+			} else {
+				a.deleteFile()
+			}
+			return nil
+		case tcell.KeyF20: // Shift+F8 em terminais que não informam o modificador
+			a.deleteFilePermanently()
 			return nil
 		case tcell.KeyF9:
 			// Sincronizar diretórios
@@ -203,6 +319,24 @@ func (a *App) SetupKeyHandlers() {
 		case tcell.KeyTab:
 			a.toggleFocus()
 			return nil
+		case tcell.KeyCtrlZ:
+			// Desfazer apenas nas listas; o editor usa Ctrl+Z para o próprio texto
+			if focus := a.app.GetFocus(); focus == a.fileView.fileList || focus == a.treeView.TreeView {
+				a.undoLast()
+				return nil
+			}
+		case tcell.KeyCtrlS:
+			// Todos os itens marcados, de qualquer diretório (marcar é com Espaço)
+			if focus := a.app.GetFocus(); focus == a.fileView.fileList || focus == a.treeView.TreeView {
+				a.showTaggedFiles()
+				return nil
+			}
+		case tcell.KeyCtrlP:
+			// Localizador aproximado apenas nas listas; dentro dele Ctrl+P sobe na lista
+			if focus := a.app.GetFocus(); focus == a.fileView.fileList || focus == a.treeView.TreeView {
+				a.showFinder()
+				return nil
+			}
 		case tcell.KeyEscape:
 			// Verificar se estamos na tela principal ou em uma tela de diálogo
 			if a.pages.HasPage("help") {
@@ -283,6 +417,51 @@ func (a *App) SetupKeyHandlers() {
 			case 'c', 'C': // Alt+C: Comparar arquivos selecionados
 				a.compareSelectedFiles()
 				return nil
+			case 'p', 'P': // Alt+P: Alternar painel duplo
+				a.toggleDualPane()
+				return nil
+			case 'j', 'J': // Alt+J: Operações em segundo plano
+				a.showJobsPanel()
+				return nil
+			case 't', 'T': // Alt+T: Lixeira
+				a.showTrash()
+				return nil
+			case 'x', 'X': // Alt+X: Extrair de arquivo compactado
+				a.extractSelected()
+				return nil
+			case 'z', 'Z': // Alt+Z: Criar arquivo compactado
+				a.showCreateArchiveDialog()
+				return nil
+			case 'r', 'R': // Alt+R: Renomear em lote
+				a.showBulkRenameDialog()
+				return nil
+			case 'g', 'G': // Alt+G: Ir para diretório frequente
+				a.showJumpDialog()
+				return nil
+			case 'b', 'B': // Alt+B: Marcadores
+				a.showBookmarks()
+				return nil
+			case 'h', 'H': // Alt+H: Editor hexadecimal
+				a.hexEditFile()
+				return nil
+			case 'a', 'A': // Alt+A: Permissões e dono
+				a.showAttributesDialog()
+				return nil
+			case 'm', 'M': // Alt+M: Modo de visualização (árvore, ramo, detalhes)
+				a.cycleViewMode()
+				return nil
+			case 'o', 'O': // Alt+O: Colunas da lista de arquivos
+				a.showColumnsDialog()
+				return nil
+			case 'f', 'F': // Alt+F: Filtro da listagem
+				a.showFilterDialog()
+				return nil
+			case 'u', 'U': // Alt+U: Preparar/retirar do commit (git add)
+				a.toggleGitStage()
+				return nil
+			case 'd', 'D': // Alt+D: Diferenças em relação ao HEAD do git
+				a.diffWithHead()
+				return nil
 			}
 		}
 
@@ -322,7 +501,12 @@ func (a *App) setFocus(p tview.Primitive) {
 
 // confirmExit confirma a saída da aplicação
 func (a *App) confirmExit() {
-	a.showConfirmDialog("Sair", "Deseja realmente sair da aplicação?", func(confirmed bool) {
+	message := "Deseja realmente sair da aplicação?"
+	if active := a.jobs.Active(); active > 0 {
+		message = fmt.Sprintf("Há %d operação(ões) em andamento que serão interrompidas. Deseja realmente sair?", active)
+	}
+
+	a.showConfirmDialog("Sair", message, func(confirmed bool) {
 		if confirmed {
 			a.app.Stop()
 		}
@@ -402,7 +586,7 @@ func (a *App) viewFile() {
 	}
 
 	// Verificar se é um diretório
-	fileInfo, err := os.Stat(filepath.Join(a.currentDir, selectedFile))
+	fileInfo, err := utils.Stat(filepath.Join(a.currentDir, selectedFile))
 	if err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar arquivo: %v", err))
 		return
@@ -413,37 +597,41 @@ func (a *App) viewFile() {
 		return
 	}
 
-	// Ler conteúdo do arquivo
-	content, err := os.ReadFile(filepath.Join(a.currentDir, selectedFile))
+	// Arquivos dentro de arquivos compactados são extraídos antes
+	filePath, err := a.localFile(filepath.Join(a.currentDir, selectedFile))
 	if err != nil {
-		a.showError(fmt.Sprintf("Erro ao ler arquivo: %v", err))
+		a.showError(fmt.Sprintf("Erro ao extrair arquivo: %v", err))
 		return
 	}
 
-	// Exibir conteúdo
-	textView := tview.NewTextView().
-		SetText(string(content)).
-		SetScrollable(true).
-		SetBorder(true).
-		SetTitle(filepath.Base(selectedFile)).
-		SetTitleAlign(tview.AlignCenter)
+	// Exibir com destaque de sintaxe (texto), como imagem ou em hexadecimal
+	if err := NewFileViewer(a).ViewFile(filePath); err != nil {
+		a.showError(err.Error())
+	}
+}
 
-	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("fileView")
-			return nil
-		}
-		return event
-	})
+// hexEditFile abre o arquivo selecionado no editor hexadecimal
+func (a *App) hexEditFile() {
+	selectedFile := a.fileView.GetSelectedFile()
+	if selectedFile == "" || selectedFile == ".." {
+		a.showError("Nenhum arquivo selecionado")
+		return
+	}
 
-	a.pages.AddPage("fileView", textView, true, true)
-	a.app.SetFocus(textView)
+	filePath := filepath.Join(a.currentDir, selectedFile)
+	if !utils.IsLocalPath(filePath) {
+		a.showError("A edição hexadecimal só é possível em arquivos locais")
+		return
+	}
+	if err := NewFileViewer(a).EditHex(filePath); err != nil {
+		a.showError(err.Error())
+	}
 }
 
 // navigateTo navega para um diretório específico
 func (a *App) navigateTo(dir string) {
-	// Verificar se o diretório existe
-	fileInfo, err := os.Stat(dir)
+	// Verificar se o diretório existe (ou se é um arquivo compactado)
+	fileInfo, err := utils.Stat(dir)
 	if err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar diretório: %s", err))
 		return
@@ -461,6 +649,7 @@ func (a *App) navigateTo(dir string) {
 	a.fileView.SetCurrentDir(dir)
 	a.addToHistory(dir)
 	a.statusBar.UpdateStatus(dir)
+	a.updateWatches()
 }
 
 // handleKeyEvents manipula eventos de teclado
@@ -524,16 +713,12 @@ func (a *App) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 	case tcell.KeyCtrlE:
 		// Editar arquivo
-		a.editCurrentFile()
+		a.editFile()
 		return nil
 	case tcell.KeyCtrlY:
 		// Sincronizar diretórios
 		a.syncDirectories()
 		return nil
-	case tcell.KeyCtrlS:
-		// Selecionar arquivo
-		a.toggleSelection()
-		return nil
 	case tcell.KeyCtrlI:
 		// Informações do sistema
 		a.showSystemInfo()
@@ -553,6 +738,11 @@ func (a *App) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
 
 // handleFileViewKeys manipula teclas na visualização de arquivos
 func (a *App) handleFileViewKeys(event *tcell.EventKey) *tcell.EventKey {
+	// Marcadores de diretório
+	if a.handleBookmarkKeys(event) == nil {
+		return nil
+	}
+
 	// Verificar teclas de navegação
 	switch event.Key() {
 	case tcell.KeyUp:
@@ -600,6 +790,11 @@ func (a *App) handleFileViewKeys(event *tcell.EventKey) *tcell.EventKey {
 
 // handleTreeViewKeys manipula teclas na visualização de árvore
 func (a *App) handleTreeViewKeys(event *tcell.EventKey) *tcell.EventKey {
+	// Marcadores de diretório
+	if a.handleBookmarkKeys(event) == nil {
+		return nil
+	}
+
 	switch event.Key() {
 	case tcell.KeyEnter:
 		// Navegar para o diretório selecionado
@@ -672,14 +867,16 @@ func (a *App) showMessage(message string) {
 
 // refreshView atualiza a visualização
 func (a *App) refreshView() {
+	a.invalidateGitStatus()
 	a.treeView.Refresh()
 	a.updateFileList()
+	a.refreshOtherPanel()
 	a.statusBar.UpdateStatus(a.currentDir)
 }
 
 // updateFileList atualiza a lista de arquivos
 func (a *App) updateFileList() {
-	files, err := utils.ListFiles(a.currentDir, a.showHidden)
+	files, err := a.fileView.listFiles(a.showHidden)
 	if err != nil {
 		a.showError("Erro ao listar arquivos: " + err.Error())
 		return
@@ -699,14 +896,14 @@ func (a *App) advancedSearch() {
 
 // syncDirectories sincroniza dois diretórios
 func (a *App) syncDirectories() {
-	a.showMessage("Sincronização de diretórios não implementada")
+	a.showSyncDialog()
 }
 
 // goToDirectory abre o diálogo para ir para um diretório específico
 func (a *App) goToDirectory() {
 	a.showInputDialog("Ir para Diretório", "", func(path string) {
-		// Verificar se o diretório existe
-		fileInfo, err := os.Stat(path)
+		// Verificar se o diretório existe (ou se é um caminho dentro de um arquivo compactado)
+		fileInfo, err := utils.Stat(path)
 		if err != nil {
 			a.showError(fmt.Sprintf("Erro ao acessar diretório: %s", err))
 			return
@@ -736,6 +933,12 @@ func (a *App) toggleHiddenFiles() {
 
 // renameFile abre o diálogo para renomear um arquivo
 func (a *App) renameFile() {
+	// Com vários itens marcados, renomear em lote
+	if len(a.selectedFiles) > 1 {
+		a.showBulkRenameDialog()
+		return
+	}
+
 	// Obter arquivo selecionado
 	selectedFile := a.fileView.GetSelectedFile()
 	if selectedFile == "" {
@@ -746,8 +949,12 @@ func (a *App) renameFile() {
 	// Obter caminho completo
 	oldPath := filepath.Join(a.currentDir, selectedFile)
 
+	if a.checkReadOnly(a.currentDir) {
+		return
+	}
+
 	// Verificar se o arquivo existe
-	if _, err := os.Stat(oldPath); err != nil {
+	if _, err := utils.LstatFS(utils.FSFor(oldPath), oldPath); err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar arquivo: %v", err))
 		return
 	}
@@ -768,53 +975,129 @@ func (a *App) renameFile() {
 		newPath := filepath.Join(a.currentDir, newName)
 
 		// Verificar se já existe um arquivo com o novo nome
-		if _, err := os.Stat(newPath); err == nil {
-			a.showConfirmDialog("Confirmar substituição", fmt.Sprintf("Já existe um arquivo ou diretório com o nome '%s'. Deseja substituí-lo?", newName), func(confirmed bool) {
+		if _, err := utils.LstatFS(utils.FSFor(newPath), newPath); err == nil {
+			question := fmt.Sprintf("Já existe um arquivo ou diretório com o nome '%s'. Deseja substituí-lo?", newName)
+			if !utils.IsLocalPath(newPath) {
+				question = fmt.Sprintf("Já existe um arquivo ou diretório com o nome '%s'. Deseja substituí-lo? Ele será excluído definitivamente (sem lixeira).", newName)
+			}
+			a.showConfirmDialog("Confirmar substituição", question, func(confirmed bool) {
 				if confirmed {
-					// Remover arquivo existente
-					if err := os.RemoveAll(newPath); err != nil {
-						a.showError(fmt.Sprintf("Erro ao remover arquivo existente: %v", err))
-						return
-					}
-
-					// Renomear arquivo
-					if err := os.Rename(oldPath, newPath); err != nil {
-						a.showError(fmt.Sprintf("Erro ao renomear: %v", err))
-						return
-					}
-
-					a.refreshCurrentDir()
-					a.showMessage(fmt.Sprintf("'%s' renomeado para '%s'", selectedFile, newName))
+					a.doRename(oldPath, newPath, true)
 				}
 			})
 		} else {
-			// Renomear arquivo
-			if err := os.Rename(oldPath, newPath); err != nil {
-				a.showError(fmt.Sprintf("Erro ao renomear: %v", err))
-				return
-			}
-
-			a.refreshCurrentDir()
-			a.showMessage(fmt.Sprintf("'%s' renomeado para '%s'", selectedFile, newName))
+			a.doRename(oldPath, newPath, false)
 		}
 	})
 }
 
+// doRename renomeia um arquivo e registra a operação na pilha de desfazer.
+// Com replace, o item existente com o novo nome vai para a lixeira (fora do
+// disco local ele é excluído, sem como desfazer).
+func (a *App) doRename(oldPath, newPath string, replace bool) {
+	oldName, newName := filepath.Base(oldPath), filepath.Base(newPath)
+	undo := utils.UndoOperation{Description: fmt.Sprintf("Renomear '%s' para '%s'", oldName, newName)}
+
+	if replace && utils.IsArchivePath(newPath) {
+		a.showError("Arquivos compactados são somente leitura")
+		return
+	}
+	if replace && !utils.IsLocalPath(newPath) {
+		if err := utils.RemoveAllFS(utils.FSFor(newPath), newPath); err != nil {
+			a.showError(fmt.Sprintf("Erro ao remover arquivo existente: %v", err))
+			return
+		}
+	} else if replace {
+		// Guardar o arquivo existente na lixeira
+		entry, err := utils.MoveToTrash(newPath)
+		if err != nil {
+			a.showError(fmt.Sprintf("Erro ao remover arquivo existente: %v", err))
+			return
+		}
+		undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRestoreTrash, Path: newPath, OriginalPath: newPath, Trash: entry})
+	}
+
+	// Renomear arquivo
+	if err := utils.FSFor(oldPath).Rename(oldPath, newPath); err != nil {
+		a.undo.Push(undo)
+		a.showError(fmt.Sprintf("Erro ao renomear: %v", err))
+		return
+	}
+	undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoMoveBack, Path: newPath, OriginalPath: oldPath})
+	a.undo.Push(undo)
+
+	a.refreshCurrentDir()
+	a.showMessage(fmt.Sprintf("'%s' renomeado para '%s'", oldName, newName))
+}
+
 // createDirectory abre o diálogo para criar um diretório
 func (a *App) createDirectory() {
 	a.showMessage("Criar diretório não implementado")
 }
 
-// deleteFile abre o diálogo para excluir um arquivo
+// deleteFile envia para a lixeira os arquivos selecionados (ou o item sob o cursor)
 func (a *App) deleteFile() {
-	a.showMessage("Excluir arquivo não implementado")
+	a.confirmDelete(false)
+}
+
+// deleteFilePermanently exclui definitivamente os arquivos selecionados (Shift+F8)
+func (a *App) deleteFilePermanently() {
+	a.confirmDelete(true)
+}
+
+// confirmDelete pede confirmação e exclui os arquivos em segundo plano
+func (a *App) confirmDelete(permanent bool) {
+	files := a.panelTargets()
+	if len(files) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+
+	// Itens de arquivos compactados não podem ser excluídos; sistemas de
+	// arquivos remotos ou em memória não têm lixeira
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		if utils.IsArchivePath(path) {
+			a.showError("Arquivos compactados são somente leitura. Use Alt+X para extrair.")
+			return
+		}
+		if !utils.IsLocalPath(path) {
+			permanent = true
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	target := fmt.Sprintf("%d item(ns)", len(paths))
+	if len(paths) == 1 {
+		target = fmt.Sprintf("'%s'", filepath.Base(paths[0]))
+	}
+	message := fmt.Sprintf("Mover %s para a lixeira?", target)
+	if permanent {
+		message = fmt.Sprintf("Excluir %s definitivamente? Esta operação não pode ser desfeita.", target)
+	}
+
+	a.showConfirmDialog("Excluir", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		a.selectedFiles = make(map[string]bool)
+		a.startDeleteJob(paths, permanent)
+	})
 }
 
 // toggleFocus alterna o foco entre a árvore e a lista de arquivos
+// (com o painel duplo ligado: árvore -> painel ativo -> outro painel)
 func (a *App) toggleFocus() {
-	if a.app.GetFocus() == a.treeView.TreeView {
+	switch {
+	case a.app.GetFocus() == a.treeView.TreeView:
 		a.app.SetFocus(a.fileView.fileList)
-	} else {
+	case a.dualPane && a.app.GetFocus() == a.fileView.fileList:
+		a.switchPanel()
+	case a.viewMode == ViewModeDetails:
+		// Sem a árvore, o foco volta para a lista
+		a.app.SetFocus(a.fileView.fileList)
+	default:
 		a.app.SetFocus(a.treeView.TreeView)
 	}
 }
@@ -905,12 +1188,30 @@ func (a *App) showFileInfo() {
 
 // editFile abre o arquivo selecionado para edição
 func (a *App) editFile() {
-	// Implementar edição de arquivo
-	a.showMessage("Editando arquivo (não implementado)")
+	selectedFile := a.fileView.GetSelectedFile()
+	if selectedFile == "" || selectedFile == ".." {
+		a.showError("Nenhum arquivo selecionado")
+		return
+	}
+
+	filePath := filepath.Join(a.currentDir, selectedFile)
+	if !utils.IsLocalPath(filePath) {
+		a.showError("A edição só é possível em arquivos locais")
+		return
+	}
+	if err := NewFileEditor(a).EditFile(filePath); err != nil {
+		a.showError(err.Error())
+	}
 }
 
 // compareSelectedFiles compara os arquivos selecionados
 func (a *App) compareSelectedFiles() {
+	// Com o painel duplo, comparar com o item de mesmo nome no outro painel
+	if a.dualPane && len(a.selectedFiles) != 2 {
+		a.comparePanels()
+		return
+	}
+
 	// Verificar se há exatamente dois arquivos selecionados
 	if len(a.selectedFiles) != 2 {
 		a.showError("Selecione exatamente dois arquivos para comparar")
@@ -924,12 +1225,12 @@ func (a *App) compareSelectedFiles() {
 	}
 
 	// Verificar se ambos são arquivos (não diretórios)
-	file1Info, err := os.Stat(files[0])
+	file1Info, err := utils.Stat(files[0])
 	if err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar arquivo: %s", err))
 		return
 	}
-	file2Info, err := os.Stat(files[1])
+	file2Info, err := utils.Stat(files[1])
 	if err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar arquivo: %s", err))
 		return
@@ -943,59 +1244,53 @@ func (a *App) compareSelectedFiles() {
 	a.compareFiles(files[0], files[1])
 }
 
-// compareFiles compara dois arquivos e exibe as diferenças
+// compareFiles compara dois arquivos e exibe as diferenças lado a lado
 func (a *App) compareFiles(file1, file2 string) {
-	// Ler conteúdo dos arquivos
-	content1, err := os.ReadFile(file1)
-	if err != nil {
-		a.showError(fmt.Sprintf("Erro ao ler arquivo %s: %v", file1, err))
-		return
-	}
-
-	content2, err := os.ReadFile(file2)
-	if err != nil {
-		a.showError(fmt.Sprintf("Erro ao ler arquivo %s: %v", file2, err))
+	diffViewer := viewer.NewDiffViewer(a.app)
+	if err := diffViewer.LoadFiles(file1, file2); err != nil {
+		a.showError(fmt.Sprintf("Erro ao comparar arquivos: %v", err))
 		return
 	}
 
-	// Criar diff
-	dmp := diffmatchpatch.New()
-	diffs := dmp.DiffMain(string(content1), string(content2), false)
-	diffText := dmp.DiffPrettyText(diffs)
-
-	// Criar visualizador de texto
-	textView := tview.NewTextView().
-		SetDynamicColors(true).
-		SetRegions(true).
-		SetScrollable(true).
-		SetText(diffText)
-
-	// Configurar visualizador
-	textView.SetBorder(true).
-		SetTitle(fmt.Sprintf(" Comparando: %s <-> %s ", filepath.Base(file1), filepath.Base(file2))).
-		SetTitleAlign(tview.AlignCenter)
+	// Ao fechar, voltar para onde o diff foi aberto (lista de arquivos ou comparação de diretórios)
+	previousFocus := a.app.GetFocus()
+	diffViewer.SetCloseFunc(func() {
+		a.pages.RemovePage("compare")
+		a.app.SetFocus(previousFocus)
+	})
+	diffViewer.SetExportFunc(func(unified string) {
+		a.exportDiff(file1, file2, unified)
+	})
 
-	// Criar modal
-	modal := tview.NewFlex().
-		AddItem(nil, 0, 1, false).
-		AddItem(tview.NewFlex().
-			SetDirection(tview.FlexRow).
-			AddItem(nil, 0, 1, false).
-			AddItem(textView, 0, 1, true).
-			AddItem(nil, 0, 1, false), 0, 1, true).
-		AddItem(nil, 0, 1, false)
+	a.pages.AddPage("compare", diffViewer.Show(), true, true)
+	a.app.SetFocus(diffViewer.Show())
+}
 
-	// Adicionar à página
-	a.pages.AddPage("compare", modal, true, true)
-	a.app.SetFocus(textView)
+// exportDiff salva a comparação no formato diff unificado
+func (a *App) exportDiff(file1, file2, unified string) {
+	if unified == "" {
+		a.showMessage("Os arquivos são iguais")
+		return
+	}
 
-	// Adicionar manipulador de teclas para fechar
-	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter {
-			a.pages.RemovePage("compare")
-			return nil
+	// Padrão: <arquivo>.diff no diretório atual
+	defaultPath := filepath.Join(a.currentDir, filepath.Base(file2)+".diff")
+	a.showInputDialogWithValue("Exportar diff para", defaultPath, func(path string) {
+		if path == "" {
+			return
 		}
-		return event
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(a.currentDir, path)
+		}
+		if a.checkReadOnly(filepath.Dir(path)) {
+			return
+		}
+		if err := utils.WriteFileFS(utils.FSFor(path), path, []byte(unified), 0644); err != nil {
+			a.showError(fmt.Sprintf("Erro ao exportar diff: %v", err))
+			return
+		}
+		a.refreshFileView()
+		a.statusBar.SetStatus(fmt.Sprintf("Diff exportado para %s", path))
 	})
 }
 
@@ -1009,6 +1304,9 @@ func (a *App) addToHistory(dir string) {
 	// Adicionar diretório ao histórico
 	a.history = append(a.history, dir)
 	a.historyPos = len(a.history) - 1
+
+	// Registrar a visita para o salto por frecência (Alt+G)
+	a.recordVisit(dir)
 }
 
 // sortByName ordena os arquivos por nome
@@ -38,7 +38,7 @@ Teclas de Controle:
   Ctrl+G - Ir para diretório
   Ctrl+R - Atualizar visualizações
   Ctrl+H - Mostrar/ocultar arquivos ocultos
-  Ctrl+S - Selecionar arquivo
+  Ctrl+S - Mostrar todos os marcados
   Ctrl+A - Selecionar todos os arquivos
   Ctrl+D - Desmarcar todos os arquivos
   Ctrl+C - Comparar arquivos selecionados
@@ -93,6 +93,13 @@ func (a *App) showGotoDialog() {
 			return
 		}
 
+		// Endereços sftp:// abrem uma conexão remota
+		if strings.HasPrefix(dir, "sftp://") {
+			a.pages.RemovePage("gotoDialog")
+			a.openSFTP(dir)
+			return
+		}
+
 		// Expandir caminho
 		if strings.HasPrefix(dir, "~") {
 			homeDir, err := a.getHomeDir()
@@ -116,7 +123,7 @@ func (a *App) showGotoDialog() {
 
 	// Configurar borda
 	form.SetBorder(true).
-		SetTitle(" Ir para Diretório ").
+		SetTitle(" Ir para Diretório (ou sftp://usuario@host/caminho) ").
 		SetTitleAlign(tview.AlignLeft)
 
 	// Configurar manipulador de teclas
@@ -129,7 +136,7 @@ func (a *App) showGotoDialog() {
 	})
 
 	// Exibir diálogo
-	a.pages.AddPage("gotoDialog", a.modal(form, 50, 7), true, true)
+	a.pages.AddPage("gotoDialog", a.modal(form, 60, 7), true, true)
 }
 
 // showMenu exibe o menu principal
@@ -0,0 +1,336 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// dirCompareView é a tela de comparação recursiva de dois diretórios
+type dirCompareView struct {
+	left, right   string
+	options       utils.DirCompareOptions
+	showIdentical bool
+	entries       []utils.DirCompareEntry
+	visible       []int
+	marked        map[string]bool
+	table         *tview.Table
+	status        *tview.TextView
+	layout        *tview.Flex
+}
+
+// compareStatusColor retorna a cor e o símbolo de cada classificação
+func compareStatusColor(s utils.CompareStatus) (tcell.Color, string) {
+	switch s {
+	case utils.CompareIdentical:
+		return tcell.ColorGray, "="
+	case utils.CompareContentDiffers:
+		return tcell.ColorYellow, "≠"
+	case utils.CompareNewerLeft:
+		return tcell.ColorAqua, ">"
+	case utils.CompareNewerRight:
+		return tcell.ColorAqua, "<"
+	case utils.CompareTypeMismatch:
+		return tcell.ColorRed, "!"
+	case utils.CompareOrphanLeft:
+		return tcell.ColorGreen, "←"
+	case utils.CompareOrphanRight:
+		return tcell.ColorGreen, "→"
+	}
+	return tcell.ColorWhite, "?"
+}
+
+// showDirectoryComparison compara dois diretórios recursivamente em segundo plano
+// e exibe o resultado
+func (a *App) showDirectoryComparison(left, right string) {
+	v := &dirCompareView{left: left, right: right, marked: make(map[string]bool)}
+	a.runDirCompare(v)
+}
+
+// compareDirectories compara o diretório atual com o do outro painel ou com
+// um diretório informado pelo usuário
+func (a *App) compareDirectories() {
+	if other := a.otherPanelDir(); other != "" {
+		a.showDirectoryComparison(a.currentDir, other)
+		return
+	}
+
+	a.showInputDialogWithValue("Comparar com o diretório", a.currentDir, func(dir string) {
+		if dir == "" {
+			return
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(a.currentDir, dir)
+		}
+		if info, err := utils.Stat(dir); err != nil || !info.IsDir() {
+			a.showError(fmt.Sprintf("'%s' não é um diretório", dir))
+			return
+		}
+		a.showDirectoryComparison(a.currentDir, dir)
+	})
+}
+
+// runDirCompare executa (ou refaz) a comparação e atualiza a tela ao término
+func (a *App) runDirCompare(v *dirCompareView) {
+	var entries []utils.DirCompareEntry
+	name := fmt.Sprintf("Comparar %s e %s", filepath.Base(v.left), filepath.Base(v.right))
+
+	a.submitJob(name, func(jc *utils.JobContext) error {
+		var err error
+		entries, err = utils.CompareDirectoryTrees(jc, v.left, v.right, v.options)
+		return err
+	}, func(s utils.JobSnapshot) {
+		if s.Status != utils.JobDone {
+			return
+		}
+		v.entries = entries
+		if v.layout == nil {
+			a.buildDirCompareView(v)
+		}
+		a.loadDirCompareView(v)
+	})
+}
+
+// buildDirCompareView cria a tabela e os atalhos da tela de comparação
+func (a *App) buildDirCompareView(v *dirCompareView) {
+	v.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	v.table.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ⇄ %s ", v.left, v.right)).
+		SetTitleAlign(tview.AlignLeft)
+
+	v.status = tview.NewTextView().SetDynamicColors(true)
+	v.status.SetTextColor(utils.ColorStatusText)
+	v.status.SetBackgroundColor(utils.ColorStatusBar)
+
+	v.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 1, false)
+
+	closeView := func() {
+		a.pages.RemovePage("dirCompare")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyEnter:
+			a.diffCompareEntry(v)
+			return nil
+		}
+
+		switch event.Rune() {
+		case ' ':
+			if entry, ok := v.current(); ok {
+				if v.marked[entry.RelPath] {
+					delete(v.marked, entry.RelPath)
+				} else {
+					v.marked[entry.RelPath] = true
+				}
+				row, _ := v.table.GetSelection()
+				a.loadDirCompareView(v)
+				v.table.Select(min(row+1, len(v.visible)), 0)
+			}
+			return nil
+		case '*':
+			// Marcar todos os itens diferentes visíveis (ou desmarcar, se já estiverem)
+			if len(v.marked) > 0 {
+				v.marked = make(map[string]bool)
+			} else {
+				for _, i := range v.visible {
+					if v.entries[i].Status != utils.CompareIdentical {
+						v.marked[v.entries[i].RelPath] = true
+					}
+				}
+			}
+			a.loadDirCompareView(v)
+			return nil
+		case '>':
+			a.copyCompareEntries(v, true)
+			return nil
+		case '<':
+			a.copyCompareEntries(v, false)
+			return nil
+		case 'd', 'D':
+			a.diffCompareEntry(v)
+			return nil
+		case 'i', 'I':
+			v.showIdentical = !v.showIdentical
+			a.loadDirCompareView(v)
+			return nil
+		case 'b', 'B':
+			v.options.ByteCompare = !v.options.ByteCompare
+			a.runDirCompare(v)
+			return nil
+		case 'h', 'H':
+			v.options.IncludeHidden = !v.options.IncludeHidden
+			a.runDirCompare(v)
+			return nil
+		case 'r', 'R':
+			a.runDirCompare(v)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("dirCompare", v.layout, true, true)
+	a.app.SetFocus(v.table)
+}
+
+// loadDirCompareView preenche a tabela com os itens da comparação
+func (a *App) loadDirCompareView(v *dirCompareView) {
+	row, _ := v.table.GetSelection()
+	v.table.Clear()
+
+	headers := []string{"", "Caminho", "Situação", "Tamanho (esq.)", "Data (esq.)", "Tamanho (dir.)", "Data (dir.)"}
+	for col, header := range headers {
+		v.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	counts := make(map[utils.CompareStatus]int)
+	v.visible = v.visible[:0]
+	for i, entry := range v.entries {
+		counts[entry.Status]++
+		if entry.Status == utils.CompareIdentical && !v.showIdentical {
+			continue
+		}
+		v.visible = append(v.visible, i)
+
+		color, symbol := compareStatusColor(entry.Status)
+		name := entry.RelPath
+		if entry.IsDir() {
+			name += string(filepath.Separator)
+		}
+		if v.marked[entry.RelPath] {
+			symbol = "*" + symbol
+		}
+
+		r := len(v.visible)
+		v.table.SetCell(r, 0, tview.NewTableCell(symbol).SetTextColor(color))
+		v.table.SetCell(r, 1, tview.NewTableCell(name).SetTextColor(color).SetExpansion(1))
+		v.table.SetCell(r, 2, tview.NewTableCell(entry.Status.String()).SetTextColor(color))
+		for side, info := range []os.FileInfo{entry.Left, entry.Right} {
+			size, date := "", ""
+			if info != nil {
+				size, date = compareSizeText(info), info.ModTime().Format("02/01/2006 15:04")
+			}
+			v.table.SetCell(r, 3+side*2, tview.NewTableCell(size).SetAlign(tview.AlignRight))
+			v.table.SetCell(r, 4+side*2, tview.NewTableCell(date))
+		}
+	}
+
+	if len(v.visible) == 0 {
+		v.table.SetCell(1, 1, tview.NewTableCell("Nenhuma diferença encontrada").SetSelectable(false))
+	}
+	v.table.Select(max(1, min(row, len(v.visible))), 0)
+
+	differ := counts[utils.CompareContentDiffers] + counts[utils.CompareNewerLeft] + counts[utils.CompareNewerRight] + counts[utils.CompareTypeMismatch]
+	mode := "hash"
+	if v.options.ByteCompare {
+		mode = "bytes"
+	}
+	v.status.SetText(fmt.Sprintf(" %d idêntico(s), %d diferente(s), %d só à esquerda, %d só à direita, %d marcado(s) | Comparação: %s  "+
+		"[::b]Espaço/*[-:-:-] Marcar  [::b]>/<[-:-:-] Copiar  [::b]Enter[-:-:-] Diff  [::b]I[-:-:-] Idênticos  [::b]B[-:-:-] Bytes  [::b]H[-:-:-] Ocultos  [::b]R[-:-:-] Refazer",
+		counts[utils.CompareIdentical], differ, counts[utils.CompareOrphanLeft], counts[utils.CompareOrphanRight], len(v.marked), mode))
+}
+
+// compareSizeText formata o tamanho de um item (diretórios não têm tamanho)
+func compareSizeText(info os.FileInfo) string {
+	if info.IsDir() {
+		return "<DIR>"
+	}
+	return utils.FormatFileSize(info.Size())
+}
+
+// current retorna o item sob o cursor
+func (v *dirCompareView) current() (utils.DirCompareEntry, bool) {
+	row, _ := v.table.GetSelection()
+	if row < 1 || row > len(v.visible) {
+		return utils.DirCompareEntry{}, false
+	}
+	return v.entries[v.visible[row-1]], true
+}
+
+// targets retorna os itens marcados ou, sem marcação, o item sob o cursor
+func (v *dirCompareView) targets() []utils.DirCompareEntry {
+	if len(v.marked) == 0 {
+		if entry, ok := v.current(); ok {
+			return []utils.DirCompareEntry{entry}
+		}
+		return nil
+	}
+
+	var entries []utils.DirCompareEntry
+	for _, entry := range v.entries {
+		if v.marked[entry.RelPath] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// copyCompareEntries copia os itens da esquerda para a direita (ou o contrário).
+// Itens substituídos vão para a lixeira e a cópia pode ser desfeita.
+func (a *App) copyCompareEntries(v *dirCompareView, leftToRight bool) {
+	from, to := v.left, v.right
+	if !leftToRight {
+		from, to = v.right, v.left
+	}
+	if a.checkReadOnly(to) {
+		return
+	}
+
+	var transfers []jobTransfer
+	for _, entry := range v.targets() {
+		source := entry.Left
+		if !leftToRight {
+			source = entry.Right
+		}
+		if source == nil || entry.Status == utils.CompareIdentical {
+			continue
+		}
+		transfers = append(transfers, jobTransfer{
+			src:  filepath.Join(from, entry.RelPath),
+			dest: filepath.Join(to, entry.RelPath),
+		})
+	}
+	if len(transfers) == 0 {
+		a.showMessage("Nada a copiar nesta direção")
+		return
+	}
+
+	a.showConfirmDialog("Copiar", fmt.Sprintf("Copiar %d item(ns) de %s para %s?", len(transfers), from, to), func(confirmed bool) {
+		a.app.SetFocus(v.table)
+		if !confirmed {
+			return
+		}
+		v.marked = make(map[string]bool)
+		a.startTransferJob(transfers, to, false, func(s utils.JobSnapshot) {
+			a.runDirCompare(v)
+		})
+	})
+}
+
+// diffCompareEntry abre a comparação de conteúdo do arquivo sob o cursor
+func (a *App) diffCompareEntry(v *dirCompareView) {
+	entry, ok := v.current()
+	if !ok {
+		return
+	}
+	if entry.Left == nil || entry.Right == nil || entry.IsDir() {
+		a.showMessage("O diff só está disponível para arquivos presentes nos dois lados")
+		return
+	}
+	a.compareFiles(filepath.Join(v.left, entry.RelPath), filepath.Join(v.right, entry.RelPath))
+}
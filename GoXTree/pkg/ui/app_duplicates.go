@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// duplicatesView é a tela com os grupos de arquivos duplicados
+type duplicatesView struct {
+	root    string
+	groups  []utils.DuplicateGroup
+	keep    []int        // Índice do arquivo preservado em cada grupo
+	skipped map[int]bool // Grupos deixados de fora das ações
+	rows    [][2]int     // Grupo e arquivo de cada linha (arquivo -1 = cabeçalho do grupo)
+	policy  utils.KeepPolicy
+	table   *tview.Table
+	status  *tview.TextView
+	layout  *tview.Flex
+}
+
+// findDuplicates procura arquivos duplicados a partir do diretório atual
+func (a *App) findDuplicates() {
+	v := &duplicatesView{root: a.currentDir, skipped: make(map[int]bool)}
+	a.runDuplicateSearch(v)
+}
+
+// runDuplicateSearch executa (ou refaz) a busca em segundo plano e exibe o resultado
+func (a *App) runDuplicateSearch(v *duplicatesView) {
+	var groups []utils.DuplicateGroup
+	name := fmt.Sprintf("Localizar duplicados em %s", filepath.Base(v.root))
+	opts := utils.DuplicateOptions{Recursive: true, IncludeHidden: a.showHidden}
+
+	a.submitJob(name, func(jc *utils.JobContext) error {
+		var err error
+		groups, err = utils.FindDuplicatesJob(jc, v.root, opts)
+		return err
+	}, func(s utils.JobSnapshot) {
+		if s.Status != utils.JobDone {
+			return
+		}
+		v.groups = groups
+		v.skipped = make(map[int]bool)
+		v.applyPolicy(v.policy)
+		if v.layout == nil {
+			a.buildDuplicatesView(v)
+		}
+		a.loadDuplicatesView(v)
+	})
+}
+
+// applyPolicy escolhe o arquivo preservado de todos os grupos
+func (v *duplicatesView) applyPolicy(policy utils.KeepPolicy) {
+	v.policy = policy
+	v.keep = make([]int, len(v.groups))
+	for i, group := range v.groups {
+		v.keep[i] = group.Keeper(policy)
+	}
+}
+
+// buildDuplicatesView cria a tabela e os atalhos da tela de duplicados
+func (a *App) buildDuplicatesView(v *duplicatesView) {
+	v.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	v.table.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Arquivos duplicados em %s ", v.root)).
+		SetTitleAlign(tview.AlignLeft)
+
+	v.status = tview.NewTextView().SetDynamicColors(true)
+	v.status.SetTextColor(utils.ColorStatusText)
+	v.status.SetBackgroundColor(utils.ColorStatusBar)
+
+	v.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 1, false)
+
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("duplicates")
+			a.app.SetFocus(a.fileView.fileList)
+			return nil
+		case tcell.KeyEnter:
+			// Ir para o arquivo no gerenciador
+			if group, file, ok := v.current(); ok && file >= 0 {
+				path := v.groups[group].Files[file].Path
+				a.pages.RemovePage("duplicates")
+				a.navigateTo(filepath.Dir(path))
+				a.fileView.SelectFile(filepath.Base(path))
+				a.app.SetFocus(a.fileView.fileList)
+			}
+			return nil
+		case tcell.KeyDelete:
+			a.dedupDuplicates(v, utils.DedupDelete)
+			return nil
+		}
+
+		switch event.Rune() {
+		case ' ':
+			// Preservar o arquivo sob o cursor
+			if group, file, ok := v.current(); ok && file >= 0 {
+				v.keep[group] = file
+				a.loadDuplicatesView(v)
+			}
+			return nil
+		case 'e', 'E':
+			// Incluir/excluir o grupo das ações
+			if group, _, ok := v.current(); ok {
+				v.skipped[group] = !v.skipped[group]
+				a.loadDuplicatesView(v)
+			}
+			return nil
+		case 'n', 'N':
+			v.applyPolicy(utils.KeepNewest)
+			a.loadDuplicatesView(v)
+			return nil
+		case 'o', 'O':
+			v.applyPolicy(utils.KeepOldest)
+			a.loadDuplicatesView(v)
+			return nil
+		case 'x', 'X':
+			a.dedupDuplicates(v, utils.DedupDelete)
+			return nil
+		case 'l', 'L':
+			a.dedupDuplicates(v, utils.DedupHardlink)
+			return nil
+		case 's', 'S':
+			a.dedupDuplicates(v, utils.DedupSymlink)
+			return nil
+		case 'r', 'R':
+			a.runDuplicateSearch(v)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("duplicates", v.layout, true, true)
+	a.app.SetFocus(v.table)
+}
+
+// loadDuplicatesView preenche a tabela com os grupos encontrados
+func (a *App) loadDuplicatesView(v *duplicatesView) {
+	row, _ := v.table.GetSelection()
+	v.table.Clear()
+	v.rows = v.rows[:0]
+
+	headers := []string{"", "Arquivo", "Tamanho", "Modificado"}
+	for col, header := range headers {
+		v.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	var wasted int64
+	files := 0
+	for g, group := range v.groups {
+		color := tcell.ColorAqua
+		if v.skipped[g] {
+			color = tcell.ColorGray
+		} else {
+			wasted += group.Wasted()
+			files += len(group.Files) - 1
+		}
+
+		v.rows = append(v.rows, [2]int{g, -1})
+		r := len(v.rows)
+		title := fmt.Sprintf("%d cópias de %s (%s desperdiçados)  %s", len(group.Files), utils.FormatFileSize(group.Size), utils.FormatFileSize(group.Wasted()), group.Hash[:12])
+		if v.skipped[g] {
+			title += "  (ignorado)"
+		}
+		v.table.SetCell(r, 0, tview.NewTableCell("").SetTextColor(color))
+		v.table.SetCell(r, 1, tview.NewTableCell(title).SetTextColor(color).SetExpansion(1))
+		v.table.SetCell(r, 2, tview.NewTableCell(""))
+		v.table.SetCell(r, 3, tview.NewTableCell(""))
+
+		for f, file := range group.Files {
+			symbol, fileColor := "✗", tcell.ColorRed
+			if f == v.keep[g] {
+				symbol, fileColor = "✓", tcell.ColorGreen
+			}
+			if v.skipped[g] {
+				fileColor = tcell.ColorGray
+			}
+
+			v.rows = append(v.rows, [2]int{g, f})
+			r := len(v.rows)
+			v.table.SetCell(r, 0, tview.NewTableCell(symbol).SetTextColor(fileColor))
+			v.table.SetCell(r, 1, tview.NewTableCell("  "+file.Path).SetTextColor(fileColor).SetExpansion(1))
+			v.table.SetCell(r, 2, tview.NewTableCell(utils.FormatFileSize(file.Size)).SetAlign(tview.AlignRight))
+			v.table.SetCell(r, 3, tview.NewTableCell(file.ModTime.Format("02/01/2006 15:04")))
+		}
+	}
+
+	if len(v.groups) == 0 {
+		v.table.SetCell(1, 1, tview.NewTableCell("Nenhum arquivo duplicado encontrado").SetSelectable(false))
+	}
+	v.table.Select(max(1, min(row, len(v.rows))), 0)
+
+	policy := "mais novo"
+	if v.policy == utils.KeepOldest {
+		policy = "mais antigo"
+	}
+	v.status.SetText(fmt.Sprintf(" %d grupo(s), %d cópia(s) a eliminar, %s recuperáveis | Preservar: %s  "+
+		"[::b]N/O[-:-:-] Mais novo/antigo  [::b]Espaço[-:-:-] Preservar  [::b]E[-:-:-] Ignorar grupo  [::b]X[-:-:-] Excluir  [::b]L[-:-:-] Link físico  [::b]S[-:-:-] Link simbólico  [::b]R[-:-:-] Refazer",
+		len(v.groups), files, utils.FormatFileSize(wasted), policy))
+}
+
+// current retorna o grupo e o arquivo sob o cursor (arquivo -1 no cabeçalho do grupo)
+func (v *duplicatesView) current() (int, int, bool) {
+	row, _ := v.table.GetSelection()
+	if row < 1 || row > len(v.rows) {
+		return 0, 0, false
+	}
+	return v.rows[row-1][0], v.rows[row-1][1], true
+}
+
+// dedupDuplicates elimina as cópias não preservadas dos grupos não ignorados.
+// As cópias vão para a lixeira (no disco local) e a operação pode ser desfeita.
+func (a *App) dedupDuplicates(v *duplicatesView, action utils.DedupAction) {
+	type dedupPair struct {
+		keep, duplicate utils.FileInfo
+	}
+
+	var pairs []dedupPair
+	for g, group := range v.groups {
+		if v.skipped[g] {
+			continue
+		}
+		for f, file := range group.Files {
+			if f != v.keep[g] {
+				pairs = append(pairs, dedupPair{keep: group.Files[v.keep[g]], duplicate: file})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		a.showMessage("Nenhuma cópia a eliminar")
+		return
+	}
+	if a.checkReadOnly(v.root) {
+		return
+	}
+
+	message := fmt.Sprintf("%s: %d cópia(s) serão eliminadas, preservando o arquivo marcado com ✓ em cada grupo. Continuar?", action, len(pairs))
+	a.showConfirmDialog("Duplicados", message, func(confirmed bool) {
+		a.app.SetFocus(v.table)
+		if !confirmed {
+			return
+		}
+
+		name := fmt.Sprintf("Duplicados: %s (%d cópia(s))", action, len(pairs))
+		undo := utils.UndoOperation{Description: name}
+		a.submitJob(name, func(jc *utils.JobContext) error {
+			defer func() { a.undo.Push(undo) }()
+
+			jc.AddTotal(len(pairs), 0)
+			for _, pair := range pairs {
+				if err := jc.Checkpoint(); err != nil {
+					return err
+				}
+				jc.SetCurrent(pair.duplicate.Path)
+
+				// Sem lixeira fora do disco local
+				remove := func(path string) error {
+					if !utils.IsLocalPath(path) {
+						return utils.RemoveAllFS(utils.FSFor(path), path)
+					}
+					entry, err := utils.MoveToTrash(path)
+					if err == nil {
+						undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRestoreTrash, Path: path, OriginalPath: path, Trash: entry})
+					}
+					return err
+				}
+
+				err := utils.DedupFile(pair.keep, pair.duplicate, action, remove)
+				if err == nil && action != utils.DedupDelete {
+					undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRemove, Path: pair.duplicate.Path})
+				}
+				if err := jc.Fail(pair.duplicate.Path, err); err != nil {
+					return err
+				}
+				jc.FileDone()
+			}
+			return nil
+		}, func(s utils.JobSnapshot) {
+			a.runDuplicateSearch(v)
+		})
+	})
+}
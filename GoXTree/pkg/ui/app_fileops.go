@@ -26,16 +26,76 @@ func (a *App) showToolsMenu() {
 		a.showSearchDialog()
 	})
 
+	menu.AddItem("Localizar Arquivo ou Diretório", "Busca aproximada nos itens abaixo do diretório atual (Ctrl+P)", 'f', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showFinder()
+	})
+
 	menu.AddItem("Comparar Arquivos", "Compara dois arquivos", 'c', func() {
 		a.pages.RemovePage("toolsMenu")
 		a.showCompareDialog()
 	})
 
+	menu.AddItem("Comparar Diretórios", "Compara recursivamente dois diretórios pelo conteúdo", 'r', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.compareDirectories()
+	})
+
+	menu.AddItem("Localizar Duplicados", "Procura arquivos com conteúdo idêntico a partir do diretório atual", 'u', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.findDuplicates()
+	})
+
+	menu.AddItem("Ir para Diretório Frequente", "Busca aproximada nos diretórios mais visitados (Alt+G)", 'i', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showJumpDialog()
+	})
+
+	menu.AddItem("Marcadores", "Lista os diretórios marcados com m+letra (Alt+B)", 'm', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showBookmarks()
+	})
+
+	menu.AddItem("Editor Hexadecimal", "Abre o arquivo atual no editor hexadecimal (Alt+H)", 'h', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.hexEditFile()
+	})
+
+	menu.AddItem("Permissões e Dono", "Altera permissões, dono e grupo dos itens selecionados (Alt+A)", 'a', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showAttributesDialog()
+	})
+
 	menu.AddItem("Sincronizar Diretórios", "Sincroniza dois diretórios", 's', func() {
 		a.pages.RemovePage("toolsMenu")
 		a.syncDirectories()
 	})
 
+	menu.AddItem("Criar Arquivo Compactado", "Compacta os itens selecionados em zip, tar, tar.gz, tar.zst ou tar.xz (Alt+Z)", 'z', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showCreateArchiveDialog()
+	})
+
+	menu.AddItem("Extrair", "Extrai itens de um arquivo compactado (Alt+X)", 'x', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.extractSelected()
+	})
+
+	menu.AddItem("Operações em Andamento", "Exibe as operações em segundo plano (Alt+J)", 'o', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showJobsPanel()
+	})
+
+	menu.AddItem("Lixeira", "Restaura ou exclui itens da lixeira (Alt+T)", 'l', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.showTrash()
+	})
+
+	menu.AddItem("Desfazer", "Desfaz a última exclusão, renomeação, movimentação ou colagem (Ctrl+Z)", 'd', func() {
+		a.pages.RemovePage("toolsMenu")
+		a.undoLast()
+	})
+
 	menu.AddItem("Voltar", "Volta ao gerenciador de arquivos", 'v', func() {
 		a.pages.RemovePage("toolsMenu")
 	})
@@ -65,6 +125,10 @@ func (a *App) getHomeDir() (string, error) {
 
 // createFile cria um novo arquivo
 func (a *App) createFile() {
+	if a.checkReadOnly(a.currentDir) {
+		return
+	}
+
 	a.showInputDialog("Novo Arquivo", "Nome:", func(fileName string) {
 		if fileName == "" {
 			return
@@ -80,13 +144,14 @@ func (a *App) createFile() {
 		filePath := filepath.Join(a.currentDir, fileName)
 
 		// Verificar se o arquivo já existe
-		if _, err := os.Stat(filePath); err == nil {
+		fsys := utils.FSFor(filePath)
+		if _, err := fsys.Stat(filePath); err == nil {
 			a.showError(fmt.Sprintf("Já existe um arquivo ou diretório com o nome '%s'", fileName))
 			return
 		}
 
 		// Criar arquivo
-		file, err := os.Create(filePath)
+		file, err := fsys.Create(filePath)
 		if err != nil {
 			a.showError(fmt.Sprintf("Erro ao criar arquivo: %v", err))
 			return
@@ -100,6 +165,18 @@ func (a *App) createFile() {
 
 // copyFile copia um arquivo ou diretório
 func (a *App) copyFile() {
+	// Dentro de um arquivo compactado, copiar significa extrair
+	if utils.IsArchivePath(a.currentDir) {
+		a.extractSelected()
+		return
+	}
+
+	// Com itens marcados, a cópia vale para todos eles
+	if len(a.selectedFiles) > 0 {
+		a.transferTagged(false)
+		return
+	}
+
 	// Obter arquivo selecionado
 	selectedFile := a.fileView.GetSelectedFile()
 	if selectedFile == "" {
@@ -156,25 +233,9 @@ func (a *App) copyFile() {
 	})
 }
 
-// doCopy realiza a cópia de um arquivo ou diretório
+// doCopy realiza a cópia de um arquivo ou diretório em segundo plano
 func (a *App) doCopy(src, dest string, isDir bool) {
-	var err error
-
-	if isDir {
-		// Copiar diretório
-		err = utils.CopyDir(src, dest)
-	} else {
-		// Copiar arquivo
-		err = utils.CopyFile(src, dest)
-	}
-
-	if err != nil {
-		a.showError(fmt.Sprintf("Erro ao copiar: %v", err))
-		return
-	}
-
-	a.refreshCurrentDir()
-	a.showMessage("Cópia concluída com sucesso")
+	a.startTransferJob([]jobTransfer{{src: src, dest: dest}}, filepath.Dir(dest), false, nil)
 }
 
 // getSelectedFile retorna o caminho completo do arquivo selecionado
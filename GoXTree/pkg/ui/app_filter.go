@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// setFilter aplica o filtro ao painel ativo; vazio remove o filtro
+func (a *App) setFilter(spec string) error {
+	filter, err := utils.ParseFilespec(spec)
+	if err != nil {
+		return err
+	}
+
+	a.fileView.SetFilter(filter)
+	a.updatePanelBorders()
+	a.refreshStatus()
+	return nil
+}
+
+// saveNamedFilter grava o filtro com o nome na configuração
+func (a *App) saveNamedFilter(name, spec string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("erro ao carregar configuração: %w", err)
+	}
+	if config.Filters == nil {
+		config.Filters = make(map[string]string)
+	}
+	config.Filters[name] = spec
+	return SaveConfig(config)
+}
+
+// deleteNamedFilter remove o filtro com o nome da configuração
+func (a *App) deleteNamedFilter(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("erro ao carregar configuração: %w", err)
+	}
+	delete(config.Filters, name)
+	return SaveConfig(config)
+}
+
+// showFilterDialog abre o filtro da listagem do painel ativo (Alt+F). As setas
+// escolhem um filtro salvo, que pode ser editado antes de aplicado.
+func (a *App) showFilterDialog() {
+	config, _ := LoadConfig()
+	filters := config.Filters
+
+	var names []string
+	load := func() {
+		names = names[:0]
+		for name := range filters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	load()
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(" Filtros Salvos ").
+		SetTitleAlign(tview.AlignLeft)
+
+	input := tview.NewInputField().
+		SetLabel("Filtro: ").
+		SetText(a.fileView.Filter().String())
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("Ex.: [green]*.go;*.prw;!*_test.go[white]  [green]size>1MB[white]  [green]modified<7d[white]  [green]type=dir[white]\n" +
+			"[yellow]Enter[white] aplica (vazio remove)  [yellow]↑↓[white] filtros salvos  [yellow]Ctrl+S[white] salva  [yellow]Ctrl+D[white] exclui salvo  [yellow]Esc[white] fecha")
+
+	render := func() {
+		row, _ := table.GetSelection()
+		table.Clear()
+		for i, name := range names {
+			table.SetCell(i, 0, tview.NewTableCell(tview.Escape(name)).SetTextColor(tcell.ColorYellow))
+			table.SetCell(i, 1, tview.NewTableCell(tview.Escape(filters[name])).SetExpansion(1))
+		}
+		if len(names) == 0 {
+			table.SetCell(0, 0, tview.NewTableCell("Nenhum filtro salvo").SetTextColor(tcell.ColorGray).SetSelectable(false))
+		}
+		table.Select(min(row, max(len(names)-1, 0)), 0)
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("filter")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	apply := func(spec string) {
+		if err := a.setFilter(spec); err != nil {
+			a.showError(err.Error())
+			return
+		}
+		closeDialog()
+		if filter := a.fileView.Filter(); filter != nil {
+			a.statusBar.SetStatus(fmt.Sprintf("Filtro aplicado: %s", tview.Escape(filter.String())))
+		} else {
+			a.statusBar.SetStatus("Filtro removido")
+		}
+	}
+
+	// pick seleciona o filtro salvo e copia a especificação para o campo. A
+	// primeira seta só copia o filtro já selecionado.
+	picked := false
+	pick := func(delta int) {
+		row, _ := table.GetSelection()
+		if picked {
+			row += delta
+		}
+		if row < 0 || row >= len(names) {
+			return
+		}
+		picked = true
+		table.Select(row, 0)
+		input.SetText(filters[names[row]])
+	}
+
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyEnter:
+			apply(input.GetText())
+			return nil
+		case tcell.KeyUp:
+			pick(-1)
+			return nil
+		case tcell.KeyDown:
+			pick(1)
+			return nil
+		case tcell.KeyCtrlS:
+			spec := strings.TrimSpace(input.GetText())
+			if _, err := utils.ParseFilespec(spec); err != nil || spec == "" {
+				a.showError("Informe um filtro válido para salvar")
+				return nil
+			}
+			closeDialog()
+			a.showInputDialogWithValue("Nome do filtro:", "", func(name string) {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					a.app.SetFocus(a.fileView.fileList)
+					return
+				}
+				if err := a.saveNamedFilter(name, spec); err != nil {
+					a.showError(fmt.Sprintf("Erro ao salvar filtro: %v", err))
+					return
+				}
+				if err := a.setFilter(spec); err != nil {
+					a.showError(err.Error())
+					return
+				}
+				a.app.SetFocus(a.fileView.fileList)
+				a.statusBar.SetStatus(fmt.Sprintf("Filtro %q salvo e aplicado", name))
+			})
+			return nil
+		case tcell.KeyCtrlD:
+			if row < len(names) {
+				if err := a.deleteNamedFilter(names[row]); err != nil {
+					a.showError(fmt.Sprintf("Erro ao salvar configuração: %v", err))
+					return nil
+				}
+				delete(filters, names[row])
+				load()
+				render()
+			}
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, false).
+		AddItem(input, 1, 0, true).
+		AddItem(hint, 2, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Filtro da Listagem ").
+		SetTitleAlign(tview.AlignLeft)
+
+	render()
+	a.pages.AddPage("filter", a.modal(layout, 96, min(max(len(names), 1), 10)+7), true, true)
+	a.app.SetFocus(input)
+}
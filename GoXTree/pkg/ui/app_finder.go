@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// finderLimit é o número máximo de resultados exibidos pelo localizador
+const finderLimit = 300
+
+// finderIndex retorna o índice de arquivos do diretório atual, reaproveitando o
+// anterior quando a raiz e a exibição de ocultos não mudaram
+func (a *App) finderIndex(rebuild bool) *utils.FileIndex {
+	if ix := a.fileIndex; ix != nil && !rebuild && ix.Root == a.currentDir && a.fileIndexHidden == a.showHidden {
+		return ix
+	}
+	if a.fileIndex != nil {
+		a.fileIndex.Stop()
+	}
+	a.fileIndex = utils.NewFileIndex(a.currentDir, utils.IndexOptions{IncludeHidden: a.showHidden})
+	a.fileIndexHidden = a.showHidden
+	return a.fileIndex
+}
+
+// showFinder abre o localizador aproximado de arquivos e diretórios (Ctrl+P), no
+// estilo do fzf: os itens abaixo do diretório atual são indexados em segundo plano
+// e ordenados pela pontuação enquanto se digita
+func (a *App) showFinder() {
+	ix := a.finderIndex(false)
+
+	list := tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true)
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Localizar em %s ", tview.Escape(ix.Root))).
+		SetTitleAlign(tview.AlignLeft)
+
+	input := tview.NewInputField().SetLabel("> ")
+	status := tview.NewTextView().SetDynamicColors(true)
+
+	// As consultas rodam fora da interface; só o resultado mais recente é exibido
+	var mu sync.Mutex
+	var matches []utils.IndexMatch
+	generation := 0
+	closed := make(chan struct{})
+
+	show := func(gen int, result []utils.IndexMatch) {
+		mu.Lock()
+		if gen != generation {
+			mu.Unlock()
+			return
+		}
+		matches = result
+		mu.Unlock()
+
+		current := list.GetCurrentItem()
+		list.Clear()
+		for _, m := range result {
+			text := highlightMatches(m.Path, m.Positions)
+			if m.IsDir {
+				text = "[blue]" + text + "/[-]"
+			}
+			list.AddItem(text, "", 0, nil)
+		}
+		list.SetCurrentItem(min(current, max(len(result)-1, 0)))
+
+		count, truncated := ix.Status()
+		state := "indexando..."
+		if ix.Done() {
+			state = "índice completo"
+		}
+		if truncated {
+			state = fmt.Sprintf("limite de %d itens atingido", utils.DefaultIndexLimit)
+		}
+		status.SetText(fmt.Sprintf(" %d/%d (%s)  [::b]Enter[-:-:-] Abrir  [::b]Ctrl+E[-:-:-] Editar  [::b]Ctrl+L[-:-:-] Localizar  [::b]Ctrl+R[-:-:-] Reindexar  [::b]ESC[-:-:-] Fechar",
+			len(result), count, state))
+	}
+
+	query := func(reset bool) {
+		mu.Lock()
+		generation++
+		gen := generation
+		mu.Unlock()
+
+		pattern := input.GetText()
+		if reset {
+			list.SetCurrentItem(0)
+		}
+		go func() {
+			result := ix.Query(pattern, finderLimit)
+			a.app.QueueUpdateDraw(func() { show(gen, result) })
+		}()
+	}
+
+	// Atualizar os resultados enquanto a indexação está em andamento
+	go func() {
+		ticker := time.NewTicker(300 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-ticker.C:
+				done := ix.Done()
+				a.app.QueueUpdate(func() { query(false) })
+				if done {
+					return
+				}
+			}
+		}
+	}()
+
+	closeDialog := func() {
+		close(closed)
+		a.pages.RemovePage("finder")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	// open executa a ação escolhida sobre o item destacado
+	open := func(action rune) {
+		mu.Lock()
+		current := list.GetCurrentItem()
+		if current >= len(matches) {
+			mu.Unlock()
+			return
+		}
+		m := matches[current]
+		mu.Unlock()
+
+		closeDialog()
+		path := filepath.Join(ix.Root, m.Path)
+		if m.IsDir && action != 'l' {
+			a.navigateTo(path)
+			return
+		}
+
+		// Arquivos: ir para o diretório e selecionar o item
+		a.navigateTo(filepath.Dir(path))
+		if !a.fileView.SelectFile(filepath.Base(path)) {
+			a.showError(fmt.Sprintf("Arquivo não encontrado: %s", path))
+			return
+		}
+		a.app.SetFocus(a.fileView.fileList)
+		switch action {
+		case 'o':
+			a.openFile()
+		case 'e':
+			a.editFile()
+		}
+	}
+
+	input.SetChangedFunc(func(string) { query(true) })
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		current := list.GetCurrentItem()
+		last := max(list.GetItemCount()-1, 0)
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyEnter:
+			open('o')
+			return nil
+		case tcell.KeyCtrlE:
+			open('e')
+			return nil
+		case tcell.KeyCtrlL:
+			open('l')
+			return nil
+		case tcell.KeyCtrlR:
+			closeDialog()
+			a.finderIndex(true)
+			a.showFinder()
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			list.SetCurrentItem(max(current-1, 0))
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			list.SetCurrentItem(min(current+1, last))
+			return nil
+		case tcell.KeyPgUp:
+			list.SetCurrentItem(max(current-10, 0))
+			return nil
+		case tcell.KeyPgDn:
+			list.SetCurrentItem(min(current+10, last))
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, false).
+		AddItem(input, 1, 0, true).
+		AddItem(status, 1, 0, false)
+
+	a.pages.AddPage("finder", a.modal(layout, 110, 30), true, true)
+	a.app.SetFocus(input)
+	query(true)
+}
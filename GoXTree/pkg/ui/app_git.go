@@ -0,0 +1,364 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/peder1981/GoXTree/pkg/viewer"
+	"github.com/rivo/tview"
+)
+
+// gitStatusTTL é o tempo em que a situação de um repositório é reaproveitada
+// sem reler o índice e o diretório de trabalho
+const gitStatusTTL = 2 * time.Second
+
+// gitState é a situação de um repositório lida por último. A leitura (índice,
+// HEAD e todo o diretório de trabalho) roda fora da goroutine da interface;
+// enquanto isso as telas usam o resultado anterior.
+type gitState struct {
+	repo       *utils.GitRepo
+	status     *utils.GitStatus // nil até a primeira leitura terminar ou se ela falhou
+	err        error
+	loaded     time.Time // Zero quando a situação precisa ser relida
+	loading    bool
+	generation int // Incrementado a cada invalidação; descarta leituras antigas
+}
+
+// gitRepoFor retorna o repositório que contém o diretório, ou nil fora de
+// repositórios e em caminhos remotos ou dentro de arquivos compactados
+func (a *App) gitRepoFor(dir string) *utils.GitRepo {
+	if dir == "" || !utils.IsLocalPath(dir) {
+		return nil
+	}
+	if a.gitRoots == nil {
+		a.gitRoots = make(map[string]string)
+	}
+	if a.gitStates == nil {
+		a.gitStates = make(map[string]*gitState)
+	}
+
+	root, known := a.gitRoots[dir]
+	if !known {
+		repo, err := utils.OpenGitRepo(dir)
+		if err == nil {
+			root = repo.Root
+			if a.gitStates[root] == nil {
+				a.gitStates[root] = &gitState{repo: repo}
+			}
+		}
+		a.gitRoots[dir] = root
+	}
+	if root == "" {
+		return nil
+	}
+	return a.gitStates[root].repo
+}
+
+// gitStateFor retorna a situação do repositório que contém o diretório; nil
+// fora de repositórios. Se ela tiver mais de gitStatusTTL, uma nova leitura é
+// iniciada em segundo plano e as visualizações são redesenhadas ao final.
+func (a *App) gitStateFor(dir string) *gitState {
+	repo := a.gitRepoFor(dir)
+	if repo == nil {
+		return nil
+	}
+	state := a.gitStates[repo.Root]
+	expired := !a.gitRedrawing && time.Since(state.loaded) > gitStatusTTL
+	if !state.loading && (state.loaded.IsZero() || expired) {
+		a.loadGitStatus(state)
+	}
+	return state
+}
+
+// loadGitStatus relê a situação do repositório em segundo plano
+func (a *App) loadGitStatus(state *gitState) {
+	state.loading = true
+	gen := state.generation
+	go func() {
+		status, err := state.repo.Status()
+		a.app.QueueUpdateDraw(func() {
+			state.loading = false
+			state.status, state.err = status, err
+			if gen == state.generation {
+				state.loaded = time.Now()
+			}
+			// Uma invalidação durante a leitura pede outra, feita pelo redesenho.
+			// O prazo dos outros repositórios não conta aqui, para que leituras
+			// demoradas não se encadeiem sem fim.
+			a.gitRedrawing = true
+			a.refreshGit()
+			a.gitRedrawing = false
+		})
+	}()
+}
+
+// gitStatusFor retorna a situação do repositório que contém o diretório, ou nil
+// se não houver repositório ou a primeira leitura ainda não terminou
+func (a *App) gitStatusFor(dir string) *utils.GitStatus {
+	if state := a.gitStateFor(dir); state != nil {
+		return state.status
+	}
+	return nil
+}
+
+// invalidateGitStatus marca a situação dos repositórios para ser relida; até
+// lá as telas continuam com a anterior. Os repositórios dos diretórios também
+// são procurados de novo (podem ter sido criados ou removidos).
+func (a *App) invalidateGitStatus() {
+	a.gitRoots = nil
+	for _, state := range a.gitStates {
+		state.loaded = time.Time{}
+		state.generation++
+	}
+}
+
+// gitSummary retorna o texto do git na barra de status: branch, distância do
+// upstream e contagem de alterações; vazio fora de repositórios
+func (a *App) gitSummary() string {
+	state := a.gitStateFor(a.currentDir)
+	if state == nil {
+		return ""
+	}
+	if state.err != nil {
+		return "[red]erro: " + tview.Escape(state.err.Error())
+	}
+	if state.status == nil {
+		return "[gray]lendo...[white]"
+	}
+
+	status := state.status
+	summary := tview.Escape(status.Summary())
+	if status.Upstream != "" {
+		summary += " [gray](" + tview.Escape(status.Upstream) + ")[white]"
+	}
+	return summary + gitMarkers(status.Lookup(status.Root, true).Flags())
+}
+
+// gitTargets agrupa os itens marcados (ou o item sob o cursor) por repositório
+func (a *App) gitTargets() (map[*utils.GitRepo][]string, error) {
+	targets := a.panelTargets()
+	if len(targets) == 0 {
+		return nil, errors.New("Nenhum arquivo selecionado")
+	}
+
+	groups := make(map[*utils.GitRepo][]string)
+	for path := range targets {
+		repo := a.gitRepoFor(filepath.Dir(path))
+		if repo == nil {
+			return nil, fmt.Errorf("%s não está em um repositório git", filepath.Base(path))
+		}
+		groups[repo] = append(groups[repo], path)
+	}
+	for _, paths := range groups {
+		sort.Strings(paths)
+	}
+	return groups, nil
+}
+
+// toggleGitStage prepara para o commit (git add) os itens marcados ou o item
+// sob o cursor; se já estiverem todos preparados, tira-os da preparação (Alt+U)
+func (a *App) toggleGitStage() {
+	groups, err := a.gitTargets()
+	if err != nil {
+		a.showError(err.Error())
+		return
+	}
+
+	var flags utils.GitFlags
+	for repo, paths := range groups {
+		status := a.gitStatusFor(repo.Root)
+		if status == nil {
+			a.statusBar.SetStatus("A situação do git ainda está sendo lida; tente de novo em instantes")
+			return
+		}
+		for _, path := range paths {
+			info, err := os.Lstat(path)
+			flags |= status.Lookup(path, err == nil && info.IsDir()).Flags()
+		}
+	}
+
+	switch {
+	case flags&(utils.GitModified|utils.GitUntracked|utils.GitConflicted) != 0:
+		a.gitStage(groups, false)
+	case flags&utils.GitStaged != 0:
+		a.gitStage(groups, true)
+	default:
+		a.statusBar.SetStatus("Nada a preparar: os itens não têm alterações")
+	}
+}
+
+// gitStage prepara (ou tira da preparação) os caminhos de cada repositório em
+// segundo plano e atualiza as visualizações ao final
+func (a *App) gitStage(groups map[*utils.GitRepo][]string, unstage bool) {
+	a.statusBar.SetStatus("Atualizando a área de preparação do git...")
+	go func() {
+		count := 0
+		var err error
+		for repo, paths := range groups {
+			if unstage {
+				err = repo.Unstage(paths)
+			} else {
+				err = repo.Stage(paths)
+			}
+			if err != nil {
+				break
+			}
+			count += len(paths)
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			a.invalidateGitStatus()
+			a.refreshGit()
+			switch {
+			case err != nil:
+				a.showError(fmt.Sprintf("Erro no git: %v", err))
+			case unstage:
+				a.statusBar.SetStatus(fmt.Sprintf("%d item(ns) removido(s) da área de preparação", count))
+			default:
+				a.statusBar.SetStatus(fmt.Sprintf("%d item(ns) preparado(s) para o commit", count))
+			}
+		})
+	}()
+}
+
+// stageSelected prepara para o commit os itens marcados ou o item sob o cursor
+func (a *App) stageSelected() {
+	if groups, err := a.gitTargets(); err != nil {
+		a.showError(err.Error())
+	} else {
+		a.gitStage(groups, false)
+	}
+}
+
+// unstageSelected tira da preparação os itens marcados ou o item sob o cursor
+func (a *App) unstageSelected() {
+	if groups, err := a.gitTargets(); err != nil {
+		a.showError(err.Error())
+	} else {
+		a.gitStage(groups, true)
+	}
+}
+
+// refreshGit redesenha as marcações do git nos painéis e na árvore sem perder
+// o cursor
+func (a *App) refreshGit() {
+	a.fileView.Reload()
+	if other := a.otherPanelDir(); other != "" {
+		a.otherPanel.fileView.Reload()
+	}
+	a.treeView.RefreshGit()
+	a.refreshStatus()
+}
+
+// diffWithHead compara o arquivo sob o cursor com a versão do último commit
+// (Alt+D), no mesmo visualizador da comparação de arquivos
+func (a *App) diffWithHead() {
+	selectedFile := a.fileView.GetSelectedFile()
+	if selectedFile == "" || selectedFile == ".." {
+		a.showError("Nenhum arquivo selecionado")
+		return
+	}
+	path := filepath.Join(a.currentDir, selectedFile)
+
+	repo := a.gitRepoFor(filepath.Dir(path))
+	if repo == nil {
+		a.showError("O arquivo não está em um repositório git")
+		return
+	}
+
+	info, statErr := os.Lstat(path)
+	if statErr == nil && info.IsDir() {
+		a.showError("Selecione um arquivo para comparar com o HEAD")
+		return
+	}
+
+	old, inHead, err := repo.HeadFile(path)
+	if err != nil {
+		a.showError(fmt.Sprintf("Erro ao ler o HEAD: %v", err))
+		return
+	}
+	var current []byte
+	if statErr == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				a.showError(fmt.Sprintf("Erro ao ler o link: %v", err))
+				return
+			}
+			current = []byte(target)
+		} else if current, err = os.ReadFile(path); err != nil {
+			a.showError(fmt.Sprintf("Erro ao ler o arquivo: %v", err))
+			return
+		}
+	}
+	if !inHead && statErr != nil {
+		a.showError("O arquivo não existe no HEAD nem no diretório de trabalho")
+		return
+	}
+	if isBinaryContent(old) || isBinaryContent(current) {
+		a.showError(fmt.Sprintf("%s é um arquivo binário", selectedFile))
+		return
+	}
+
+	// Nomes no formato do git diff, para o diff unificado exportado
+	rel, _ := filepath.Rel(repo.Root, path)
+	rel = filepath.ToSlash(rel)
+	oldName, newName := "a/"+rel, "b/"+rel
+	if !inHead {
+		oldName = "/dev/null"
+	}
+	if statErr != nil {
+		newName = "/dev/null"
+	}
+
+	diffViewer := viewer.NewDiffViewer(a.app)
+	diffViewer.LoadText(oldName, "HEAD:"+rel, string(old), newName, rel, string(current))
+
+	previousFocus := a.app.GetFocus()
+	diffViewer.SetCloseFunc(func() {
+		a.pages.RemovePage("compare")
+		a.app.SetFocus(previousFocus)
+	})
+	diffViewer.SetExportFunc(func(unified string) {
+		a.exportDiff(path, path, unified)
+	})
+
+	a.pages.AddPage("compare", diffViewer.Show(), true, true)
+	a.app.SetFocus(diffViewer.Show())
+}
+
+// isBinaryContent indica se o conteúdo parece binário (NUL nos primeiros 512
+// bytes), o mesmo critério de utils.IsTextFile
+func isBinaryContent(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 512)], 0) >= 0
+}
+
+// gitMarkers retorna os marcadores coloridos do git que seguem o nome de um
+// diretório na árvore e o branch na barra de status
+func gitMarkers(flags utils.GitFlags) string {
+	var b strings.Builder
+	for _, m := range []struct {
+		flag  utils.GitFlags
+		color string
+	}{
+		{utils.GitConflicted, "red"},
+		{utils.GitModified, "orange"},
+		{utils.GitStaged, "green"},
+		{utils.GitUntracked, "fuchsia"},
+	} {
+		if flags&m.flag != 0 {
+			fmt.Fprintf(&b, "[%s]%s[-]", m.color, m.flag.Markers())
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return " " + b.String()
+}
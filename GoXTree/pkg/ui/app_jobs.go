@@ -0,0 +1,370 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxConcurrentJobs é o número de operações de arquivo executadas ao mesmo tempo
+const maxConcurrentJobs = 2
+
+// jobTransfer descreve a cópia ou movimentação de um item para o destino
+type jobTransfer struct {
+	src  string
+	dest string
+}
+
+// jobsPanel é o painel que lista as operações em segundo plano
+type jobsPanel struct {
+	layout  *tview.Flex
+	table   *tview.Table
+	details *tview.TextView
+	jobs    []*utils.Job
+}
+
+// initJobs cria o gerenciador de operações em segundo plano
+func (a *App) initJobs() {
+	a.jobs = utils.NewJobManager(maxConcurrentJobs, func() {
+		a.app.QueueUpdateDraw(a.updateJobsView)
+	})
+}
+
+// submitJob coloca uma operação na fila. Ao término as visualizações são atualizadas
+// e done (opcional) é chamado na goroutine da interface.
+func (a *App) submitJob(name string, run utils.JobFunc, done func(s utils.JobSnapshot)) *utils.Job {
+	job := a.jobs.Submit(name, run, func(job *utils.Job) {
+		s := job.Snapshot()
+		a.app.QueueUpdateDraw(func() {
+			a.refreshView()
+			a.statusBar.SetStatus(jobSummary(s))
+			if done != nil {
+				done(s)
+			}
+		})
+	})
+
+	a.statusBar.SetStatus(fmt.Sprintf("%s: na fila (Alt+J para acompanhar)", name))
+	return job
+}
+
+// jobSummary descreve o resultado de uma operação para a barra de status
+func jobSummary(s utils.JobSnapshot) string {
+	switch s.Status {
+	case utils.JobDone:
+		return fmt.Sprintf("%s: concluído (%d arquivo(s), %s)", s.Name, s.DoneFiles, utils.FormatFileSize(s.DoneBytes))
+	case utils.JobFailed:
+		return fmt.Sprintf("%s: falhou - %v (Alt+J para detalhes)", s.Name, s.Err)
+	default:
+		return fmt.Sprintf("%s: %s", s.Name, strings.ToLower(s.Status.String()))
+	}
+}
+
+// startTransferJob copia ou move os itens informados em segundo plano.
+// Itens já existentes no destino são enviados para a lixeira antes de serem substituídos,
+// e a operação é registrada na pilha de desfazer. done (opcional) é chamado ao término.
+func (a *App) startTransferJob(transfers []jobTransfer, destDir string, move bool, done func(s utils.JobSnapshot)) {
+	if len(transfers) == 0 {
+		return
+	}
+
+	operation := "Copiar"
+	if move {
+		operation = "Mover"
+	}
+	name := fmt.Sprintf("%s %d item(ns) para %s", operation, len(transfers), destDir)
+	if len(transfers) == 1 {
+		name = fmt.Sprintf("%s %s para %s", operation, filepath.Base(transfers[0].src), destDir)
+	}
+
+	undo := utils.UndoOperation{Description: name}
+	a.submitJob(name, func(jc *utils.JobContext) error {
+		defer func() { a.undo.Push(undo) }()
+
+		for _, t := range transfers {
+			jc.Scan(t.src)
+		}
+		for _, t := range transfers {
+			if err := jc.Checkpoint(); err != nil {
+				return err
+			}
+
+			// Guardar na lixeira o item que será substituído (sem lixeira fora do disco local)
+			if _, err := utils.LstatFS(utils.FSFor(t.dest), t.dest); err == nil && !utils.IsLocalPath(t.dest) {
+				if err := utils.RemoveAllFS(utils.FSFor(t.dest), t.dest); err != nil {
+					jc.Fail(t.dest, fmt.Errorf("erro ao substituir: %v", err))
+					continue
+				}
+			} else if err == nil {
+				entry, err := utils.MoveToTrash(t.dest)
+				if err != nil {
+					jc.Fail(t.dest, fmt.Errorf("erro ao substituir: %v", err))
+					continue
+				}
+				undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRestoreTrash, Path: t.dest, OriginalPath: t.dest, Trash: entry})
+			}
+
+			var err error
+			if move {
+				err = utils.MovePathJob(jc, t.src, t.dest)
+			} else {
+				err = utils.CopyPathJob(jc, t.src, t.dest)
+			}
+
+			// Registrar o que efetivamente foi feito, mesmo em caso de falha parcial.
+			// Os passos de desfazer usam o disco local: cópias para SFTP ou para a
+			// memória e movimentações que envolvem esses destinos não são registradas.
+			if _, statErr := utils.LstatFS(utils.FSFor(t.dest), t.dest); statErr == nil && utils.IsLocalPath(t.dest) {
+				if !move {
+					undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRemove, Path: t.dest})
+				} else if _, srcErr := utils.LstatFS(utils.FSFor(t.src), t.src); os.IsNotExist(srcErr) && utils.IsLocalPath(t.src) {
+					undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoMoveBack, Path: t.dest, OriginalPath: t.src})
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, done)
+}
+
+// startDeleteJob exclui os itens informados em segundo plano. Sem permanent, os itens
+// vão para a lixeira e a exclusão pode ser desfeita.
+func (a *App) startDeleteJob(paths []string, permanent bool) {
+	name := fmt.Sprintf("Excluir %d item(ns)", len(paths))
+	if len(paths) == 1 {
+		name = fmt.Sprintf("Excluir %s", filepath.Base(paths[0]))
+	}
+
+	if permanent {
+		a.submitJob(name+" definitivamente", func(jc *utils.JobContext) error {
+			jc.Scan(paths...)
+			for _, path := range paths {
+				if err := utils.DeletePathJob(jc, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil)
+		return
+	}
+
+	undo := utils.UndoOperation{Description: name}
+	a.submitJob(name, func(jc *utils.JobContext) error {
+		defer func() { a.undo.Push(undo) }()
+
+		jc.Scan(paths...)
+		for _, path := range paths {
+			entry, err := utils.MoveToTrashJob(jc, path)
+			if err != nil {
+				if err := jc.Fail(path, err); err != nil {
+					return err
+				}
+				continue
+			}
+			undo.Steps = append(undo.Steps, utils.UndoStep{Kind: utils.UndoRestoreTrash, Path: path, OriginalPath: entry.OriginalPath, Trash: entry})
+		}
+		return nil
+	}, nil)
+}
+
+// showJobsPanel exibe o painel de operações em segundo plano (Alt+J)
+func (a *App) showJobsPanel() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Operações (P:Pausar/Retomar C:Cancelar X:Limpar concluídas ESC:Fechar) ").
+		SetTitleAlign(tview.AlignLeft)
+
+	details := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	details.SetBorder(true).
+		SetTitle(" Detalhes ").
+		SetTitleAlign(tview.AlignLeft)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 3, true).
+		AddItem(details, 0, 2, false)
+
+	a.jobsPanel = &jobsPanel{layout: layout, table: table, details: details}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		a.updateJobDetails()
+	})
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("jobs")
+			a.jobsPanel = nil
+			return nil
+		}
+
+		job := a.selectedJob()
+		switch event.Rune() {
+		case 'p', 'P':
+			if job != nil {
+				job.TogglePause()
+			}
+			return nil
+		case 'c', 'C':
+			if job != nil {
+				job.Cancel()
+			}
+			return nil
+		case 'x', 'X':
+			a.jobs.ClearFinished()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("jobs", a.modal(layout, 100, 25), true, true)
+	a.updateJobsView()
+	a.app.SetFocus(table)
+}
+
+// selectedJob retorna a operação sob o cursor do painel de operações
+func (a *App) selectedJob() *utils.Job {
+	if a.jobsPanel == nil {
+		return nil
+	}
+	row, _ := a.jobsPanel.table.GetSelection()
+	if row < 1 || row > len(a.jobsPanel.jobs) {
+		return nil
+	}
+	return a.jobsPanel.jobs[row-1]
+}
+
+// updateJobsView atualiza o painel de operações e o andamento na barra de status
+// (executado na goroutine da interface)
+func (a *App) updateJobsView() {
+	jobs := a.jobs.Jobs()
+
+	// Mostrar o andamento na barra de status enquanto houver operações ativas
+	var active []utils.JobSnapshot
+	for _, job := range jobs {
+		if s := job.Snapshot(); !s.Status.Finished() {
+			active = append(active, s)
+		}
+	}
+	if len(active) > 0 {
+		s := active[0]
+		status := fmt.Sprintf("%s: %s %d%%", s.Name, strings.ToLower(s.Status.String()), s.Percent())
+		if len(active) > 1 {
+			status += fmt.Sprintf(" (+%d operação(ões))", len(active)-1)
+		}
+		a.statusBar.SetStatus(status)
+	}
+
+	if a.jobsPanel == nil {
+		return
+	}
+
+	// Mais recentes primeiro
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Snapshot().ID > jobs[j].Snapshot().ID
+	})
+
+	table := a.jobsPanel.table
+	selected := a.selectedJob()
+	a.jobsPanel.jobs = jobs
+
+	table.Clear()
+	headers := []string{"#", "Operação", "Estado", "Arquivos", "Bytes", "%"}
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	for i, job := range jobs {
+		s := job.Snapshot()
+		row := i + 1
+		table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf("%d", s.ID)))
+		table.SetCell(row, 1, tview.NewTableCell(s.Name).SetExpansion(1).SetMaxWidth(50))
+		table.SetCell(row, 2, tview.NewTableCell(s.Status.String()).SetTextColor(jobStatusColor(s.Status)))
+		table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%d/%d", s.DoneFiles, s.TotalFiles)))
+		table.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("%s/%s", utils.FormatFileSize(s.DoneBytes), utils.FormatFileSize(s.TotalBytes))))
+		table.SetCell(row, 5, tview.NewTableCell(fmt.Sprintf("%3d%%", s.Percent())).SetAlign(tview.AlignRight))
+
+		if job == selected {
+			table.Select(row, 0)
+		}
+	}
+
+	if selected == nil && len(jobs) > 0 {
+		table.Select(1, 0)
+	}
+
+	a.updateJobDetails()
+}
+
+// updateJobDetails mostra os detalhes e os erros por arquivo da operação selecionada
+func (a *App) updateJobDetails() {
+	if a.jobsPanel == nil {
+		return
+	}
+
+	job := a.selectedJob()
+	if job == nil {
+		a.jobsPanel.details.SetText("Nenhuma operação")
+		return
+	}
+
+	s := job.Snapshot()
+	text := fmt.Sprintf("[yellow]%s[white]\n", tview.Escape(s.Name))
+	text += fmt.Sprintf("Estado: %s\n", s.Status)
+	text += fmt.Sprintf("Progresso: %d%% - %d de %d arquivo(s), %s de %s\n",
+		s.Percent(), s.DoneFiles, s.TotalFiles, utils.FormatFileSize(s.DoneBytes), utils.FormatFileSize(s.TotalBytes))
+	if s.CurrentFile != "" {
+		text += fmt.Sprintf("Atual: %s\n", tview.Escape(s.CurrentFile))
+	}
+	if !s.Started.IsZero() {
+		text += fmt.Sprintf("Início: %s", s.Started.Format("15:04:05"))
+		if !s.Finished.IsZero() {
+			text += fmt.Sprintf(" - Duração: %s", s.Finished.Sub(s.Started).Round(100*time.Millisecond))
+		}
+		text += "\n"
+	}
+	if s.Err != nil {
+		text += fmt.Sprintf("[red]Erro: %s[white]\n", tview.Escape(s.Err.Error()))
+	}
+	if len(s.Errors) > 0 {
+		text += fmt.Sprintf("\n[red]Erros por arquivo (%d):[white]\n", len(s.Errors))
+		for _, e := range s.Errors {
+			text += fmt.Sprintf("  %s: %s\n", tview.Escape(e.Path), tview.Escape(e.Err.Error()))
+		}
+	}
+
+	a.jobsPanel.details.SetText(text)
+}
+
+// jobStatusColor retorna a cor usada para exibir o estado de uma operação
+func jobStatusColor(status utils.JobStatus) tcell.Color {
+	switch status {
+	case utils.JobRunning:
+		return tcell.ColorGreen
+	case utils.JobPaused:
+		return tcell.ColorYellow
+	case utils.JobFailed:
+		return tcell.ColorRed
+	case utils.JobCanceled:
+		return tcell.ColorGray
+	default:
+		return ColorText
+	}
+}
@@ -91,8 +91,8 @@ func (a *App) NavigateToParentDirectory() {
 
 // NavigateToDirectory navega para um diretório específico
 func (a *App) NavigateToDirectory(path string) {
-	// Verificar se o diretório existe
-	fileInfo, err := os.Stat(path)
+	// Verificar se o diretório existe (ou se é um arquivo compactado)
+	fileInfo, err := utils.Stat(path)
 	if err != nil {
 		a.showError(fmt.Sprintf("Erro ao acessar diretório: %v", err))
 		return
@@ -110,6 +110,7 @@ func (a *App) NavigateToDirectory(path string) {
 	// Atualizar visualizações
 	a.refreshTreeView()
 	a.refreshFileView()
+	a.updateWatches()
 }
 
 // navigateBack navega para o diretório anterior no histórico
@@ -144,18 +145,25 @@ func (a *App) openFile() {
 
 	// Verificar se é um diretório
 	filePath := filepath.Join(a.currentDir, selectedFile)
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := utils.Stat(filePath)
 	if err != nil {
 		a.showMessage(fmt.Sprintf("Erro ao acessar arquivo: %v", err))
 		return
 	}
 
-	// Se for um diretório, navegar para ele
+	// Se for um diretório (ou um arquivo compactado), navegar para ele
 	if fileInfo.IsDir() {
 		a.NavigateToDirectory(filePath)
 		return
 	}
 
+	// Arquivos dentro de arquivos compactados são extraídos antes de abrir
+	filePath, err = a.localFile(filePath)
+	if err != nil {
+		a.showMessage(fmt.Sprintf("Erro ao extrair arquivo: %v", err))
+		return
+	}
+
 	// Verificar tipo de arquivo
 	isText, err := utils.IsTextFile(filePath)
 	if err != nil {
@@ -198,13 +206,13 @@ func (a *App) enterDirectory() {
 
 	// Verificar se é um diretório
 	filePath := filepath.Join(a.currentDir, selectedFile)
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := utils.Stat(filePath)
 	if err != nil {
 		a.showMessage(fmt.Sprintf("Erro ao acessar arquivo: %v", err))
 		return
 	}
 
-	// Se for um diretório, navegar para ele
+	// Se for um diretório (ou um arquivo compactado), navegar para ele
 	if fileInfo.IsDir() {
 		a.NavigateToDirectory(filePath)
 	}
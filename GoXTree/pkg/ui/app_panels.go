@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+)
+
+// panelState guarda o estado de navegação de um painel de arquivos
+type panelState struct {
+	fileView   *FileView
+	currentDir string
+	history    []string
+	historyPos int
+}
+
+// newPanelState cria o estado inicial de um painel para o diretório informado
+func newPanelState(fileView *FileView, dir string) *panelState {
+	return &panelState{
+		fileView:   fileView,
+		currentDir: dir,
+		history:    []string{dir},
+		historyPos: 0,
+	}
+}
+
+// saveActivePanel captura o estado do painel ativo
+func (a *App) saveActivePanel() *panelState {
+	return &panelState{
+		fileView:   a.fileView,
+		currentDir: a.currentDir,
+		history:    a.history,
+		historyPos: a.historyPos,
+	}
+}
+
+// restorePanel torna o painel informado o painel ativo
+func (a *App) restorePanel(p *panelState) {
+	a.fileView = p.fileView
+	a.currentDir = p.currentDir
+	a.history = p.history
+	a.historyPos = p.historyPos
+}
+
+// toggleDualPane alterna entre o layout de painel único e o de painel duplo
+func (a *App) toggleDualPane() {
+	if a.dualPane {
+		// Voltar para o painel principal caso o segundo esteja ativo
+		if a.fileView != a.panels[0] {
+			a.switchPanel()
+		}
+
+		a.horizontalLayout.RemoveItem(a.otherPanel.fileView.fileList)
+		a.dualPane = false
+		a.updatePanelBorders()
+		a.updateWatches()
+		a.app.SetFocus(a.fileView.fileList)
+		a.statusBar.SetStatus("Painel duplo desativado")
+		return
+	}
+
+	// Inicializar o segundo painel no diretório atual na primeira ativação
+	if a.otherPanel == nil {
+		a.otherPanel = newPanelState(a.panels[1], a.currentDir)
+	}
+
+	// Garantir que o diretório do segundo painel ainda exista
+	if info, err := utils.Stat(a.otherPanel.currentDir); err != nil || !info.IsDir() {
+		a.otherPanel.currentDir = a.currentDir
+	}
+
+	a.otherPanel.fileView.SetShowHidden(a.showHidden)
+	if err := a.otherPanel.fileView.SetCurrentDir(a.otherPanel.currentDir); err != nil {
+		a.showError(fmt.Sprintf("Erro ao abrir segundo painel: %s", err))
+		return
+	}
+
+	a.horizontalLayout.AddItem(a.otherPanel.fileView.fileList, 0, 2, false)
+	a.dualPane = true
+	a.syncPanelStyles()
+	a.updatePanelBorders()
+	a.updateWatches()
+	a.statusBar.SetStatus("Painel duplo ativado")
+}
+
+// switchPanel troca o painel de arquivos ativo
+func (a *App) switchPanel() {
+	if a.otherPanel == nil {
+		return
+	}
+
+	// Trocar estados
+	active := a.saveActivePanel()
+	a.restorePanel(a.otherPanel)
+	a.otherPanel = active
+
+	// Atualizar árvore e barra de status para o novo painel ativo
+	a.treeView.LoadTree(a.currentDir)
+	a.statusBar.UpdateStatus(a.currentDir)
+	a.updatePanelBorders()
+	a.updateWatches()
+	a.app.SetFocus(a.fileView.fileList)
+}
+
+// otherPanelDir retorna o diretório do painel inativo, ou "" se o painel duplo estiver desligado
+func (a *App) otherPanelDir() string {
+	if !a.dualPane || a.otherPanel == nil {
+		return ""
+	}
+	return a.otherPanel.currentDir
+}
+
+// refreshOtherPanel atualiza a lista de arquivos do painel inativo
+func (a *App) refreshOtherPanel() {
+	if !a.dualPane || a.otherPanel == nil {
+		return
+	}
+	a.otherPanel.fileView.SetShowHidden(a.showHidden)
+}
+
+// syncPanelStyles copia as cores do painel ativo para o painel inativo
+func (a *App) syncPanelStyles() {
+	for _, fv := range a.panels {
+		if fv == a.fileView {
+			continue
+		}
+		fv.fileList.SetBackgroundColor(a.fileView.fileList.GetBackgroundColor())
+		fv.fileList.SetBorderColor(a.fileView.fileList.GetBorderColor())
+		fv.fileList.SetTitleColor(ColorTitle)
+	}
+}
+
+// updatePanelBorders destaca o painel ativo quando o painel duplo está ligado
+func (a *App) updatePanelBorders() {
+	for _, fv := range a.panels {
+		title := " Arquivos "
+		if a.dualPane {
+			title = fmt.Sprintf(" %s ", filepath.Base(fv.currentDir))
+			if fv == a.fileView {
+				title = fmt.Sprintf(" [*] %s ", filepath.Base(fv.currentDir))
+			}
+		}
+		if fv.viewMode == ViewModeFlat {
+			title += "(ramo) "
+		}
+		if fv.filter != nil {
+			title += tview.Escape(fmt.Sprintf("[%s] ", fv.filter))
+		}
+		fv.fileList.SetTitle(title)
+	}
+}
+
+// panelTargets retorna os arquivos a serem copiados/movidos para o outro painel:
+// os itens marcados (de qualquer diretório) ou, se não houver, o item sob o cursor
+func (a *App) panelTargets() map[string]bool {
+	if len(a.selectedFiles) > 0 {
+		return a.selectedFiles
+	}
+
+	selectedFile := a.fileView.GetSelectedFile()
+	if selectedFile == "" || selectedFile == ".." {
+		return nil
+	}
+	return map[string]bool{filepath.Join(a.currentDir, selectedFile): true}
+}
+
+// copyToOtherPanel copia os arquivos do painel ativo para o diretório do outro painel (F5)
+func (a *App) copyToOtherPanel() {
+	if !a.dualPane {
+		a.copyFile()
+		return
+	}
+	a.transferToOtherPanel(false)
+}
+
+// moveToOtherPanel move os arquivos do painel ativo para o diretório do outro painel (F6)
+func (a *App) moveToOtherPanel() {
+	if !a.dualPane {
+		a.moveFile()
+		return
+	}
+	a.transferToOtherPanel(true)
+}
+
+// transferToOtherPanel copia ou move os arquivos do painel ativo para o painel inativo
+func (a *App) transferToOtherPanel(move bool) {
+	files := a.panelTargets()
+	if len(files) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+
+	destDir := a.otherPanelDir()
+	if destDir == a.currentDir {
+		a.showError("Os dois painéis estão no mesmo diretório")
+		return
+	}
+
+	operation := "Copiar"
+	if move {
+		operation = "Mover"
+	}
+
+	a.showConfirmDialog(operation, fmt.Sprintf("%s %d item(ns) para %s?", operation, len(files), destDir), func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		a.pasteFilesTo(files, destDir, move)
+	})
+}
+
+// comparePanels compara o arquivo sob o cursor com o arquivo de mesmo nome no outro painel
+func (a *App) comparePanels() {
+	selectedFile := a.fileView.GetSelectedFile()
+	if selectedFile == "" {
+		a.showError("Nenhum arquivo selecionado")
+		return
+	}
+
+	// Sobre ".." os diretórios dos dois painéis são comparados
+	if selectedFile == ".." {
+		a.showDirectoryComparison(a.currentDir, a.otherPanelDir())
+		return
+	}
+
+	file1 := filepath.Join(a.currentDir, selectedFile)
+	file2 := filepath.Join(a.otherPanelDir(), selectedFile)
+
+	info1, err1 := utils.Stat(file1)
+	info2, err2 := utils.Stat(file2)
+	if err1 != nil || err2 != nil {
+		a.showError(fmt.Sprintf("'%s' não existe nos dois painéis", selectedFile))
+		return
+	}
+
+	// Diretórios de mesmo nome são comparados pelo conteúdo
+	if info1.IsDir() && info2.IsDir() {
+		a.showDirectoryComparison(file1, file2)
+		return
+	}
+
+	if info1.IsDir() || info2.IsDir() {
+		a.showError("Não é possível comparar um arquivo com um diretório")
+		return
+	}
+
+	a.compareFiles(file1, file2)
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// openSFTP conecta a um servidor sftp://usuario@host/caminho em segundo plano e
+// navega para o diretório remoto, que passa a ser tratado como um diretório comum
+func (a *App) openSFTP(rawURL string) {
+	cfg, _, err := utils.ParseSFTPURL(rawURL)
+	if err != nil {
+		a.showError(err.Error())
+		return
+	}
+
+	a.statusBar.SetStatus(fmt.Sprintf("Conectando a %s...", cfg.Address()))
+	go func() {
+		dir, err := utils.OpenSFTP(rawURL)
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				a.statusBar.SetStatus("")
+				a.showError(fmt.Sprintf("Erro ao abrir %s: %v", rawURL, err))
+				return
+			}
+			a.statusBar.SetStatus(fmt.Sprintf("Conectado a %s", cfg.MountPoint()))
+			a.navigateTo(dir)
+		})
+	}()
+}
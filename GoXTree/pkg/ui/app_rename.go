@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// renameTokensHelp resume os tokens aceitos no modelo de nome
+const renameTokensHelp = `[yellow]Tokens do modelo:[white]
+ {name}    nome sem extensão
+ {ext}     extensão original
+ {parent}  diretório do item
+ {n}       contador; {n:03} = 001
+ {date}    data de modificação
+ {date:AAAAMMDD_hhmmss}
+ {exif}    data da foto (EXIF)
+
+[yellow]Substituição:[white] com expressão
+regular, use $1, $2... no texto.
+[yellow]Nova extensão:[white] vazio mantém,
+"." remove.`
+
+// showBulkRenameDialog renomeia vários itens de uma vez (Alt+R), com prévia dos novos nomes
+func (a *App) showBulkRenameDialog() {
+	targets := a.panelTargets()
+	if len(targets) == 0 {
+		a.showMessage("Nenhum arquivo selecionado")
+		return
+	}
+	if a.checkReadOnly(a.currentDir) {
+		return
+	}
+
+	// O contador segue a ordem alfabética
+	paths := make([]string, 0, len(targets))
+	for path := range targets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	rule := utils.RenameRule{Template: utils.DefaultRenameTemplate, CounterStart: 1, CounterStep: 1}
+	var plans []utils.RenamePlan
+	var planErr error
+
+	preview := tview.NewTable().SetFixed(1, 0)
+	preview.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Prévia (%d itens) ", len(paths))).
+		SetTitleAlign(tview.AlignLeft)
+
+	status := tview.NewTextView().SetDynamicColors(true)
+	status.SetTextColor(utils.ColorStatusText)
+	status.SetBackgroundColor(utils.ColorStatusBar)
+
+	// Recalcular os novos nomes a cada alteração
+	update := func() {
+		plans, planErr = utils.PlanRenames(paths, rule)
+
+		preview.Clear()
+		for col, header := range []string{"Nome atual", "", "Novo nome", "Situação"} {
+			preview.SetCell(0, col, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		}
+
+		if planErr != nil {
+			preview.SetCell(1, 0, tview.NewTableCell(planErr.Error()).SetTextColor(tcell.ColorRed))
+			status.SetText(" " + tview.Escape(planErr.Error()))
+			return
+		}
+
+		counts := make(map[utils.RenameStatus]int)
+		for i, plan := range plans {
+			counts[plan.Status]++
+			color, situation := tcell.ColorGreen, "ok"
+			switch plan.Status {
+			case utils.RenameUnchanged:
+				color, situation = tcell.ColorGray, "sem alteração"
+			case utils.RenameInvalid, utils.RenameCollision:
+				color, situation = tcell.ColorRed, plan.Reason
+			}
+			if plan.Cycle {
+				situation += " (troca circular)"
+			}
+
+			preview.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(filepath.Base(plan.Old))).SetExpansion(1))
+			preview.SetCell(i+1, 1, tview.NewTableCell("→").SetTextColor(tcell.ColorGray))
+			preview.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(filepath.Base(plan.New))).SetTextColor(color).SetExpansion(1))
+			preview.SetCell(i+1, 3, tview.NewTableCell(situation).SetTextColor(color))
+		}
+
+		problems := counts[utils.RenameInvalid] + counts[utils.RenameCollision]
+		text := fmt.Sprintf(" %d a renomear, %d sem alteração", counts[utils.RenameReady], counts[utils.RenameUnchanged])
+		if problems > 0 {
+			text += fmt.Sprintf(", [red]%d com problema[-]", problems)
+		}
+		status.SetText(text + "  [::b]PgUp/PgDn[-:-:-] Rolar a prévia  [::b]ESC[-:-:-] Cancelar")
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("bulkRename")
+		a.app.SetFocus(a.fileView.fileList)
+	}
+
+	form := tview.NewForm()
+	form.SetTitle(" Renomear em Lote ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorder(true)
+
+	form.AddInputField("Procurar:", "", 30, nil, func(text string) {
+		rule.Find = text
+		update()
+	})
+	form.AddInputField("Substituir por:", "", 30, nil, func(text string) {
+		rule.Replace = text
+		update()
+	})
+	form.AddCheckbox("Expressão regular", false, func(checked bool) {
+		rule.Regexp = checked
+		update()
+	})
+	form.AddCheckbox("Diferenciar maiúsculas", false, func(checked bool) {
+		rule.CaseSensitive = checked
+		update()
+	})
+	form.AddInputField("Modelo:", rule.Template, 30, nil, func(text string) {
+		rule.Template = text
+		update()
+	})
+	form.AddInputField("Contador inicia em:", "1", 8, tview.InputFieldInteger, func(text string) {
+		rule.CounterStart, _ = strconv.Atoi(text)
+		update()
+	})
+	form.AddInputField("Incremento:", "1", 8, tview.InputFieldInteger, func(text string) {
+		rule.CounterStep, _ = strconv.Atoi(text)
+		update()
+	})
+	form.AddDropDown("Maiúsculas/minúsculas:", utils.CaseTransformNames, 0, func(option string, index int) {
+		rule.Case = utils.CaseTransform(index)
+		if plans != nil {
+			update()
+		}
+	})
+	form.AddInputField("Nova extensão:", "", 12, nil, func(text string) {
+		rule.Extension = text
+		update()
+	})
+
+	form.AddButton("Renomear", func() {
+		if planErr != nil {
+			a.showError(planErr.Error())
+			return
+		}
+		steps, err := utils.ApplyRenames(plans)
+		if err != nil {
+			a.showError(fmt.Sprintf("Nenhum item foi renomeado: %v", err))
+			return
+		}
+		if len(steps) == 0 {
+			a.showMessage("Nenhum nome foi alterado")
+			return
+		}
+
+		renamed := len(steps) / 2
+		a.undo.Push(utils.UndoOperation{
+			Description: fmt.Sprintf("Renomear %d item(ns) em lote", renamed),
+			Steps:       steps,
+		})
+		a.selectedFiles = make(map[string]bool)
+		closeDialog()
+		a.refreshCurrentDir()
+		a.statusBar.SetStatus(fmt.Sprintf("%d item(ns) renomeado(s)", renamed))
+	})
+	form.AddButton("Cancelar", closeDialog)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := preview.GetOffset()
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyPgDn:
+			preview.SetOffset(min(row+10, max(len(plans)-1, 0)), 0)
+			return nil
+		case tcell.KeyPgUp:
+			preview.SetOffset(max(row-10, 0), 0)
+			return nil
+		}
+		return event
+	})
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(renameTokensHelp)
+	help.SetBorder(true)
+
+	left := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 23, 0, true).
+		AddItem(help, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(left, 56, 0, true).
+			AddItem(preview, 0, 1, false), 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	update()
+	a.pages.AddPage("bulkRename", layout, true, true)
+	a.app.SetFocus(form)
+}
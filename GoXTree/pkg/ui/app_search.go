@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
@@ -27,7 +30,10 @@ func (a *App) showSearchDialog() {
 		recursive     bool   = true
 		matchCase     bool   = false
 		searchContent bool   = false
+		useRegexp     bool   = false
+		useIgnore     bool   = true
 		fileType      string
+		contextLines  string = "2"
 	)
 
 	// Adicionar campos
@@ -51,14 +57,37 @@ func (a *App) showSearchDialog() {
 		searchContent = checked
 	})
 
+	form.AddCheckbox("Expressão regular", useRegexp, func(checked bool) {
+		useRegexp = checked
+	})
+
+	form.AddCheckbox("Respeitar .gitignore/.ignore", useIgnore, func(checked bool) {
+		useIgnore = checked
+	})
+
 	form.AddInputField("Tipo de arquivo (ex: .txt, .go):", "", 40, nil, func(text string) {
 		fileType = text
 	})
 
+	form.AddInputField("Linhas de contexto:", contextLines, 5, tview.InputFieldInteger, func(text string) {
+		contextLines = text
+	})
+
 	// Adicionar botões
 	form.AddButton("Buscar", func() {
 		a.pages.RemovePage("searchDialog")
-		a.performSearch(pattern, searchDir, recursive, matchCase, searchContent, fileType)
+		context, _ := strconv.Atoi(contextLines)
+		a.performSearch(utils.SearchOptions{
+			Pattern:       pattern,
+			Directory:     searchDir,
+			Recursive:     recursive,
+			CaseSensitive: matchCase,
+			MatchContent:  searchContent,
+			FileTypes:     parseFileTypes(fileType),
+			Literal:       !useRegexp,
+			NoIgnore:      !useIgnore,
+			Context:       context,
+		})
 	})
 
 	form.AddButton("Cancelar", func() {
@@ -79,149 +108,202 @@ func (a *App) showSearchDialog() {
 	a.app.SetFocus(form)
 }
 
-// performSearch realiza a busca de arquivos
-func (a *App) performSearch(pattern, searchDir string, recursive, matchCase, searchContent bool, fileType string) {
+// parseFileTypes separa uma lista de extensões como ".txt, .go" ou "txt;go"
+func parseFileTypes(fileType string) []string {
+	return strings.FieldsFunc(fileType, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	})
+}
+
+// searchRow identifica o arquivo (e a linha, quando houver) de uma linha dos resultados
+type searchRow struct {
+	path string
+	line int
+}
+
+// performSearch realiza a busca em segundo plano, exibindo os resultados à medida
+// que são encontrados. ESC fecha a janela e cancela a busca em andamento.
+func (a *App) performSearch(options utils.SearchOptions) {
 	// Verificar padrão de busca
-	if pattern == "" {
+	if options.Pattern == "" {
 		a.showError("Padrão de busca não pode ser vazio")
 		return
 	}
 
 	// Verificar diretório de busca
-	if searchDir == "" {
-		searchDir = a.currentDir
+	if options.Directory == "" {
+		options.Directory = a.currentDir
 	}
 
 	// Expandir caminho
-	if strings.HasPrefix(searchDir, "~") {
+	if strings.HasPrefix(options.Directory, "~") {
 		homeDir, err := a.getHomeDir()
 		if err != nil {
 			a.showError(fmt.Sprintf("Erro ao obter diretório home: %v", err))
 			return
 		}
-		searchDir = filepath.Join(homeDir, searchDir[1:])
+		options.Directory = filepath.Join(homeDir, options.Directory[1:])
 	}
 
 	// Verificar se o diretório existe
-	fileInfo, err := os.Stat(searchDir)
+	fileInfo, err := utils.Stat(options.Directory)
 	if err != nil || !fileInfo.IsDir() {
-		a.showError(fmt.Sprintf("Diretório de busca inválido: %s", searchDir))
+		a.showError(fmt.Sprintf("Diretório de busca inválido: %s", options.Directory))
 		return
 	}
+	if !options.Literal {
+		if _, err := regexp.Compile(options.Pattern); err != nil {
+			a.showError(fmt.Sprintf("Expressão regular inválida: %v", err))
+			return
+		}
+	}
+	options.IncludeHidden = a.showHidden
 
-	// Criar lista de resultados
-	resultList := tview.NewList()
-	resultList.SetTitle(fmt.Sprintf("Resultados da busca: %s", pattern)).
+	// Criar tabela de resultados
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetTitle(fmt.Sprintf(" Resultados da busca: %s ", options.Pattern)).
 		SetTitleAlign(tview.AlignCenter).
 		SetBorder(true)
+	status := tview.NewTextView().SetDynamicColors(true)
+	status.SetTextColor(utils.ColorStatusText)
+	status.SetBackgroundColor(utils.ColorStatusBar)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(status, 1, 1, false)
 
-	// Adicionar manipulador de eventos
-	resultList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("searchResults")
-			return nil
-		}
-		return event
-	})
-
-	// Adicionar página
-	a.pages.AddPage("searchResults", resultList, true, true)
-	a.app.SetFocus(resultList)
-
-	// Realizar busca em goroutine
-	go func() {
-		// Resultados
-		var results []string
-
-		// Buscar arquivos
-		err := filepath.Walk(searchDir, func(path string, info os.FileInfo, err error) error {
-			// Verificar erro
-			if err != nil {
-				return nil
-			}
+	var (
+		rows    []searchRow
+		files   int
+		matches int
+		running = true
+		job     *utils.Job
+	)
 
-			// Verificar se é diretório e não é recursivo
-			if info.IsDir() && path != searchDir && !recursive {
-				return filepath.SkipDir
-			}
+	// Os resultados chegam dos workers e são acumulados até a próxima atualização da tela
+	var (
+		mu        sync.Mutex
+		pending   []utils.SearchResult
+		scheduled bool
+	)
 
-			// Verificar tipo de arquivo
-			if fileType != "" && !info.IsDir() {
-				if !strings.HasSuffix(strings.ToLower(path), strings.ToLower(fileType)) {
-					return nil
-				}
-			}
+	updateStatus := func() {
+		state := "buscando..."
+		if !running {
+			state = "concluída"
+		}
+		status.SetText(fmt.Sprintf(" Busca %s  %d arquivo(s), %d ocorrência(s)  [::b]Enter[-:-:-] Ir para o arquivo  [::b]ESC[-:-:-] Fechar", state, files, matches))
+	}
 
-			// Verificar nome do arquivo
-			fileName := filepath.Base(path)
-			match := false
+	addRow := func(row searchRow, cell *tview.TableCell) {
+		rows = append(rows, row)
+		table.SetCell(len(rows)-1, 0, cell.SetExpansion(1))
+	}
 
-			if matchCase {
-				match = strings.Contains(fileName, pattern)
-			} else {
-				match = strings.Contains(strings.ToLower(fileName), strings.ToLower(pattern))
+	flush := func() {
+		mu.Lock()
+		results := pending
+		pending, scheduled = nil, false
+		mu.Unlock()
+
+		for _, result := range results {
+			files++
+			name := result.Path
+			if result.IsDir {
+				name += string(filepath.Separator)
 			}
+			addRow(searchRow{path: result.Path}, tview.NewTableCell(tview.Escape(name)).SetTextColor(tcell.ColorAqua))
 
-			// Verificar conteúdo do arquivo
-			if !match && searchContent && !info.IsDir() {
-				// Verificar tamanho do arquivo
-				if info.Size() > 10*1024*1024 {
-					return nil // Ignorar arquivos muito grandes
+			for _, match := range result.Matches {
+				matches++
+				for i, line := range match.Before {
+					addRow(searchRow{path: result.Path, line: match.LineNum - len(match.Before) + i},
+						tview.NewTableCell(searchLineText(match.LineNum-len(match.Before)+i, line, nil)).SetTextColor(tcell.ColorGray))
 				}
-
-				// Ler conteúdo do arquivo
-				content, err := os.ReadFile(path)
-				if err != nil {
-					return nil
+				addRow(searchRow{path: result.Path, line: match.LineNum},
+					tview.NewTableCell(searchLineText(match.LineNum, match.Line, match.Spans)))
+				for i, line := range match.After {
+					addRow(searchRow{path: result.Path, line: match.LineNum + 1 + i},
+						tview.NewTableCell(searchLineText(match.LineNum+1+i, line, nil)).SetTextColor(tcell.ColorGray))
 				}
-
-				// Verificar conteúdo
-				if matchCase {
-					match = strings.Contains(string(content), pattern)
-				} else {
-					match = strings.Contains(strings.ToLower(string(content)), strings.ToLower(pattern))
+				if len(match.Before)+len(match.After) > 0 {
+					addRow(searchRow{path: result.Path}, tview.NewTableCell("  --").SetTextColor(tcell.ColorGray))
 				}
 			}
+		}
+		updateStatus()
+	}
 
-			// Adicionar ao resultado
-			if match {
-				results = append(results, path)
-			}
-
-			return nil
-		})
+	closeResults := func() {
+		if running && job != nil {
+			job.Cancel()
+		}
+		a.pages.RemovePage("searchResults")
+		a.app.SetFocus(a.fileView.fileList)
+	}
 
-		// Verificar erro
-		if err != nil {
-			a.app.QueueUpdateDraw(func() {
-				a.showError(fmt.Sprintf("Erro ao buscar arquivos: %v", err))
-				a.pages.RemovePage("searchResults")
-			})
+	table.SetSelectedFunc(func(row, column int) {
+		if row < 0 || row >= len(rows) {
 			return
 		}
+		// Navegar para o diretório do arquivo
+		path := rows[row].path
+		closeResults()
+		a.navigateTo(filepath.Dir(path))
+		a.fileView.SelectFile(filepath.Base(path))
+	})
 
-		// Atualizar lista de resultados
-		a.app.QueueUpdateDraw(func() {
-			// Verificar se há resultados
-			if len(results) == 0 {
-				resultList.AddItem("Nenhum resultado encontrado", "", 0, nil)
-				return
-			}
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeResults()
+			return nil
+		}
+		return event
+	})
 
-			// Adicionar resultados
-			for _, path := range results {
-				resultList.AddItem(path, "", 0, func() {
-					// Navegar para o diretório do arquivo
-					dir := filepath.Dir(path)
-					file := filepath.Base(path)
-
-					a.navigateTo(dir)
-					a.fileView.SelectFile(file)
-					a.pages.RemovePage("searchResults")
-				})
+	// Adicionar página
+	a.pages.AddPage("searchResults", layout, true, true)
+	a.app.SetFocus(table)
+	updateStatus()
+
+	name := fmt.Sprintf("Buscar '%s' em %s", options.Pattern, options.Directory)
+	job = a.submitJob(name, func(jc *utils.JobContext) error {
+		return utils.SearchFiles(jc, options, func(result utils.SearchResult) {
+			mu.Lock()
+			pending = append(pending, result)
+			schedule := !scheduled
+			scheduled = true
+			mu.Unlock()
+
+			if schedule {
+				a.app.QueueUpdateDraw(flush)
 			}
 		})
-	}()
+	}, func(s utils.JobSnapshot) {
+		running = false
+		flush()
+		if s.Status == utils.JobFailed {
+			a.showError(fmt.Sprintf("Erro ao buscar arquivos: %v", s.Err))
+			return
+		}
+		if len(rows) == 0 {
+			table.SetCell(0, 0, tview.NewTableCell("Nenhum resultado encontrado").SetSelectable(false))
+		}
+	})
+}
+
+// searchLineText formata uma linha do conteúdo com o número e os trechos encontrados em destaque
+func searchLineText(lineNum int, line string, spans [][2]int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  [gray]%6d:[-] ", lineNum))
+
+	pos := 0
+	for _, span := range spans {
+		sb.WriteString(tview.Escape(line[pos:span[0]]))
+		sb.WriteString("[black:yellow]" + tview.Escape(line[span[0]:span[1]]) + "[-:-]")
+		pos = span[1]
+	}
+	sb.WriteString(tview.Escape(line[pos:]))
+	return sb.String()
 }
 
 // showCompareDialog exibe o diálogo de comparação de arquivos
@@ -273,8 +355,8 @@ func (a *App) showCompareDialog() {
 		}
 
 		// Verificar se os arquivos existem
-		info1, err1 := os.Stat(file1)
-		info2, err2 := os.Stat(file2)
+		info1, err1 := utils.Stat(file1)
+		info2, err2 := utils.Stat(file2)
 
 		if err1 != nil || err2 != nil {
 			a.showError("Um ou ambos os arquivos não existem")
@@ -319,12 +401,12 @@ func (a *App) showSyncDialog() {
 	// Variáveis para os campos
 	var (
 		sourceDir      string = a.currentDir
-		targetDir      string
-		deleteOrphans  bool = false
-		overwriteNewer bool = false
-		skipExisting   bool = false
-		includeHidden  bool = false
-		previewOnly    bool = true
+		targetDir      string = a.otherPanelDir()
+		deleteOrphans  bool   = false
+		overwriteNewer bool   = false
+		skipExisting   bool   = false
+		includeHidden  bool   = false
+		previewOnly    bool   = true
 	)
 
 	// Adicionar campos
@@ -332,7 +414,7 @@ func (a *App) showSyncDialog() {
 		sourceDir = text
 	})
 
-	form.AddInputField("Diretório de destino:", "", 40, nil, func(text string) {
+	form.AddInputField("Diretório de destino:", targetDir, 40, nil, func(text string) {
 		targetDir = text
 	})
 
@@ -411,17 +493,22 @@ func (a *App) showSyncDialog() {
 			IncludeHidden:  includeHidden,
 		}
 
-		actions, err := utils.SyncDirectories(options)
-		if err != nil {
-			a.showError(fmt.Sprintf("Erro ao sincronizar: %v", err))
-			return
-		}
-
-		// Mostrar resultado
-		a.showSyncResults(actions)
-
-		// Atualizar visualização
-		a.refreshView()
+		// Executar em segundo plano e mostrar o resultado ao final
+		var actions []utils.SyncAction
+		a.submitJob(fmt.Sprintf("Sincronizar %s -> %s", sourceDir, targetDir), func(jc *utils.JobContext) error {
+			var err error
+			actions, err = utils.SyncDirectoriesJob(jc, options)
+			return err
+		}, func(s utils.JobSnapshot) {
+			if s.Status == utils.JobCanceled {
+				return
+			}
+			if s.Err != nil && len(s.Errors) == 0 {
+				a.showError(fmt.Sprintf("Erro ao sincronizar: %v", s.Err))
+				return
+			}
+			a.showSyncResults(actions)
+		})
 	})
 
 	form.AddButton("Cancelar", func() {
@@ -503,7 +590,7 @@ func (a *App) showSimpleSearchDialog() {
 		a.pages.RemovePage("simpleSearchDialog")
 
 		// Realizar busca
-		a.performSearch(pattern, a.currentDir, true, false, false, "")
+		a.performSearch(utils.SearchOptions{Pattern: pattern, Directory: a.currentDir, Recursive: true, Literal: true})
 	})
 
 	form.AddButton("Cancelar", func() {
@@ -541,6 +628,8 @@ func (a *App) showAdvancedSearchDialog() {
 	form.AddCheckbox("Buscar em subdiretórios", true, nil)
 	form.AddCheckbox("Diferenciar maiúsculas/minúsculas", false, nil)
 	form.AddCheckbox("Buscar no conteúdo dos arquivos", false, nil)
+	form.AddCheckbox("Expressão regular", false, nil)
+	form.AddCheckbox("Respeitar .gitignore/.ignore", true, nil)
 
 	// Adicionar botões
 	form.AddButton("Buscar", func() {
@@ -552,6 +641,8 @@ func (a *App) showAdvancedSearchDialog() {
 		recursive := form.GetFormItem(4).(*tview.Checkbox).IsChecked()
 		matchCase := form.GetFormItem(5).(*tview.Checkbox).IsChecked()
 		searchContent := form.GetFormItem(6).(*tview.Checkbox).IsChecked()
+		useRegexp := form.GetFormItem(7).(*tview.Checkbox).IsChecked()
+		useIgnore := form.GetFormItem(8).(*tview.Checkbox).IsChecked()
 
 		// Validar campos
 		if pattern == "" {
@@ -602,7 +693,17 @@ func (a *App) showAdvancedSearchDialog() {
 		a.pages.RemovePage("advancedSearchDialog")
 
 		// Realizar busca
-		a.performSearch(pattern, searchDir, recursive, matchCase, searchContent, fileType)
+		a.performSearch(utils.SearchOptions{
+			Pattern:       pattern,
+			Directory:     searchDir,
+			Recursive:     recursive,
+			CaseSensitive: matchCase,
+			MatchContent:  searchContent,
+			FileTypes:     parseFileTypes(fileType),
+			Literal:       !useRegexp,
+			NoIgnore:      !useIgnore,
+			Context:       2,
+		})
 	})
 
 	form.AddButton("Cancelar", func() {
@@ -619,5 +720,5 @@ func (a *App) showAdvancedSearchDialog() {
 	})
 
 	// Exibir diálogo
-	a.pages.AddPage("advancedSearchDialog", a.modal(form, 60, 15), true, true)
+	a.pages.AddPage("advancedSearchDialog", a.modal(form, 60, 19), true, true)
 }
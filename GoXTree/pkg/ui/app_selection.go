@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,7 +23,7 @@ func (a *App) selectAll() {
 	}
 
 	// Obter lista de arquivos no diretório atual
-	files, err := os.ReadDir(a.currentDir)
+	files, err := a.fileView.listFiles(true)
 	if err != nil {
 		a.showError("Erro ao listar arquivos: " + err.Error())
 		return
@@ -30,8 +31,8 @@ func (a *App) selectAll() {
 
 	// Adicionar todos os arquivos à seleção
 	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(a.currentDir, file.Name())
+		if !file.IsDir {
+			filePath := filepath.Join(a.currentDir, file.Name)
 			a.selectedFiles[filePath] = true
 		}
 	}
@@ -55,38 +56,6 @@ func (a *App) unselectAll() {
 	a.statusBar.SetStatus("Seleção removida")
 }
 
-// toggleSelection alterna a seleção do arquivo atual
-func (a *App) toggleSelection() {
-	// Obter arquivo selecionado
-	row, _ := a.fileView.fileList.GetSelection()
-	if row <= 1 { // Cabeçalho ou diretório pai
-		return
-	}
-
-	// Obter nome do arquivo
-	fileName := a.fileView.fileList.GetCell(row, 0).Text
-	filePath := filepath.Join(a.currentDir, fileName)
-
-	// Verificar se o arquivo já está selecionado
-	if _, ok := a.selectedFiles[filePath]; ok {
-		// Remover da seleção
-		delete(a.selectedFiles, filePath)
-	} else {
-		// Adicionar à seleção
-		a.selectedFiles[filePath] = true
-	}
-
-	// Atualizar visualização
-	a.refreshFileView()
-
-	// Atualizar barra de status
-	if len(a.selectedFiles) > 0 {
-		a.statusBar.SetStatus(fmt.Sprintf("%d arquivos selecionados", len(a.selectedFiles)))
-	} else {
-		a.statusBar.SetStatus("Nenhum arquivo selecionado")
-	}
-}
-
 // toggleSelectionWithSpace alterna a seleção do arquivo atual e move para o próximo
 func (a *App) toggleSelectionWithSpace() {
 	// Obter arquivo selecionado
@@ -95,24 +64,20 @@ func (a *App) toggleSelectionWithSpace() {
 		return
 	}
 
-	// Obter nome do arquivo
-	fileName := a.fileView.fileList.GetCell(row, 0).Text
-	filePath := filepath.Join(a.currentDir, fileName)
-
-	// Verificar se o arquivo já está selecionado
-	if _, ok := a.selectedFiles[filePath]; ok {
-		// Remover da seleção
-		delete(a.selectedFiles, filePath)
-	} else {
-		// Adicionar à seleção
-		a.selectedFiles[filePath] = true
+	// Obter nome do arquivo (a célula pode ter ícone e destino de link)
+	fileName := a.fileView.GetSelectedFile()
+	if fileName == "" || fileName == ".." {
+		return
 	}
+	filePath := filepath.Join(a.currentDir, fileName)
 
-	// Mover para o próximo item
-	a.fileView.fileList.Select(row+1, 0)
+	a.ToggleTag(filePath)
 
-	// Atualizar visualização
-	a.refreshFileView()
+	// Atualizar visualização e mover para o próximo item
+	a.fileView.Reload()
+	if row+1 < a.fileView.fileList.GetRowCount() {
+		a.fileView.fileList.Select(row+1, 0)
+	}
 
 	// Atualizar barra de status
 	if len(a.selectedFiles) > 0 {
@@ -130,12 +95,15 @@ func (a *App) viewCurrentFile() {
 		return
 	}
 
-	// Obter nome do arquivo
-	fileName := a.fileView.fileList.GetCell(row, 0).Text
+	// Obter nome do arquivo (a célula pode ter ícone e destino de link)
+	fileName := a.fileView.GetSelectedFile()
+	if fileName == "" || fileName == ".." {
+		return
+	}
 	filePath := filepath.Join(a.currentDir, fileName)
 
 	// Verificar se é um diretório
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := utils.Stat(filePath)
 	if err != nil {
 		a.showError("Erro ao acessar arquivo: " + err.Error())
 		return
@@ -146,6 +114,13 @@ func (a *App) viewCurrentFile() {
 		return
 	}
 
+	// Arquivos dentro de arquivos compactados são extraídos antes
+	filePath, err = a.localFile(filePath)
+	if err != nil {
+		a.showError("Erro ao extrair arquivo: " + err.Error())
+		return
+	}
+
 	// Ler conteúdo do arquivo
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -179,100 +154,6 @@ func (a *App) viewCurrentFile() {
 	a.pages.AddAndSwitchToPage("view", textView, true)
 }
 
-// editCurrentFile abre o arquivo atual para edição
-func (a *App) editCurrentFile() {
-	// Obter arquivo selecionado
-	selectedFile := a.fileView.GetSelectedFile()
-	if selectedFile == "" {
-		a.showMessage("Nenhum arquivo selecionado")
-		return
-	}
-
-	// Obter caminho completo
-	filePath := filepath.Join(a.currentDir, selectedFile)
-
-	// Verificar se é um arquivo
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		a.showMessage(fmt.Sprintf("Erro ao acessar arquivo: %v", err))
-		return
-	}
-
-	if fileInfo.IsDir() {
-		a.showMessage("Não é possível editar um diretório")
-		return
-	}
-
-	// Ler conteúdo do arquivo
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		a.showMessage(fmt.Sprintf("Erro ao ler arquivo: %v", err))
-		return
-	}
-
-	// Criar página de edição
-	editorPage := tview.NewFlex().SetDirection(tview.FlexRow)
-
-	// Criar área de texto
-	textArea := tview.NewTextView().
-		SetText(string(content)).
-		SetScrollable(true).
-		SetWordWrap(true).
-		SetChangedFunc(func() {
-			a.app.Draw()
-		})
-
-	// Configurar borda
-	textArea.SetBorder(true).
-		SetTitle(fmt.Sprintf(" Editando: %s ", selectedFile)).
-		SetTitleAlign(tview.AlignLeft)
-
-	// Criar botões
-	saveButton := tview.NewButton("Salvar").SetSelectedFunc(func() {
-		// Obter conteúdo editado
-		newContent := textArea.GetText(true)
-
-		// Salvar arquivo
-		err := os.WriteFile(filePath, []byte(newContent), 0644)
-		if err != nil {
-			a.showError(fmt.Sprintf("Erro ao salvar arquivo: %v", err))
-			return
-		}
-
-		// Voltar à visualização principal
-		a.pages.RemovePage("editor")
-		a.refreshView()
-		a.showMessage("Arquivo salvo com sucesso")
-	})
-
-	cancelButton := tview.NewButton("Cancelar").SetSelectedFunc(func() {
-		// Voltar à visualização principal
-		a.pages.RemovePage("editor")
-	})
-
-	// Criar layout de botões
-	buttons := tview.NewFlex().
-		AddItem(saveButton, 0, 1, false).
-		AddItem(nil, 1, 0, false).
-		AddItem(cancelButton, 0, 1, false)
-
-	// Adicionar componentes à página
-	editorPage.AddItem(textArea, 0, 1, true)
-	editorPage.AddItem(buttons, 1, 0, false)
-
-	// Adicionar página ao aplicativo
-	a.pages.AddPage("editor", editorPage, true, true)
-
-	// Configurar manipulador de teclas
-	editorPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("editor")
-			return nil
-		}
-		return event
-	})
-}
-
 // copySelectedFiles copia os arquivos selecionados para a área de transferência
 func (a *App) copySelectedFiles() {
 	// Verificar se há arquivos selecionados
@@ -311,48 +192,90 @@ func (a *App) pasteFiles() {
 		return
 	}
 
-	// Contar arquivos processados
-	processed := 0
+	a.pasteFilesTo(a.selectedFiles, a.currentDir, a.clipboard == "cut")
+}
 
-	// Processar cada arquivo selecionado
-	for filePath := range a.selectedFiles {
-		// Obter nome do arquivo
-		fileName := filepath.Base(filePath)
+// pasteFilesTo copia (ou move) os arquivos informados para o diretório de destino
+func (a *App) pasteFilesTo(files map[string]bool, destDir string, move bool) {
+	if a.checkReadOnly(destDir) {
+		return
+	}
 
-		// Definir caminho de destino
-		destPath := filepath.Join(a.currentDir, fileName)
+	// Itens marcados em diretórios diferentes podem ter o mesmo nome
+	if duplicates := DuplicateTaggedNames(files); len(duplicates) > 0 {
+		a.showError(fmt.Sprintf("Há itens com o mesmo nome em diretórios diferentes: %s. Desmarque um deles (Ctrl+S lista os marcados).", strings.Join(duplicates, ", ")))
+		return
+	}
 
-		// Verificar se o arquivo já existe no destino
+	// Itens de arquivos compactados só podem ser extraídos (copiados)
+	fromArchive := hasArchiveFiles(files)
+	if fromArchive && move {
+		a.showError("Arquivos compactados são somente leitura: não é possível mover itens de dentro deles")
+		return
+	}
+
+	// Verificar conflitos no destino antes de começar
+	var conflicts []string
+	for filePath := range files {
+		destPath := filepath.Join(destDir, filepath.Base(filePath))
+		if destPath == filePath {
+			continue
+		}
 		if _, err := os.Stat(destPath); err == nil {
-			// Perguntar se deseja sobrescrever
-			a.showConfirmDialog("Confirmação", fmt.Sprintf("O arquivo %s já existe. Sobrescrever?", fileName), func(confirmed bool) {
-				if !confirmed {
-					return
-				}
+			conflicts = append(conflicts, filepath.Base(filePath))
+		}
+	}
 
-				// Continuar com a cópia
-				a.doCopy(filePath, destPath, false)
+	// Executar a operação em segundo plano
+	run := func(overwrite bool) {
+		if fromArchive {
+			if len(conflicts) == 0 || overwrite {
+				a.extractFilesTo(files, destDir)
+			}
+			return
+		}
 
-				a.refreshView()
-			})
-		} else {
-			// Copiar arquivo
-			a.doCopy(filePath, destPath, false)
+		var transfers []jobTransfer
+		for filePath := range files {
+			destPath := filepath.Join(destDir, filepath.Base(filePath))
 
-			a.refreshView()
+			// Ignorar arquivos que já estão no destino
+			if destPath == filePath {
+				continue
+			}
+
+			// Ignorar conflitos se o usuário não quiser sobrescrever
+			if _, err := os.Stat(destPath); err == nil && !overwrite {
+				continue
+			}
+
+			transfers = append(transfers, jobTransfer{src: filePath, dest: destPath})
 		}
+		sort.Slice(transfers, func(i, j int) bool {
+			return transfers[i].src < transfers[j].src
+		})
 
-		processed++
-	}
+		// Limpar seleção
+		a.selectedFiles = make(map[string]bool)
+		if move {
+			a.clipboard = ""
+		}
+		a.refreshFileView()
 
-	// Limpar seleção
-	a.selectedFiles = make(map[string]bool)
+		if len(transfers) == 0 {
+			a.statusBar.SetStatus("Nenhum arquivo a processar")
+			return
+		}
+		a.startTransferJob(transfers, destDir, move, nil)
+	}
 
-	// Atualizar visualização
-	a.refreshFileView()
+	if len(conflicts) == 0 {
+		run(false)
+		return
+	}
 
-	// Atualizar barra de status
-	a.statusBar.SetStatus(fmt.Sprintf("%d arquivos processados", processed))
+	// Perguntar se deseja sobrescrever
+	a.showConfirmDialog("Confirmação", fmt.Sprintf("%d arquivo(s) já existe(m) em %s. Sobrescrever?", len(conflicts), destDir), run)
 }
 
 // invertSelection inverte a seleção de arquivos
@@ -363,7 +286,7 @@ func (a *App) invertSelection() {
 	}
 
 	// Obter lista de arquivos no diretório atual
-	files, err := os.ReadDir(a.currentDir)
+	files, err := a.fileView.listFiles(true)
 	if err != nil {
 		a.showError("Erro ao listar arquivos: " + err.Error())
 		return
@@ -371,8 +294,8 @@ func (a *App) invertSelection() {
 
 	// Inverter seleção
 	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(a.currentDir, file.Name())
+		if !file.IsDir {
+			filePath := filepath.Join(a.currentDir, file.Name)
 			a.selectedFiles[filePath] = !a.selectedFiles[filePath]
 		}
 	}
@@ -397,7 +320,7 @@ func (a *App) selectByPattern() {
 		}
 
 		// Obter lista de arquivos
-		files, err := os.ReadDir(a.currentDir)
+		files, err := a.fileView.listFiles(true)
 		if err != nil {
 			a.showError("Erro ao ler diretório: " + err.Error())
 			return
@@ -409,14 +332,14 @@ func (a *App) selectByPattern() {
 		// Selecionar arquivos que correspondem ao padrão
 		for _, file := range files {
 			// Ignorar diretório pai
-			if file.Name() == ".." {
+			if file.Name == ".." {
 				continue
 			}
 
 			// Verificar se corresponde ao padrão
-			if regex.MatchString(file.Name()) {
+			if regex.MatchString(filepath.Base(file.Name)) {
 				// Adicionar à seleção
-				filePath := filepath.Join(a.currentDir, file.Name())
+				filePath := filepath.Join(a.currentDir, file.Name)
 				a.selectedFiles[filePath] = true
 				count++
 			}
@@ -457,6 +380,11 @@ func (a *App) showSelectionMenu() {
 		a.selectByPattern()
 	})
 
+	menu.AddItem("Mostrar Todos os Marcados", "Lista os itens marcados em todos os diretórios (Ctrl+S)", 'm', func() {
+		a.pages.RemovePage("selectionMenu")
+		a.showTaggedFiles()
+	})
+
 	menu.AddItem("Voltar", "Volta ao gerenciador de arquivos", 'v', func() {
 		a.pages.RemovePage("selectionMenu")
 	})
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Os itens marcados (a.selectedFiles) formam um conjunto global, como no XTree:
+// guardam o caminho completo, continuam marcados ao trocar de diretório ou de
+// painel e as operações de cópia, movimentação, exclusão e compactação valem
+// para todos eles, estejam onde estiverem.
+
+// ToggleTag marca o caminho ou, se já estiver marcado, desmarca-o
+func (a *App) ToggleTag(path string) {
+	if a.selectedFiles[path] {
+		delete(a.selectedFiles, path)
+	} else {
+		a.selectedFiles[path] = true
+	}
+}
+
+// TaggedPaths retorna os caminhos marcados em ordem alfabética
+func (a *App) TaggedPaths() []string {
+	paths := make([]string, 0, len(a.selectedFiles))
+	for path := range a.selectedFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// DuplicateTaggedNames retorna os nomes que aparecem em mais de um item marcado;
+// copiados para o mesmo destino, um sobrescreveria o outro
+func DuplicateTaggedNames(files map[string]bool) []string {
+	count := make(map[string]int)
+	for path := range files {
+		count[filepath.Base(path)]++
+	}
+	var names []string
+	for name, n := range count {
+		if n > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// transferTagged pergunta o destino e copia (ou move) todos os itens marcados.
+// O destino sugerido é o outro painel, se estiver aberto, ou o diretório atual.
+func (a *App) transferTagged(move bool) {
+	files := a.selectedFiles
+	operation := "Copiar"
+	if move {
+		operation = "Mover"
+	}
+
+	defaultDir := a.otherPanelDir()
+	if defaultDir == "" {
+		defaultDir = a.currentDir
+	}
+
+	title := fmt.Sprintf("%s %d item(ns) marcado(s) para", operation, len(files))
+	a.showInputDialogWithValue(title, defaultDir, func(destDir string) {
+		destDir = strings.TrimSpace(destDir)
+		if destDir == "" {
+			return
+		}
+
+		// Expandir ~ e caminhos relativos ao diretório atual
+		if strings.HasPrefix(destDir, "~") {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				destDir = filepath.Join(homeDir, destDir[1:])
+			}
+		}
+		if !filepath.IsAbs(destDir) {
+			destDir = filepath.Join(a.currentDir, destDir)
+		}
+
+		info, err := utils.Stat(destDir)
+		if err != nil {
+			a.showError(fmt.Sprintf("Destino inválido: %v", err))
+			return
+		}
+		if !info.IsDir() {
+			a.showError("O destino deve ser um diretório")
+			return
+		}
+
+		a.pasteFilesTo(files, destDir, move)
+	})
+}
+
+// showTaggedFiles lista todos os itens marcados, de qualquer diretório (Ctrl+S),
+// como o "showall" do XTree. Itens que não existem mais são desmarcados.
+func (a *App) showTaggedFiles() {
+	if len(a.selectedFiles) == 0 {
+		a.showMessage("Nenhum item marcado")
+		return
+	}
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Enter[white] vai até o item  [yellow]Espaço/Del[white] desmarca  [yellow]Ctrl+D[white] desmarca todos  " +
+			"[yellow]F5[white] copia  [yellow]F6[white] move  [yellow]F8[white] exclui  [yellow]Alt+Z[white] compacta  [yellow]Esc[white] fecha")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+
+	var paths []string
+	load := func() {
+		row, _ := table.GetSelection()
+		table.Clear()
+		for col, title := range []string{"Nome", "Diretório", "Tamanho", "Modificado"} {
+			table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		}
+
+		var total int64
+		paths = paths[:0]
+		for _, path := range a.TaggedPaths() {
+			info, err := utils.LstatFS(utils.FSFor(path), path)
+			if err != nil {
+				delete(a.selectedFiles, path)
+				continue
+			}
+			paths = append(paths, path)
+
+			file := utils.NewFileInfo(utils.FSFor(path), path, info)
+			color := tview.Styles.PrimaryTextColor
+			if file.IsDir {
+				color = tcell.ColorLightBlue
+			} else {
+				total += file.Size
+			}
+			r := len(paths)
+			table.SetCell(r, 0, tview.NewTableCell(tview.Escape(utils.GetFileIcon(file)+" "+file.Name)).SetTextColor(color))
+			table.SetCell(r, 1, tview.NewTableCell(tview.Escape(filepath.Dir(path))).SetTextColor(color).SetExpansion(1))
+			table.SetCell(r, 2, tview.NewTableCell(findColumn("size").text(file)).SetTextColor(color).SetAlign(tview.AlignRight))
+			table.SetCell(r, 3, tview.NewTableCell(formatDate(file.ModTime)).SetTextColor(color))
+		}
+
+		layout.SetTitle(fmt.Sprintf(" Todos os Marcados: %d item(ns), %s ", len(paths), utils.FormatFileSize(total)))
+		table.Select(min(max(row, 1), max(len(paths), 1)), 0)
+	}
+
+	closePage := func() {
+		a.pages.RemovePage("tagged")
+		a.app.SetFocus(a.fileView.fileList)
+		a.fileView.Reload()
+		a.refreshStatus()
+	}
+
+	current := func() string {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(paths) {
+			return ""
+		}
+		return paths[row-1]
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closePage()
+			return nil
+		case tcell.KeyEnter:
+			// Ir até o item no seu diretório
+			if path := current(); path != "" {
+				closePage()
+				a.navigateTo(filepath.Dir(path))
+				a.fileView.SelectFile(filepath.Base(path))
+			}
+			return nil
+		case tcell.KeyDelete:
+			if path := current(); path != "" {
+				delete(a.selectedFiles, path)
+				load()
+			}
+			return nil
+		case tcell.KeyF5, tcell.KeyF6:
+			move := event.Key() == tcell.KeyF6
+			closePage()
+			if a.dualPane {
+				a.transferToOtherPanel(move)
+			} else {
+				a.transferTagged(move)
+			}
+			return nil
+		case tcell.KeyF8, tcell.KeyF20: // F20: Shift+F8 em terminais que não informam o modificador
+			closePage()
+			a.confirmDelete(event.Key() == tcell.KeyF20 || event.Modifiers()&tcell.ModShift != 0)
+			return nil
+		case tcell.KeyCtrlD:
+			a.selectedFiles = make(map[string]bool)
+			closePage()
+			a.statusBar.SetStatus("Marcações removidas")
+			return nil
+		case tcell.KeyRune:
+			switch {
+			case event.Rune() == ' ':
+				if path := current(); path != "" {
+					delete(a.selectedFiles, path)
+					load()
+				}
+				return nil
+			case event.Modifiers()&tcell.ModAlt != 0 && (event.Rune() == 'z' || event.Rune() == 'Z'):
+				closePage()
+				a.showCreateArchiveDialog()
+				return nil
+			}
+		}
+		return event
+	})
+
+	load()
+
+	a.pages.AddPage("tagged", layout, true, true)
+	a.app.SetFocus(table)
+}
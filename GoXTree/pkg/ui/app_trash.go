@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// undoLast desfaz a última operação registrada (Ctrl+Z)
+func (a *App) undoLast() {
+	op, ok := a.undo.Pop()
+	if !ok {
+		a.statusBar.SetStatus("Nada para desfazer")
+		return
+	}
+
+	a.submitJob("Desfazer: "+op.Description, func(jc *utils.JobContext) error {
+		return op.Undo(jc)
+	}, nil)
+}
+
+// showTrash exibe o conteúdo da lixeira, permitindo restaurar ou excluir itens (Alt+T)
+func (a *App) showTrash() {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(" Lixeira (R:Restaurar Del:Excluir E:Esvaziar ESC:Fechar) ").
+		SetTitleAlign(tview.AlignLeft)
+
+	var entries []utils.TrashEntry
+
+	// Recarregar a lista de itens
+	load := func() {
+		var err error
+		entries, err = utils.ListTrash()
+		if err != nil {
+			a.showError(fmt.Sprintf("Erro ao ler a lixeira: %v", err))
+			return
+		}
+
+		table.Clear()
+		headers := []string{"Nome", "Local original", "Excluído em", "Tamanho"}
+		for col, header := range headers {
+			table.SetCell(0, col, tview.NewTableCell(header).
+				SetTextColor(tcell.ColorYellow).
+				SetSelectable(false))
+		}
+
+		for i, entry := range entries {
+			name := entry.Name
+			if entry.IsDir {
+				name += "/"
+			}
+			table.SetCell(i+1, 0, tview.NewTableCell(name).SetMaxWidth(30))
+			table.SetCell(i+1, 1, tview.NewTableCell(entry.OriginalPath).SetExpansion(1))
+			table.SetCell(i+1, 2, tview.NewTableCell(entry.DeletionDate.Format("02/01/2006 15:04")))
+			table.SetCell(i+1, 3, tview.NewTableCell(utils.FormatFileSize(entry.Size)).SetAlign(tview.AlignRight))
+		}
+
+		if len(entries) == 0 {
+			table.SetCell(1, 0, tview.NewTableCell("A lixeira está vazia").SetSelectable(false))
+		}
+		table.ScrollToBeginning()
+		table.Select(1, 0)
+	}
+
+	// Item sob o cursor
+	selected := func() (utils.TrashEntry, bool) {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(entries) {
+			return utils.TrashEntry{}, false
+		}
+		return entries[row-1], true
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("trash")
+			a.app.SetFocus(a.fileView.fileList)
+			return nil
+		case tcell.KeyDelete:
+			a.purgeTrashEntry(selected, load, table)
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'r', 'R':
+			entry, ok := selected()
+			if !ok {
+				return nil
+			}
+			if err := utils.RestoreFromTrash(entry); err != nil {
+				a.showError(fmt.Sprintf("Erro ao restaurar: %v", err))
+				return nil
+			}
+			load()
+			a.refreshView()
+			a.statusBar.SetStatus(fmt.Sprintf("'%s' restaurado em %s", entry.Name, entry.OriginalPath))
+			return nil
+		case 'd', 'D':
+			a.purgeTrashEntry(selected, load, table)
+			return nil
+		case 'e', 'E':
+			a.showConfirmDialog("Esvaziar Lixeira", "Excluir definitivamente todos os itens da lixeira?", func(confirmed bool) {
+				if confirmed {
+					if err := utils.EmptyTrash(); err != nil {
+						a.showError(fmt.Sprintf("Erro ao esvaziar a lixeira: %v", err))
+					}
+					load()
+				}
+				a.app.SetFocus(table)
+			})
+			return nil
+		}
+		return event
+	})
+
+	load()
+	a.pages.AddPage("trash", a.modal(table, 100, 25), true, true)
+	a.app.SetFocus(table)
+}
+
+// purgeTrashEntry exclui definitivamente o item selecionado da lixeira, após confirmação
+func (a *App) purgeTrashEntry(selected func() (utils.TrashEntry, bool), reload func(), table *tview.Table) {
+	entry, ok := selected()
+	if !ok {
+		return
+	}
+
+	a.showConfirmDialog("Excluir Definitivamente", fmt.Sprintf("Excluir '%s' definitivamente?", entry.Name), func(confirmed bool) {
+		if confirmed {
+			if err := utils.PurgeTrashEntry(entry); err != nil {
+				a.showError(fmt.Sprintf("Erro ao excluir: %v", err))
+			}
+			reload()
+		}
+		a.app.SetFocus(table)
+	})
+}
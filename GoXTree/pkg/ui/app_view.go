@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// setViewMode aplica o modo de visualização aos dois painéis. No modo
+// detalhes a árvore é escondida e a lista ocupa toda a largura.
+func (a *App) setViewMode(mode ViewMode) {
+	a.viewMode = mode
+	for _, fv := range a.panels {
+		fv.SetViewMode(mode)
+	}
+
+	treeProportion := 1
+	if mode == ViewModeDetails {
+		treeProportion = 0
+		if a.app.GetFocus() == a.treeView.TreeView {
+			a.app.SetFocus(a.fileView.fileList)
+		}
+	}
+	a.horizontalLayout.ResizeItem(a.treeView.TreeView, 0, treeProportion)
+	a.updatePanelBorders()
+}
+
+// cycleViewMode alterna entre árvore, ramo e detalhes (Alt+M) e salva a escolha
+func (a *App) cycleViewMode() {
+	mode := (a.viewMode + 1) % ViewMode(len(viewModeNames))
+	a.setViewMode(mode)
+	a.saveViewConfig()
+	a.statusBar.SetStatus("Modo de visualização: " + mode.Label())
+}
+
+// setColumns aplica as colunas aos dois painéis
+func (a *App) setColumns(columns []ColumnConfig) {
+	for _, fv := range a.panels {
+		fv.SetColumns(columns)
+	}
+}
+
+// saveViewConfig salva as colunas e o modo de visualização na configuração
+func (a *App) saveViewConfig() {
+	config, err := LoadConfig()
+	if err != nil {
+		a.showError(fmt.Sprintf("Erro ao carregar configuração: %s", err))
+		return
+	}
+	config.Columns = a.fileView.Columns()
+	config.ViewMode = a.viewMode.String()
+	if err := SaveConfig(config); err != nil {
+		a.showError(fmt.Sprintf("Erro ao salvar configuração: %s", err))
+	}
+}
+
+// columnEntry é uma linha do diálogo de colunas
+type columnEntry struct {
+	column  ColumnConfig
+	visible bool
+}
+
+// showColumnsDialog abre o diálogo para escolher, ordenar e dimensionar as
+// colunas da lista de arquivos (Alt+O). As mudanças aparecem na hora e são
+// salvas ao fechar.
+func (a *App) showColumnsDialog() {
+	// Colunas visíveis na ordem atual, seguidas das ocultas
+	var entries []columnEntry
+	visible := make(map[string]bool)
+	for _, column := range a.fileView.Columns() {
+		entries = append(entries, columnEntry{column: column, visible: true})
+		visible[column.ID] = true
+	}
+	for _, def := range fileColumns {
+		if !visible[def.id] {
+			entries = append(entries, columnEntry{column: ColumnConfig{ID: def.id}})
+		}
+	}
+
+	table := tview.NewTable().SetSelectable(true, false)
+
+	render := func() {
+		row, _ := table.GetSelection()
+		table.Clear()
+		for i, entry := range entries {
+			mark, color := "[ ]", tcell.ColorGray
+			if entry.visible {
+				mark, color = "[x]", tview.Styles.PrimaryTextColor
+			}
+			width := "auto"
+			if entry.column.Width > 0 {
+				width = strconv.Itoa(entry.column.Width)
+			}
+			table.SetCell(i, 0, tview.NewTableCell(tview.Escape(mark)).SetTextColor(color))
+			table.SetCell(i, 1, tview.NewTableCell(findColumn(entry.column.ID).title).SetTextColor(color).SetExpansion(1))
+			table.SetCell(i, 2, tview.NewTableCell(width).SetTextColor(color).SetAlign(tview.AlignRight))
+		}
+		table.Select(row, 0)
+	}
+
+	apply := func() {
+		var columns []ColumnConfig
+		for _, entry := range entries {
+			if entry.visible {
+				columns = append(columns, entry.column)
+			}
+		}
+		a.setColumns(columns)
+		render()
+	}
+
+	// move troca a linha atual com a vizinha e leva o cursor junto
+	move := func(delta int) {
+		row, _ := table.GetSelection()
+		if row+delta < 0 || row+delta >= len(entries) {
+			return
+		}
+		entries[row], entries[row+delta] = entries[row+delta], entries[row]
+		table.Select(row+delta, 0)
+		apply()
+	}
+
+	// resize muda a largura da coluna atual; a partir de "auto" começa na
+	// largura do título ou em 10
+	resize := func(delta int) {
+		row, _ := table.GetSelection()
+		column := &entries[row].column
+		if column.Width == 0 {
+			column.Width = max(tview.TaggedStringWidth(findColumn(column.ID).title), 10)
+		}
+		column.Width = max(column.Width+delta, 3)
+		apply()
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("columns")
+		a.app.SetFocus(a.fileView.fileList)
+		a.saveViewConfig()
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyEnter:
+			closeDialog()
+			return nil
+		case tcell.KeyUp:
+			if event.Modifiers()&tcell.ModShift != 0 {
+				move(-1)
+				return nil
+			}
+		case tcell.KeyDown:
+			if event.Modifiers()&tcell.ModShift != 0 {
+				move(1)
+				return nil
+			}
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case ' ':
+				// O nome não pode ser escondido
+				if entries[row].column.ID != "name" {
+					entries[row].visible = !entries[row].visible
+					apply()
+				}
+			case '<':
+				move(-1)
+			case '>':
+				move(1)
+			case '+', '=':
+				resize(1)
+			case '-':
+				resize(-1)
+			case '0':
+				entries[row].column.Width = 0
+				apply()
+			}
+			return nil
+		}
+		return event
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Espaço[white] mostra/oculta  [yellow]< >[white] ou [yellow]Shift+↑↓[white] movem\n[yellow]+/-[white] largura  [yellow]0[white] automática  [yellow]Enter/Esc[white] fecha")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(hint, 2, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Colunas ").
+		SetTitleAlign(tview.AlignLeft)
+
+	render()
+	a.pages.AddPage("columns", a.modal(layout, 52, len(entries)+4), true, true)
+	a.app.SetFocus(table)
+}
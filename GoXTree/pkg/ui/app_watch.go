@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// startWatcher inicia o observador de diretórios que atualiza as visualizações
+// automaticamente quando arquivos são criados, alterados ou removidos
+func (a *App) startWatcher() {
+	watcher, err := utils.NewDirWatcher(utils.DefaultWatchDebounce, func(dirs []string) {
+		a.app.QueueUpdateDraw(func() {
+			a.handleFSChanges(dirs)
+		})
+	})
+	if err != nil {
+		// Sem observador, a atualização continua disponível via Ctrl+R
+		a.statusBar.SetStatus("Observador de arquivos indisponível: " + err.Error())
+		return
+	}
+
+	a.watcher = watcher
+	a.updateWatches()
+}
+
+// stopWatcher encerra o observador de diretórios
+func (a *App) stopWatcher() {
+	if a.watcher != nil {
+		a.watcher.Close()
+		a.watcher = nil
+	}
+}
+
+// updateWatches ajusta os diretórios observados: o diretório de cada painel
+// e os nós expandidos da árvore
+func (a *App) updateWatches() {
+	if a.watcher == nil {
+		return
+	}
+
+	var dirs []string
+	candidates := []string{a.currentDir}
+	if dir := a.otherPanelDir(); dir != "" {
+		candidates = append(candidates, dir)
+	}
+	candidates = append(candidates, a.treeView.ExpandedDirs()...)
+
+	// Diretórios dentro de arquivos compactados não podem ser observados
+	for _, dir := range candidates {
+		if utils.IsLocalPath(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	a.watcher.SetDirs(dirs)
+}
+
+// handleFSChanges atualiza as visualizações afetadas pelos diretórios alterados
+// (executado na goroutine da interface)
+func (a *App) handleFSChanges(dirs []string) {
+	// Arquivos alterados mudam a situação no git
+	a.invalidateGitStatus()
+
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+
+		// Painel ativo
+		if dir == filepath.Clean(a.currentDir) {
+			a.fileView.Reload()
+			a.statusBar.UpdateStatus(a.currentDir)
+		}
+
+		// Painel inativo
+		if other := a.otherPanelDir(); other != "" && dir == filepath.Clean(other) {
+			a.otherPanel.fileView.Reload()
+		}
+
+		// Nós da árvore
+		a.treeView.RefreshDir(dir)
+	}
+
+	// Marcadores do git nos diretórios acima dos alterados
+	a.treeView.RefreshGit()
+
+	// Diretórios podem ter surgido ou sumido na árvore
+	a.updateWatches()
+}
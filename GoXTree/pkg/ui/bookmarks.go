@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// Bookmarks associa letras (a–z) a diretórios
+type Bookmarks map[string]string
+
+// frecencyFileName é o arquivo com os diretórios visitados, ao lado de config.json
+const frecencyFileName = "frecency.json"
+
+// LoadBookmarks carrega os marcadores do arquivo
+func LoadBookmarks() (Bookmarks, error) {
+	// Obter diretório de configuração
+	configDir, err := getConfigDir()
+	if err != nil {
+		return make(Bookmarks), err
+	}
+
+	// Sem arquivo, não há marcadores
+	data, err := os.ReadFile(filepath.Join(configDir, "bookmarks.json"))
+	if os.IsNotExist(err) {
+		return make(Bookmarks), nil
+	}
+	if err != nil {
+		return make(Bookmarks), err
+	}
+
+	// Decodificar JSON
+	bookmarks := make(Bookmarks)
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return make(Bookmarks), err
+	}
+	return bookmarks, nil
+}
+
+// SaveBookmarks salva os marcadores no arquivo
+func SaveBookmarks(bookmarks Bookmarks) error {
+	// Obter diretório de configuração
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+
+	// Criar diretório se não existir
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+
+	// Codificar marcadores em JSON
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Salvar no arquivo
+	return os.WriteFile(filepath.Join(configDir, "bookmarks.json"), data, 0644)
+}
+
+// LoadFrecency carrega a base de diretórios visitados. Sem o diretório de
+// configuração não há onde guardá-la: o erro é retornado e a base fica nil.
+func LoadFrecency() (*utils.FrecencyDB, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return utils.LoadFrecencyDB(filepath.Join(configDir, frecencyFileName))
+}
@@ -13,6 +13,16 @@ type Config struct {
 	ColorScheme   map[string]string `json:"colorScheme"`
 	ShowHidden    bool              `json:"showHidden"`
 	CustomHotkeys map[string]string `json:"customHotkeys"`
+	Columns       []ColumnConfig    `json:"columns,omitempty"`
+	ViewMode      string            `json:"viewMode,omitempty"`
+	Filters       map[string]string `json:"filters,omitempty"` // Filtros nomeados (nome -> filespec)
+}
+
+// ColumnConfig descreve uma coluna visível da lista de arquivos; a ordem na
+// configuração é a ordem na tela
+type ColumnConfig struct {
+	ID    string `json:"id"`              // name, size, modified, created, accessed, permissions, owner, mime, git, inode, links
+	Width int    `json:"width,omitempty"` // 0 ajusta a largura ao conteúdo
 }
 
 // DefaultConfig retorna as configurações padrão
@@ -34,6 +44,8 @@ func DefaultConfig() Config {
 			"deselectAll":  "Ctrl+D",
 			"toggleHidden": "Ctrl+H",
 		},
+		Columns:  DefaultColumns(),
+		ViewMode: ViewModeTree.String(),
 	}
 }
 
@@ -118,5 +130,9 @@ func ApplyTheme(app *App, themeName string) error {
 	default:
 		return fmt.Errorf("tema desconhecido: %s", themeName)
 	}
+	app.theme = themeName
+
+	// Aplicar as mesmas cores ao segundo painel de arquivos
+	app.syncPanelStyles()
 	return nil
 }
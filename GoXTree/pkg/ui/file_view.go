@@ -2,10 +2,11 @@ package ui
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
@@ -13,14 +14,131 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
+// branchLimit é o máximo de arquivos listados na visão de ramo (ViewModeFlat)
+const branchLimit = 20000
+
+// dateFormat é o formato das colunas de data
+const dateFormat = "02/01/2006 15:04:05"
+
+// fileColumn descreve uma coluna que pode ser exibida na lista de arquivos
+type fileColumn struct {
+	id    string
+	title string
+	align int
+	text  func(file utils.FileInfo) string // nil na coluna do nome, montada à parte
+}
+
+// fileColumns são as colunas disponíveis, na ordem em que aparecem no diálogo
+var fileColumns = []fileColumn{
+	{"name", "Nome", tview.AlignLeft, nil},
+	{"size", "Tamanho", tview.AlignRight, func(file utils.FileInfo) string {
+		if file.IsDir {
+			return "<DIR>"
+		}
+		return utils.FormatFileSize(file.Size)
+	}},
+	{"modified", "Modificado", tview.AlignLeft, func(file utils.FileInfo) string {
+		return formatDate(file.ModTime)
+	}},
+	{"created", "Criado", tview.AlignLeft, func(file utils.FileInfo) string {
+		return formatDate(file.BirthTime)
+	}},
+	{"accessed", "Acessado", tview.AlignLeft, func(file utils.FileInfo) string {
+		return formatDate(file.AccessTime)
+	}},
+	{"permissions", "Permissões", tview.AlignLeft, func(file utils.FileInfo) string {
+		if file.Mode == 0 {
+			return ""
+		}
+		return utils.FormatMode(file.Mode)
+	}},
+	{"owner", "Dono", tview.AlignLeft, func(file utils.FileInfo) string {
+		if file.Group == "" {
+			return file.Owner
+		}
+		return file.Owner + ":" + file.Group
+	}},
+	{"mime", "Tipo MIME", tview.AlignLeft, func(file utils.FileInfo) string {
+		if file.Name == ".." {
+			return ""
+		}
+		return utils.FileMIMEType(file)
+	}},
+	{"git", "Git", tview.AlignLeft, func(file utils.FileInfo) string {
+		return file.Git.Label()
+	}},
+	{"inode", "Inode", tview.AlignRight, func(file utils.FileInfo) string {
+		if file.Inode == 0 {
+			return ""
+		}
+		return strconv.FormatUint(file.Inode, 10)
+	}},
+	{"links", "Links", tview.AlignRight, func(file utils.FileInfo) string {
+		if file.Links == 0 {
+			return ""
+		}
+		return strconv.FormatUint(file.Links, 10)
+	}},
+}
+
+// formatDate formata as colunas de data; datas desconhecidas ficam em branco
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateFormat)
+}
+
+// findColumn retorna a coluna com o identificador, ou nil
+func findColumn(id string) *fileColumn {
+	for i := range fileColumns {
+		if fileColumns[i].id == id {
+			return &fileColumns[i]
+		}
+	}
+	return nil
+}
+
+// DefaultColumns retorna as colunas exibidas por padrão
+func DefaultColumns() []ColumnConfig {
+	return []ColumnConfig{{ID: "name"}, {ID: "git"}, {ID: "size"}, {ID: "modified"}, {ID: "permissions"}, {ID: "owner"}}
+}
+
+// normalizeColumns descarta colunas desconhecidas ou repetidas e garante que o
+// nome esteja sempre visível
+func normalizeColumns(columns []ColumnConfig) []ColumnConfig {
+	if len(columns) == 0 {
+		return DefaultColumns()
+	}
+
+	result := make([]ColumnConfig, 0, len(columns))
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		if findColumn(column.ID) == nil || seen[column.ID] {
+			continue
+		}
+		seen[column.ID] = true
+		column.Width = max(column.Width, 0)
+		result = append(result, column)
+	}
+	if !seen["name"] {
+		result = append([]ColumnConfig{{ID: "name"}}, result...)
+	}
+	return result
+}
+
 // FileView representa a visualização de arquivos
 type FileView struct {
-	app        *App
-	fileList   *tview.Table
-	currentDir string
-	files      []string
-	showHidden bool
-	itemCount  int
+	app         *App
+	fileList    *tview.Table
+	currentDir  string
+	files       []string
+	showHidden  bool
+	itemCount   int
+	headerColor tcell.Color
+	columns     []ColumnConfig
+	viewMode    ViewMode
+	filter      *utils.Filespec // Filtro da listagem deste painel; nil mostra tudo
 }
 
 // NewFileView cria uma nova visualização de arquivos
@@ -30,12 +148,6 @@ func NewFileView(app *App) *FileView {
 		SetBorders(false).
 		SetSelectable(true, false)
 
-	// Configurar cabeçalho
-	table.SetCell(0, 0, tview.NewTableCell("Nome").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
-	table.SetCell(0, 1, tview.NewTableCell("Tamanho").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignRight).SetSelectable(false))
-	table.SetCell(0, 2, tview.NewTableCell("Data").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
-	table.SetCell(0, 3, tview.NewTableCell("Permissões").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
-
 	// Configurar borda
 	table.SetBorder(true).
 		SetTitle(" Arquivos ").
@@ -43,14 +155,20 @@ func NewFileView(app *App) *FileView {
 
 	// Criar FileView
 	f := &FileView{
-		app:        app,
-		fileList:   table,
-		currentDir: "",
-		files:      make([]string, 0),
-		showHidden: false,
-		itemCount:  0,
+		app:         app,
+		fileList:    table,
+		currentDir:  "",
+		files:       make([]string, 0),
+		showHidden:  false,
+		itemCount:   0,
+		headerColor: tcell.ColorYellow,
+		columns:     DefaultColumns(),
+		viewMode:    ViewModeTree,
 	}
 
+	// Configurar cabeçalho
+	f.setHeader()
+
 	// Configurar manipulador de seleção
 	table.SetSelectedFunc(func(row, column int) {
 		if row == 0 {
@@ -62,10 +180,186 @@ func NewFileView(app *App) *FileView {
 	return f
 }
 
+// SetHeaderColor define a cor dos títulos das colunas
+func (f *FileView) SetHeaderColor(color tcell.Color) {
+	f.headerColor = color
+	f.setHeader()
+}
+
+// SetColumns define as colunas exibidas, na ordem informada, e redesenha a lista
+func (f *FileView) SetColumns(columns []ColumnConfig) {
+	f.columns = normalizeColumns(columns)
+	f.Reload()
+}
+
+// Columns retorna as colunas exibidas
+func (f *FileView) Columns() []ColumnConfig {
+	return append([]ColumnConfig(nil), f.columns...)
+}
+
+// SetViewMode define o modo de visualização. No modo ramo (ViewModeFlat) a
+// lista traz todos os arquivos abaixo do diretório atual.
+func (f *FileView) SetViewMode(mode ViewMode) {
+	if mode == f.viewMode {
+		return
+	}
+	f.viewMode = mode
+	f.Refresh()
+}
+
+// ViewMode retorna o modo de visualização
+func (f *FileView) ViewMode() ViewMode {
+	return f.viewMode
+}
+
+// setHeader escreve os títulos das colunas na primeira linha. Colunas com
+// largura fixa têm o título completado com espaços até a largura.
+func (f *FileView) setHeader() {
+	for col, column := range f.columns {
+		def := findColumn(column.ID)
+		title := def.title
+		if pad := column.Width - tview.TaggedStringWidth(title); pad > 0 {
+			title += strings.Repeat(" ", pad)
+		}
+		cell := tview.NewTableCell(title).SetTextColor(f.headerColor).SetAlign(def.align).SetSelectable(false)
+		if column.Width > 0 {
+			cell.SetMaxWidth(column.Width)
+		}
+		f.fileList.SetCell(0, col, cell)
+	}
+}
+
+// setRow preenche as colunas de uma linha. A célula do nome vem pronta, pois
+// ícone e cores dependem de quem monta a lista. Permissões com setuid, setgid
+// ou sticky ficam em destaque e a coluna do git tem a cor da situação.
+func (f *FileView) setRow(row int, file utils.FileInfo, nameCell *tview.TableCell, color tcell.Color) {
+	for col, column := range f.columns {
+		def := findColumn(column.ID)
+		cell := nameCell
+		if def.text != nil {
+			cell = tview.NewTableCell(def.text(file)).SetTextColor(color).SetAlign(def.align)
+			if def.id == "permissions" && utils.HasSpecialBits(file.Mode) {
+				cell.SetTextColor(tcell.ColorRed)
+			}
+			if gitColor, ok := gitFlagColor(file.Git.Flags()); def.id == "git" && ok {
+				cell.SetTextColor(gitColor)
+			}
+		}
+		if column.Width > 0 {
+			cell.SetMaxWidth(column.Width)
+		}
+		f.fileList.SetCell(row, col, cell)
+	}
+}
+
+// gitFlagColor retorna a cor da situação no git, pela ordem de importância:
+// conflito, alterado, preparado, não rastreado e ignorado
+func gitFlagColor(flags utils.GitFlags) (tcell.Color, bool) {
+	switch {
+	case flags&utils.GitConflicted != 0:
+		return tcell.ColorRed, true
+	case flags&utils.GitModified != 0:
+		return tcell.ColorOrange, true
+	case flags&utils.GitStaged != 0:
+		return tcell.ColorGreen, true
+	case flags&utils.GitUntracked != 0:
+		return tcell.ColorFuchsia, true
+	case flags&utils.GitIgnored != 0:
+		return tcell.ColorGray, true
+	}
+	return 0, false
+}
+
+// gitNameColor retorna a cor do nome conforme a situação no git. Diretórios
+// só mudam de cor quando inteiros não rastreados ou ignorados; as alterações
+// do conteúdo aparecem na coluna do git.
+func gitNameColor(file utils.FileInfo) (tcell.Color, bool) {
+	own := file.Git
+	own.Dir = 0
+	return gitFlagColor(own.Flags())
+}
+
+// displayName retorna o nome exibido; links simbólicos mostram o destino
+func displayName(file utils.FileInfo) string {
+	if file.LinkTarget != "" {
+		return file.Name + " -> " + file.LinkTarget
+	}
+	return file.Name
+}
+
+// SetFilter define o filtro da listagem e recarrega a lista
+func (f *FileView) SetFilter(filter *utils.Filespec) {
+	f.filter = filter
+	f.Reload()
+}
+
+// Filter retorna o filtro ativo, ou nil
+func (f *FileView) Filter() *utils.Filespec {
+	return f.filter
+}
+
+// IsTagged indica se o item do diretório exibido está marcado
+func (f *FileView) IsTagged(name string) bool {
+	return f.app != nil && f.app.selectedFiles[filepath.Join(f.currentDir, name)]
+}
+
+// listFiles lista o conteúdo do diretório atual ou, no modo ramo, todos os
+// arquivos abaixo dele (com o caminho relativo em Name), aplicando o filtro e
+// preenchendo a situação no git
+func (f *FileView) listFiles(showHidden bool) ([]utils.FileInfo, error) {
+	var files []utils.FileInfo
+	var err error
+	if f.viewMode != ViewModeFlat {
+		files, err = utils.ListDirectory(f.currentDir, showHidden)
+	} else {
+		var truncated bool
+		files, truncated, err = utils.ListBranch(f.currentDir, showHidden, branchLimit)
+		if truncated && f.app != nil {
+			f.app.statusBar.SetStatus(fmt.Sprintf("Visão de ramo limitada aos primeiros %d arquivos", branchLimit))
+		}
+	}
+	files = utils.FilterFiles(files, f.filter)
+
+	if f.app != nil {
+		if status := f.app.gitStatusFor(f.currentDir); status != nil {
+			for i := range files {
+				files[i].Git = status.Lookup(filepath.Join(f.currentDir, files[i].Name), files[i].IsDir)
+			}
+		}
+	}
+	return files, err
+}
+
+// parentEntry retorna a entrada ".." com os atributos do diretório pai, ou
+// false na raiz e no modo ramo, que não têm essa entrada
+func (f *FileView) parentEntry() (utils.FileInfo, bool) {
+	parentDir := strings.TrimSpace(filepath.Dir(f.currentDir))
+	if f.viewMode == ViewModeFlat || parentDir == f.currentDir || parentDir == "" {
+		return utils.FileInfo{}, false
+	}
+
+	parent := utils.FileInfo{Name: "..", Path: parentDir, IsDir: true}
+	if info, err := utils.Stat(parentDir); err == nil {
+		parent = utils.NewFileInfo(utils.FSFor(parentDir), parentDir, info)
+		parent.Name = ".."
+	}
+	return parent, true
+}
+
+// sortFiles ordena a lista (diretórios primeiro, depois por nome)
+func sortFiles(files []utils.FileInfo) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].IsDir != files[j].IsDir {
+			return files[i].IsDir
+		}
+		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+	})
+}
+
 // SetCurrentDir define o diretório atual
 func (f *FileView) SetCurrentDir(dir string) error {
-	// Verificar se o diretório existe
-	fileInfo, err := os.Stat(dir)
+	// Verificar se o diretório existe (arquivos compactados são abertos como diretórios)
+	fileInfo, err := utils.Stat(dir)
 	if err != nil {
 		return err
 	}
@@ -92,10 +386,7 @@ func (f *FileView) Refresh() {
 	f.fileList.Clear()
 
 	// Configurar cabeçalho
-	f.fileList.SetCell(0, 0, tview.NewTableCell("Nome").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
-	f.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignRight).SetSelectable(false))
-	f.fileList.SetCell(0, 2, tview.NewTableCell("Data").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
-	f.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignLeft).SetSelectable(false))
+	f.setHeader()
 
 	// Verificar se o diretório atual existe
 	if f.currentDir == "" {
@@ -107,33 +398,18 @@ func (f *FileView) Refresh() {
 	row := 1
 
 	// Adicionar entrada para o diretório pai (..) se não estiver na raiz
-	parentDir := strings.TrimSpace(filepath.Dir(f.currentDir))
-	if parentDir != f.currentDir && parentDir != "" {
-		// Adicionar ".." à lista de arquivos
+	if parent, ok := f.parentEntry(); ok {
 		f.files = append(f.files, "..")
-
-		// Adicionar linha à tabela
-		f.fileList.SetCell(row, 0, tview.NewTableCell("📁 ..").SetTextColor(tcell.ColorBlue).SetAlign(tview.AlignLeft))
-		f.fileList.SetCell(row, 1, tview.NewTableCell("<DIR>").SetTextColor(tcell.ColorBlue).SetAlign(tview.AlignRight))
-		f.fileList.SetCell(row, 2, tview.NewTableCell("").SetTextColor(tcell.ColorBlue).SetAlign(tview.AlignLeft))
-		f.fileList.SetCell(row, 3, tview.NewTableCell("rwx------").SetTextColor(tcell.ColorBlue).SetAlign(tview.AlignLeft))
-
+		f.setRow(row, parent, tview.NewTableCell("📁 ..").SetTextColor(tcell.ColorBlue).SetAlign(tview.AlignLeft), tcell.ColorBlue)
 		row++
 	}
 
 	// Listar arquivos
-	files, err := utils.ListFiles(f.currentDir, f.showHidden)
+	files, err := f.listFiles(f.showHidden)
 	if err != nil {
 		return
 	}
-
-	// Ordenar arquivos (diretórios primeiro, depois por nome)
-	sort.Slice(files, func(i, j int) bool {
-		if files[i].IsDir != files[j].IsDir {
-			return files[i].IsDir
-		}
-		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-	})
+	sortFiles(files)
 
 	// Adicionar arquivos à tabela
 	for _, file := range files {
@@ -145,20 +421,21 @@ func (f *FileView) Refresh() {
 		if file.IsDir {
 			color = tcell.ColorLightBlue
 		}
+		nameColor := color
+		if gitColor, ok := gitNameColor(file); ok {
+			nameColor = gitColor
+		}
 
 		// Adicionar ícone
 		icon := utils.GetFileIcon(file)
-		name := fmt.Sprintf("%s %s", icon, file.Name)
+		name := fmt.Sprintf("%s %s", icon, displayName(file))
 
-		// Adicionar linha à tabela
-		f.fileList.SetCell(row, 0, tview.NewTableCell(name).SetTextColor(color).SetAlign(tview.AlignLeft))
-		if file.IsDir {
-			f.fileList.SetCell(row, 1, tview.NewTableCell("<DIR>").SetTextColor(color).SetAlign(tview.AlignRight))
-		} else {
-			f.fileList.SetCell(row, 1, tview.NewTableCell(utils.FormatFileSize(file.Size)).SetTextColor(color).SetAlign(tview.AlignRight))
+		// Adicionar linha à tabela (itens marcados ficam em destaque)
+		nameCell := tview.NewTableCell(name).SetTextColor(nameColor).SetAlign(tview.AlignLeft)
+		if f.IsTagged(file.Name) {
+			nameCell.SetTextColor(tcell.ColorWhite).SetBackgroundColor(tcell.ColorDarkBlue)
 		}
-		f.fileList.SetCell(row, 2, tview.NewTableCell(file.ModTime.Format("02/01/2006 15:04:05")).SetTextColor(color).SetAlign(tview.AlignLeft))
-		f.fileList.SetCell(row, 3, tview.NewTableCell("rwx------").SetTextColor(color).SetAlign(tview.AlignLeft))
+		f.setRow(row, file, nameCell, color)
 
 		row++
 	}
@@ -172,22 +449,42 @@ func (f *FileView) Refresh() {
 	f.itemCount = row - 1
 }
 
+// Reload recarrega a lista de arquivos mantendo o cursor e a rolagem
+func (f *FileView) Reload() {
+	// Guardar posição atual
+	selectedFile := f.GetSelectedFile()
+	row, _ := f.fileList.GetSelection()
+	rowOffset, colOffset := f.fileList.GetOffset()
+
+	f.Refresh()
+
+	// Restaurar rolagem e cursor (pelo nome, ou pela linha se o arquivo sumiu)
+	f.fileList.SetOffset(rowOffset, colOffset)
+	if selectedFile != "" && f.SelectFile(selectedFile) {
+		return
+	}
+	if row >= f.fileList.GetRowCount() {
+		row = f.fileList.GetRowCount() - 1
+	}
+	if row > 0 {
+		f.fileList.Select(row, 0)
+	}
+}
+
 // UpdateFileList atualiza a lista de arquivos
 func (f *FileView) UpdateFileList(files []utils.FileInfo, showHidden bool) {
 	// Limpar tabela
 	f.fileList.Clear()
 
 	// Adicionar cabeçalho
-	f.fileList.SetCell(0, 0, tview.NewTableCell("Nome").SetTextColor(tcell.ColorYellow).SetSelectable(false))
-	f.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").SetTextColor(tcell.ColorYellow).SetSelectable(false))
-	f.fileList.SetCell(0, 2, tview.NewTableCell("Data").SetTextColor(tcell.ColorYellow).SetSelectable(false))
-	f.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	f.setHeader()
 
 	// Adicionar diretório pai
-	f.fileList.SetCell(1, 0, tview.NewTableCell("..").SetTextColor(tcell.ColorBlue))
-	f.fileList.SetCell(1, 1, tview.NewTableCell(""))
-	f.fileList.SetCell(1, 2, tview.NewTableCell(""))
-	f.fileList.SetCell(1, 3, tview.NewTableCell(""))
+	f.files = make([]string, 0, len(files)+1)
+	if parent, ok := f.parentEntry(); ok {
+		f.files = append(f.files, "..")
+		f.setRow(1, parent, tview.NewTableCell("..").SetTextColor(tcell.ColorBlue), tcell.ColorBlue)
+	}
 
 	// Filtrar arquivos ocultos
 	var visibleFiles []utils.FileInfo
@@ -197,54 +494,31 @@ func (f *FileView) UpdateFileList(files []utils.FileInfo, showHidden bool) {
 		}
 		visibleFiles = append(visibleFiles, file)
 	}
-
-	// Ordenar arquivos (diretórios primeiro, depois por nome)
-	sort.Slice(visibleFiles, func(i, j int) bool {
-		if visibleFiles[i].IsDir != visibleFiles[j].IsDir {
-			return visibleFiles[i].IsDir
-		}
-		return strings.ToLower(visibleFiles[i].Name) < strings.ToLower(visibleFiles[j].Name)
-	})
+	sortFiles(visibleFiles)
 
 	// Adicionar arquivos
-	for i, file := range visibleFiles {
-		row := i + 2 // +2 para o cabeçalho e o diretório pai
+	for _, file := range visibleFiles {
+		row := len(f.files) + 1 // +1 para o cabeçalho
+		f.files = append(f.files, file.Name)
 
 		// Determinar se é um arquivo oculto
-		isHidden := strings.HasPrefix(file.Name, ".")
-
-		// Verificar se o arquivo está selecionado
-		filePath := filepath.Join(f.app.currentDir, file.Name)
-		isSelected := f.app.selectedFiles[filePath]
+		isHidden := strings.HasPrefix(filepath.Base(file.Name), ".")
 
 		// Nome com cor baseada no tipo de arquivo
-		nameCell := tview.NewTableCell(file.Name)
-		if isSelected {
+		nameCell := tview.NewTableCell(displayName(file))
+		if f.IsTagged(file.Name) {
 			nameCell.SetTextColor(tcell.ColorWhite).
 				SetBackgroundColor(tcell.ColorDarkBlue)
+		} else if gitColor, ok := gitNameColor(file); ok {
+			nameCell.SetTextColor(gitColor)
 		} else {
 			nameCell.SetTextColor(GetFileColorByExt(file.Name, file.IsDir, isHidden))
 		}
-		f.fileList.SetCell(row, 0, nameCell)
-
-		// Tamanho
-		sizeText := ""
-		if !file.IsDir {
-			sizeText = utils.FormatFileSize(file.Size)
-		}
-		f.fileList.SetCell(row, 1, tview.NewTableCell(sizeText))
-
-		// Data
-		dateText := file.ModTime.Format("02/01/2006 15:04:05")
-		f.fileList.SetCell(row, 2, tview.NewTableCell(dateText))
-
-		// Permissões
-		permText := "rwx------" // Valor padrão simplificado
-		f.fileList.SetCell(row, 3, tview.NewTableCell(permText))
+		f.setRow(row, file, nameCell, tview.Styles.PrimaryTextColor)
 	}
 
 	// Atualizar contagem de itens
-	f.itemCount = len(visibleFiles) + 1 // +1 para o diretório pai
+	f.itemCount = len(f.files)
 
 	// Selecionar primeiro item
 	if f.itemCount > 0 {
@@ -258,7 +532,7 @@ func (f *FileView) UpdateFileList(files []utils.FileInfo, showHidden bool) {
 // GetSelectedFile retorna o arquivo selecionado
 func (f *FileView) GetSelectedFile() string {
 	row, _ := f.fileList.GetSelection()
-	if row <= 0 || row >= len(f.files) {
+	if row <= 0 || row > len(f.files) {
 		return ""
 	}
 	return f.files[row-1]
@@ -300,7 +574,7 @@ func (f *FileView) SelectAll() {
 	}
 
 	// Obter lista de arquivos no diretório atual
-	files, err := os.ReadDir(f.currentDir)
+	files, err := f.listFiles(true)
 	if err != nil {
 		f.app.showError("Erro ao listar arquivos: " + err.Error())
 		return
@@ -308,8 +582,8 @@ func (f *FileView) SelectAll() {
 
 	// Adicionar todos os arquivos à seleção
 	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(f.currentDir, file.Name())
+		if !file.IsDir {
+			filePath := filepath.Join(f.currentDir, file.Name)
 			f.app.selectedFiles[filePath] = true
 		}
 	}
@@ -340,7 +614,7 @@ func (f *FileView) InvertSelection() {
 	}
 
 	// Obter lista de arquivos no diretório atual
-	files, err := os.ReadDir(f.currentDir)
+	files, err := f.listFiles(true)
 	if err != nil {
 		f.app.showError("Erro ao listar arquivos: " + err.Error())
 		return
@@ -348,8 +622,8 @@ func (f *FileView) InvertSelection() {
 
 	// Inverter seleção para cada arquivo
 	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(f.currentDir, file.Name())
+		if !file.IsDir {
+			filePath := filepath.Join(f.currentDir, file.Name)
 			f.app.selectedFiles[filePath] = !f.app.selectedFiles[filePath]
 
 			// Se o arquivo não estiver mais selecionado, remover do mapa
@@ -373,7 +647,7 @@ func (f *FileView) SelectByPattern(pattern string) int {
 	}
 
 	// Obter lista de arquivos no diretório atual
-	files, err := os.ReadDir(f.currentDir)
+	files, err := f.listFiles(true)
 	if err != nil {
 		f.app.showError("Erro ao listar arquivos: " + err.Error())
 		return 0
@@ -389,11 +663,11 @@ func (f *FileView) SelectByPattern(pattern string) int {
 
 	// Selecionar arquivos que correspondam ao padrão
 	for _, file := range files {
-		if !file.IsDir() {
-			fileName := file.Name()
+		if !file.IsDir {
+			fileName := file.Name
 
 			// Verificar se o nome do arquivo corresponde ao padrão
-			match, err := filepath.Match(pattern, fileName)
+			match, err := filepath.Match(pattern, filepath.Base(fileName))
 			if err == nil && match {
 				filePath := filepath.Join(f.currentDir, fileName)
 				f.app.selectedFiles[filePath] = true
@@ -411,7 +685,7 @@ func (f *FileView) SelectByPattern(pattern string) int {
 // SetSortBy define o critério de ordenação
 func (f *FileView) SetSortBy(sortBy string) {
 	// Atualizar a lista de arquivos com o novo critério de ordenação
-	files, err := utils.ListFiles(f.currentDir, f.app.showHidden)
+	files, err := f.listFiles(f.app.showHidden)
 	if err != nil {
 		f.app.showError("Erro ao listar arquivos: " + err.Error())
 		return
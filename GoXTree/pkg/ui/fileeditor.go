@@ -7,26 +7,24 @@ import (
 
 	"github.com/peder1981/GoXTree/pkg/editor"
 	"github.com/rivo/tview"
-
-	"github.com/gdamore/tcell/v2"
 )
 
-// FileEditor representa o componente de edição de arquivos
+// FileEditor representa o componente de edição de arquivos, exibido sobre a
+// tela atual com o editor.TextEditor
 type FileEditor struct {
-	app      *App
-	pages    *tview.Pages
-	filePath string
+	app           *App
+	filePath      string
+	previousFocus tview.Primitive
 }
 
 // NewFileEditor cria um novo editor de arquivos
 func NewFileEditor(app *App) *FileEditor {
 	return &FileEditor{
-		app:   app,
-		pages: tview.NewPages(),
+		app: app,
 	}
 }
 
-// EditFile abre um arquivo no editor apropriado
+// EditFile abre um arquivo no editor; arquivos inexistentes são criados ao salvar
 func (fe *FileEditor) EditFile(filePath string) error {
 	// Verificar se o arquivo existe
 	fileInfo, err := os.Stat(filePath)
@@ -44,15 +42,10 @@ func (fe *FileEditor) EditFile(filePath string) error {
 	// Armazenar o caminho do arquivo
 	fe.filePath = filePath
 
-	// Por enquanto, só temos um editor de texto
-	// No futuro, podemos adicionar editores específicos para outros tipos de arquivo
 	textEditor := editor.NewTextEditor(fe.app.app)
 
 	// Configurar callbacks
-	textEditor.SetOnClose(func() {
-		fe.Close()
-	})
-
+	textEditor.SetOnClose(fe.Close)
 	textEditor.SetOnSave(func() {
 		// Atualizar a visualização após salvar
 		fe.app.refreshView()
@@ -60,94 +53,25 @@ func (fe *FileEditor) EditFile(filePath string) error {
 
 	// Carregar o arquivo, se existir
 	if fileInfo != nil {
-		err = textEditor.LoadFile(filePath)
-		if err != nil {
+		if err := textEditor.LoadFile(filePath); err != nil {
 			return fmt.Errorf("erro ao carregar arquivo: %w", err)
 		}
+	} else {
+		textEditor.NewFile(filePath)
 	}
 
-	// Adicionar o editor às páginas
-	fe.pages.AddPage("editor", textEditor.Show(), true, true)
-
-	// Exibir o editor
-	fe.app.app.SetRoot(fe.pages, true)
+	// Exibir o editor sobre a tela atual
+	fe.previousFocus = fe.app.app.GetFocus()
+	fe.app.pages.AddPage("fileEdit", textEditor.Show(), true, true)
+	fe.app.app.SetFocus(textEditor.Show())
 
 	return nil
 }
 
-// Close fecha o editor
+// Close fecha o editor e devolve o foco para onde ele foi aberto
 func (fe *FileEditor) Close() {
-	fe.app.pages.RemovePage("editor")
-}
-
-// showEditor exibe o editor
-func (fe *FileEditor) showEditor(filePath string) {
-	// Ler conteúdo do arquivo
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		fe.app.showError(fmt.Sprintf("Erro ao ler arquivo: %v", err))
-		return
-	}
-
-	// Criar editor de texto
-	textView := tview.NewTextView().
-		SetDynamicColors(true).
-		SetRegions(true).
-		SetWordWrap(true).
-		SetChangedFunc(func() {
-			fe.app.app.Draw()
-		})
-
-	// Configurar texto
-	textView.SetText(string(content))
-
-	// Criar layout
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(textView, 0, 1, true).
-		AddItem(tview.NewTextView().
-			SetTextAlign(tview.AlignCenter).
-			SetText("F2: Salvar | F10: Sair"), 1, 0, false)
-
-	// Configurar manipulador de eventos
-	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyF2:
-			// Salvar arquivo
-			fe.saveFile(filePath, textView.GetText(false))
-			return nil
-		case tcell.KeyF10, tcell.KeyEscape:
-			// Sair do editor
-			fe.app.pages.RemovePage("editor")
-			return nil
-		}
-		return event
-	})
-
-	// Adicionar página
-	fe.app.pages.AddPage("editor", flex, true, true)
-	fe.app.app.SetFocus(flex)
-}
-
-// saveFile salva o conteúdo em um arquivo
-func (fe *FileEditor) saveFile(filePath, content string) {
-	// Criar diretório pai se necessário
-	dir := filepath.Dir(filePath)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		err = os.MkdirAll(dir, 0755)
-		if err != nil {
-			fe.app.showError(fmt.Sprintf("Erro ao criar diretório: %v", err))
-			return
-		}
-	}
-
-	// Salvar conteúdo no arquivo
-	err := os.WriteFile(filePath, []byte(content), 0644)
-	if err != nil {
-		fe.app.showError(fmt.Sprintf("Erro ao salvar arquivo: %v", err))
-		return
+	fe.app.pages.RemovePage("fileEdit")
+	if fe.previousFocus != nil {
+		fe.app.app.SetFocus(fe.previousFocus)
 	}
-
-	// Exibir mensagem de sucesso
-	fe.app.showMessage(fmt.Sprintf("Arquivo salvo: %s", filePath))
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/peder1981/GoXTree/pkg/viewer"
 	"github.com/rivo/tview"
 
@@ -14,19 +15,31 @@ import (
 
 // FileViewer representa o componente de visualização de arquivos
 type FileViewer struct {
-	app      *App
-	pages    *tview.Pages
-	filePath string
+	app           *App
+	filePath      string
+	previousFocus tview.Primitive
 }
 
 // NewFileViewer cria um novo visualizador de arquivos
 func NewFileViewer(app *App) *FileViewer {
 	return &FileViewer{
-		app:   app,
-		pages: tview.NewPages(),
+		app: app,
 	}
 }
 
+// SyntaxColors retorna as cores do destaque de sintaxe do tema, definidas no
+// esquema de cores pelas chaves "syntax.<categoria>"
+func SyntaxColors(themeName string) viewer.SyntaxColors {
+	scheme := GetColorScheme(themeName)
+	colors := make(viewer.SyntaxColors)
+	for _, kind := range viewer.TokenKinds {
+		if color, ok := scheme["syntax."+kind.String()]; ok {
+			colors[kind] = color
+		}
+	}
+	return colors
+}
+
 // ViewFile abre um arquivo no visualizador apropriado
 func (fv *FileViewer) ViewFile(filePath string) error {
 	// Verificar se o arquivo existe
@@ -43,30 +56,45 @@ func (fv *FileViewer) ViewFile(filePath string) error {
 	// Armazenar o caminho do arquivo
 	fv.filePath = filePath
 
-	// Determinar o tipo de visualizador com base na extensão do arquivo
+	// Determinar o tipo de visualizador com base na extensão e no conteúdo do arquivo
 	ext := strings.ToLower(filepath.Ext(filePath))
+	isText, _ := utils.IsTextFile(filePath)
 
-	// Criar o visualizador apropriado
+	// Criar o visualizador apropriado; cada um trata o ESC (que antes limpa a
+	// busca ou sai da edição) e chama fv.Close
 	var viewerFlex *tview.Flex
 
 	// Verificar se é uma imagem
 	if isImageFile(ext) {
 		imageViewer := viewer.NewImageViewer(fv.app.app)
+		imageViewer.SetCloseFunc(fv.Close)
 		err := imageViewer.LoadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("erro ao carregar imagem: %w", err)
 		}
 		viewerFlex = imageViewer.Show()
-	} else if isTextFile(ext) {
+	} else if (isText || isTextFile(ext)) && (fileInfo.Size() > viewer.TextViewerLimit || ext == ".log") {
+		// Arquivos grandes e logs: leitura por página, com modo seguir
+		largeViewer := viewer.NewLargeViewer(fv.app.app)
+		largeViewer.SetCloseFunc(fv.Close)
+		err := largeViewer.LoadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir arquivo: %w", err)
+		}
+		viewerFlex = largeViewer.Show()
+	} else if isText || isTextFile(ext) {
 		textViewer := viewer.NewTextViewer(fv.app.app)
+		textViewer.SetSyntaxColors(SyntaxColors(fv.app.theme))
+		textViewer.SetCloseFunc(fv.Close)
 		err := textViewer.LoadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("erro ao carregar arquivo de texto: %w", err)
 		}
 		viewerFlex = textViewer.Show()
 	} else {
-		// Para outros tipos de arquivo, usar o visualizador hexadecimal
+		// Para outros tipos de arquivo, usar o visualizador e editor hexadecimal
 		hexViewer := viewer.NewHexViewer(fv.app.app)
+		hexViewer.SetCloseFunc(fv.Close)
 		err := hexViewer.LoadFile(filePath)
 		if err != nil {
 			return fmt.Errorf("erro ao carregar arquivo para visualização hexadecimal: %w", err)
@@ -74,24 +102,29 @@ func (fv *FileViewer) ViewFile(filePath string) error {
 		viewerFlex = hexViewer.Show()
 	}
 
-	// Adicionar o visualizador às páginas
-	fv.pages.AddPage("viewer", viewerFlex, true, true)
-
-	// Configurar manipulador de teclas para fechar o visualizador
-	fv.app.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			fv.Close()
-			return nil
-		}
-		return event
-	})
-
-	// Exibir o visualizador
-	fv.app.app.SetRoot(fv.pages, true)
+	fv.show(viewerFlex)
+	return nil
+}
 
+// EditHex abre o arquivo no editor hexadecimal, qualquer que seja o seu tipo
+func (fv *FileViewer) EditHex(filePath string) error {
+	hexViewer := viewer.NewHexViewer(fv.app.app)
+	hexViewer.SetCloseFunc(fv.Close)
+	if err := hexViewer.LoadFile(filePath); err != nil {
+		return fmt.Errorf("erro ao abrir arquivo para edição hexadecimal: %w", err)
+	}
+	fv.filePath = filePath
+	fv.show(hexViewer.Show())
 	return nil
 }
 
+// show exibe o visualizador sobre a tela atual
+func (fv *FileViewer) show(viewerFlex *tview.Flex) {
+	fv.previousFocus = fv.app.app.GetFocus()
+	fv.app.pages.AddPage("fileView", viewerFlex, true, true)
+	fv.app.app.SetFocus(viewerFlex)
+}
+
 // showViewer exibe o visualizador
 func (fv *FileViewer) showViewer(filePath string) {
 	// Ler conteúdo do arquivo
@@ -137,9 +170,12 @@ func (fv *FileViewer) showViewer(filePath string) {
 	fv.app.app.SetFocus(flex)
 }
 
-// Close fecha o visualizador
+// Close fecha o visualizador e devolve o foco para onde ele foi aberto
 func (fv *FileViewer) Close() {
-	fv.app.pages.RemovePage("viewer")
+	fv.app.pages.RemovePage("fileView")
+	if fv.previousFocus != nil {
+		fv.app.app.SetFocus(fv.previousFocus)
+	}
 }
 
 // isTextFile verifica se um arquivo é de texto com base na extensão
@@ -148,7 +184,7 @@ func isTextFile(ext string) bool {
 		".txt", ".log", ".md", ".json", ".xml", ".html", ".htm", ".css", ".js",
 		".go", ".c", ".cpp", ".h", ".hpp", ".py", ".rb", ".pl", ".php", ".java",
 		".sh", ".bat", ".cmd", ".ini", ".cfg", ".conf", ".yaml", ".yml", ".toml",
-		".csv", ".tsv", ".sql", ".prg", ".tlpp", ".ch",
+		".csv", ".tsv", ".sql", ".prg", ".prw", ".prx", ".tlpp", ".ch",
 	}
 
 	for _, textExt := range textExtensions {
@@ -163,7 +199,7 @@ func isTextFile(ext string) bool {
 // isImageFile verifica se um arquivo é uma imagem com base na extensão
 func isImageFile(ext string) bool {
 	imageExtensions := []string{
-		".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tiff", ".webp",
+		".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tif", ".tiff", ".webp",
 	}
 
 	for _, imgExt := range imageExtensions {
@@ -118,19 +118,91 @@ func (hv *HelpView) getHelpText() string {
   - [green]Home/End[white] para ir para o início/fim da lista
   - [green]PgUp/PgDn[white] para navegar páginas
 
+[yellow]Marcadores e Diretórios Frequentes:[white]
+  - [green]m[white] seguido de uma letra (a–z) marca o diretório atual
+  - [green]'[white] seguido da letra volta ao diretório marcado
+  - [green]Alt+B[white] lista os marcadores ([green]Del[white] remove)
+  - [green]Alt+G[white] busca aproximada nos diretórios mais visitados
+    (frequência e recência); digite partes do caminho, ex.: "proj api"
+  - [green]Ctrl+D[white] na busca esquece o diretório destacado
+  - Marcadores e diretórios visitados ficam em ~/.gxtree, ao lado de config.json
+
 [yellow]Seleção:[white]
   - [green]Espaço[white] para selecionar/deselecionar um arquivo
   - [green]Ins[white] para selecionar um arquivo e mover para o próximo
   - [green]Ctrl+A[white] para selecionar todos os arquivos
   - [green]Ctrl+N[white] para deselecionar todos os arquivos
   - [green]Ctrl+I[white] para inverter a seleção
+  - As marcações valem para a árvore toda: continuam ao trocar de diretório ou de painel, e
+    [green]F5[white]/[green]F6[white]/[green]F8[white]/[green]Alt+Z[white] copiam, movem, excluem e compactam todos os itens marcados
+  - [green]Ctrl+S[white] mostra todos os marcados, de qualquer diretório ([green]Enter[white] vai até o item,
+    [green]Espaço[white] desmarca, [green]Ctrl+D[white] desmarca todos)
 
 [yellow]Operações de Arquivo:[white]
   - [green]F5[white] para copiar arquivos selecionados
   - [green]F6[white] para mover arquivos selecionados
   - [green]F7[white] para criar um novo diretório
-  - [green]F8/Del[white] para excluir arquivos selecionados
+  - [green]F8/Del[white] para enviar os arquivos selecionados para a lixeira
+  - [green]Shift+F8[white] para excluir definitivamente, sem passar pela lixeira
+  - [green]Ctrl+Z[white] desfaz a última exclusão, renomeação, movimentação ou colagem
+  - [green]Alt+T[white] abre a lixeira para restaurar ou excluir itens
   - [green]F9[white] para criar um novo arquivo
+  - [green]Alt+A[white] altera permissões, dono e grupo dos itens selecionados: em octal (755, 2775) ou
+    simbólico (u+x, go-w, a+rX, +t), opcionalmente também no conteúdo dos diretórios
+  - A coluna Permissões segue o ls -l ([red]s/t em vermelho[white] para setuid, setgid e sticky) e os
+    links simbólicos mostram o destino (nome -> destino)
+
+[yellow]Modos e Colunas:[white]
+  - [green]Alt+M[white] alterna o modo: árvore, ramo (todos os arquivos abaixo do diretório, como o
+    "branch" do XTree, com o caminho relativo no nome) e detalhes (lista em tela cheia, sem a árvore)
+  - [green]Alt+O[white] escolhe as colunas: nome, tamanho, datas de modificação, criação e acesso,
+    permissões, dono, tipo MIME, git, inode e links; [green]< >[white] reordenam e [green]+/-[white] ajustam a largura
+  - O modo e as colunas ficam salvos em config.json
+
+[yellow]Filtro da Listagem:[white]
+  - [green]Alt+F[white] filtra o painel ativo com termos separados por ";", como no XTree:
+    [green]*.go;*.prw[white] mostra só esses arquivos e [green]!*_test.go[white] esconde os que correspondem
+  - Atributos: [green]size>1MB[white], [green]modified<7d[white] (alterados há menos de 7 dias; h, d, w, y ou
+    uma data como 2024-01-31) e [green]type=dir[white] (dir, file ou link)
+  - Padrões, tamanho e data valem só para arquivos; os diretórios continuam visíveis
+  - O filtro ativo aparece no título do painel e na barra de status; campo vazio remove o filtro
+  - [green]Ctrl+S[white] no diálogo salva o filtro com um nome em config.json; as setas escolhem os salvos
+
+[yellow]Git:[white]
+  - Dentro de um repositório, a coluna [green]Git[white] mostra o código do git status (M, A, D, UU, ??, !!)
+    e os nomes ganham cor: [red]conflito[white], [orange]alterado[white], [green]preparado[white], [fuchsia]não rastreado[white] e [gray]ignorado[white]
+  - Diretórios na árvore e na lista mostram o resumo do conteúdo: ✗ conflito, ● alterado,
+    ✚ preparado e ? não rastreado
+  - [green]Alt+U[white] prepara os itens marcados (ou o atual) para o commit; se já estiverem preparados,
+    retira-os da preparação. O menu Git (F2) tem as duas operações separadas
+  - [green]Alt+D[white] compara o arquivo atual com a versão do último commit (HEAD)
+  - A barra de status mostra o branch, o upstream e os commits à frente (↑) e atrás (↓)
+
+[yellow]Painel Duplo:[white]
+  - [green]Alt+P[white] para ligar/desligar o segundo painel de arquivos
+  - [green]Tab[white] alterna entre a árvore e os dois painéis
+  - Com o painel duplo ligado, [green]F5[white]/[green]F6[white] copiam/movem para o outro painel
+  - [green]Alt+C[white] compara o item atual com o de mesmo nome no outro painel
+  - A sincronização usa o diretório do outro painel como destino
+
+[yellow]Operações em Segundo Plano:[white]
+  - Cópia, movimentação, exclusão, compactação e sincronização rodam em segundo plano
+  - [green]Alt+J[white] abre o painel de operações com o progresso e os erros por arquivo
+  - No painel: [green]P[white] pausa/retoma, [green]C[white] cancela, [green]X[white] limpa as concluídas
+
+[yellow]Arquivos Compactados:[white]
+  - [green]Enter[white] em um .zip, .tar, .tar.gz, .tar.bz2, .tar.xz ou .tar.zst abre o arquivo como diretório
+  - Dentro dele, [green]Alt+V[white]/[green]Enter[white] visualizam os arquivos normalmente (somente leitura)
+  - [green]Alt+X[white] extrai os itens selecionados (ou o arquivo compactado sob o cursor)
+  - [green]F5[white] dentro de um arquivo compactado extrai para o outro painel
+  - [green]Alt+Z[white] cria um arquivo zip, tar, tar.gz, tar.zst ou tar.xz com os itens selecionados,
+    com nível de compressão, exclusões, permissões, links simbólicos e divisão em volumes
+
+[yellow]Servidores Remotos (SFTP):[white]
+  - No diálogo Ir para Diretório, digite [green]sftp://usuario@host:porta/caminho[white]
+  - A autenticação usa o ssh-agent e as chaves em ~/.ssh; o servidor precisa estar em ~/.ssh/known_hosts
+  - O servidor aparece como [green]/sftp:usuario@host[white] e pode ser navegado, visualizado,
+    copiado e sincronizado como um diretório local (a exclusão não usa a lixeira)
 
 [yellow]Visualização:[white]
   - [green]F3[white] para alternar entre visualização em árvore e lista
@@ -145,6 +217,16 @@ func (hv *HelpView) getHelpText() string {
   - [green]F[white] para busca rápida na lista atual
   - [green]n[white] para ir para o próximo resultado da busca
   - [green]N[white] para ir para o resultado anterior da busca
+  - Os resultados aparecem enquanto a busca roda; [green]ESC[white] fecha a janela e cancela a busca
+  - Arquivos binários e itens excluídos por [green].gitignore[white]/[green].ignore[white] são ignorados
+  - No conteúdo, todas as ocorrências de cada arquivo são listadas com as linhas de contexto
+
+[yellow]Localizador Aproximado (Ctrl+P):[white]
+  - Indexa em segundo plano os itens abaixo do diretório atual (respeitando .gitignore)
+  - Digite partes do caminho fora de ordem contínua, ex.: "pkui app" acha pkg/ui/app.go
+  - [green]Enter[white] entra no diretório ou abre o arquivo no visualizador
+  - [green]Ctrl+E[white] edita o arquivo; [green]Ctrl+L[white] apenas seleciona o item na lista
+  - [green]Ctrl+R[white] refaz o índice; [green]Ctrl+N/Ctrl+P[white] ou as setas movem a seleção
 
 [yellow]Esquema de Cores:[white]
   * [blue]Diretórios[white] - Azul
@@ -160,14 +242,61 @@ func (hv *HelpView) getHelpText() string {
   - Use [green]Alt+V[white] para visualizar o conteúdo do arquivo atual
   - Use [green]Alt+E[white] para editar o arquivo atual no editor interno
   - Use [green]ESC[white] para sair do visualizador/editor
+  - No editor: [green]Ctrl+S[white] salva, [green]Ctrl+Z/Ctrl+Y[white] desfazem/refazem, [green]Ctrl+F[white] busca por expressão
+    regular, [green]Ctrl+N/Ctrl+P[white] vão para a próxima/anterior, [green]Ctrl+R[white] substitui ($1 no texto de
+    substituição), [green]Ctrl+G[white] vai para uma linha e [green]Alt+N[white] mostra/oculta os números de linha
+  - O editor mantém a indentação ao quebrar linhas e salva o arquivo com a mesma codificação
+    (UTF-8 ou Windows-1252) e a mesma quebra de linha (LF ou CRLF) em que foi aberto
+  - O visualizador destaca a sintaxe de Go, AdvPL/TLPP, JSON, YAML, Markdown, shell e SQL
+    (pela extensão ou pela linha #!), com as cores do tema ativo
+  - No visualizador: [green]/[white] ou [green]Ctrl+F[white] busca, [green]n/N[white] vai para a próxima/anterior,
+    [green]W[white] liga/desliga a quebra de linhas e [green]L[white] os números de linha
+  - Arquivos acima de 10MB e logs abrem no visualizador de arquivos grandes, que lê só a
+    página visível e indexa as linhas em segundo plano: [green]:[white] ou [green]Ctrl+G[white] vai para uma
+    linha ou percentual (ex.: 50%), [green]/[white] e [green]?[white] buscam para frente e para trás,
+    [green]n/N[white] repetem a busca e [green]F[white] acompanha o fim do arquivo (como o tail -f)
+  - Arquivos binários abrem no editor hexadecimal, que também pode ser aberto para qualquer
+    arquivo com [green]Alt+H[white]: [green]E[white] ou [green]Enter[white] liga a edição por sobrescrita, [green]Tab[white] alterna
+    entre as colunas hexadecimal e de texto, [green]:[white] vai para uma posição (0x1F, 4096, 50%, +16),
+    [green]/[white] busca texto, [green]X[white] busca bytes (DE AD BE EF), [green]n/N[white] repetem a busca, [green]I[white] mostra os
+    valores no cursor (inteiros e ponto flutuante, little/big-endian), [green]Ctrl+Z[white] desfaz e
+    [green]Ctrl+S[white] salva, guardando antes uma cópia do original em arquivo.bak
+  - Imagens (PNG, JPEG, GIF, WebP, BMP e TIFF) usam os protocolos gráficos sixel ou kitty
+    quando o terminal os suporta e, nos demais, meios-blocos coloridos (ou ASCII com poucas
+    cores): [green]+/-[white] ou a roda do mouse ajustam o zoom, [green]0[white] mostra a imagem inteira, [green]1[white] o tamanho
+    real, as setas (ou [green]h/j/k/l[white]) movem a imagem e [green]M[white] alterna o modo de desenho. A variável
+    GOXTREE_IMAGE (sixel, kitty, blocos ou ascii) força um modo
+
+[yellow]Renomear em Lote:[white]
+  - [green]Alt+R[white] (ou [green]F2[white] com vários itens marcados) renomeia os itens selecionados
+  - Procurar/substituir com texto ou expressão regular ($1, $2... no texto de substituição)
+  - Modelo com tokens: [green]{name}[white], [green]{ext}[white], [green]{parent}[white], [green]{n:03}[white], [green]{date:AAAAMMDD}[white], [green]{exif}[white]
+  - Conversão de maiúsculas/minúsculas e troca de extensão
+  - A prévia mostra os novos nomes e aponta colisões; nada é renomeado se houver problemas
+  - A renomeação é revertida em caso de falha e pode ser desfeita depois
 
 [yellow]Comparação de Arquivos:[white]
   - Selecione exatamente dois arquivos
-  - Pressione [green]Alt+C[white] para comparar os arquivos
-  - As diferenças são destacadas em cores:
-    * [green]+ Texto adicionado[white]
-    * [red]- Texto removido[white]
-    * Texto sem alteração
+  - Pressione [green]Alt+C[white] para comparar os arquivos lado a lado
+  - Linhas removidas em [red]vermelho[white], inseridas em [green]verde[white]; o trecho alterado da linha é realçado
+  - [green]N[white]/[green]P[white] vão para a próxima/anterior alteração
+  - [green]W[white] ignora espaços em branco e [green]I[white] ignora maiúsculas/minúsculas
+  - [green]U[white] exporta a comparação no formato diff unificado (.diff)
+
+[yellow]Comparação de Diretórios:[white]
+  - Com o painel duplo, [green]Alt+C[white] sobre [green]..[white] compara os diretórios dos dois painéis
+  - Também disponível no menu Ferramentas ([green]Comparar Diretórios[white])
+  - Arquivos de mesmo tamanho são comparados pelo conteúdo (hash ou, com [green]B[white], byte a byte)
+  - [green]Espaço[white] marca, [green]*[white] marca todas as diferenças, [green]>[white]/[green]<[white] copiam para o outro lado
+  - [green]Enter[white] abre o diff do arquivo, [green]I[white] mostra/oculta idênticos, [green]R[white] refaz a comparação
+
+[yellow]Arquivos Duplicados:[white]
+  - Menu Ferramentas > [green]Localizar Duplicados[white] procura a partir do diretório atual
+  - Os arquivos são comparados pelo tamanho, pelos blocos inicial e final e pelo conteúdo completo
+  - [green]N[white]/[green]O[white] preservam a cópia mais nova/antiga; [green]Espaço[white] preserva a cópia sob o cursor
+  - [green]E[white] ignora o grupo; [green]X[white] exclui as demais cópias (para a lixeira)
+  - [green]L[white]/[green]S[white] substituem as demais cópias por links físicos/simbólicos
+  - [green]Enter[white] vai até o arquivo no gerenciador
 
 [yellow]Configuração:[white]
   - As configurações são salvas em ~/.gxtree/config.json
@@ -99,6 +99,10 @@ func (a *App) showMainMenu() {
 			a.pages.RemovePage("mainMenu")
 			a.showToolsMenu()
 		}).
+		AddItem("Git", "Preparar alterações e comparar com o HEAD", 'g', func() {
+			a.pages.RemovePage("mainMenu")
+			a.showGitMenu()
+		}).
 		AddItem("Configurações", "Configurar aplicação", 'c', func() {
 			a.pages.RemovePage("mainMenu")
 			a.showConfigMenu()
@@ -143,6 +147,10 @@ func (a *App) showFileMenu() {
 			a.pages.RemovePage("fileMenu")
 			a.renameFile()
 		}).
+		AddItem("Renomear em Lote", "Renomear vários itens com padrões e contadores (Alt+R)", 'l', func() {
+			a.pages.RemovePage("fileMenu")
+			a.showBulkRenameDialog()
+		}).
 		AddItem("Copiar", "Copiar arquivo ou pasta", 'c', func() {
 			a.pages.RemovePage("fileMenu")
 			a.copyFile()
@@ -183,6 +191,18 @@ func (a *App) showViewMenu() {
 			a.pages.RemovePage("viewMenu")
 			a.toggleHiddenFiles()
 		}).
+		AddItem("Modo de Visualização", "Alternar entre árvore, ramo e detalhes (Alt+M)", 'm', func() {
+			a.pages.RemovePage("viewMenu")
+			a.cycleViewMode()
+		}).
+		AddItem("Colunas", "Escolher, ordenar e dimensionar as colunas (Alt+O)", 'c', func() {
+			a.pages.RemovePage("viewMenu")
+			a.showColumnsDialog()
+		}).
+		AddItem("Filtro", "Filtrar a listagem por nome, tamanho, data ou tipo (Alt+F)", 'f', func() {
+			a.pages.RemovePage("viewMenu")
+			a.showFilterDialog()
+		}).
 		AddItem("Ordenar por Nome", "Ordenar arquivos por nome", 'n', func() {
 			a.pages.RemovePage("viewMenu")
 			a.sortByName()
@@ -224,6 +244,46 @@ func (a *App) showViewMenu() {
 	a.app.SetFocus(menu)
 }
 
+// showGitMenu exibe o menu do git
+func (a *App) showGitMenu() {
+	menu := tview.NewList().
+		AddItem("Preparar", "Preparar para o commit os itens marcados ou o atual (git add)", 'p', func() {
+			a.pages.RemovePage("gitMenu")
+			a.stageSelected()
+		}).
+		AddItem("Retirar da Preparação", "Desfazer a preparação dos itens (git restore --staged)", 'r', func() {
+			a.pages.RemovePage("gitMenu")
+			a.unstageSelected()
+		}).
+		AddItem("Alternar Preparação", "Preparar ou retirar da preparação, conforme a situação (Alt+U)", 'u', func() {
+			a.pages.RemovePage("gitMenu")
+			a.toggleGitStage()
+		}).
+		AddItem("Diferenças com o HEAD", "Comparar o arquivo atual com o último commit (Alt+D)", 'd', func() {
+			a.pages.RemovePage("gitMenu")
+			a.diffWithHead()
+		}).
+		AddItem("Voltar", "Voltar ao menu principal", 'v', func() {
+			a.pages.RemovePage("gitMenu")
+			a.showMainMenu()
+		})
+
+	menu.SetBorder(true).
+		SetTitle("Git").
+		SetTitleAlign(tview.AlignCenter)
+
+	menu.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("gitMenu")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("gitMenu", menu, true, true)
+	a.app.SetFocus(menu)
+}
+
 // showConfigMenu exibe o menu de configurações
 func (a *App) showConfigMenu() {
 	menu := tview.NewList().
@@ -286,11 +346,20 @@ func (a *App) showCreateMenu() {
 
 // moveFile move um arquivo ou diretório
 func (a *App) moveFile() {
+	// Com itens marcados, a movimentação vale para todos eles
+	if len(a.selectedFiles) > 0 {
+		a.transferTagged(true)
+		return
+	}
+
 	selectedFile := a.getSelectedFile()
 	if selectedFile == "" {
 		a.showError("Nenhum arquivo selecionado")
 		return
 	}
+	if a.checkReadOnly(a.currentDir) {
+		return
+	}
 
 	a.showInputDialog("Mover para", "Diretório de destino:", func(destDir string) {
 		if destDir == "" {
@@ -328,32 +397,16 @@ func (a *App) moveFile() {
 		destPath := filepath.Join(destDir, fileName)
 
 		// Verificar se o destino já existe
+		transfer := []jobTransfer{{src: selectedFile, dest: destPath}}
 		if _, err := os.Stat(destPath); err == nil {
 			a.showConfirmDialog("Substituir", fmt.Sprintf("'%s' já existe. Deseja substituir?", fileName), func(confirmed bool) {
-				if !confirmed {
-					return
-				}
-
-				// Mover arquivo ou diretório
-				err := os.Rename(selectedFile, destPath)
-				if err != nil {
-					a.showError(fmt.Sprintf("Erro ao mover: %v", err))
-					return
+				if confirmed {
+					a.startTransferJob(transfer, destDir, true, nil)
 				}
-
-				a.refreshCurrentDir()
-				a.showMessage(fmt.Sprintf("'%s' movido com sucesso", fileName))
 			})
 		} else {
-			// Mover arquivo ou diretório
-			err := os.Rename(selectedFile, destPath)
-			if err != nil {
-				a.showError(fmt.Sprintf("Erro ao mover: %v", err))
-				return
-			}
-
-			a.refreshCurrentDir()
-			a.showMessage(fmt.Sprintf("'%s' movido com sucesso", fileName))
+			// Mover arquivo ou diretório em segundo plano
+			a.startTransferJob(transfer, destDir, true, nil)
 		}
 	})
 }
@@ -83,14 +83,7 @@ func ApplyRetroThemeToApp(a *App) {
 	a.fileView.fileList.SetBorder(true)
 
 	// Personalizar cabeçalhos da tabela de arquivos
-	a.fileView.fileList.SetCell(0, 0, tview.NewTableCell("Nome").
-		SetTextColor(ColorHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").
-		SetTextColor(ColorHeader).SetAlign(tview.AlignRight).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 2, tview.NewTableCell("Data").
-		SetTextColor(ColorHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").
-		SetTextColor(ColorHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
+	a.fileView.SetHeaderColor(ColorHeader)
 
 	// Personalizar StatusBar
 	a.statusBar.statusBar.SetBackgroundColor(ColorBackground)
@@ -152,14 +145,7 @@ func ApplyModernThemeToApp(a *App) {
 	a.fileView.fileList.SetBorder(true)
 
 	// Personalizar cabeçalhos da tabela de arquivos
-	a.fileView.fileList.SetCell(0, 0, tview.NewTableCell("Nome").
-		SetTextColor(modernHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").
-		SetTextColor(modernHeader).SetAlign(tview.AlignRight).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 2, tview.NewTableCell("Data").
-		SetTextColor(modernHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").
-		SetTextColor(modernHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
+	a.fileView.SetHeaderColor(modernHeader)
 
 	// Personalizar StatusBar
 	a.statusBar.statusBar.SetBackgroundColor(modernBackground)
@@ -221,14 +207,7 @@ func ApplyDarkThemeToApp(a *App) {
 	a.fileView.fileList.SetBorder(true)
 
 	// Personalizar cabeçalhos da tabela de arquivos
-	a.fileView.fileList.SetCell(0, 0, tview.NewTableCell("Nome").
-		SetTextColor(darkHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").
-		SetTextColor(darkHeader).SetAlign(tview.AlignRight).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 2, tview.NewTableCell("Data").
-		SetTextColor(darkHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").
-		SetTextColor(darkHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
+	a.fileView.SetHeaderColor(darkHeader)
 
 	// Personalizar StatusBar
 	a.statusBar.statusBar.SetBackgroundColor(darkBackground)
@@ -290,14 +269,7 @@ func ApplyLightThemeToApp(a *App) {
 	a.fileView.fileList.SetBorder(true)
 
 	// Personalizar cabeçalhos da tabela de arquivos
-	a.fileView.fileList.SetCell(0, 0, tview.NewTableCell("Nome").
-		SetTextColor(lightHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 1, tview.NewTableCell("Tamanho").
-		SetTextColor(lightHeader).SetAlign(tview.AlignRight).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 2, tview.NewTableCell("Data").
-		SetTextColor(lightHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
-	a.fileView.fileList.SetCell(0, 3, tview.NewTableCell("Permissões").
-		SetTextColor(lightHeader).SetAlign(tview.AlignLeft).SetSelectable(false))
+	a.fileView.SetHeaderColor(lightHeader)
 
 	// Personalizar StatusBar
 	a.statusBar.statusBar.SetBackgroundColor(lightBackground)
@@ -362,6 +334,21 @@ func GetRetroColorScheme() map[string]tcell.Color {
 		"pptx":    tcell.ColorYellow,
 		"hidden":  tcell.ColorGray,
 		"default": tcell.ColorWhite,
+
+		// Destaque de sintaxe no visualizador de texto
+		"syntax.text":     tcell.ColorWhite,
+		"syntax.keyword":  tcell.ColorYellow,
+		"syntax.type":     tcell.NewRGBColor(0, 255, 255),
+		"syntax.literal":  tcell.NewRGBColor(255, 0, 255),
+		"syntax.string":   tcell.ColorLime,
+		"syntax.number":   tcell.NewRGBColor(255, 0, 255),
+		"syntax.comment":  tcell.ColorGray,
+		"syntax.operator": tcell.ColorWhite,
+		"syntax.function": tcell.NewRGBColor(0, 255, 255),
+		"syntax.preproc":  tcell.ColorRed,
+		"syntax.key":      tcell.NewRGBColor(0, 255, 255),
+		"syntax.variable": tcell.ColorYellow,
+		"syntax.heading":  tcell.ColorYellow,
 	}
 }
 
@@ -417,6 +404,21 @@ func GetModernColorScheme() map[string]tcell.Color {
 		"pptx":    tcell.ColorYellow,
 		"hidden":  tcell.ColorGray,
 		"default": tcell.ColorBlack,
+
+		// Destaque de sintaxe no visualizador de texto
+		"syntax.text":     tcell.ColorBlack,
+		"syntax.keyword":  tcell.ColorBlue,
+		"syntax.type":     tcell.ColorTeal,
+		"syntax.literal":  tcell.ColorPurple,
+		"syntax.string":   tcell.ColorGreen,
+		"syntax.number":   tcell.ColorPurple,
+		"syntax.comment":  tcell.ColorGray,
+		"syntax.operator": tcell.ColorBlack,
+		"syntax.function": tcell.ColorTeal,
+		"syntax.preproc":  tcell.ColorMaroon,
+		"syntax.key":      tcell.ColorNavy,
+		"syntax.variable": tcell.ColorOlive,
+		"syntax.heading":  tcell.ColorBlue,
 	}
 }
 
@@ -472,6 +474,21 @@ func GetDarkColorScheme() map[string]tcell.Color {
 		"pptx":    tcell.ColorYellow,
 		"hidden":  tcell.ColorGray,
 		"default": tcell.ColorWhite,
+
+		// Destaque de sintaxe no visualizador de texto
+		"syntax.text":     tcell.NewRGBColor(212, 212, 212),
+		"syntax.keyword":  tcell.NewRGBColor(86, 156, 214),
+		"syntax.type":     tcell.NewRGBColor(78, 201, 176),
+		"syntax.literal":  tcell.NewRGBColor(86, 156, 214),
+		"syntax.string":   tcell.NewRGBColor(206, 145, 120),
+		"syntax.number":   tcell.NewRGBColor(181, 206, 168),
+		"syntax.comment":  tcell.NewRGBColor(106, 153, 85),
+		"syntax.operator": tcell.NewRGBColor(212, 212, 212),
+		"syntax.function": tcell.NewRGBColor(220, 220, 170),
+		"syntax.preproc":  tcell.NewRGBColor(197, 134, 192),
+		"syntax.key":      tcell.NewRGBColor(156, 220, 254),
+		"syntax.variable": tcell.NewRGBColor(156, 220, 254),
+		"syntax.heading":  tcell.NewRGBColor(86, 156, 214),
 	}
 }
 
@@ -527,7 +544,35 @@ func GetLightColorScheme() map[string]tcell.Color {
 		"pptx":    tcell.ColorYellow,
 		"hidden":  tcell.ColorGray,
 		"default": tcell.ColorBlack,
+
+		// Destaque de sintaxe no visualizador de texto
+		"syntax.text":     tcell.ColorBlack,
+		"syntax.keyword":  tcell.NewRGBColor(0, 0, 255),
+		"syntax.type":     tcell.NewRGBColor(38, 127, 153),
+		"syntax.literal":  tcell.NewRGBColor(0, 0, 255),
+		"syntax.string":   tcell.NewRGBColor(163, 21, 21),
+		"syntax.number":   tcell.NewRGBColor(9, 134, 88),
+		"syntax.comment":  tcell.NewRGBColor(0, 128, 0),
+		"syntax.operator": tcell.ColorBlack,
+		"syntax.function": tcell.NewRGBColor(121, 94, 38),
+		"syntax.preproc":  tcell.NewRGBColor(175, 0, 219),
+		"syntax.key":      tcell.NewRGBColor(4, 81, 165),
+		"syntax.variable": tcell.NewRGBColor(0, 16, 128),
+		"syntax.heading":  tcell.NewRGBColor(128, 0, 0),
+	}
+}
+
+// GetColorScheme retorna o esquema de cores do tema informado (retrô por padrão)
+func GetColorScheme(themeName string) map[string]tcell.Color {
+	switch themeName {
+	case "modern":
+		return GetModernColorScheme()
+	case "dark":
+		return GetDarkColorScheme()
+	case "light":
+		return GetLightColorScheme()
 	}
+	return GetRetroColorScheme()
 }
 
 // GetFileColor retorna a cor para um determinado arquivo com base em sua extensão
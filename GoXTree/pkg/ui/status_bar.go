@@ -13,6 +13,9 @@ import (
 // StatusBar representa a barra de status
 type StatusBar struct {
 	statusBar *tview.TextView
+	tagged    func() int    // Número de itens marcados em todos os diretórios
+	filter    func() string // Filtro ativo no painel atual
+	git       func() string // Branch e situação do repositório git do diretório atual
 }
 
 // NewStatusBar cria uma nova barra de status
@@ -36,6 +39,21 @@ func NewStatusBar() *StatusBar {
 	return s
 }
 
+// SetTaggedCounter define a função que informa quantos itens estão marcados
+func (s *StatusBar) SetTaggedCounter(counter func() int) {
+	s.tagged = counter
+}
+
+// SetFilterSource define a função que informa o filtro ativo
+func (s *StatusBar) SetFilterSource(source func() string) {
+	s.filter = source
+}
+
+// SetGitSource define a função que informa a situação do repositório git
+func (s *StatusBar) SetGitSource(source func() string) {
+	s.git = source
+}
+
 // Update atualiza a barra de status
 func (s *StatusBar) Update(currentDir string, numFiles, numDirs int, dirSize int64) {
 	// Obter informações do sistema
@@ -69,6 +87,21 @@ func (s *StatusBar) UpdateStatus(currentDir string) {
 	// Definir um texto informativo
 	infoText := fmt.Sprintf("[yellow]Diretório:[white] %s | [yellow]Arquivos:[white] %d | [yellow]Diretórios:[white] %d | [yellow]Tamanho:[white] %s",
 		currentDir, numFiles, numDirs, formatSize(dirSize))
+	if s.tagged != nil {
+		if n := s.tagged(); n > 0 {
+			infoText += fmt.Sprintf(" | [yellow]Marcados:[white] %d (Ctrl+S)", n)
+		}
+	}
+	if s.filter != nil {
+		if filter := s.filter(); filter != "" {
+			infoText += fmt.Sprintf(" | [yellow]Filtro:[white] %s (Alt+F)", tview.Escape(filter))
+		}
+	}
+	if s.git != nil {
+		if git := s.git(); git != "" {
+			infoText += " | [yellow]Git:[white] " + git
+		}
+	}
 
 	// Atualizar texto
 	s.statusBar.Clear()
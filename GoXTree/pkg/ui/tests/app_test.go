@@ -1,9 +1,12 @@
 package ui_test
 
 import (
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/peder1981/GoXTree/pkg/ui"
+	"github.com/peder1981/GoXTree/pkg/utils"
 )
 
 func TestNewApp(t *testing.T) {
@@ -22,3 +25,176 @@ func TestAppInitialization(t *testing.T) {
 	// Verificar se os componentes básicos foram inicializados
 	// Nota: Este teste pode precisar ser adaptado com base na implementação real
 }
+
+func TestFileViewOnMemFS(t *testing.T) {
+	mem := utils.NewMemFS()
+	mem.MkdirAll("/memfs-ui/docs", 0755)
+	utils.WriteFileFS(mem, "/memfs-ui/leia.txt", []byte("olá"), 0644)
+	utils.WriteFileFS(mem, "/memfs-ui/.oculto", []byte("x"), 0644)
+
+	utils.Mount("/memfs-ui", mem)
+	defer utils.Unmount("/memfs-ui")
+
+	fv := ui.NewFileView(ui.NewApp())
+	if err := fv.SetCurrentDir("/memfs-ui"); err != nil {
+		t.Fatalf("SetCurrentDir() error = %v", err)
+	}
+
+	// "..", docs e leia.txt (o arquivo oculto não aparece)
+	if got := fv.GetItemCount(); got != 3 {
+		t.Errorf("GetItemCount() = %d, esperava 3", got)
+	}
+	if !fv.SelectFile("leia.txt") || fv.GetSelectedFile() != "leia.txt" {
+		t.Errorf("SelectFile(leia.txt) falhou")
+	}
+
+	if err := fv.SetCurrentDir("/memfs-ui/leia.txt"); err == nil {
+		t.Errorf("SetCurrentDir() em um arquivo deveria falhar")
+	}
+}
+
+func TestFileViewModesAndColumns(t *testing.T) {
+	mem := utils.NewMemFS()
+	mem.MkdirAll("/memfs-ramo/src/pkg", 0755)
+	utils.WriteFileFS(mem, "/memfs-ramo/leia.txt", []byte("a"), 0644)
+	utils.WriteFileFS(mem, "/memfs-ramo/src/main.go", []byte("b"), 0644)
+	utils.WriteFileFS(mem, "/memfs-ramo/src/pkg/util.go", []byte("c"), 0644)
+
+	utils.Mount("/memfs-ramo", mem)
+	defer utils.Unmount("/memfs-ramo")
+
+	fv := ui.NewFileView(ui.NewApp())
+	fv.SetViewMode(ui.ViewModeTree)
+	if err := fv.SetCurrentDir("/memfs-ramo"); err != nil {
+		t.Fatal(err)
+	}
+	// "..", src e leia.txt
+	if got := fv.GetItemCount(); got != 3 {
+		t.Errorf("GetItemCount(árvore) = %d, esperava 3", got)
+	}
+
+	// No modo ramo aparecem só os arquivos, com o caminho relativo
+	fv.SetViewMode(ui.ViewModeFlat)
+	if got := fv.GetItemCount(); got != 3 {
+		t.Errorf("GetItemCount(ramo) = %d, esperava 3", got)
+	}
+	if !fv.SelectFile(filepath.Join("src", "pkg", "util.go")) || fv.SelectFile("src") {
+		t.Errorf("SelectFile() no modo ramo falhou")
+	}
+
+	// Colunas desconhecidas e repetidas são descartadas e o nome é mantido
+	fv.SetColumns([]ui.ColumnConfig{{ID: "size", Width: 8}, {ID: "xyz"}, {ID: "size"}, {ID: "inode", Width: -2}})
+	want := []ui.ColumnConfig{{ID: "name"}, {ID: "size", Width: 8}, {ID: "inode"}}
+	if got := fv.Columns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns() = %v, want %v", got, want)
+	}
+	if fv.GetSelectedFile() != filepath.Join("src", "pkg", "util.go") {
+		t.Errorf("SetColumns() perdeu o cursor: %q", fv.GetSelectedFile())
+	}
+
+	// O filtro vale para a listagem do painel e sobrevive à troca de modo
+	filter, err := utils.ParseFilespec("*.go;!util*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fv.SetFilter(filter)
+	if got := fv.GetItemCount(); got != 1 {
+		t.Errorf("GetItemCount(ramo, filtro) = %d, esperava 1", got)
+	}
+	fv.SetViewMode(ui.ViewModeTree)
+	if got := fv.GetItemCount(); got != 2 { // ".." e src; leia.txt fica de fora
+		t.Errorf("GetItemCount(árvore, filtro) = %d, esperava 2", got)
+	}
+
+	if ui.ParseViewMode(ui.ViewModeFlat.String()) != ui.ViewModeFlat || ui.ParseViewMode("?") != ui.ViewModeTree {
+		t.Error("ParseViewMode() não converte os nomes da configuração")
+	}
+}
+
+func TestTaggedSetAcrossDirs(t *testing.T) {
+	mem := utils.NewMemFS()
+	mem.MkdirAll("/memfs-tags/a", 0755)
+	mem.MkdirAll("/memfs-tags/b", 0755)
+	utils.WriteFileFS(mem, "/memfs-tags/a/nota.txt", []byte("a"), 0644)
+	utils.WriteFileFS(mem, "/memfs-tags/b/nota.txt", []byte("b"), 0644)
+	utils.WriteFileFS(mem, "/memfs-tags/b/outro.txt", []byte("c"), 0644)
+
+	utils.Mount("/memfs-tags", mem)
+	defer utils.Unmount("/memfs-tags")
+
+	app := ui.NewApp()
+	fv := ui.NewFileView(app)
+	if err := fv.SetCurrentDir("/memfs-tags/a"); err != nil {
+		t.Fatal(err)
+	}
+	app.ToggleTag("/memfs-tags/a/nota.txt")
+
+	// A marca é do caminho completo: não passa para o b/nota.txt e continua
+	// lá ao voltar para a/
+	if err := fv.SetCurrentDir("/memfs-tags/b"); err != nil {
+		t.Fatal(err)
+	}
+	if fv.IsTagged("nota.txt") {
+		t.Error("b/nota.txt aparece marcado")
+	}
+	app.ToggleTag("/memfs-tags/b/outro.txt")
+	if err := fv.SetCurrentDir("/memfs-tags/a"); err != nil {
+		t.Fatal(err)
+	}
+	if !fv.IsTagged("nota.txt") {
+		t.Error("a/nota.txt perdeu a marca ao trocar de diretório")
+	}
+	want := []string{"/memfs-tags/a/nota.txt", "/memfs-tags/b/outro.txt"}
+	if got := app.TaggedPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TaggedPaths() = %q, want %q", got, want)
+	}
+
+	// Dois itens com o mesmo nome não podem ir para o mesmo destino
+	tagged := make(map[string]bool)
+	for _, path := range app.TaggedPaths() {
+		tagged[path] = true
+	}
+	if got := ui.DuplicateTaggedNames(tagged); len(got) != 0 {
+		t.Errorf("DuplicateTaggedNames() = %q, want nenhum", got)
+	}
+	tagged["/memfs-tags/b/nota.txt"] = true
+	if got := ui.DuplicateTaggedNames(tagged); !reflect.DeepEqual(got, []string{"nota.txt"}) {
+		t.Errorf("DuplicateTaggedNames() = %q, want [nota.txt]", got)
+	}
+
+	// Marcar de novo desmarca
+	app.ToggleTag("/memfs-tags/a/nota.txt")
+	if fv.IsTagged("nota.txt") || len(app.TaggedPaths()) != 1 {
+		t.Errorf("ToggleTag() não desmarcou: %q", app.TaggedPaths())
+	}
+}
+
+func TestBookmarksPersist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	bookmarks, err := ui.LoadBookmarks()
+	if err != nil || len(bookmarks) != 0 {
+		t.Fatalf("LoadBookmarks() = %v, %v; want empty", bookmarks, err)
+	}
+
+	bookmarks["p"] = "/src/projeto"
+	if err := ui.SaveBookmarks(bookmarks); err != nil {
+		t.Fatalf("SaveBookmarks() error = %v", err)
+	}
+	loaded, err := ui.LoadBookmarks()
+	if err != nil {
+		t.Fatalf("LoadBookmarks() error = %v", err)
+	}
+	if loaded["p"] != "/src/projeto" {
+		t.Errorf("loaded bookmarks = %v", loaded)
+	}
+}
+
+func TestLoadFrecencyWithoutHome(t *testing.T) {
+	t.Setenv("HOME", "")
+
+	// Sem diretório de configuração não há base (nem frecency.json no diretório atual)
+	if db, err := ui.LoadFrecency(); err == nil || db != nil {
+		t.Errorf("LoadFrecency() = %v, %v; want nil e erro", db, err)
+	}
+}
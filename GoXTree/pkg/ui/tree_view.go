@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
 
 	"github.com/gdamore/tcell/v2"
@@ -78,11 +79,11 @@ func (t *TreeView) Refresh() {
 	}
 
 	// Criar nó raiz
-	root := tview.NewTreeNode(filepath.Base(t.rootDir)).
+	root := tview.NewTreeNode("").
 		SetReference(t.rootDir).
 		SetSelectable(true).
-		SetExpanded(true).
-		SetColor(tcell.ColorYellow)
+		SetExpanded(true)
+	t.setNodeLabel(root, t.rootDir)
 
 	// Adicionar nó raiz
 	t.SetRoot(root)
@@ -103,8 +104,8 @@ func (t *TreeView) Refresh() {
 
 // addSubDirectories adiciona subdiretórios a um nó
 func (t *TreeView) addSubDirectories(node *tview.TreeNode, path string) {
-	// Listar diretórios
-	entries, err := os.ReadDir(path)
+	// Listar diretórios (inclusive dentro de arquivos compactados)
+	entries, err := utils.ListDirectory(path, t.showHiddenDirs)
 	if err != nil {
 		return
 	}
@@ -112,30 +113,61 @@ func (t *TreeView) addSubDirectories(node *tview.TreeNode, path string) {
 	// Adicionar subdiretórios
 	for _, entry := range entries {
 		// Verificar se é um diretório
-		if !entry.IsDir() {
-			continue
-		}
-
-		// Verificar se é um diretório oculto
-		name := entry.Name()
-		if !t.showHiddenDirs && strings.HasPrefix(name, ".") {
+		if !entry.IsDir {
 			continue
 		}
 
 		// Criar caminho completo
-		childPath := filepath.Join(path, name)
+		childPath := filepath.Join(path, entry.Name)
 
-		// Criar nó
-		child := tview.NewTreeNode(name).
+		// Criar nó (com os marcadores do git)
+		child := tview.NewTreeNode("").
 			SetReference(childPath).
-			SetSelectable(true).
-			SetColor(tcell.ColorYellow)
+			SetSelectable(true)
+		t.setNodeLabel(child, childPath)
 
 		// Adicionar nó ao pai
 		node.AddChild(child)
 	}
 }
 
+// setNodeLabel escreve o nome do diretório no nó seguido dos marcadores do
+// git do seu conteúdo; diretórios ignorados ficam em cinza
+func (t *TreeView) setNodeLabel(node *tview.TreeNode, path string) {
+	label := tview.Escape(filepath.Base(path))
+	color := tcell.ColorYellow
+	if status := t.gitStatus(path); status != nil {
+		s := status.Lookup(path, true)
+		if s.Flags() == utils.GitIgnored {
+			color = tcell.ColorGray
+		}
+		label += gitMarkers(s.Flags())
+	}
+	node.SetText(label).SetColor(color)
+}
+
+// gitStatus retorna a situação do repositório git do diretório, ou nil
+func (t *TreeView) gitStatus(path string) *utils.GitStatus {
+	if t.app == nil {
+		return nil
+	}
+	return t.app.gitStatusFor(path)
+}
+
+// RefreshGit atualiza os marcadores do git de todos os nós da árvore
+func (t *TreeView) RefreshGit() {
+	root := t.GetRoot()
+	if root == nil {
+		return
+	}
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if path, ok := node.GetReference().(string); ok && node.GetText() != ".." {
+			t.setNodeLabel(node, path)
+		}
+		return true
+	})
+}
+
 // ExpandSelected expande o nó selecionado
 func (t *TreeView) ExpandSelected() {
 	// Obter nó selecionado
@@ -161,6 +193,9 @@ func (t *TreeView) ExpandSelected() {
 
 	// Adicionar subdiretórios
 	t.addSubDirectories(node, path)
+
+	// Observar o novo nó expandido
+	t.app.updateWatches()
 }
 
 // CollapseSelected colapsa o nó selecionado
@@ -174,6 +209,103 @@ func (t *TreeView) CollapseSelected() {
 	// Colapsar nó
 	node.SetExpanded(false)
 	node.ClearChildren()
+
+	// Deixar de observar os nós removidos
+	t.app.updateWatches()
+}
+
+// ExpandedDirs retorna os caminhos dos nós expandidos da árvore
+func (t *TreeView) ExpandedDirs() []string {
+	var dirs []string
+
+	root := t.GetRoot()
+	if root == nil {
+		return dirs
+	}
+
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if !node.IsExpanded() {
+			return false
+		}
+		if path, ok := node.GetReference().(string); ok && node.GetText() != ".." {
+			dirs = append(dirs, path)
+		}
+		return true
+	})
+
+	return dirs
+}
+
+// RefreshDir atualiza os filhos do nó que representa o diretório informado,
+// preservando os nós existentes (e sua expansão) e a seleção atual
+func (t *TreeView) RefreshDir(path string) {
+	root := t.GetRoot()
+	if root == nil {
+		return
+	}
+
+	// Localizar o nó expandido do diretório
+	var target *tview.TreeNode
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if ref, ok := node.GetReference().(string); ok && ref == path && node.GetText() != ".." && node.IsExpanded() {
+			target = node
+			return false
+		}
+		return target == nil
+	})
+	if target == nil {
+		return
+	}
+
+	// Mapear filhos atuais pelo caminho
+	existing := make(map[string]*tview.TreeNode)
+	var special []*tview.TreeNode
+	for _, child := range target.GetChildren() {
+		ref, _ := child.GetReference().(string)
+		if child.GetText() == ".." {
+			special = append(special, child)
+			continue
+		}
+		existing[ref] = child
+	}
+
+	// Montar nova lista de filhos, reaproveitando os nós existentes
+	fresh := tview.NewTreeNode("")
+	t.addSubDirectories(fresh, path)
+
+	children := special
+	for _, child := range fresh.GetChildren() {
+		ref, _ := child.GetReference().(string)
+		if old, ok := existing[ref]; ok {
+			old.SetText(child.GetText()).SetColor(child.GetColor())
+			children = append(children, old)
+			delete(existing, ref)
+		} else {
+			children = append(children, child)
+		}
+	}
+	target.SetChildren(children)
+
+	// Se o nó selecionado foi removido, selecionar o diretório pai
+	current := t.GetCurrentNode()
+	for _, removed := range existing {
+		if removed == current || containsNode(removed, current) {
+			t.SetCurrentNode(target)
+			break
+		}
+	}
+}
+
+// containsNode verifica se needle é descendente de node
+func containsNode(node, needle *tview.TreeNode) bool {
+	found := false
+	node.Walk(func(n, parent *tview.TreeNode) bool {
+		if n == needle {
+			found = true
+		}
+		return !found
+	})
+	return found
 }
 
 // GetCurrentNode retorna o nó atualmente selecionado
@@ -0,0 +1,790 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Formatos de arquivos compactados que podem ser abertos como diretórios
+const (
+	ArchiveZip    = "zip"
+	ArchiveTar    = "tar"
+	ArchiveTarGz  = "tar.gz"
+	ArchiveTarBz2 = "tar.bz2"
+	ArchiveTarXz  = "tar.xz"
+	ArchiveTarZst = "tar.zst"
+)
+
+// archiveExtensions associa extensões aos formatos (extensões compostas primeiro)
+var archiveExtensions = []struct {
+	ext    string
+	format string
+}{
+	{".tar.gz", ArchiveTarGz},
+	{".tgz", ArchiveTarGz},
+	{".tar.bz2", ArchiveTarBz2},
+	{".tbz2", ArchiveTarBz2},
+	{".tbz", ArchiveTarBz2},
+	{".tar.xz", ArchiveTarXz},
+	{".txz", ArchiveTarXz},
+	{".tar.zst", ArchiveTarZst},
+	{".tzst", ArchiveTarZst},
+	{".tar", ArchiveTar},
+	{".zip", ArchiveZip},
+}
+
+// maxCachedArchives é o número de índices de arquivos compactados mantidos em memória
+const maxCachedArchives = 8
+
+// ArchiveFormat retorna o formato do arquivo compactado pela extensão, ou "" se não for suportado
+func ArchiveFormat(filePath string) string {
+	lower := strings.ToLower(filePath)
+	for _, e := range archiveExtensions {
+		if strings.HasSuffix(lower, e.ext) {
+			return e.format
+		}
+	}
+	return ""
+}
+
+// IsArchive indica se o arquivo pode ser aberto como diretório virtual
+func IsArchive(filePath string) bool {
+	return ArchiveFormat(filePath) != ""
+}
+
+// ArchiveEntry representa um arquivo ou diretório dentro de um arquivo compactado
+type ArchiveEntry struct {
+	Name       string // Caminho dentro do arquivo, separado por "/" e sem barra final
+	Size       int64
+	ModTime    time.Time
+	Mode       os.FileMode
+	IsDir      bool
+	LinkTarget string // Destino de links simbólicos; em links físicos, a entrada ligada
+	Typeflag   byte   // Tipo da entrada no tar (tar.TypeReg, tar.TypeLink...); 0 no zip
+}
+
+// archiveFileInfo adapta ArchiveEntry para os.FileInfo
+type archiveFileInfo struct {
+	entry *ArchiveEntry
+}
+
+func (i archiveFileInfo) Name() string       { return path.Base(i.entry.Name) }
+func (i archiveFileInfo) Size() int64        { return i.entry.Size }
+func (i archiveFileInfo) Mode() os.FileMode  { return i.entry.Mode }
+func (i archiveFileInfo) ModTime() time.Time { return i.entry.ModTime }
+func (i archiveFileInfo) IsDir() bool        { return i.entry.IsDir }
+func (i archiveFileInfo) Sys() interface{}   { return i.entry }
+
+// Archive é o índice de um arquivo compactado aberto para leitura
+type Archive struct {
+	Path     string
+	Format   string
+	modTime  time.Time
+	size     int64
+	entries  map[string]*ArchiveEntry
+	children map[string][]string
+}
+
+var (
+	archiveCacheMu sync.Mutex
+	archiveCache   = make(map[string]*cachedArchive)
+)
+
+// cachedArchive guarda um índice e o momento do último uso
+type cachedArchive struct {
+	archive  *Archive
+	lastUsed time.Time
+}
+
+// OpenArchive lê o índice de um arquivo compactado. Os índices são mantidos em cache
+// enquanto o arquivo não for alterado.
+func OpenArchive(archivePath string) (*Archive, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveCacheMu.Lock()
+	cached, ok := archiveCache[archivePath]
+	if ok && cached.archive.modTime.Equal(info.ModTime()) && cached.archive.size == info.Size() {
+		cached.lastUsed = time.Now()
+		archiveCacheMu.Unlock()
+		return cached.archive, nil
+	}
+	archiveCacheMu.Unlock()
+
+	archive, err := readArchiveIndex(archivePath, info)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveCacheMu.Lock()
+	defer archiveCacheMu.Unlock()
+
+	// Descartar o índice usado há mais tempo
+	if len(archiveCache) >= maxCachedArchives {
+		var oldest string
+		for p, c := range archiveCache {
+			if oldest == "" || c.lastUsed.Before(archiveCache[oldest].lastUsed) {
+				oldest = p
+			}
+		}
+		delete(archiveCache, oldest)
+	}
+	archiveCache[archivePath] = &cachedArchive{archive: archive, lastUsed: time.Now()}
+
+	return archive, nil
+}
+
+// readArchiveIndex percorre o arquivo compactado montando o índice de entradas
+func readArchiveIndex(archivePath string, info os.FileInfo) (*Archive, error) {
+	a := &Archive{
+		Path:     archivePath,
+		Format:   ArchiveFormat(archivePath),
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		entries:  make(map[string]*ArchiveEntry),
+		children: make(map[string][]string),
+	}
+
+	switch a.Format {
+	case ArchiveZip:
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		for _, file := range reader.File {
+			a.add(ArchiveEntry{
+				Name:    file.Name,
+				Size:    int64(file.UncompressedSize64),
+				ModTime: file.Modified,
+				Mode:    file.Mode(),
+				IsDir:   file.FileInfo().IsDir(),
+			})
+		}
+	case "":
+		return nil, fmt.Errorf("formato de arquivo compactado não suportado: %s", archivePath)
+	default:
+		err := walkTar(archivePath, a.Format, func(header *tar.Header, r io.Reader) (bool, error) {
+			a.add(ArchiveEntry{
+				Name:       header.Name,
+				Size:       header.Size,
+				ModTime:    header.ModTime,
+				Mode:       header.FileInfo().Mode(),
+				IsDir:      header.Typeflag == tar.TypeDir,
+				LinkTarget: header.Linkname,
+				Typeflag:   header.Typeflag,
+			})
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for dir := range a.children {
+		sort.Strings(a.children[dir])
+	}
+
+	return a, nil
+}
+
+// cleanArchiveName normaliza o nome de uma entrada ("./a/b/" -> "a/b")
+func cleanArchiveName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+// add inclui uma entrada no índice, criando os diretórios implícitos
+func (a *Archive) add(entry ArchiveEntry) {
+	entry.Name = cleanArchiveName(entry.Name)
+	if entry.Name == "" {
+		return
+	}
+
+	if existing, ok := a.entries[entry.Name]; ok {
+		// Diretório implícito sendo definido explicitamente
+		*existing = entry
+		return
+	}
+
+	e := entry
+	a.entries[e.Name] = &e
+
+	// Registrar no diretório pai, criando os pais implícitos
+	name := e.Name
+	for {
+		parent := path.Dir(name)
+		if parent == "." {
+			parent = ""
+		}
+		a.children[parent] = append(a.children[parent], path.Base(name))
+		if parent == "" {
+			return
+		}
+		if _, ok := a.entries[parent]; ok {
+			return
+		}
+		a.entries[parent] = &ArchiveEntry{Name: parent, IsDir: true, Mode: os.ModeDir | 0755, ModTime: e.ModTime}
+		name = parent
+	}
+}
+
+// Stat retorna as informações de uma entrada ("" é a raiz do arquivo)
+func (a *Archive) Stat(name string) (os.FileInfo, error) {
+	name = cleanArchiveName(name)
+	if name == "" {
+		return archiveFileInfo{&ArchiveEntry{Name: filepath.Base(a.Path), IsDir: true, Mode: os.ModeDir | 0755, ModTime: a.modTime}}, nil
+	}
+	entry, ok := a.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: a.Path + "/" + name, Err: fs.ErrNotExist}
+	}
+	return archiveFileInfo{entry}, nil
+}
+
+// ReadDir lista o conteúdo de um diretório dentro do arquivo compactado
+func (a *Archive) ReadDir(dir string) ([]os.FileInfo, error) {
+	dir = cleanArchiveName(dir)
+	if dir != "" {
+		entry, ok := a.entries[dir]
+		if !ok || !entry.IsDir {
+			return nil, fmt.Errorf("%s não é um diretório", dir)
+		}
+	}
+
+	infos := make([]os.FileInfo, 0, len(a.children[dir]))
+	for _, childName := range a.children[dir] {
+		infos = append(infos, archiveFileInfo{a.entries[path.Join(dir, childName)]})
+	}
+	return infos, nil
+}
+
+// Open abre uma entrada do arquivo compactado para leitura
+func (a *Archive) Open(name string) (io.ReadCloser, error) {
+	name = cleanArchiveName(name)
+	entry, ok := a.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: a.Path + "/" + name, Err: fs.ErrNotExist}
+	}
+	if entry.IsDir {
+		return nil, fmt.Errorf("%s é um diretório", name)
+	}
+
+	if a.Format == ArchiveZip {
+		reader, err := zip.OpenReader(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range reader.File {
+			if cleanArchiveName(file.Name) == name {
+				rc, err := file.Open()
+				if err != nil {
+					reader.Close()
+					return nil, err
+				}
+				return &multiCloser{Reader: rc, closers: []io.Closer{rc, reader}}, nil
+			}
+		}
+		reader.Close()
+		return nil, &fs.PathError{Op: "open", Path: a.Path + "/" + name, Err: fs.ErrNotExist}
+	}
+
+	// Em arquivos tar é preciso percorrer o fluxo até a entrada
+	stream, err := openTarStream(a.Path, a.Format)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			stream.Close()
+			if err == io.EOF {
+				return nil, &fs.PathError{Op: "open", Path: a.Path + "/" + name, Err: fs.ErrNotExist}
+			}
+			return nil, err
+		}
+		if cleanArchiveName(header.Name) == name {
+			return &multiCloser{Reader: tr, closers: []io.Closer{stream}}, nil
+		}
+	}
+}
+
+// ReadFile lê o conteúdo de uma entrada (até maxSize bytes, se maxSize > 0)
+func (a *Archive) ReadFile(name string, maxSize int64) ([]byte, error) {
+	rc, err := a.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if maxSize > 0 {
+		return io.ReadAll(io.LimitReader(rc, maxSize))
+	}
+	return io.ReadAll(rc)
+}
+
+// Extract extrai as entradas informadas (diretórios recursivamente) para destDir.
+// Cada entrada é criada em destDir com o próprio nome, sem os diretórios acima dela.
+func (a *Archive) Extract(jc *JobContext, names []string, destDir string) error {
+	// Mapear cada entrada a extrair para o caminho relativo no destino
+	targets := make(map[string]string)
+	for _, name := range names {
+		name = cleanArchiveName(name)
+		base := path.Dir(name)
+		if base == "." {
+			base = ""
+		}
+
+		for entryName, entry := range a.entries {
+			if entryName != name && !strings.HasPrefix(entryName, name+"/") && name != "" {
+				continue
+			}
+			rel := strings.TrimPrefix(strings.TrimPrefix(entryName, base), "/")
+			targets[entryName] = rel
+			if !entry.IsDir {
+				jc.AddTotal(1, entry.Size)
+			}
+		}
+	}
+
+	extract := func(name string, r io.Reader) error {
+		rel, ok := targets[name]
+		if !ok {
+			return nil
+		}
+		entry := a.entries[name]
+
+		// Impedir caminhos que escapem do destino ("zip slip"), inclusive
+		// através de links simbólicos extraídos antes
+		target := filepath.Join(destDir, filepath.FromSlash(rel))
+		if !insideDir(destDir, target) {
+			return jc.Fail(name, fmt.Errorf("caminho inválido no arquivo compactado: %s", name))
+		}
+		if err := checkSymlinkParents(destDir, target); err != nil {
+			return jc.Fail(name, err)
+		}
+
+		if err := jc.Checkpoint(); err != nil {
+			return err
+		}
+		jc.SetCurrent(name)
+
+		if entry.Typeflag == tar.TypeLink {
+			return jc.Fail(name, a.extractHardLink(jc, entry, destDir, target, targets))
+		}
+		return jc.Fail(name, writeArchiveEntry(jc, entry, r, destDir, target))
+	}
+
+	if a.Format == ArchiveZip {
+		reader, err := zip.OpenReader(a.Path)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		// Diretórios implícitos não aparecem na lista do zip
+		for name, entry := range a.entries {
+			if entry.IsDir {
+				if err := extract(name, nil); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, file := range reader.File {
+			name := cleanArchiveName(file.Name)
+			if _, ok := targets[name]; !ok || a.entries[name].IsDir {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				if err := jc.Fail(name, err); err != nil {
+					return err
+				}
+				continue
+			}
+			err = extract(name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Diretórios implícitos primeiro; depois as entradas na ordem do fluxo tar
+	for name, entry := range a.entries {
+		if entry.IsDir {
+			if err := extract(name, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return walkTar(a.Path, a.Format, func(header *tar.Header, r io.Reader) (bool, error) {
+		name := cleanArchiveName(header.Name)
+		if entry, ok := a.entries[name]; ok && entry.IsDir {
+			return true, nil
+		}
+		return true, extract(name, r)
+	})
+}
+
+// insideDir indica se path é o próprio dir ou está dentro dele
+func insideDir(dir, path string) bool {
+	dir, path = filepath.Clean(dir), filepath.Clean(path)
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// checkSymlinkParents recusa destinos cujo caminho abaixo de destDir passe por
+// um link simbólico: um link extraído antes (a -> /fora) faria a entrada a/x
+// ser gravada fora do destino
+func checkSymlinkParents(destDir, target string) error {
+	rel, err := filepath.Rel(filepath.Clean(destDir), filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	current := filepath.Clean(destDir)
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return nil // O restante ainda não existe e será criado como diretório
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("caminho inválido no arquivo compactado: %s passa pelo link simbólico %s", target, current)
+		}
+	}
+	return nil
+}
+
+// extractHardLink extrai um link físico do tar em target: um novo link para o
+// arquivo ligado, se ele foi extraído junto, ou uma cópia do seu conteúdo lida
+// do arquivo compactado
+func (a *Archive) extractHardLink(jc *JobContext, entry *ArchiveEntry, destDir, target string, targets map[string]string) error {
+	linkName := cleanArchiveName(entry.LinkTarget)
+	linked, ok := a.entries[linkName]
+	if !ok || linked.IsDir || !isRegularArchiveEntry(linked) {
+		return fmt.Errorf("link físico para uma entrada inválida: %s -> %s", entry.Name, entry.LinkTarget)
+	}
+
+	if rel, ok := targets[linkName]; ok {
+		source := filepath.Join(destDir, filepath.FromSlash(rel))
+		if !insideDir(destDir, source) {
+			return fmt.Errorf("link físico para fora do destino: %s -> %s", entry.Name, entry.LinkTarget)
+		}
+		if err := checkSymlinkParents(destDir, source); err != nil {
+			return err
+		}
+		if info, err := os.Lstat(source); err != nil || !info.Mode().IsRegular() {
+			return fmt.Errorf("o arquivo ligado por %s não foi extraído: %s", entry.Name, entry.LinkTarget)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		// Sistemas de arquivos sem links físicos recebem uma cópia
+		if err := os.Link(source, target); err != nil {
+			if err := CopyFile(source, target); err != nil {
+				return err
+			}
+		}
+		jc.FileDone()
+		return nil
+	}
+
+	rc, err := a.Open(linkName)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return writeArchiveEntry(jc, linked, rc, destDir, target)
+}
+
+// isRegularArchiveEntry indica se a entrada é um arquivo comum (as do zip, que
+// não têm tipo, também são)
+func isRegularArchiveEntry(entry *ArchiveEntry) bool {
+	switch entry.Typeflag {
+	case tar.TypeReg, 0, tar.TypeCont, tar.TypeGNUSparse:
+		return entry.Mode&os.ModeSymlink == 0
+	}
+	return false
+}
+
+// writeArchiveEntry grava uma entrada extraída em target, dentro de destDir.
+// Links físicos são extraídos por extractHardLink; dispositivos e FIFOs são recusados.
+func writeArchiveEntry(jc *JobContext, entry *ArchiveEntry, r io.Reader, destDir, target string) error {
+	switch {
+	case entry.IsDir:
+		return os.MkdirAll(target, 0755)
+	case entry.Mode&os.ModeSymlink != 0:
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		// No zip o destino do link é o conteúdo da entrada
+		linkTarget := entry.LinkTarget
+		if linkTarget == "" && r != nil {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			linkTarget = string(data)
+		}
+		// Links que apontam para fora do destino são recusados
+		if filepath.IsAbs(linkTarget) || !insideDir(destDir, filepath.Join(filepath.Dir(target), linkTarget)) {
+			return fmt.Errorf("link simbólico para fora do destino: %s -> %s", entry.Name, linkTarget)
+		}
+		os.Remove(target)
+		return os.Symlink(linkTarget, target)
+	case !isRegularArchiveEntry(entry):
+		return fmt.Errorf("tipo de entrada não suportado na extração (%q): %s", entry.Typeflag, entry.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	// Um link já existente no lugar do arquivo seria seguido pelo OpenFile
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		os.Remove(target)
+	}
+
+	perm := entry.Mode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = file
+	if jc != nil {
+		w = &progressWriter{w: file, jc: jc}
+	}
+	if r != nil {
+		_, err = io.Copy(w, r)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return err
+	}
+
+	jc.FileDone()
+	if !entry.ModTime.IsZero() {
+		os.Chtimes(target, entry.ModTime, entry.ModTime)
+	}
+	return nil
+}
+
+// openTarStream abre um arquivo tar (compactado ou não) e retorna o fluxo descompactado.
+// Fechar o fluxo fecha também o arquivo.
+func openTarStream(archivePath, format string) (io.ReadCloser, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &multiCloser{closers: []io.Closer{file}}
+	switch format {
+	case ArchiveTar:
+		stream.Reader = file
+	case ArchiveTarGz:
+		stream.Reader, err = gzip.NewReader(file)
+	case ArchiveTarBz2:
+		stream.Reader = bzip2.NewReader(file)
+	case ArchiveTarXz:
+		stream.Reader, err = xz.NewReader(file)
+	case ArchiveTarZst:
+		var decoder *zstd.Decoder
+		decoder, err = zstd.NewReader(file)
+		if err == nil {
+			stream.Reader = decoder
+			stream.closers = append(stream.closers, decoder.IOReadCloser())
+		}
+	default:
+		err = fmt.Errorf("formato de arquivo compactado não suportado: %s", format)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// walkTar percorre as entradas de um arquivo tar; fn retorna false para interromper
+func walkTar(archivePath, format string, fn func(header *tar.Header, r io.Reader) (bool, error)) error {
+	stream, err := openTarStream(archivePath, format)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %v", filepath.Base(archivePath), err)
+		}
+
+		more, err := fn(header, tr)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// multiCloser é um leitor que fecha vários recursos ao ser fechado
+type multiCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close fecha todos os recursos associados
+func (m *multiCloser) Close() error {
+	var errs []error
+	for _, c := range m.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SplitArchivePath separa um caminho virtual ("/dados/a.zip/docs/x.txt") no caminho do
+// arquivo compactado e no caminho interno. ok é false para caminhos reais.
+func SplitArchivePath(virtualPath string) (archivePath, inner string, ok bool) {
+	virtualPath = filepath.Clean(virtualPath)
+
+	// O primeiro ancestral existente decide: se for um arquivo compactado, o caminho é virtual
+	for p := virtualPath; ; {
+		info, err := os.Stat(p)
+		if err == nil {
+			if !info.Mode().IsRegular() || !IsArchive(p) {
+				return "", "", false
+			}
+			rel, err := filepath.Rel(p, virtualPath)
+			if err != nil {
+				return "", "", false
+			}
+			if rel == "." {
+				rel = ""
+			}
+			return p, filepath.ToSlash(rel), true
+		}
+
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", "", false
+		}
+		p = parent
+	}
+}
+
+// IsArchivePath indica se o caminho aponta para dentro de um arquivo compactado
+func IsArchivePath(virtualPath string) bool {
+	_, _, ok := SplitArchivePath(virtualPath)
+	return ok
+}
+
+// Stat retorna as informações de um caminho em qualquer sistema de arquivos
+// (um arquivo compactado é tratado como diretório)
+func Stat(name string) (os.FileInfo, error) {
+	return FSFor(name).Stat(name)
+}
+
+// ListDirectory lista um diretório em qualquer sistema de arquivos (local, montado ou
+// dentro de um arquivo compactado)
+func ListDirectory(dirPath string, showHidden bool) ([]FileInfo, error) {
+	return ListFilesFS(FSFor(dirPath), dirPath, showHidden)
+}
+
+// errArchiveReadOnly é retornado pelas operações de escrita dentro de arquivos compactados
+var errArchiveReadOnly = errors.New("arquivos compactados são somente leitura")
+
+// archiveFS expõe o conteúdo de arquivos compactados como um VFS somente leitura.
+// Os caminhos são virtuais: "/dados/a.zip/docs/x.txt".
+type archiveFS struct{}
+
+// resolve abre o arquivo compactado de um caminho virtual
+func (archiveFS) resolve(op, name string) (*Archive, string, error) {
+	archivePath, inner, ok := SplitArchivePath(name)
+	if !ok {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	archive, err := OpenArchive(archivePath)
+	if err != nil {
+		return nil, "", err
+	}
+	return archive, inner, nil
+}
+
+func (f archiveFS) List(dir string) ([]os.FileInfo, error) {
+	archive, inner, err := f.resolve("readdir", dir)
+	if err != nil {
+		return nil, err
+	}
+	return archive.ReadDir(inner)
+}
+
+func (f archiveFS) Stat(name string) (os.FileInfo, error) {
+	archive, inner, err := f.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return archive.Stat(inner)
+}
+
+func (f archiveFS) Open(name string) (io.ReadCloser, error) {
+	archive, inner, err := f.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return archive.Open(inner)
+}
+
+func (archiveFS) Create(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: errArchiveReadOnly}
+}
+
+func (archiveFS) Rename(oldPath, newPath string) error {
+	return &fs.PathError{Op: "rename", Path: oldPath, Err: errArchiveReadOnly}
+}
+
+func (archiveFS) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: errArchiveReadOnly}
+}
+
+func (archiveFS) MkdirAll(dir string, perm os.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: dir, Err: errArchiveReadOnly}
+}
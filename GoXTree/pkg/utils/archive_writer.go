@@ -0,0 +1,506 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// ArchiveOptions configura a criação de um arquivo compactado
+type ArchiveOptions struct {
+	Format              string   // ArchiveZip, ArchiveTar, ArchiveTarGz, ArchiveTarZst ou ArchiveTarXz
+	Level               int      // Nível de compressão de 1 (mais rápido) a 9 (menor); 0 usa o padrão do formato
+	Exclude             []string // Padrões (ex.: "*.log", "node_modules") comparados com o nome e o caminho relativo
+	PreservePermissions bool     // Guardar permissões e dono; sem isso, usa 0644/0755
+	PreserveSymlinks    bool     // Guardar links simbólicos como links; sem isso, o destino é incluído
+	VolumeSize          int64    // Divide o arquivo em volumes (.001, .002, ...) deste tamanho; 0 não divide
+}
+
+// WritableArchiveFormats são os formatos que podem ser criados (tar.bz2 é somente leitura)
+var WritableArchiveFormats = []string{ArchiveZip, ArchiveTar, ArchiveTarGz, ArchiveTarZst, ArchiveTarXz}
+
+// DefaultArchiveOptions retorna as opções padrão para o destino, com o formato pela extensão (zip se desconhecida)
+func DefaultArchiveOptions(dst string) ArchiveOptions {
+	format := ArchiveFormat(dst)
+	if format == "" {
+		format = ArchiveZip
+	}
+	return ArchiveOptions{Format: format, PreservePermissions: true, PreserveSymlinks: true}
+}
+
+// ArchiveExtension retorna a extensão usual do formato (ex.: ".tar.gz")
+func ArchiveExtension(format string) string {
+	return "." + format
+}
+
+// archiveItem é um arquivo, diretório ou link a ser incluído no arquivo compactado
+type archiveItem struct {
+	path string // Caminho no sistema de arquivos
+	name string // Nome dentro do arquivo compactado (separado por "/")
+	info os.FileInfo
+	link string // Destino do link simbólico
+}
+
+// CreateArchive compacta os arquivos e diretórios informados em dst, cada um com o próprio
+// nome na raiz do arquivo compactado. Em caso de falha ou cancelamento os volumes parciais são removidos.
+func CreateArchive(jc *JobContext, paths []string, dst string, opts ArchiveOptions) error {
+	roots := make(map[string]string, len(paths))
+	for _, p := range paths {
+		roots[p] = filepath.Base(p)
+	}
+	return createArchive(jc, roots, dst, opts)
+}
+
+// createArchive compacta roots (caminho -> nome na raiz; "" inclui apenas o conteúdo do diretório)
+func createArchive(jc *JobContext, roots map[string]string, dst string, opts ArchiveOptions) (err error) {
+	if opts.Format == ArchiveTarBz2 {
+		return fmt.Errorf("o formato tar.bz2 é suportado apenas para leitura")
+	}
+
+	// Levantar os itens antes, para que o progresso considere as exclusões
+	items, err := collectArchiveItems(roots, dst, opts)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.info.Mode().IsRegular() {
+			jc.AddTotal(1, item.info.Size())
+		}
+	}
+
+	out, err := newVolumeWriter(dst, opts.VolumeSize)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			out.Remove()
+		}
+	}()
+
+	aw, err := newArchiveWriter(out, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := jc.Checkpoint(); err != nil {
+			aw.Close()
+			return err
+		}
+		jc.SetCurrent(item.path)
+
+		if err := aw.add(jc, item); err != nil {
+			if err := jc.Fail(item.path, err); err != nil {
+				aw.Close()
+				return err
+			}
+			continue
+		}
+		if item.info.Mode().IsRegular() {
+			jc.FileDone()
+		}
+	}
+
+	return aw.Close()
+}
+
+// collectArchiveItems percorre as origens aplicando as exclusões e a política de links
+func collectArchiveItems(roots map[string]string, dst string, opts ArchiveOptions) ([]archiveItem, error) {
+	var items []archiveItem
+
+	// O próprio arquivo de destino nunca é incluído
+	absDst, _ := filepath.Abs(dst)
+
+	var walk func(p, name string, visited map[string]bool) error
+	walk = func(p, name string, visited map[string]bool) error {
+		if abs, _ := filepath.Abs(p); abs == absDst || isVolumeName(abs, absDst) {
+			return nil
+		}
+		if name != "" && isExcluded(name, opts.Exclude) {
+			return nil
+		}
+
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+
+		item := archiveItem{path: p, name: name, info: info}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if opts.PreserveSymlinks {
+				if item.link, err = os.Readlink(p); err != nil {
+					return err
+				}
+				items = append(items, item)
+				return nil
+			}
+
+			// Seguir o link (sem entrar em ciclos de diretórios)
+			if info, err = os.Stat(p); err != nil {
+				return err
+			}
+			item.info = info
+		}
+
+		if !info.IsDir() {
+			items = append(items, item)
+			return nil
+		}
+
+		real, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			return err
+		}
+		if visited[real] {
+			return nil
+		}
+		visited[real] = true
+		defer delete(visited, real)
+
+		if name != "" {
+			items = append(items, item)
+		}
+
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := walk(filepath.Join(p, entry.Name()), path.Join(name, entry.Name()), visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for p, name := range roots {
+		if err := walk(p, name, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// isVolumeName indica se p é um volume de absDst (absDst.001, absDst.002, ...)
+func isVolumeName(p, absDst string) bool {
+	suffix, ok := strings.CutPrefix(p, absDst+".")
+	if !ok || len(suffix) < 3 {
+		return false
+	}
+	for _, r := range suffix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isExcluded verifica se o nome (caminho relativo com "/") corresponde a algum padrão de exclusão.
+// Padrões sem "/" são comparados com o nome base; os demais, com o caminho completo.
+func isExcluded(name string, patterns []string) bool {
+	base := path.Base(name)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = name
+			pattern = strings.Trim(pattern, "/")
+		}
+		if match, _ := path.Match(pattern, target); match {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExcludePatterns separa uma lista de padrões de exclusão ("*.log; tmp/*")
+func ParseExcludePatterns(text string) []string {
+	var patterns []string
+	for _, p := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == ',' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// archiveWriter grava entradas em um formato de arquivo compactado
+type archiveWriter interface {
+	add(jc *JobContext, item archiveItem) error
+	Close() error
+}
+
+// newArchiveWriter cria o escritor do formato escolhido sobre out
+func newArchiveWriter(out io.Writer, opts ArchiveOptions) (archiveWriter, error) {
+	if opts.Format == ArchiveZip {
+		zw := zip.NewWriter(out)
+		if opts.Level > 0 {
+			level := opts.Level
+			zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+				return flate.NewWriter(w, level)
+			})
+		}
+		return &zipArchiveWriter{zw: zw, opts: opts}, nil
+	}
+
+	var stream io.WriteCloser
+	var err error
+	switch opts.Format {
+	case ArchiveTar:
+		stream = nopWriteCloser{out}
+	case ArchiveTarGz:
+		level := gzip.DefaultCompression
+		if opts.Level > 0 {
+			level = opts.Level
+		}
+		stream, err = gzip.NewWriterLevel(out, level)
+	case ArchiveTarZst:
+		level := zstd.SpeedDefault
+		if opts.Level > 0 {
+			// Níveis 1-9 distribuídos entre os níveis do zstd
+			level = zstd.EncoderLevelFromZstd(opts.Level * 2)
+		}
+		stream, err = zstd.NewWriter(out, zstd.WithEncoderLevel(level))
+	case ArchiveTarXz:
+		config := xz.WriterConfig{}
+		if opts.Level > 0 {
+			// Tamanho do dicionário dos níveis do xz (1 MiB a 64 MiB)
+			dictSizes := []int{1, 2, 4, 4, 8, 8, 16, 32, 64}
+			config.DictCap = dictSizes[min(opts.Level, 9)-1] << 20
+		}
+		stream, err = config.NewWriter(out)
+	default:
+		err = fmt.Errorf("formato de arquivo compactado não suportado: %s", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tarArchiveWriter{tw: tar.NewWriter(stream), stream: stream, opts: opts}, nil
+}
+
+// zipArchiveWriter grava entradas em um arquivo zip
+type zipArchiveWriter struct {
+	zw   *zip.Writer
+	opts ArchiveOptions
+}
+
+func (z *zipArchiveWriter) add(jc *JobContext, item archiveItem) error {
+	header, err := zip.FileInfoHeader(item.info)
+	if err != nil {
+		return err
+	}
+	header.Name = item.name
+	if !z.opts.PreservePermissions {
+		header.SetMode(normalizedMode(item.info.Mode()))
+	}
+
+	switch {
+	case item.info.IsDir():
+		header.Name += "/"
+		_, err = z.zw.CreateHeader(header)
+		return err
+	case item.link != "":
+		// No zip o destino do link é gravado como conteúdo da entrada
+		header.Method = zip.Store
+		w, err := z.zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, item.link)
+		return err
+	}
+
+	header.Method = zip.Deflate
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	return copyArchiveContent(jc, w, item.path)
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zw.Close()
+}
+
+// tarArchiveWriter grava entradas em um arquivo tar, com ou sem compressão
+type tarArchiveWriter struct {
+	tw     *tar.Writer
+	stream io.WriteCloser
+	opts   ArchiveOptions
+}
+
+func (t *tarArchiveWriter) add(jc *JobContext, item archiveItem) error {
+	header, err := tar.FileInfoHeader(item.info, item.link)
+	if err != nil {
+		return err
+	}
+	header.Name = item.name
+	if item.info.IsDir() {
+		header.Name += "/"
+	}
+	if !t.opts.PreservePermissions {
+		header.Mode = int64(normalizedMode(item.info.Mode()).Perm())
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+	}
+
+	if err := t.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+	return copyArchiveContent(jc, t.tw, item.path)
+}
+
+func (t *tarArchiveWriter) Close() error {
+	err := t.tw.Close()
+	if closeErr := t.stream.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// normalizedMode retorna o modo padrão (0755 para diretórios e executáveis, 0644 para os demais)
+func normalizedMode(mode os.FileMode) os.FileMode {
+	if mode.IsDir() {
+		return os.ModeDir | 0755
+	}
+	if mode&os.ModeSymlink != 0 {
+		return os.ModeSymlink | 0777
+	}
+	if mode&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+// copyArchiveContent copia o conteúdo de um arquivo para a entrada, reportando o progresso
+func copyArchiveContent(jc *JobContext, w io.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if jc != nil {
+		w = &progressWriter{w: w, jc: jc}
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// nopWriteCloser adapta um io.Writer sem Close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// volumeWriter grava em um único arquivo ou em volumes de tamanho fixo (dst.001, dst.002, ...)
+type volumeWriter struct {
+	dst     string
+	size    int64
+	file    *os.File
+	written int64
+	paths   []string
+}
+
+// newVolumeWriter cria o primeiro arquivo de saída
+func newVolumeWriter(dst string, size int64) (*volumeWriter, error) {
+	v := &volumeWriter{dst: dst, size: size}
+	if err := v.next(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// VolumePath retorna o nome do volume n (a partir de 1) de um arquivo dividido
+func VolumePath(dst string, n int) string {
+	return fmt.Sprintf("%s.%03d", dst, n)
+}
+
+// next fecha o volume atual e abre o próximo
+func (v *volumeWriter) next() error {
+	if v.file != nil {
+		if err := v.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	p := v.dst
+	if v.size > 0 {
+		p = VolumePath(v.dst, len(v.paths)+1)
+	}
+	file, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	v.file = file
+	v.written = 0
+	v.paths = append(v.paths, p)
+	return nil
+}
+
+func (v *volumeWriter) Write(p []byte) (int, error) {
+	if v.size <= 0 {
+		return v.file.Write(p)
+	}
+
+	total := 0
+	for len(p) > 0 {
+		if v.written >= v.size {
+			if err := v.next(); err != nil {
+				return total, err
+			}
+		}
+		chunk := p
+		if remaining := v.size - v.written; int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+		n, err := v.file.Write(chunk)
+		total += n
+		v.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
+
+// Close fecha o volume atual
+func (v *volumeWriter) Close() error {
+	if v.file == nil {
+		return nil
+	}
+	err := v.file.Close()
+	v.file = nil
+	return err
+}
+
+// Remove apaga todos os volumes gravados
+func (v *volumeWriter) Remove() {
+	for _, p := range v.paths {
+		os.Remove(p)
+	}
+}
@@ -0,0 +1,529 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DiffOp é o tipo de uma linha no resultado da comparação
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DefaultDiffContext é o número padrão de linhas de contexto em volta das alterações
+const DefaultDiffContext = 3
+
+// myersCostLimit limita o número de passos do algoritmo de Myers em cada divisão;
+// acima dele uma divisão aproximada é usada para não travar em arquivos muito diferentes
+const myersCostLimit = 2048
+
+// DiffOptions define como as linhas são comparadas
+type DiffOptions struct {
+	IgnoreWhitespace bool
+	IgnoreCase       bool
+	// Context é o número de linhas de contexto de cada bloco (0 = DefaultDiffContext)
+	Context int
+}
+
+// DiffLine é uma linha do resultado da comparação. Os números de linha começam em 1
+// e são 0 no lado em que a linha não existe.
+type DiffLine struct {
+	Op      DiffOp
+	Old     string
+	New     string
+	OldLine int
+	NewLine int
+}
+
+// DiffHunk é um bloco de alterações com as linhas de contexto em volta
+type DiffHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []DiffLine
+}
+
+// FileDiff é o resultado da comparação de dois arquivos
+type FileDiff struct {
+	OldName  string
+	NewName  string
+	Lines    []DiffLine
+	Hunks    []DiffHunk
+	OldNoEOL bool
+	NewNoEOL bool
+}
+
+// Identical indica se não há diferenças (de acordo com as opções usadas)
+func (d *FileDiff) Identical() bool {
+	return len(d.Hunks) == 0 && d.OldNoEOL == d.NewNoEOL
+}
+
+// Stats retorna o número de linhas inseridas e removidas
+func (d *FileDiff) Stats() (inserted, deleted int) {
+	for _, line := range d.Lines {
+		switch line.Op {
+		case DiffInsert:
+			inserted++
+		case DiffDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// DiffFiles compara dois arquivos (de qualquer sistema de arquivos) linha a linha
+func DiffFiles(file1, file2 string, opts DiffOptions) (*FileDiff, error) {
+	var contents [2][]byte
+	for i, name := range []string{file1, file2} {
+		fsys := FSFor(name)
+		isText, err := IsTextFileFS(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if !isText {
+			return nil, fmt.Errorf("%s é um arquivo binário", name)
+		}
+		if contents[i], err = ReadFileFS(fsys, name); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := DiffText(string(contents[0]), string(contents[1]), opts)
+	diff.OldName, diff.NewName = file1, file2
+	return diff, nil
+}
+
+// DiffText compara dois textos linha a linha
+func DiffText(oldText, newText string, opts DiffOptions) *FileDiff {
+	oldLines, oldNoEOL := splitDiffLines(oldText)
+	newLines, newNoEOL := splitDiffLines(newText)
+
+	lines := DiffLines(oldLines, newLines, opts)
+	if oldNoEOL != newNoEOL {
+		lines = splitNoEOLLine(lines, len(oldLines), len(newLines), oldNoEOL)
+	}
+	context := opts.Context
+	if context <= 0 {
+		context = DefaultDiffContext
+	}
+
+	return &FileDiff{
+		Lines:    lines,
+		Hunks:    Hunks(lines, context),
+		OldNoEOL: oldNoEOL,
+		NewNoEOL: newNoEOL,
+	}
+}
+
+// splitDiffLines divide o texto em linhas e indica se falta a quebra de linha final
+func splitDiffLines(text string) ([]string, bool) {
+	if text == "" {
+		return nil, false
+	}
+	noEOL := !strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return lines, noEOL
+}
+
+// splitNoEOLLine separa em remoção e inserção a linha igual que é a última de
+// um dos lados sem a quebra de linha final: como no diff do GNU, "a" sem a
+// quebra não é igual a "a" com ela
+func splitNoEOLLine(lines []DiffLine, lastOld, lastNew int, oldNoEOL bool) []DiffLine {
+	for i, line := range lines {
+		if line.Op != DiffEqual {
+			continue
+		}
+		if oldNoEOL && line.OldLine != lastOld || !oldNoEOL && line.NewLine != lastNew {
+			continue
+		}
+		split := make([]DiffLine, 0, len(lines)+1)
+		split = append(split, lines[:i]...)
+		split = append(split,
+			DiffLine{Op: DiffDelete, Old: line.Old, OldLine: line.OldLine},
+			DiffLine{Op: DiffInsert, New: line.New, NewLine: line.NewLine})
+		return append(split, lines[i+1:]...)
+	}
+	return lines
+}
+
+// DiffLines compara duas listas de linhas (algoritmo de Myers em espaço linear)
+func DiffLines(oldLines, newLines []string, opts DiffOptions) []DiffLine {
+	// Mapear cada linha (normalizada conforme as opções) para um número
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		seq := make([]int, len(lines))
+		for i, line := range lines {
+			key := normalizeDiffLine(line, opts)
+			id, ok := ids[key]
+			if !ok {
+				id = len(ids)
+				ids[key] = id
+			}
+			seq[i] = id
+		}
+		return seq
+	}
+	a, b := intern(oldLines), intern(newLines)
+
+	del, ins := diffSequences(a, b)
+
+	// Montar o resultado: remoções antes das inserções em cada bloco
+	var result []DiffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && del[i]:
+			result = append(result, DiffLine{Op: DiffDelete, Old: oldLines[i], OldLine: i + 1})
+			i++
+		case j < len(b) && ins[j]:
+			result = append(result, DiffLine{Op: DiffInsert, New: newLines[j], NewLine: j + 1})
+			j++
+		default:
+			result = append(result, DiffLine{Op: DiffEqual, Old: oldLines[i], New: newLines[j], OldLine: i + 1, NewLine: j + 1})
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// normalizeDiffLine aplica as opções de comparação a uma linha
+func normalizeDiffLine(line string, opts DiffOptions) string {
+	if opts.IgnoreWhitespace {
+		line = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	if opts.IgnoreCase {
+		line = strings.ToLower(line)
+	}
+	return line
+}
+
+// diffSequences marca os itens removidos de a e inseridos em b. Itens que não
+// aparecem no outro lado são descartados antes do Myers, o que torna rápida a
+// comparação de arquivos muito diferentes.
+func diffSequences(a, b []int) (del, ins []bool) {
+	del, ins = make([]bool, len(a)), make([]bool, len(b))
+
+	inA, inB := make(map[int]bool, len(a)), make(map[int]bool, len(b))
+	for _, v := range a {
+		inA[v] = true
+	}
+	for _, v := range b {
+		inB[v] = true
+	}
+
+	var fa, fb, ia, ib []int
+	for i, v := range a {
+		if inB[v] {
+			fa, ia = append(fa, v), append(ia, i)
+		} else {
+			del[i] = true
+		}
+	}
+	for j, v := range b {
+		if inA[v] {
+			fb, ib = append(fb, v), append(ib, j)
+		} else {
+			ins[j] = true
+		}
+	}
+
+	m := &myers{a: fa, b: fb, del: make([]bool, len(fa)), ins: make([]bool, len(fb))}
+	m.compare(0, len(fa), 0, len(fb))
+
+	for i, d := range m.del {
+		if d {
+			del[ia[i]] = true
+		}
+	}
+	for j, d := range m.ins {
+		if d {
+			ins[ib[j]] = true
+		}
+	}
+	return del, ins
+}
+
+// myers implementa o algoritmo O(ND) de Myers com a divisão pela "cobra do meio"
+type myers struct {
+	a, b     []int
+	del, ins []bool
+}
+
+// compare marca as diferenças entre a[aLo:aHi] e b[bLo:bHi]
+func (m *myers) compare(aLo, aHi, bLo, bHi int) {
+	// Ignorar prefixo e sufixo comuns
+	for aLo < aHi && bLo < bHi && m.a[aLo] == m.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && m.a[aHi-1] == m.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			m.ins[j] = true
+		}
+		return
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			m.del[i] = true
+		}
+		return
+	}
+
+	x, y, ok := m.middleSnake(aLo, aHi, bLo, bHi)
+	if !ok {
+		// Sem divisão útil: tratar o trecho inteiro como alterado
+		for i := aLo; i < aHi; i++ {
+			m.del[i] = true
+		}
+		for j := bLo; j < bHi; j++ {
+			m.ins[j] = true
+		}
+		return
+	}
+
+	m.compare(aLo, x, bLo, y)
+	m.compare(x, aHi, y, bHi)
+}
+
+// middleSnake encontra um ponto do caminho de edição mínimo que divide o problema
+// em duas partes menores, avançando ao mesmo tempo do início e do fim
+func (m *myers) middleSnake(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, mm := aHi-aLo, bHi-bLo
+	delta := n - mm
+	odd := delta&1 != 0
+	maxD := (n + mm + 1) / 2
+	off := maxD + 1
+
+	// vf[k] é o x mais distante no caminho direto da diagonal k (k = x - y);
+	// vb[c] é o menor x no caminho reverso da diagonal c (centrada em delta)
+	vf := make([]int, 2*maxD+3)
+	vb := make([]int, 2*maxD+3)
+	vf[off+1] = 0
+	vb[off-1] = n
+
+	for d := 0; d <= maxD; d++ {
+		if d > myersCostLimit {
+			return m.bestForward(vf, off, d-1, aLo, bLo, n, mm)
+		}
+
+		// Caminho direto
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < mm && m.a[aLo+x] == m.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+
+			if odd && k >= delta-(d-1) && k <= delta+(d-1) && vf[off+k] >= vb[off+k-delta] {
+				return aLo + x, bLo + y, true
+			}
+		}
+
+		// Caminho reverso
+		for c := delta - d; c <= delta+d; c += 2 {
+			var x int
+			if c == delta+d || (c != delta-d && vb[off+c-delta-1] < vb[off+c-delta+1]) {
+				x = vb[off+c-delta-1]
+			} else {
+				x = vb[off+c-delta+1] - 1
+			}
+			y := x - c
+			for x > 0 && y > 0 && m.a[aLo+x-1] == m.b[bLo+y-1] {
+				x--
+				y--
+			}
+			vb[off+c-delta] = x
+
+			if !odd && c >= -d && c <= d && vb[off+c-delta] <= vf[off+c] {
+				return aLo + x, bLo + y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// bestForward escolhe o ponto mais avançado do caminho direto (divisão aproximada)
+func (m *myers) bestForward(vf []int, off, d, aLo, bLo, n, mm int) (int, int, bool) {
+	bestX, bestY := -1, -1
+	for k := -d; k <= d; k += 2 {
+		x := min(vf[off+k], n)
+		y := x - k
+		if y < 0 || y > mm {
+			continue
+		}
+		if x+y > bestX+bestY {
+			bestX, bestY = x, y
+		}
+	}
+	if bestX+bestY <= 0 || (bestX == n && bestY == mm) {
+		return 0, 0, false
+	}
+	return aLo + bestX, bLo + bestY, true
+}
+
+// Hunks agrupa as alterações em blocos com as linhas de contexto em volta
+func Hunks(lines []DiffLine, context int) []DiffHunk {
+	var hunks []DiffHunk
+
+	for i := 0; i < len(lines); {
+		if lines[i].Op == DiffEqual {
+			i++
+			continue
+		}
+
+		// Início do bloco com o contexto anterior
+		start := max(i-context, 0)
+
+		// Estender enquanto a próxima alteração estiver a até 2*context linhas
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Op != DiffEqual {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := min(end+context+1, len(lines))
+
+		hunks = append(hunks, newHunk(lines, start, stop))
+		i = stop
+	}
+	return hunks
+}
+
+// newHunk calcula o cabeçalho (posições e tamanhos) do bloco lines[start:stop]
+func newHunk(lines []DiffLine, start, stop int) DiffHunk {
+	h := DiffHunk{Lines: lines[start:stop]}
+	for _, line := range h.Lines {
+		if line.Op != DiffInsert {
+			if h.OldStart == 0 {
+				h.OldStart = line.OldLine
+			}
+			h.OldLines++
+		}
+		if line.Op != DiffDelete {
+			if h.NewStart == 0 {
+				h.NewStart = line.NewLine
+			}
+			h.NewLines++
+		}
+	}
+
+	// Um lado vazio é indicado pela última linha antes do bloco (0 no início do arquivo)
+	for i := start - 1; i >= 0 && (h.OldLines == 0 && h.OldStart == 0 || h.NewLines == 0 && h.NewStart == 0); i-- {
+		if h.OldLines == 0 && h.OldStart == 0 && lines[i].OldLine > 0 {
+			h.OldStart = lines[i].OldLine
+		}
+		if h.NewLines == 0 && h.NewStart == 0 && lines[i].NewLine > 0 {
+			h.NewStart = lines[i].NewLine
+		}
+	}
+	return h
+}
+
+// Unified retorna a comparação no formato diff unificado (diff -u)
+func (d *FileDiff) Unified() string {
+	if d.Identical() {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", d.OldName, d.NewName)
+
+	lastOld, lastNew := d.lastLines()
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+		for _, line := range h.Lines {
+			switch line.Op {
+			case DiffEqual:
+				sb.WriteString(" " + line.Old + "\n")
+			case DiffDelete:
+				sb.WriteString("-" + line.Old + "\n")
+			case DiffInsert:
+				sb.WriteString("+" + line.New + "\n")
+			}
+
+			// Marcar a última linha do arquivo sem quebra de linha final
+			if line.Op != DiffInsert && d.OldNoEOL && line.OldLine == lastOld ||
+				line.Op != DiffDelete && d.NewNoEOL && line.NewLine == lastNew {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// lastLines retorna o número da última linha de cada arquivo
+func (d *FileDiff) lastLines() (int, int) {
+	var lastOld, lastNew int
+	for _, line := range d.Lines {
+		lastOld = max(lastOld, line.OldLine)
+		lastNew = max(lastNew, line.NewLine)
+	}
+	return lastOld, lastNew
+}
+
+// hunkRange formata a posição de um lado no cabeçalho @@ (",1" é omitido como no GNU diff)
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// IntraLineDiff compara duas versões de uma linha caractere a caractere e retorna os
+// trechos alterados de cada uma como intervalos [início, fim) de índices de runas
+func IntraLineDiff(oldLine, newLine string) (oldSpans, newSpans [][2]int) {
+	a, b := []rune(oldLine), []rune(newLine)
+	seqA, seqB := make([]int, len(a)), make([]int, len(b))
+	for i, r := range a {
+		seqA[i] = int(r)
+	}
+	for i, r := range b {
+		seqB[i] = int(r)
+	}
+
+	m := &myers{a: seqA, b: seqB, del: make([]bool, len(a)), ins: make([]bool, len(b))}
+	m.compare(0, len(a), 0, len(b))
+	return markedSpans(m.del), markedSpans(m.ins)
+}
+
+// markedSpans converte as marcações em intervalos contíguos
+func markedSpans(marks []bool) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(marks); i++ {
+		if !marks[i] {
+			continue
+		}
+		start := i
+		for i < len(marks) && marks[i] {
+			i++
+		}
+		spans = append(spans, [2]int{start, i})
+	}
+	return spans
+}
@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CompareStatus é a classificação de um item na comparação de diretórios
+type CompareStatus int
+
+const (
+	CompareIdentical CompareStatus = iota
+	CompareContentDiffers
+	CompareNewerLeft
+	CompareNewerRight
+	CompareTypeMismatch
+	CompareOrphanLeft
+	CompareOrphanRight
+)
+
+// String retorna a descrição da classificação
+func (s CompareStatus) String() string {
+	switch s {
+	case CompareIdentical:
+		return "Idêntico"
+	case CompareContentDiffers:
+		return "Conteúdo diferente"
+	case CompareNewerLeft:
+		return "Mais novo à esquerda"
+	case CompareNewerRight:
+		return "Mais novo à direita"
+	case CompareTypeMismatch:
+		return "Tipos diferentes"
+	case CompareOrphanLeft:
+		return "Só à esquerda"
+	case CompareOrphanRight:
+		return "Só à direita"
+	}
+	return "Desconhecido"
+}
+
+// defaultTimeTolerance absorve a resolução de 2 segundos das datas em FAT/zip
+const defaultTimeTolerance = 2 * time.Second
+
+// DirCompareOptions define como os diretórios são comparados
+type DirCompareOptions struct {
+	// ByteCompare compara o conteúdo byte a byte em vez de calcular o hash de cada lado
+	ByteCompare   bool
+	IncludeHidden bool
+	// TimeTolerance é a diferença de data ignorada (0 = 2 segundos)
+	TimeTolerance time.Duration
+}
+
+// DirCompareEntry é um item da comparação. Left/Right são nil no lado em que o item não existe.
+// Diretórios que existem só de um lado aparecem como um único item.
+type DirCompareEntry struct {
+	RelPath string
+	Status  CompareStatus
+	Left    os.FileInfo
+	Right   os.FileInfo
+}
+
+// IsDir indica se o item é um diretório (em algum dos lados)
+func (e DirCompareEntry) IsDir() bool {
+	return e.Left != nil && e.Left.IsDir() || e.Right != nil && e.Right.IsDir()
+}
+
+// CompareDirectoryTrees compara recursivamente dois diretórios (de qualquer sistema
+// de arquivos). Arquivos de mesmo tamanho são comparados pelo conteúdo; datas só
+// decidem qual lado é mais novo quando o conteúdo difere.
+func CompareDirectoryTrees(jc *JobContext, left, right string, opts DirCompareOptions) ([]DirCompareEntry, error) {
+	if opts.TimeTolerance == 0 {
+		opts.TimeTolerance = defaultTimeTolerance
+	}
+
+	c := &dirComparer{
+		jc:      jc,
+		left:    left,
+		right:   right,
+		leftFS:  FSFor(left),
+		rightFS: FSFor(right),
+		opts:    opts,
+	}
+	if err := c.compareDir(""); err != nil {
+		return c.entries, err
+	}
+	return c.entries, nil
+}
+
+// dirComparer guarda o estado de uma comparação de diretórios
+type dirComparer struct {
+	jc              *JobContext
+	left, right     string
+	leftFS, rightFS VFS
+	opts            DirCompareOptions
+	entries         []DirCompareEntry
+}
+
+// compareDir compara o conteúdo do subdiretório rel nos dois lados
+func (c *dirComparer) compareDir(rel string) error {
+	leftInfos, err := c.leftFS.List(filepath.Join(c.left, rel))
+	if err != nil {
+		return c.jc.Fail(filepath.Join(c.left, rel), err)
+	}
+	rightInfos, err := c.rightFS.List(filepath.Join(c.right, rel))
+	if err != nil {
+		return c.jc.Fail(filepath.Join(c.right, rel), err)
+	}
+
+	// As listas estão ordenadas pelo nome: percorrer as duas ao mesmo tempo
+	i, j := 0, 0
+	for i < len(leftInfos) || j < len(rightInfos) {
+		if err := c.jc.Checkpoint(); err != nil {
+			return err
+		}
+
+		var l, r os.FileInfo
+		switch {
+		case j >= len(rightInfos) || i < len(leftInfos) && leftInfos[i].Name() < rightInfos[j].Name():
+			l = leftInfos[i]
+			i++
+		case i >= len(leftInfos) || rightInfos[j].Name() < leftInfos[i].Name():
+			r = rightInfos[j]
+			j++
+		default:
+			l, r = leftInfos[i], rightInfos[j]
+			i++
+			j++
+		}
+
+		name := r
+		if l != nil {
+			name = l
+		}
+		if !c.opts.IncludeHidden && isHidden(name.Name()) {
+			continue
+		}
+
+		if err := c.compareEntry(filepath.Join(rel, name.Name()), l, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareEntry classifica um item presente em pelo menos um dos lados
+func (c *dirComparer) compareEntry(rel string, l, r os.FileInfo) error {
+	entry := DirCompareEntry{RelPath: rel, Left: l, Right: r}
+
+	switch {
+	case r == nil:
+		entry.Status = CompareOrphanLeft
+	case l == nil:
+		entry.Status = CompareOrphanRight
+	case l.IsDir() && r.IsDir():
+		return c.compareDir(rel)
+	case l.IsDir() != r.IsDir() || l.Mode().Type() != r.Mode().Type():
+		entry.Status = CompareTypeMismatch
+	default:
+		leftPath, rightPath := filepath.Join(c.left, rel), filepath.Join(c.right, rel)
+		c.jc.SetCurrent(leftPath)
+		c.jc.AddTotal(1, l.Size()+r.Size())
+
+		same := l.Size() == r.Size()
+		if same {
+			var err error
+			if c.opts.ByteCompare {
+				same, err = sameContentFS(c.jc, c.leftFS, leftPath, c.rightFS, rightPath)
+			} else {
+				same, err = sameHashFS(c.jc, c.leftFS, leftPath, c.rightFS, rightPath)
+			}
+			if err != nil {
+				c.jc.FileDone()
+				return c.jc.Fail(leftPath, err)
+			}
+		} else {
+			c.jc.AddBytes(l.Size() + r.Size())
+		}
+		c.jc.FileDone()
+
+		diff := l.ModTime().Sub(r.ModTime())
+		switch {
+		case same:
+			entry.Status = CompareIdentical
+		case diff > c.opts.TimeTolerance:
+			entry.Status = CompareNewerLeft
+		case diff < -c.opts.TimeTolerance:
+			entry.Status = CompareNewerRight
+		default:
+			entry.Status = CompareContentDiffers
+		}
+	}
+
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+// HashFileFS calcula o SHA-256 de um arquivo reportando o progresso
+func HashFileFS(jc *JobContext, fsys VFS, name string) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	buf := make([]byte, jobBufferSize)
+	if _, err := io.CopyBuffer(&progressWriter{w: hash, jc: jc}, file, buf); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// sameHashFS compara dois arquivos pelo hash do conteúdo
+func sameHashFS(jc *JobContext, fsA VFS, a string, fsB VFS, b string) (bool, error) {
+	hashA, err := HashFileFS(jc, fsA, a)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := HashFileFS(jc, fsB, b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(hashA, hashB), nil
+}
+
+// sameContentFS compara dois arquivos byte a byte, parando na primeira diferença
+func sameContentFS(jc *JobContext, fsA VFS, a string, fsB VFS, b string) (bool, error) {
+	fileA, err := fsA.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+
+	fileB, err := fsB.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA, bufB := make([]byte, jobBufferSize), make([]byte, jobBufferSize)
+	for {
+		if err := jc.Checkpoint(); err != nil {
+			return false, err
+		}
+
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		jc.AddBytes(int64(nA + nB))
+
+		if nA != nB || !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil && errB != io.EOF && errB != io.ErrUnexpectedEOF {
+			return false, errB
+		}
+	}
+}
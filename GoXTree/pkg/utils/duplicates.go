@@ -0,0 +1,376 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// duplicateBlockSize é o tamanho dos blocos do início e do fim usados na triagem rápida
+const duplicateBlockSize = 16 * 1024
+
+// KeepPolicy define qual cópia de um grupo de duplicados é preservada
+type KeepPolicy int
+
+const (
+	KeepNewest KeepPolicy = iota
+	KeepOldest
+)
+
+// DedupAction define o que é feito com as cópias não preservadas
+type DedupAction int
+
+const (
+	// DedupDelete exclui as cópias
+	DedupDelete DedupAction = iota
+	// DedupHardlink substitui as cópias por links físicos para o arquivo preservado
+	DedupHardlink
+	// DedupSymlink substitui as cópias por links simbólicos para o arquivo preservado
+	DedupSymlink
+)
+
+// String retorna a descrição da ação
+func (a DedupAction) String() string {
+	switch a {
+	case DedupHardlink:
+		return "Substituir por link físico"
+	case DedupSymlink:
+		return "Substituir por link simbólico"
+	}
+	return "Excluir"
+}
+
+// DuplicateOptions define como os duplicados são procurados
+type DuplicateOptions struct {
+	Recursive     bool
+	IncludeHidden bool
+	// MinSize ignora arquivos menores (arquivos vazios são sempre ignorados)
+	MinSize int64
+	// Workers é o número de arquivos lidos em paralelo (0 = número de CPUs)
+	Workers int
+}
+
+// DuplicateGroup é um conjunto de arquivos com o mesmo conteúdo
+type DuplicateGroup struct {
+	Size  int64
+	Hash  string // SHA-256 do conteúdo, em hexadecimal
+	Files []FileInfo
+}
+
+// Wasted retorna o espaço ocupado pelas cópias excedentes
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// Keeper retorna o índice do arquivo preservado pela política informada
+func (g DuplicateGroup) Keeper(policy KeepPolicy) int {
+	keep := 0
+	for i, file := range g.Files {
+		switch {
+		case policy == KeepNewest && file.ModTime.After(g.Files[keep].ModTime):
+			keep = i
+		case policy == KeepOldest && file.ModTime.Before(g.Files[keep].ModTime):
+			keep = i
+		}
+	}
+	return keep
+}
+
+// dupCandidate é um arquivo em análise; info guarda os dados do sistema para
+// reconhecer links físicos que já apontam para o mesmo conteúdo
+type dupCandidate struct {
+	file FileInfo
+	info os.FileInfo
+	hash string
+}
+
+// FindDuplicateFiles encontra arquivos com conteúdo idêntico em um diretório
+func FindDuplicateFiles(rootDir string, recursive bool) ([]DuplicateGroup, error) {
+	return FindDuplicatesJob(nil, rootDir, DuplicateOptions{Recursive: recursive, IncludeHidden: true})
+}
+
+// FindDuplicatesJob encontra arquivos duplicados em etapas, cada uma lendo apenas
+// os arquivos que sobraram da anterior: agrupa pelo tamanho, compara o hash do
+// primeiro e do último bloco e, por fim, o hash do conteúdo completo.
+// Os grupos são ordenados pelo espaço desperdiçado.
+func FindDuplicatesJob(jc *JobContext, rootDir string, opts DuplicateOptions) ([]DuplicateGroup, error) {
+	fsys := FSFor(rootDir)
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Etapa 1: agrupar pelo tamanho
+	bySize := make(map[int64][]*dupCandidate)
+	err := WalkFS(fsys, rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			jc.Fail(path, err)
+			return nil
+		}
+		if err := jc.Checkpoint(); err != nil {
+			return err
+		}
+
+		if path != rootDir && !opts.IncludeHidden && isHidden(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if path != rootDir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() == 0 || info.Size() < opts.MinSize {
+			return nil
+		}
+
+		// Links físicos para o mesmo arquivo não são duplicados
+		for _, other := range bySize[info.Size()] {
+			if os.SameFile(other.info, info) {
+				return nil
+			}
+		}
+
+		name := info.Name()
+		bySize[info.Size()] = append(bySize[info.Size()], &dupCandidate{
+			file: FileInfo{
+				Name:      name,
+				Path:      path,
+				Size:      info.Size(),
+				ModTime:   info.ModTime(),
+				IsHidden:  strings.HasPrefix(name, "."),
+				Extension: strings.ToLower(filepath.Ext(name)),
+			},
+			info: info,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates [][]*dupCandidate
+	for _, group := range bySize {
+		if len(group) > 1 {
+			candidates = append(candidates, group)
+			jc.AddTotal(len(group), 0)
+		}
+	}
+
+	// Etapa 2: primeiro e último bloco
+	candidates, err = refineDuplicates(jc, candidates, workers, func(c *dupCandidate) (string, error) {
+		return hashEdgesFS(fsys, c.file.Path, c.file.Size)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Etapa 3: conteúdo completo (desnecessária quando os blocos já cobrem o arquivo)
+	var full [][]*dupCandidate
+	var groups []DuplicateGroup
+	for _, group := range candidates {
+		if group[0].file.Size > 2*duplicateBlockSize {
+			full = append(full, group)
+			for _, c := range group {
+				jc.AddTotal(0, c.file.Size)
+			}
+			continue
+		}
+		groups = append(groups, newDuplicateGroup(group))
+		for range group {
+			jc.FileDone()
+		}
+	}
+
+	full, err = refineDuplicates(jc, full, workers, func(c *dupCandidate) (string, error) {
+		hash, err := HashFileFS(jc, fsys, c.file.Path)
+		jc.FileDone()
+		return hex.EncodeToString(hash), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range full {
+		groups = append(groups, newDuplicateGroup(group))
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted() != groups[j].Wasted() {
+			return groups[i].Wasted() > groups[j].Wasted()
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups, nil
+}
+
+// newDuplicateGroup monta o resultado de um grupo, com os arquivos ordenados pelo caminho
+func newDuplicateGroup(group []*dupCandidate) DuplicateGroup {
+	g := DuplicateGroup{Size: group[0].file.Size, Hash: group[0].hash}
+	for _, c := range group {
+		g.Files = append(g.Files, c.file)
+	}
+	sort.Slice(g.Files, func(i, j int) bool { return g.Files[i].Path < g.Files[j].Path })
+	return g
+}
+
+// refineDuplicates calcula o hash de todos os candidatos com um grupo de workers e
+// divide cada grupo pelos hashes obtidos, descartando os que ficaram sozinhos.
+// Arquivos que não puderam ser lidos são registrados como falha e descartados.
+func refineDuplicates(jc *JobContext, groups [][]*dupCandidate, workers int, hash func(c *dupCandidate) (string, error)) ([][]*dupCandidate, error) {
+	queue := make(chan *dupCandidate)
+	failed := make(map[*dupCandidate]bool)
+	var mu sync.Mutex
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range queue {
+				if jc.Checkpoint() != nil {
+					continue
+				}
+				jc.SetCurrent(c.file.Path)
+
+				sum, err := hash(c)
+				mu.Lock()
+				if err != nil {
+					failed[c] = true
+					if err := jc.Fail(c.file.Path, err); err != nil && firstErr == nil {
+						firstErr = err
+					}
+				}
+				c.hash = sum
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, group := range groups {
+		for _, c := range group {
+			queue <- c
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := jc.Checkpoint(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var refined [][]*dupCandidate
+	for _, group := range groups {
+		byHash := make(map[string][]*dupCandidate)
+		var order []string
+		for _, c := range group {
+			if failed[c] {
+				continue
+			}
+			if _, ok := byHash[c.hash]; !ok {
+				order = append(order, c.hash)
+			}
+			byHash[c.hash] = append(byHash[c.hash], c)
+		}
+		for _, h := range order {
+			if len(byHash[h]) > 1 {
+				refined = append(refined, byHash[h])
+			}
+		}
+	}
+	return refined, nil
+}
+
+// hashEdgesFS calcula o SHA-256 do primeiro e do último bloco de um arquivo
+// (ou do arquivo inteiro, quando ele cabe nos dois blocos)
+func hashEdgesFS(fsys VFS, name string, size int64) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if size <= 2*duplicateBlockSize {
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	if _, err := io.CopyN(hash, file, duplicateBlockSize); err != nil {
+		return "", err
+	}
+	skip := size - 2*duplicateBlockSize
+	if seeker, ok := file.(io.Seeker); ok {
+		_, err = seeker.Seek(skip, io.SeekCurrent)
+	} else {
+		_, err = io.CopyN(io.Discard, file, skip)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.CopyN(hash, file, duplicateBlockSize); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DedupFile elimina a cópia duplicate de keep: remove é chamada para excluí-la
+// (permitindo enviá-la para a lixeira) e, conforme a ação, um link para keep é
+// criado em seu lugar. O link é criado antes da exclusão, de modo que uma falha
+// (ex.: link físico entre sistemas de arquivos diferentes) não remove nada.
+func DedupFile(keep, duplicate FileInfo, action DedupAction, remove func(path string) error) error {
+	// Não agir sobre arquivos alterados desde a busca
+	for _, file := range []FileInfo{keep, duplicate} {
+		info, err := LstatFS(FSFor(file.Path), file.Path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || info.Size() != file.Size || !info.ModTime().Equal(file.ModTime) {
+			return fmt.Errorf("'%s' foi alterado desde a busca", file.Path)
+		}
+	}
+
+	if action == DedupDelete {
+		return remove(duplicate.Path)
+	}
+	if !IsLocalPath(keep.Path) || !IsLocalPath(duplicate.Path) {
+		return fmt.Errorf("links só podem ser criados no disco local")
+	}
+
+	// Criar o link com um nome temporário ao lado da cópia
+	temp := filepath.Join(filepath.Dir(duplicate.Path), fmt.Sprintf(".%s.gxtree-link", duplicate.Name))
+	var err error
+	if action == DedupHardlink {
+		err = os.Link(keep.Path, temp)
+	} else {
+		target, absErr := filepath.Abs(keep.Path)
+		if absErr != nil {
+			return absErr
+		}
+		err = os.Symlink(target, temp)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao criar o link: %v", err)
+	}
+
+	if err := remove(duplicate.Path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return os.Rename(temp, duplicate.Path)
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// exifReadLimit é quanto do início do arquivo é lido à procura dos dados EXIF
+const exifReadLimit = 256 * 1024
+
+// Marcas EXIF usadas
+const (
+	exifTagDateTime         = 0x0132
+	exifTagExifIFD          = 0x8769
+	exifTagDateTimeOriginal = 0x9003
+)
+
+// errNoEXIFDate indica que o arquivo não tem data EXIF
+var errNoEXIFDate = errors.New("data EXIF não encontrada")
+
+// ReadEXIFDate retorna a data em que a foto foi tirada (DateTimeOriginal ou, na
+// falta dela, DateTime) de arquivos JPEG e TIFF
+func ReadEXIFDate(fsys VFS, name string) (time.Time, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, exifReadLimit))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	tiff, err := findTIFFHeader(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseEXIFDate(tiff)
+}
+
+// findTIFFHeader localiza o bloco TIFF com os dados EXIF: o próprio arquivo (TIFF
+// e formatos RAW derivados) ou o segmento APP1 de um JPEG
+func findTIFFHeader(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")) {
+		return data, nil
+	}
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errNoEXIFDate
+	}
+
+	// Percorrer os segmentos do JPEG até o início da imagem
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || length < 2 || pos+2+length > len(data) {
+			break
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+		pos += 2 + length
+	}
+	return nil, errNoEXIFDate
+}
+
+// parseEXIFDate lê as datas do IFD0 e do IFD EXIF
+func parseEXIFDate(tiff []byte) (time.Time, error) {
+	if len(tiff) < 8 {
+		return time.Time{}, errNoEXIFDate
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if tiff[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	// readIFD retorna o valor das marcas ASCII e ponteiros de um diretório
+	readIFD := func(offset uint32) map[uint16]uint32 {
+		entries := make(map[uint16]uint32)
+		if int(offset)+2 > len(tiff) {
+			return entries
+		}
+		count := int(order.Uint16(tiff[offset:]))
+		for i := 0; i < count; i++ {
+			entry := int(offset) + 2 + i*12
+			if entry+12 > len(tiff) {
+				break
+			}
+			entries[order.Uint16(tiff[entry:])] = order.Uint32(tiff[entry+8:])
+		}
+		return entries
+	}
+
+	// dateAt lê uma data no formato "2006:01:02 15:04:05"
+	dateAt := func(offset uint32) (time.Time, bool) {
+		if int(offset)+19 > len(tiff) {
+			return time.Time{}, false
+		}
+		text := strings.TrimRight(string(tiff[offset:offset+19]), "\x00 ")
+		date, err := time.ParseInLocation("2006:01:02 15:04:05", text, time.Local)
+		return date, err == nil
+	}
+
+	ifd0 := readIFD(order.Uint32(tiff[4:]))
+	if exifOffset, ok := ifd0[exifTagExifIFD]; ok {
+		if offset, ok := readIFD(exifOffset)[exifTagDateTimeOriginal]; ok {
+			if date, ok := dateAt(offset); ok {
+				return date, nil
+			}
+		}
+	}
+	if offset, ok := ifd0[exifTagDateTime]; ok {
+		if date, ok := dateAt(offset); ok {
+			return date, nil
+		}
+	}
+	return time.Time{}, errNoEXIFDate
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// DefaultIndexLimit é o número máximo de itens indexados quando IndexOptions.MaxEntries é zero
+const DefaultIndexLimit = 200000
+
+// errIndexStopped interrompe o percurso quando o índice é descartado
+var errIndexStopped = errors.New("indexação interrompida")
+
+// IndexOptions controla o que entra no índice de arquivos
+type IndexOptions struct {
+	IncludeHidden bool // Incluir arquivos e diretórios ocultos
+	NoIgnore      bool // Não respeitar .gitignore/.ignore
+	MaxEntries    int  // Limite de itens (0 = DefaultIndexLimit)
+}
+
+// IndexEntry é um item do índice, com o caminho relativo à raiz
+type IndexEntry struct {
+	Path  string
+	IsDir bool
+}
+
+// IndexMatch é um resultado de FileIndex.Query
+type IndexMatch struct {
+	IndexEntry
+	Score     int
+	Positions []int // Posições (em runas) dos caracteres encontrados em Path
+}
+
+// FileIndex é a lista dos arquivos e diretórios abaixo de uma raiz, preenchida em
+// segundo plano. Pode ser consultada enquanto a indexação ainda está em andamento.
+type FileIndex struct {
+	Root string
+
+	mu        sync.RWMutex
+	entries   []IndexEntry
+	truncated bool
+	err       error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	finished chan struct{}
+}
+
+// NewFileIndex inicia a indexação de root em segundo plano
+func NewFileIndex(root string, opts IndexOptions) *FileIndex {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultIndexLimit
+	}
+	ix := &FileIndex{
+		Root:     root,
+		stop:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go ix.build(opts)
+	return ix
+}
+
+// build percorre a árvore acrescentando os itens ao índice
+func (ix *FileIndex) build(opts IndexOptions) {
+	defer close(ix.finished)
+
+	fsys := FSFor(ix.Root)
+	var ignore *IgnoreMatcher
+	if !opts.NoIgnore {
+		ignore = NewIgnoreMatcher(fsys)
+	}
+
+	// Acrescentar em lotes para não disputar a trava a cada item
+	var batch []IndexEntry
+	flush := func() {
+		ix.mu.Lock()
+		ix.entries = append(ix.entries, batch...)
+		ix.mu.Unlock()
+		batch = batch[:0]
+	}
+	count := 0
+
+	err := WalkFS(fsys, ix.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == ix.Root {
+				return err
+			}
+			return nil // Ignorar itens inacessíveis e continuar
+		}
+		select {
+		case <-ix.stop:
+			return errIndexStopped
+		default:
+		}
+
+		if path == ix.Root {
+			if ignore != nil {
+				ignore.Enter(path)
+			}
+			return nil
+		}
+		if !opts.IncludeHidden && isHidden(path) || ignore != nil && ignore.Ignored(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() && ignore != nil {
+			ignore.Enter(path)
+		}
+
+		if count >= opts.MaxEntries {
+			ix.mu.Lock()
+			ix.truncated = true
+			ix.mu.Unlock()
+			return filepath.SkipAll
+		}
+		rel, err := filepath.Rel(ix.Root, path)
+		if err != nil {
+			return nil
+		}
+		batch = append(batch, IndexEntry{Path: rel, IsDir: info.IsDir()})
+		count++
+		if len(batch) >= 1000 {
+			flush()
+		}
+		return nil
+	})
+	flush()
+
+	if err != nil && err != errIndexStopped {
+		ix.mu.Lock()
+		ix.err = err
+		ix.mu.Unlock()
+	}
+}
+
+// Stop interrompe a indexação (o que já foi indexado continua disponível)
+func (ix *FileIndex) Stop() {
+	ix.stopOnce.Do(func() { close(ix.stop) })
+}
+
+// Wait aguarda o fim da indexação e retorna o erro, se houver
+func (ix *FileIndex) Wait() error {
+	<-ix.finished
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.err
+}
+
+// Done indica se a indexação terminou
+func (ix *FileIndex) Done() bool {
+	select {
+	case <-ix.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status retorna quantos itens já foram indexados e se o limite foi atingido
+func (ix *FileIndex) Status() (count int, truncated bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries), ix.truncated
+}
+
+// Query retorna os itens que correspondem ao padrão (busca aproximada), do mais
+// para o menos relevante; empates favorecem caminhos mais curtos. Sem padrão, os
+// itens vêm na ordem da indexação. limit <= 0 retorna todos.
+func (ix *FileIndex) Query(pattern string, limit int) []IndexMatch {
+	// Os itens só são acrescentados, então basta copiar a fatia
+	ix.mu.RLock()
+	entries := ix.entries
+	ix.mu.RUnlock()
+
+	var matches []IndexMatch
+	for _, entry := range entries {
+		score, positions, ok := FuzzyMatch(pattern, entry.Path)
+		if !ok {
+			continue
+		}
+		matches = append(matches, IndexMatch{IndexEntry: entry, Score: score, Positions: positions})
+		if pattern == "" && limit > 0 && len(matches) >= limit {
+			return matches
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return len(matches[i].Path) < len(matches[j].Path)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filespec é um filtro de listagem no estilo do XTree: termos separados por ";"
+// (ex.: "*.go;*.prw;!*_test.go;size>1MB;modified<7d"). Os termos são:
+//
+//   - padrões (*.go): o arquivo deve corresponder a pelo menos um;
+//   - exclusões (!*_test.go): o item não pode corresponder a nenhuma;
+//   - atributos (size>1MB, modified<7d, type=dir): todos devem valer.
+//
+// Os padrões, o tamanho e a data só se aplicam a arquivos, para que os
+// diretórios continuem visíveis e navegáveis; diretórios só são filtrados por
+// exclusões e por type. Nomes são comparados sem diferenciar maiúsculas.
+type Filespec struct {
+	spec     string
+	includes []string
+	excludes []string
+	attrs    []filespecAttr
+}
+
+// filespecAttr é um termo de atributo já interpretado
+type filespecAttr struct {
+	name  string // size, modified ou type
+	op    string // =, !=, <, <=, > ou >=
+	size  int64
+	age   time.Duration // modified com idade (7d): "<" significa mais recente
+	date  time.Time     // modified com data (2024-01-31, início do dia): "<" significa mais antigo
+	types []string      // dir, file, link
+}
+
+// filespecAttrRe separa nome, operador e valor de um termo de atributo
+var filespecAttrRe = regexp.MustCompile(`(?i)^(size|modified|type)\s*(<=|>=|!=|=|<|>)\s*(.+)$`)
+
+// sizeUnits são os multiplicadores aceitos em size (base 1024, como na listagem)
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40,
+}
+
+// ageUnits são as unidades aceitas em modified (h, d, w e y)
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// ParseFilespec interpreta um filtro; vazio retorna nil (sem filtro)
+func ParseFilespec(spec string) (*Filespec, error) {
+	f := &Filespec{}
+	var terms []string
+	for _, term := range strings.Split(spec, ";") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		terms = append(terms, term)
+
+		if m := filespecAttrRe.FindStringSubmatch(term); m != nil {
+			attr, err := parseFilespecAttr(strings.ToLower(m[1]), m[2], strings.TrimSpace(m[3]))
+			if err != nil {
+				return nil, fmt.Errorf("filtro inválido: %s: %w", term, err)
+			}
+			f.attrs = append(f.attrs, attr)
+			continue
+		}
+
+		pattern, exclude := strings.CutPrefix(term, "!")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("filtro inválido: %s", term)
+		}
+		if exclude {
+			f.excludes = append(f.excludes, pattern)
+		} else {
+			f.includes = append(f.includes, pattern)
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	f.spec = strings.Join(terms, ";")
+	return f, nil
+}
+
+// parseFilespecAttr interpreta o valor de um termo de atributo
+func parseFilespecAttr(name, op, value string) (filespecAttr, error) {
+	attr := filespecAttr{name: name, op: op}
+	value = strings.ToLower(value)
+
+	switch name {
+	case "size":
+		number := strings.TrimRight(value, "kmgtb ")
+		unit, ok := sizeUnits[strings.TrimSpace(value[len(number):])]
+		n, err := strconv.ParseFloat(number, 64)
+		if !ok || err != nil || n < 0 {
+			return attr, errors.New("tamanho inválido (ex.: 500K, 1MB, 2G)")
+		}
+		attr.size = int64(n * float64(unit))
+
+	case "modified":
+		if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+			attr.date = date
+			break
+		}
+		unit, ok := ageUnits[value[len(value)-1]]
+		n, err := strconv.ParseFloat(value[:len(value)-1], 64)
+		if !ok || err != nil || n < 0 {
+			return attr, errors.New("data inválida (ex.: 12h, 7d, 2w, 1y ou 2024-01-31)")
+		}
+		attr.age = time.Duration(n * float64(unit))
+
+	case "type":
+		if op != "=" && op != "!=" {
+			return attr, errors.New("type aceita apenas = e !=")
+		}
+		for _, t := range strings.Split(value, ",") {
+			switch strings.TrimSpace(t) {
+			case "dir", "d":
+				attr.types = append(attr.types, "dir")
+			case "file", "f":
+				attr.types = append(attr.types, "file")
+			case "link", "l":
+				attr.types = append(attr.types, "link")
+			default:
+				return attr, errors.New("tipo inválido (dir, file ou link)")
+			}
+		}
+	}
+	return attr, nil
+}
+
+// String retorna o filtro normalizado, como deve ser exibido
+func (f *Filespec) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.spec
+}
+
+// Match indica se o item passa pelo filtro. Um filtro nil aceita tudo.
+func (f *Filespec) Match(file FileInfo) bool {
+	if f == nil {
+		return true
+	}
+
+	name := strings.ToLower(filepath.Base(file.Name))
+	for _, pattern := range f.excludes {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return false
+		}
+	}
+
+	for _, attr := range f.attrs {
+		if !attr.match(file) {
+			return false
+		}
+	}
+
+	if file.IsDir || len(f.includes) == 0 {
+		return true
+	}
+	for _, pattern := range f.includes {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// match verifica um termo de atributo
+func (attr filespecAttr) match(file FileInfo) bool {
+	switch attr.name {
+	case "type":
+		kind := "file"
+		switch {
+		case file.Mode&os.ModeSymlink != 0:
+			kind = "link"
+		case file.IsDir:
+			kind = "dir"
+		}
+		found := false
+		for _, t := range attr.types {
+			found = found || t == kind
+		}
+		return found == (attr.op == "=")
+
+	case "size":
+		return file.IsDir || compareFilespec(file.Size, attr.size, attr.op)
+
+	case "modified":
+		if file.IsDir {
+			return true
+		}
+		if !attr.date.IsZero() {
+			// Datas valem o dia inteiro: "modified=2024-01-31" é qualquer hora
+			// desse dia e "modified<=2024-01-31" inclui o próprio dia
+			y, m, d := file.ModTime.In(time.Local).Date()
+			day := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+			return compareFilespec(day.Unix(), attr.date.Unix(), attr.op)
+		}
+		// Idade: "modified<7d" são os alterados há menos de 7 dias
+		return compareFilespec(int64(time.Since(file.ModTime)), int64(attr.age), attr.op)
+	}
+	return true
+}
+
+// compareFilespec aplica o operador de comparação
+func compareFilespec(a, b int64, op string) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// FilterFiles retorna os itens que passam pelo filtro
+func FilterFiles(files []FileInfo, f *Filespec) []FileInfo {
+	if f == nil {
+		return files
+	}
+	result := files[:0:0]
+	for _, file := range files {
+		if f.Match(file) {
+			result = append(result, file)
+		}
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+//go:build darwin
+
+package utils
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// systemStat preenche acesso, criação, inode e links pelo stat
+func systemStat(file *FileInfo, fsys VFS, info os.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	file.AccessTime = time.Unix(stat.Atimespec.Unix())
+	file.BirthTime = time.Unix(stat.Birthtimespec.Unix())
+	file.Inode = stat.Ino
+	file.Links = uint64(stat.Nlink)
+}
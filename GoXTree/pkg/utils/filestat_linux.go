@@ -0,0 +1,32 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// systemStat preenche acesso, inode e links pelo stat. A data de criação vem do
+// statx e só existe em sistemas de arquivos que a guardam (ext4, btrfs, xfs).
+func systemStat(file *FileInfo, fsys VFS, info os.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	file.AccessTime = time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec))
+	file.Inode = stat.Ino
+	file.Links = uint64(stat.Nlink)
+
+	if _, local := fsys.(LocalFS); !local {
+		return
+	}
+	var stx unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, file.Path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx)
+	if err == nil && stx.Mask&unix.STATX_BTIME != 0 {
+		file.BirthTime = time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package utils
+
+import "os"
+
+// systemStat não tem implementação neste sistema; os campos ficam zerados
+func systemStat(file *FileInfo, fsys VFS, info os.FileInfo) {}
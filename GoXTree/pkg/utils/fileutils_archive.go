@@ -1,134 +1,26 @@
 package utils
 
 import (
-	"archive/zip"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
-// CompressFile compacta um arquivo para um arquivo zip
+// CompressFile compacta um arquivo. O formato é escolhido pela extensão do destino
+// (.zip, .tar, .tar.gz, .tar.zst, .tar.xz); extensões desconhecidas geram um zip.
 func CompressFile(src, dst string) error {
-	// Abrir o arquivo de origem
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	// Obter informações do arquivo
-	srcInfo, err := srcFile.Stat()
-	if err != nil {
-		return err
-	}
-
-	// Criar o arquivo zip de destino
-	zipFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer zipFile.Close()
-
-	// Criar um novo escritor zip
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	// Criar um cabeçalho para o arquivo dentro do zip
-	header, err := zip.FileInfoHeader(srcInfo)
-	if err != nil {
-		return err
-	}
-
-	// Usar apenas o nome do arquivo, não o caminho completo
-	header.Name = filepath.Base(src)
-
-	// Usar compressão máxima
-	header.Method = zip.Deflate
-
-	// Criar um escritor para o arquivo dentro do zip
-	writer, err := zipWriter.CreateHeader(header)
-	if err != nil {
-		return err
-	}
-
-	// Copiar o conteúdo do arquivo para o zip
-	_, err = io.Copy(writer, srcFile)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return createArchive(nil, map[string]string{src: filepath.Base(src)}, dst, DefaultArchiveOptions(dst))
 }
 
-// CompressDirectory compacta um diretório para um arquivo zip
+// CompressDirectory compacta o conteúdo de um diretório (formato pela extensão do destino)
 func CompressDirectory(src, dst string) error {
-	// Criar o arquivo zip de destino
-	zipFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer zipFile.Close()
-
-	// Criar um novo escritor zip
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	// Percorrer o diretório recursivamente
-	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Criar um cabeçalho para o arquivo/diretório
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-
-		// Ajustar o nome para ser relativo ao diretório de origem
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		if relPath == "." {
-			return nil // Pular o diretório raiz
-		}
-		header.Name = relPath
-
-		// Ajustar o separador de caminho para o padrão do zip (sempre /)
-		header.Name = strings.ReplaceAll(header.Name, string(os.PathSeparator), "/")
-
-		// Adicionar barra no final para diretórios
-		if info.IsDir() {
-			header.Name += "/"
-			// Criar uma entrada para o diretório
-			_, err = zipWriter.CreateHeader(header)
-			return err
-		}
-
-		// Usar compressão máxima para arquivos
-		header.Method = zip.Deflate
-
-		// Criar um escritor para o arquivo dentro do zip
-		writer, err := zipWriter.CreateHeader(header)
-		if err != nil {
-			return err
-		}
-
-		// Se for um arquivo, copiar o conteúdo
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		_, err = io.Copy(writer, file)
-		return err
-	})
+	return CompressDirectoryJob(nil, src, dst)
+}
 
-	return err
+// CompressDirectoryJob compacta o conteúdo de um diretório reportando o progresso
+// (em caso de falha ou cancelamento o arquivo parcial é removido)
+func CompressDirectoryJob(jc *JobContext, src, dst string) error {
+	return createArchive(jc, map[string]string{src: ""}, dst, DefaultArchiveOptions(dst))
 }
 
 // FormatHexDump formata um array de bytes em um dump hexadecimal
@@ -4,66 +4,153 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/pkg/sftp"
 )
 
 // FileInfo representa informações de um arquivo
 type FileInfo struct {
-	Name      string
-	Path      string
-	Size      int64
-	ModTime   time.Time
-	IsDir     bool
-	IsHidden  bool
-	Extension string
+	Name       string
+	Path       string
+	Size       int64
+	ModTime    time.Time
+	IsDir      bool
+	IsHidden   bool
+	Extension  string
+	Mode       os.FileMode
+	Owner      string    // Nome (ou UID) do dono; vazio se desconhecido
+	Group      string    // Nome (ou GID) do grupo; vazio se desconhecido
+	LinkTarget string    // Destino, se for um link simbólico
+	AccessTime time.Time // Último acesso; zero se desconhecido
+	BirthTime  time.Time // Criação; zero se o sistema de arquivos não informa
+	Inode      uint64
+	Links      uint64        // Número de links físicos; zero se desconhecido
+	Git        GitFileStatus // Situação no git, preenchida pela listagem; zero fora de repositórios
 }
 
-// ListFiles lista arquivos em um diretório
+// ListFiles lista arquivos em um diretório local
 func ListFiles(dirPath string, showHidden bool) ([]FileInfo, error) {
+	return ListFilesFS(Local, dirPath, showHidden)
+}
+
+// ListFilesFS lista arquivos em um diretório de um sistema de arquivos
+func ListFilesFS(fsys VFS, dirPath string, showHidden bool) ([]FileInfo, error) {
 	var files []FileInfo
 
 	// Ler diretório
-	entries, err := os.ReadDir(dirPath)
+	entries, err := fsys.List(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
 	// Processar entradas
-	for _, entry := range entries {
-		// Verificar se é arquivo oculto
-		name := entry.Name()
-		isHidden := strings.HasPrefix(name, ".")
-
+	for _, info := range entries {
 		// Pular arquivos ocultos se não estiver mostrando
-		if isHidden && !showHidden {
+		name := info.Name()
+		if strings.HasPrefix(name, ".") && !showHidden {
 			continue
 		}
 
-		// Obter informações do arquivo
-		info, err := entry.Info()
+		files = append(files, NewFileInfo(fsys, filepath.Join(dirPath, name), info))
+	}
+
+	return files, nil
+}
+
+// NewFileInfo monta as informações de um item a partir do seu os.FileInfo
+func NewFileInfo(fsys VFS, path string, info os.FileInfo) FileInfo {
+	name := info.Name()
+	fileInfo := FileInfo{
+		Name:       name,
+		Path:       path,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		IsDir:      info.IsDir(),
+		IsHidden:   strings.HasPrefix(name, "."),
+		Extension:  strings.ToLower(filepath.Ext(name)),
+		Mode:       info.Mode(),
+		LinkTarget: LinkTarget(fsys, path, info),
+	}
+	fileInfo.Owner, fileInfo.Group = FileOwner(info)
+
+	// O SFTP informa só a data de acesso; o sistema local, também inode e links
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		fileInfo.AccessTime = time.Unix(int64(stat.Atime), 0)
+	} else {
+		systemStat(&fileInfo, fsys, info)
+	}
+	return fileInfo
+}
+
+// ListBranch lista todos os arquivos abaixo de um diretório (visão "branch" do XTree)
+func ListBranch(dirPath string, showHidden bool, limit int) ([]FileInfo, bool, error) {
+	return ListBranchFS(FSFor(dirPath), dirPath, showHidden, limit)
+}
+
+// ListBranchFS lista os arquivos de toda a árvore abaixo de root, sem os
+// diretórios. Name traz o caminho relativo a root (ex.: "src/main.go"). Com
+// limit > 0 a listagem para ao atingir o limite e truncated indica o corte.
+func ListBranchFS(fsys VFS, root string, showHidden bool, limit int) (files []FileInfo, truncated bool, err error) {
+	err = WalkFS(fsys, root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			continue
+			// Diretórios ilegíveis são ignorados; só a raiz interrompe a listagem
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if !showHidden && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if limit > 0 && len(files) >= limit {
+			truncated = true
+			return filepath.SkipAll
 		}
 
-		// Criar FileInfo
-		fileInfo := FileInfo{
-			Name:      name,
-			Path:      filepath.Join(dirPath, name),
-			Size:      info.Size(),
-			ModTime:   info.ModTime(),
-			IsDir:     entry.IsDir(),
-			IsHidden:  isHidden,
-			Extension: strings.ToLower(filepath.Ext(name)),
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			rel = info.Name()
 		}
+		file := NewFileInfo(fsys, path, info)
+		file.Name = rel
+		files = append(files, file)
+		return nil
+	})
+	return files, truncated, err
+}
 
-		files = append(files, fileInfo)
+// FileMIMEType retorna o tipo MIME pela extensão, sem ler o conteúdo (para listas)
+func FileMIMEType(file FileInfo) string {
+	switch {
+	case file.IsDir:
+		return "inode/directory"
+	case file.Mode&os.ModeSymlink != 0:
+		return "inode/symlink"
 	}
-
-	return files, nil
+	mimeType := mime.TypeByExtension(filepath.Ext(file.Name))
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	// Descartar parâmetros como "; charset=utf-8"
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	return mimeType
 }
 
 // GetDirectoryTree obtém a árvore de diretórios
@@ -142,7 +229,12 @@ func CountItems(dirPath string) (int, int, error) {
 
 // CopyFile copia um arquivo de origem para destino
 func CopyFile(src, dst string) error {
-	sourceFileStat, err := os.Stat(src)
+	return CopyFileFS(FSFor(src), src, FSFor(dst), dst)
+}
+
+// CopyFileFS copia um arquivo entre sistemas de arquivos (iguais ou não)
+func CopyFileFS(srcFS VFS, src string, dstFS VFS, dst string) error {
+	sourceFileStat, err := srcFS.Stat(src)
 	if err != nil {
 		return err
 	}
@@ -151,7 +243,7 @@ func CopyFile(src, dst string) error {
 		return fmt.Errorf("%s não é um arquivo regular", src)
 	}
 
-	source, err := os.Open(src)
+	source, err := srcFS.Open(src)
 	if err != nil {
 		return err
 	}
@@ -159,56 +251,58 @@ func CopyFile(src, dst string) error {
 
 	// Criar diretório de destino se não existir
 	dstDir := filepath.Dir(dst)
-	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+	if err := dstFS.MkdirAll(dstDir, os.ModePerm); err != nil {
 		return err
 	}
 
-	destination, err := os.Create(dst)
+	destination, err := dstFS.Create(dst)
 	if err != nil {
 		return err
 	}
-	defer destination.Close()
 
 	_, err = io.Copy(destination, source)
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return err
 	}
 
-	// Preservar permissões e data de modificação
-	return os.Chmod(dst, sourceFileStat.Mode())
+	// Preservar permissões
+	return chmodFS(dstFS, dst, sourceFileStat.Mode())
 }
 
 // CopyDirectory copia um diretório recursivamente
 func CopyDirectory(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+	return CopyDirectoryFS(FSFor(src), src, FSFor(dst), dst)
+}
+
+// CopyDirectoryFS copia um diretório recursivamente entre sistemas de arquivos
+func CopyDirectoryFS(srcFS VFS, src string, dstFS VFS, dst string) error {
+	srcInfo, err := srcFS.Stat(src)
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+	if err := dstFS.MkdirAll(dst, srcInfo.Mode().Perm()); err != nil {
 		return err
 	}
 
-	entries, err := os.ReadDir(src)
+	entries, err := srcFS.List(src)
 	if err != nil {
 		return err
 	}
 
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
+	for _, info := range entries {
+		srcPath := filepath.Join(src, info.Name())
+		dstPath := filepath.Join(dst, info.Name())
 
 		if info.IsDir() {
-			if err := CopyDirectory(srcPath, dstPath); err != nil {
+			if err := CopyDirectoryFS(srcFS, srcPath, dstFS, dstPath); err != nil {
 				return err
 			}
 		} else {
-			if err := CopyFile(srcPath, dstPath); err != nil {
+			if err := CopyFileFS(srcFS, srcPath, dstFS, dstPath); err != nil {
 				return err
 			}
 		}
@@ -219,35 +313,42 @@ func CopyDirectory(src, dst string) error {
 
 // MoveFile move um arquivo de origem para destino
 func MoveFile(src, dst string) error {
+	return MoveFileFS(FSFor(src), src, FSFor(dst), dst)
+}
+
+// MoveFileFS move um arquivo entre sistemas de arquivos
+func MoveFileFS(srcFS VFS, src string, dstFS VFS, dst string) error {
 	// Tentar renomear primeiro (mais eficiente, mas só funciona no mesmo sistema de arquivos)
-	err := os.Rename(src, dst)
-	if err == nil {
-		return nil
+	if srcFS == dstFS {
+		if err := srcFS.Rename(src, dst); err == nil {
+			return nil
+		}
 	}
 
 	// Se falhar, copiar e depois excluir
-	if err := CopyFile(src, dst); err != nil {
+	if err := CopyFileFS(srcFS, src, dstFS, dst); err != nil {
 		return err
 	}
-	return os.Remove(src)
+	return srcFS.Remove(src)
 }
 
 // DeleteFile exclui um arquivo ou diretório
 func DeleteFile(path string) error {
-	fileInfo, err := os.Stat(path)
+	fsys := FSFor(path)
+	fileInfo, err := fsys.Stat(path)
 	if err != nil {
 		return err
 	}
 
 	if fileInfo.IsDir() {
-		return os.RemoveAll(path)
+		return RemoveAllFS(fsys, path)
 	}
-	return os.Remove(path)
+	return fsys.Remove(path)
 }
 
 // CreateDirectory cria um diretório
 func CreateDirectory(path string) error {
-	return os.MkdirAll(path, os.ModePerm)
+	return FSFor(path).MkdirAll(path, os.ModePerm)
 }
 
 // GetFileType retorna o tipo de arquivo com base na extensão
@@ -374,8 +475,13 @@ func GetFileContent(path string, maxSize int64) ([]byte, error) {
 
 // IsTextFile verifica se um arquivo é de texto
 func IsTextFile(filePath string) (bool, error) {
+	return IsTextFileFS(FSFor(filePath), filePath)
+}
+
+// IsTextFileFS verifica se um arquivo de um sistema de arquivos é de texto
+func IsTextFileFS(fsys VFS, filePath string) (bool, error) {
 	// Abrir arquivo
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return false, err
 	}
@@ -383,8 +489,8 @@ func IsTextFile(filePath string) (bool, error) {
 
 	// Ler os primeiros 512 bytes
 	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return false, err
 	}
 
@@ -395,15 +501,6 @@ func IsTextFile(filePath string) (bool, error) {
 		}
 	}
 
-	// Verificar se o arquivo contém apenas caracteres ASCII
-	for i := 0; i < n; i++ {
-		if buffer[i] > 127 {
-			// Arquivo pode ser texto, mas não ASCII
-			// Vamos considerar como texto mesmo assim
-			return true, nil
-		}
-	}
-
 	return true, nil
 }
 
@@ -1,9 +1,7 @@
 package utils
 
 import (
-	"os"
 	"sort"
-	"strings"
 )
 
 // CompareDirectories compara dois diretórios e retorna arquivos únicos em cada um
@@ -67,99 +65,24 @@ func CompareDirectories(dir1, dir2 string) ([]FileInfo, []FileInfo, []FileInfo,
 	return uniqueInDir1, uniqueInDir2, common, nil
 }
 
-// CompareFiles compara dois arquivos e retorna as diferenças
+// CompareFiles compara dois arquivos e retorna as diferenças linha a linha,
+// prefixadas com "- " (removida), "+ " (inserida) ou "  " (igual)
 func CompareFiles(file1, file2 string) ([]string, error) {
-	// Ler o conteúdo dos arquivos
-	content1, err := os.ReadFile(file1)
+	diff, err := DiffFiles(file1, file2, DiffOptions{})
 	if err != nil {
 		return nil, err
 	}
 
-	content2, err := os.ReadFile(file2)
-	if err != nil {
-		return nil, err
-	}
-
-	// Dividir o conteúdo em linhas
-	lines1 := strings.Split(string(content1), "\n")
-	lines2 := strings.Split(string(content2), "\n")
-
-	// Encontrar a subsequência comum mais longa
-	lcs := longestCommonSubsequence(lines1, lines2)
-
-	// Gerar as diferenças
-	var diff []string
-
-	i, j := 0, 0
-	for k := 0; k < len(lcs); k++ {
-		for i < len(lines1) && lines1[i] != lcs[k] {
-			diff = append(diff, "- "+lines1[i])
-			i++
-		}
-		for j < len(lines2) && lines2[j] != lcs[k] {
-			diff = append(diff, "+ "+lines2[j])
-			j++
-		}
-		diff = append(diff, "  "+lcs[k])
-		i++
-		j++
-	}
-
-	// Adicionar linhas restantes
-	for i < len(lines1) {
-		diff = append(diff, "- "+lines1[i])
-		i++
-	}
-	for j < len(lines2) {
-		diff = append(diff, "+ "+lines2[j])
-		j++
-	}
-
-	return diff, nil
-}
-
-// longestCommonSubsequence encontra a subsequência comum mais longa entre duas slices
-func longestCommonSubsequence(a, b []string) []string {
-	// Criar a tabela de programação dinâmica
-	m, n := len(a), len(b)
-	dp := make([][]int, m+1)
-	for i := range dp {
-		dp[i] = make([]int, n+1)
-	}
-
-	// Preencher a tabela
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if a[i-1] == b[j-1] {
-				dp[i][j] = dp[i-1][j-1] + 1
-			} else {
-				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
-			}
+	result := make([]string, 0, len(diff.Lines))
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case DiffDelete:
+			result = append(result, "- "+line.Old)
+		case DiffInsert:
+			result = append(result, "+ "+line.New)
+		default:
+			result = append(result, "  "+line.Old)
 		}
 	}
-
-	// Reconstruir a subsequência
-	var lcs []string
-	i, j := m, n
-	for i > 0 && j > 0 {
-		if a[i-1] == b[j-1] {
-			lcs = append([]string{a[i-1]}, lcs...)
-			i--
-			j--
-		} else if dp[i-1][j] > dp[i][j-1] {
-			i--
-		} else {
-			j--
-		}
-	}
-
-	return lcs
-}
-
-// max retorna o maior de dois inteiros
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+	return result, nil
 }
@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -65,11 +64,9 @@ func CountFilesAndDirs(path string) (int, int, error) {
 	return numFiles, numDirs, err
 }
 
-// GetFilePermissionsString retorna uma string com as permissões do arquivo
+// GetFilePermissionsString retorna o tipo e as permissões do arquivo no formato do ls -l
 func GetFilePermissionsString(fileInfo os.FileInfo) string {
-	mode := fileInfo.Mode()
-	perms := fmt.Sprintf("%s", mode.Perm())
-	return perms
+	return FormatMode(fileInfo.Mode())
 }
 
 // compareBuffers compara dois buffers e retorna true se forem iguais
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// jobBufferSize é o tamanho do bloco usado nas cópias com progresso
+const jobBufferSize = 256 * 1024
+
+// JobContext é passado às funções de uma operação em segundo plano para reportar
+// progresso e atender pedidos de pausa e cancelamento.
+// Todos os métodos aceitam receptor nil, o que permite usar as mesmas rotinas
+// tanto em operações em segundo plano quanto em chamadas síncronas.
+type JobContext struct {
+	job *Job
+}
+
+// Checkpoint bloqueia enquanto a operação estiver pausada e retorna ErrJobCanceled
+// se ela tiver sido cancelada
+func (jc *JobContext) Checkpoint() error {
+	if jc == nil {
+		return nil
+	}
+
+	j := jc.job
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for j.paused && !j.canceled {
+		j.cond.Wait()
+	}
+	if j.canceled {
+		return ErrJobCanceled
+	}
+	return nil
+}
+
+// AddTotal acrescenta arquivos e bytes ao total esperado da operação
+func (jc *JobContext) AddTotal(files int, bytes int64) {
+	if jc == nil {
+		return
+	}
+	jc.update(func(s *JobSnapshot) {
+		s.TotalFiles += files
+		s.TotalBytes += bytes
+	})
+}
+
+// AddBytes registra bytes processados
+func (jc *JobContext) AddBytes(n int64) {
+	if jc == nil {
+		return
+	}
+	jc.update(func(s *JobSnapshot) {
+		s.DoneBytes += n
+	})
+}
+
+// SetCurrent informa o arquivo em processamento
+func (jc *JobContext) SetCurrent(path string) {
+	if jc == nil {
+		return
+	}
+	jc.update(func(s *JobSnapshot) {
+		s.CurrentFile = path
+	})
+}
+
+// FileDone registra um arquivo concluído
+func (jc *JobContext) FileDone() {
+	if jc == nil {
+		return
+	}
+	jc.update(func(s *JobSnapshot) {
+		s.DoneFiles++
+	})
+}
+
+// Fail registra a falha de um arquivo. Em uma operação em segundo plano o erro é
+// guardado e nil é retornado para que a operação continue com os demais arquivos;
+// sem contexto, o próprio erro é retornado.
+func (jc *JobContext) Fail(path string, err error) error {
+	if jc == nil || err == nil || errors.Is(err, ErrJobCanceled) {
+		return err
+	}
+	jc.update(func(s *JobSnapshot) {
+		s.Errors = append(s.Errors, JobError{Path: path, Err: err})
+	})
+	return nil
+}
+
+// Scan percorre os caminhos informados e soma seus arquivos e bytes ao total
+func (jc *JobContext) Scan(paths ...string) {
+	if jc == nil {
+		return
+	}
+
+	for _, root := range paths {
+		WalkFS(FSFor(root), root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !info.IsDir() {
+				jc.AddTotal(1, info.Size())
+			}
+			return jc.Checkpoint()
+		})
+	}
+}
+
+// update altera o estado da operação e notifica o gerenciador
+func (jc *JobContext) update(fn func(s *JobSnapshot)) {
+	j := jc.job
+	j.mu.Lock()
+	fn(&j.snapshot)
+	j.mu.Unlock()
+	j.manager.changed()
+}
+
+// progressWriter conta os bytes gravados e atende pausa/cancelamento a cada bloco
+type progressWriter struct {
+	w  io.Writer
+	jc *JobContext
+}
+
+// Write implementa io.Writer
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.jc.Checkpoint(); err != nil {
+		return 0, err
+	}
+	n, err := p.w.Write(b)
+	p.jc.AddBytes(int64(n))
+	return n, err
+}
+
+// copyFileJob copia um arquivo regular reportando o progresso.
+// Em caso de cancelamento o arquivo parcial é removido.
+func copyFileJob(jc *JobContext, src, dst string) error {
+	return copyFileJobFS(jc, FSFor(src), src, FSFor(dst), dst)
+}
+
+// copyFileJobFS copia um arquivo regular entre sistemas de arquivos reportando o progresso
+func copyFileJobFS(jc *JobContext, srcFS VFS, src string, dstFS VFS, dst string) error {
+	if jc == nil {
+		return CopyFileFS(srcFS, src, dstFS, dst)
+	}
+
+	jc.SetCurrent(src)
+	if err := jc.Checkpoint(); err != nil {
+		return err
+	}
+
+	sourceFileStat, err := srcFS.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !sourceFileStat.Mode().IsRegular() {
+		return fmt.Errorf("%s não é um arquivo regular", src)
+	}
+
+	source, err := srcFS.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := dstFS.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	destination, err := dstFS.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, jobBufferSize)
+	_, err = io.CopyBuffer(&progressWriter{w: destination, jc: jc}, source, buf)
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		dstFS.Remove(dst)
+		return err
+	}
+
+	// Preservar permissões
+	return chmodFS(dstFS, dst, sourceFileStat.Mode())
+}
+
+// CopyPathJob copia um arquivo ou diretório (recursivamente) reportando o progresso.
+// Os totais devem ter sido informados antes com JobContext.Scan.
+func CopyPathJob(jc *JobContext, src, dst string) error {
+	return CopyPathJobFS(jc, FSFor(src), src, FSFor(dst), dst)
+}
+
+// CopyPathJobFS copia um arquivo ou diretório entre sistemas de arquivos reportando o progresso
+func CopyPathJobFS(jc *JobContext, srcFS VFS, src string, dstFS VFS, dst string) error {
+	info, err := LstatFS(srcFS, src)
+	if err != nil {
+		return jc.Fail(src, err)
+	}
+
+	if !info.IsDir() {
+		err := copyFileJobFS(jc, srcFS, src, dstFS, dst)
+		jc.FileDone()
+		return jc.Fail(src, err)
+	}
+
+	if err := dstFS.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return jc.Fail(dst, err)
+	}
+
+	entries, err := srcFS.List(src)
+	if err != nil {
+		return jc.Fail(src, err)
+	}
+
+	for _, entry := range entries {
+		if err := jc.Checkpoint(); err != nil {
+			return err
+		}
+		if err := CopyPathJobFS(jc, srcFS, filepath.Join(src, entry.Name()), dstFS, filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MovePathJob move um arquivo ou diretório. Quando a origem e o destino estão em
+// sistemas de arquivos diferentes, copia com progresso e remove a origem.
+func MovePathJob(jc *JobContext, src, dst string) error {
+	if err := jc.Checkpoint(); err != nil {
+		return err
+	}
+	jc.SetCurrent(src)
+
+	srcFS, dstFS := FSFor(src), FSFor(dst)
+
+	// Renomear é instantâneo no mesmo sistema de arquivos
+	if srcFS == dstFS {
+		if err := srcFS.Rename(src, dst); err == nil {
+			if jc != nil {
+				files, bytes := countTree(dstFS, dst)
+				jc.update(func(s *JobSnapshot) {
+					s.DoneFiles += files
+					s.DoneBytes += bytes
+				})
+			}
+			return nil
+		}
+	}
+
+	var failures int
+	if jc != nil {
+		failures = len(jc.job.Snapshot().Errors)
+	}
+
+	if err := CopyPathJobFS(jc, srcFS, src, dstFS, dst); err != nil {
+		return err
+	}
+
+	// Não remover a origem se algum arquivo não foi copiado
+	if jc != nil && len(jc.job.Snapshot().Errors) > failures {
+		return nil
+	}
+
+	return jc.Fail(src, RemoveAllFS(srcFS, src))
+}
+
+// countTree conta os arquivos e bytes de um arquivo ou diretório
+func countTree(fsys VFS, root string) (int, int64) {
+	var files int
+	var bytes int64
+	WalkFS(fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files++
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return files, bytes
+}
+
+// DeletePathJob exclui um arquivo ou diretório, arquivo por arquivo, reportando o progresso
+func DeletePathJob(jc *JobContext, path string) error {
+	if jc == nil {
+		return DeleteFile(path)
+	}
+	return deletePathJobFS(jc, FSFor(path), path)
+}
+
+// deletePathJobFS exclui recursivamente um item de um sistema de arquivos
+func deletePathJobFS(jc *JobContext, fsys VFS, path string) error {
+	info, err := LstatFS(fsys, path)
+	if err != nil {
+		return jc.Fail(path, err)
+	}
+
+	if info.IsDir() {
+		entries, err := fsys.List(path)
+		if err != nil {
+			return jc.Fail(path, err)
+		}
+		for _, entry := range entries {
+			if err := deletePathJobFS(jc, fsys, filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+		if err := jc.Checkpoint(); err != nil {
+			return err
+		}
+		return jc.Fail(path, fsys.Remove(path))
+	}
+
+	if err := jc.Checkpoint(); err != nil {
+		return err
+	}
+	jc.SetCurrent(path)
+	err = fsys.Remove(path)
+	jc.AddBytes(info.Size())
+	jc.FileDone()
+	return jc.Fail(path, err)
+}
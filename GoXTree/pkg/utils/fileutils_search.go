@@ -2,13 +2,29 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	// searchMemoryLimit é o tamanho até o qual o arquivo é lido de uma vez, o que
+	// permite descartá-lo com uma única busca antes de separar as linhas
+	searchMemoryLimit = 8 * 1024 * 1024
+	// searchBinaryProbe é quanto do início do arquivo é examinado para detectar binários
+	searchBinaryProbe = 8 * 1024
+	// searchMaxLineLength é o maior tamanho de linha aceito; arquivos com linhas
+	// maiores (ex.: minificados) deixam de ser examinados a partir delas
+	searchMaxLineLength = 1024 * 1024
+)
+
 // SearchOptions define as opções para busca avançada
 type SearchOptions struct {
 	Pattern        string
@@ -21,9 +37,33 @@ type SearchOptions struct {
 	ModifiedAfter  time.Time
 	ModifiedBefore time.Time
 	FileTypes      []string
+	// FS é o sistema de arquivos pesquisado (nil = o responsável por Directory)
+	FS VFS
+	// Literal procura Pattern como texto, sem interpretá-lo como expressão regular
+	Literal       bool
+	IncludeHidden bool
+	// NoIgnore desativa as regras de .gitignore/.ignore
+	NoIgnore bool
+	// Context é o número de linhas exibidas antes e depois de cada ocorrência
+	Context int
+	// MaxMatchesPerFile limita as ocorrências informadas por arquivo (0 = sem limite)
+	MaxMatchesPerFile int
+	// Workers é o número de arquivos examinados em paralelo (0 = número de CPUs)
+	Workers int
+}
+
+// SearchMatch é uma ocorrência do padrão no conteúdo de um arquivo
+type SearchMatch struct {
+	LineNum int
+	Line    string
+	Spans   [][2]int // Posições (em bytes) dos trechos encontrados na linha
+	Before  []string // Linhas de contexto anteriores
+	After   []string // Linhas de contexto posteriores
 }
 
-// SearchResult representa um resultado de busca
+// SearchResult representa um resultado de busca. Quando o nome não corresponde
+// ao padrão, Matches traz as ocorrências no conteúdo e MatchLine/LineNum repetem
+// a primeira delas.
 type SearchResult struct {
 	Path      string
 	Name      string
@@ -32,223 +72,296 @@ type SearchResult struct {
 	IsDir     bool
 	MatchLine string
 	LineNum   int
+	Matches   []SearchMatch
 }
 
-// AdvancedSearchFiles realiza uma busca avançada de arquivos
+// AdvancedSearchFiles realiza uma busca avançada de arquivos e retorna os
+// resultados ordenados pelo caminho
 func AdvancedSearchFiles(options SearchOptions) ([]SearchResult, error) {
 	var results []SearchResult
+	err := SearchFiles(nil, options, func(r SearchResult) {
+		results = append(results, r)
+	})
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, err
+}
 
-	// Compilar a expressão regular para o padrão de busca
-	var pattern *regexp.Regexp
-	var err error
-
-	if options.CaseSensitive {
-		pattern, err = regexp.Compile(options.Pattern)
-	} else {
-		pattern, err = regexp.Compile("(?i)" + options.Pattern)
+// SearchFiles percorre a árvore enquanto vários workers examinam os arquivos em
+// paralelo. Cada resultado é entregue a emit assim que encontrado (nunca em
+// paralelo, mas fora de ordem). Diretórios ocultos, itens excluídos por
+// .gitignore/.ignore e arquivos binários são ignorados. A busca atende pausa e
+// cancelamento de jc.
+func SearchFiles(jc *JobContext, options SearchOptions, emit func(SearchResult)) error {
+	expr := options.Pattern
+	if options.Literal {
+		expr = regexp.QuoteMeta(expr)
 	}
-
+	if !options.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Função para verificar se um arquivo corresponde aos critérios
-	matchesFileType := func(ext string) bool {
-		if len(options.FileTypes) == 0 {
-			return true
-		}
-		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
-		for _, t := range options.FileTypes {
-			if t == ext {
-				return true
+	fsys := options.FS
+	if fsys == nil {
+		fsys = FSFor(options.Directory)
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	s := &searcher{options: options, fsys: fsys, pattern: pattern, jc: jc, emit: emit}
+
+	// Workers: examinam o conteúdo dos arquivos enviados pelo percurso
+	queue := make(chan searchItem, 256)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				if jc.Checkpoint() != nil {
+					continue
+				}
+				s.searchContent(item)
+				jc.FileDone()
 			}
-		}
-		return false
+		}()
 	}
 
-	// Função para verificar se um arquivo corresponde aos critérios de tamanho e data
-	matchesCriteria := func(info os.FileInfo) bool {
-		// Verificar tamanho
-		if options.MinSize > 0 && info.Size() < options.MinSize {
-			return false
-		}
-		if options.MaxSize > 0 && info.Size() > options.MaxSize {
-			return false
-		}
+	err = s.walk(queue)
+	close(queue)
+	wg.Wait()
 
-		// Verificar data de modificação
-		if !options.ModifiedAfter.IsZero() && info.ModTime().Before(options.ModifiedAfter) {
-			return false
-		}
-		if !options.ModifiedBefore.IsZero() && info.ModTime().After(options.ModifiedBefore) {
-			return false
-		}
+	if err == nil {
+		err = jc.Checkpoint()
+	}
+	return err
+}
 
-		return true
+// searchItem é um arquivo cujo conteúdo será examinado
+type searchItem struct {
+	path string
+	info os.FileInfo
+}
+
+// searcher guarda o estado compartilhado de uma busca
+type searcher struct {
+	options SearchOptions
+	fsys    VFS
+	pattern *regexp.Regexp
+	jc      *JobContext
+	emitMu  sync.Mutex
+	emit    func(SearchResult)
+}
+
+// send entrega um resultado, um de cada vez
+func (s *searcher) send(r SearchResult) {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	s.emit(r)
+}
+
+// walk percorre a árvore verificando os nomes e enfileirando os arquivos para a busca no conteúdo
+func (s *searcher) walk(queue chan<- searchItem) error {
+	root := s.options.Directory
+	var ignore *IgnoreMatcher
+	if !s.options.NoIgnore {
+		ignore = NewIgnoreMatcher(s.fsys)
 	}
 
-	// Função para processar um arquivo
-	processFile := func(path string, info os.FileInfo) error {
-		// Verificar se o arquivo corresponde aos critérios
-		if !matchesCriteria(info) {
-			return nil
+	return WalkFS(s.fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Ignorar itens inacessíveis e continuar
 		}
-
-		// Verificar se o tipo de arquivo corresponde
-		if !info.IsDir() && !matchesFileType(filepath.Ext(path)) {
-			return nil
+		if err := s.jc.Checkpoint(); err != nil {
+			return err
 		}
 
-		// Verificar se o nome corresponde ao padrão
-		name := filepath.Base(path)
-		if pattern.MatchString(name) {
-			results = append(results, SearchResult{
-				Path:    path,
-				Name:    name,
-				Size:    info.Size(),
-				ModTime: info.ModTime(),
-				IsDir:   info.IsDir(),
-			})
-			return nil
+		if path != root {
+			if !s.options.IncludeHidden && isHidden(path) || ignore != nil && ignore.Ignored(path, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 		}
 
-		// Se não for para verificar o conteúdo ou for um diretório, retornar
-		if !options.MatchContent || info.IsDir() {
-			return nil
+		if info.IsDir() {
+			if path != root && !s.options.Recursive {
+				return filepath.SkipDir
+			}
+			if ignore != nil {
+				ignore.Enter(path)
+			}
+			if path == root {
+				return nil
+			}
 		}
 
-		// Verificar se é um arquivo de texto antes de procurar no conteúdo
-		isText, err := IsTextFile(path)
-		if err != nil || !isText {
+		if !s.matchesCriteria(path, info) {
 			return nil
 		}
 
-		// Procurar no conteúdo do arquivo
-		file, err := os.Open(path)
-		if err != nil {
+		// Verificar se o nome corresponde ao padrão
+		if s.pattern.MatchString(info.Name()) {
+			s.send(newSearchResult(path, info))
 			return nil
 		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		lineNum := 0
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			if pattern.MatchString(line) {
-				results = append(results, SearchResult{
-					Path:      path,
-					Name:      name,
-					Size:      info.Size(),
-					ModTime:   info.ModTime(),
-					IsDir:     info.IsDir(),
-					MatchLine: line,
-					LineNum:   lineNum,
-				})
-				break // Encontrou uma correspondência, não precisa continuar procurando neste arquivo
-			}
+
+		if s.options.MatchContent && info.Mode().IsRegular() && info.Size() > 0 {
+			s.jc.AddTotal(1, 0)
+			queue <- searchItem{path: path, info: info}
 		}
+		return nil
+	})
+}
 
-		return scanner.Err()
+// matchesCriteria verifica o tipo, o tamanho e a data do item
+func (s *searcher) matchesCriteria(path string, info os.FileInfo) bool {
+	options := s.options
+
+	// Verificar tamanho
+	if options.MinSize > 0 && info.Size() < options.MinSize {
+		return false
+	}
+	if options.MaxSize > 0 && info.Size() > options.MaxSize {
+		return false
 	}
 
-	// Percorrer o diretório
-	if options.Recursive {
-		err = filepath.Walk(options.Directory, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Ignorar erros e continuar
-			}
-			return processFile(path, info)
-		})
-	} else {
-		// Apenas listar arquivos no diretório atual
-		entries, err := os.ReadDir(options.Directory)
-		if err != nil {
-			return nil, err
-		}
+	// Verificar data de modificação
+	if !options.ModifiedAfter.IsZero() && info.ModTime().Before(options.ModifiedAfter) {
+		return false
+	}
+	if !options.ModifiedBefore.IsZero() && info.ModTime().After(options.ModifiedBefore) {
+		return false
+	}
 
-		for _, entry := range entries {
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
-			path := filepath.Join(options.Directory, entry.Name())
-			if err := processFile(path, info); err != nil {
-				return nil, err
-			}
+	// Verificar o tipo de arquivo
+	if len(options.FileTypes) == 0 || info.IsDir() {
+		return true
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for _, t := range options.FileTypes {
+		if strings.ToLower(strings.TrimPrefix(t, ".")) == ext {
+			return true
 		}
 	}
+	return false
+}
 
-	return results, err
+// newSearchResult cria o resultado de um item
+func newSearchResult(path string, info os.FileInfo) SearchResult {
+	return SearchResult{
+		Path:    path,
+		Name:    info.Name(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}
 }
 
-// FindDuplicateFiles encontra arquivos duplicados em um diretório
-func FindDuplicateFiles(rootDir string, recursive bool) (map[int64][]FileInfo, error) {
-	// Mapa de tamanho -> lista de arquivos
-	sizeMap := make(map[int64][]FileInfo)
+// searchContent procura o padrão no conteúdo de um arquivo de texto
+func (s *searcher) searchContent(item searchItem) {
+	s.jc.SetCurrent(item.path)
 
-	// Função para processar um diretório
-	var processDir func(dirPath string) error
-	processDir = func(dirPath string) error {
-		entries, err := os.ReadDir(dirPath)
-		if err != nil {
-			return err
+	file, err := s.fsys.Open(item.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// Arquivos pequenos são lidos de uma vez e descartados sem separar as linhas
+	var reader *bufio.Reader
+	if item.info.Size() <= searchMemoryLimit {
+		data, err := io.ReadAll(file)
+		if err != nil || isBinaryData(data) || !s.pattern.Match(data) {
+			return
+		}
+		reader = bufio.NewReader(bytes.NewReader(data))
+	} else {
+		reader = bufio.NewReaderSize(file, searchBinaryProbe)
+		if probe, _ := reader.Peek(searchBinaryProbe); isBinaryData(probe) {
+			return
 		}
+	}
 
-		for _, entry := range entries {
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
+	matches := s.matchLines(reader)
+	if len(matches) == 0 {
+		return
+	}
 
-			path := filepath.Join(dirPath, entry.Name())
+	result := newSearchResult(item.path, item.info)
+	result.Matches = matches
+	result.MatchLine, result.LineNum = matches[0].Line, matches[0].LineNum
+	s.send(result)
+}
 
-			if info.IsDir() {
-				if recursive {
-					if err := processDir(path); err != nil {
-						return err
-					}
-				}
-				continue
-			}
+// matchLines retorna as ocorrências do padrão linha a linha, com as linhas de contexto
+func (s *searcher) matchLines(reader io.Reader) []SearchMatch {
+	context := s.options.Context
+	limit := s.options.MaxMatchesPerFile
+
+	var matches []SearchMatch
+	var previous []string // Últimas linhas lidas, para o contexto anterior
+	pending := 0          // Ocorrências ainda aguardando o contexto posterior
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), searchMaxLineLength)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum%4096 == 0 && s.jc.Checkpoint() != nil {
+			break
+		}
+		line := strings.TrimSuffix(scanner.Text(), "\r")
 
-			// Ignorar arquivos vazios
-			if info.Size() == 0 {
-				continue
-			}
+		// Completar o contexto das ocorrências anteriores
+		for i := len(matches) - pending; i < len(matches); i++ {
+			matches[i].After = append(matches[i].After, line)
+		}
+		if pending > 0 && len(matches[len(matches)-pending].After) == context {
+			pending--
+		}
 
-			name := entry.Name()
-			isHidden := strings.HasPrefix(name, ".")
-			extension := strings.TrimPrefix(filepath.Ext(name), ".")
-
-			fileInfo := FileInfo{
-				Name:      name,
-				Path:      path,
-				Size:      info.Size(),
-				ModTime:   info.ModTime(),
-				IsDir:     false,
-				IsHidden:  isHidden,
-				Extension: extension,
+		limitReached := limit > 0 && len(matches) >= limit
+		if !limitReached {
+			if spans := s.pattern.FindAllStringIndex(line, -1); spans != nil {
+				match := SearchMatch{LineNum: lineNum, Line: line}
+				for _, span := range spans {
+					match.Spans = append(match.Spans, [2]int{span[0], span[1]})
+				}
+				match.Before = append([]string(nil), previous...)
+				matches = append(matches, match)
+				if context > 0 {
+					pending++
+				}
 			}
-
-			sizeMap[info.Size()] = append(sizeMap[info.Size()], fileInfo)
+		} else if pending == 0 {
+			break
 		}
 
-		return nil
-	}
-
-	// Processar o diretório raiz
-	if err := processDir(rootDir); err != nil {
-		return nil, err
-	}
-
-	// Filtrar para manter apenas tamanhos com mais de um arquivo
-	result := make(map[int64][]FileInfo)
-	for size, files := range sizeMap {
-		if len(files) > 1 {
-			result[size] = files
+		if context > 0 {
+			previous = append(previous, line)
+			if len(previous) > context {
+				previous = previous[1:]
+			}
 		}
 	}
+	// Um erro de leitura (ou linha longa demais) encerra o arquivo com o que já foi encontrado
+	return matches
+}
 
-	return result, nil
+// isBinaryData indica se o início do conteúdo tem bytes nulos, como nos arquivos binários
+func isBinaryData(data []byte) bool {
+	if len(data) > searchBinaryProbe {
+		data = data[:searchBinaryProbe]
+	}
+	return bytes.IndexByte(data, 0) >= 0
 }
@@ -16,6 +16,10 @@ type SyncOptions struct {
 	SkipNewer      bool
 	SkipExisting   bool
 	IncludeHidden  bool
+	// SourceFS e DestFS permitem sincronizar entre sistemas de arquivos
+	// diferentes (nil = sistema de arquivos responsável pelo caminho)
+	SourceFS VFS
+	DestFS   VFS
 }
 
 // SyncAction representa uma ação de sincronização
@@ -31,10 +35,24 @@ type SyncAction struct {
 
 // SyncDirectories sincroniza dois diretórios
 func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
+	return SyncDirectoriesJob(nil, options)
+}
+
+// SyncDirectoriesJob sincroniza dois diretórios reportando o progresso. Em uma operação
+// em segundo plano, falhas em arquivos individuais são registradas e a sincronização continua.
+func SyncDirectoriesJob(jc *JobContext, options SyncOptions) ([]SyncAction, error) {
 	var actions []SyncAction
 
+	srcFS, destFS := options.SourceFS, options.DestFS
+	if srcFS == nil {
+		srcFS = FSFor(options.SourceDir)
+	}
+	if destFS == nil {
+		destFS = FSFor(options.DestDir)
+	}
+
 	// Verificar se os diretórios existem
-	srcInfo, err := os.Stat(options.SourceDir)
+	srcInfo, err := srcFS.Stat(options.SourceDir)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao acessar diretório de origem: %v", err)
 	}
@@ -43,11 +61,11 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 	}
 
 	// Verificar se o diretório de destino existe, se não, criar
-	destInfo, err := os.Stat(options.DestDir)
+	destInfo, err := destFS.Stat(options.DestDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			if !options.PreviewOnly {
-				if err := os.MkdirAll(options.DestDir, srcInfo.Mode()); err != nil {
+				if err := destFS.MkdirAll(options.DestDir, srcInfo.Mode().Perm()); err != nil {
 					return nil, fmt.Errorf("erro ao criar diretório de destino: %v", err)
 				}
 			}
@@ -66,22 +84,29 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 	}
 
 	// Mapear arquivos no diretório de origem
-	srcFiles, err := mapDirectoryFiles(options.SourceDir, options.IncludeHidden)
+	srcFiles, err := mapDirectoryFiles(srcFS, options.SourceDir, options.IncludeHidden)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao mapear diretório de origem: %v", err)
 	}
 
 	// Mapear arquivos no diretório de destino
-	destFiles, err := mapDirectoryFiles(options.DestDir, options.IncludeHidden)
+	destFiles, err := mapDirectoryFiles(destFS, options.DestDir, options.IncludeHidden)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao mapear diretório de destino: %v", err)
 	}
 
 	// Comparar e sincronizar
+	jc.AddTotal(len(srcFiles), 0)
 	for relPath, srcFile := range srcFiles {
 		srcFullPath := filepath.Join(options.SourceDir, relPath)
 		destFullPath := filepath.Join(options.DestDir, relPath)
 
+		if err := jc.Checkpoint(); err != nil {
+			return actions, err
+		}
+		jc.SetCurrent(srcFullPath)
+		jc.FileDone()
+
 		// Verificar se o arquivo existe no destino
 		destFile, exists := destFiles[relPath]
 
@@ -98,8 +123,11 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 				})
 
 				if !options.PreviewOnly {
-					if err := os.MkdirAll(destFullPath, os.ModePerm); err != nil {
-						return nil, fmt.Errorf("erro ao criar diretório: %v", err)
+					if err := destFS.MkdirAll(destFullPath, os.ModePerm); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao criar diretório: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				}
 			} else {
@@ -116,12 +144,18 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 				if !options.PreviewOnly {
 					// Criar diretório pai se necessário
 					destDir := filepath.Dir(destFullPath)
-					if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
-						return nil, fmt.Errorf("erro ao criar diretório pai: %v", err)
+					if err := destFS.MkdirAll(destDir, os.ModePerm); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao criar diretório pai: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 
-					if err := CopyFile(srcFullPath, destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao copiar arquivo: %v", err)
+					if err := copyFileJobFS(jc, srcFS, srcFullPath, destFS, destFullPath); err != nil {
+						if err := jc.Fail(srcFullPath, fmt.Errorf("erro ao copiar arquivo: %w", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				}
 			}
@@ -161,8 +195,11 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 					})
 
 					if !options.PreviewOnly {
-						if err := CopyFile(srcFullPath, destFullPath); err != nil {
-							return nil, fmt.Errorf("erro ao atualizar arquivo: %v", err)
+						if err := copyFileJobFS(jc, srcFS, srcFullPath, destFS, destFullPath); err != nil {
+							if err := jc.Fail(srcFullPath, fmt.Errorf("erro ao atualizar arquivo: %w", err)); err != nil {
+								return nil, err
+							}
+							continue
 						}
 					}
 				}
@@ -178,11 +215,17 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 				})
 
 				if !options.PreviewOnly {
-					if err := os.Remove(destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao remover arquivo: %v", err)
+					if err := destFS.Remove(destFullPath); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao remover arquivo: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
-					if err := os.MkdirAll(destFullPath, os.ModePerm); err != nil {
-						return nil, fmt.Errorf("erro ao criar diretório: %v", err)
+					if err := destFS.MkdirAll(destFullPath, os.ModePerm); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao criar diretório: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				}
 			} else if !srcFile.IsDir && destFile.IsDir {
@@ -198,11 +241,17 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 				})
 
 				if !options.PreviewOnly {
-					if err := os.RemoveAll(destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao remover diretório: %v", err)
+					if err := RemoveAllFS(destFS, destFullPath); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao remover diretório: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
-					if err := CopyFile(srcFullPath, destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao copiar arquivo: %v", err)
+					if err := copyFileJobFS(jc, srcFS, srcFullPath, destFS, destFullPath); err != nil {
+						if err := jc.Fail(srcFullPath, fmt.Errorf("erro ao copiar arquivo: %w", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				}
 			}
@@ -236,12 +285,18 @@ func SyncDirectories(options SyncOptions) ([]SyncAction, error) {
 
 			if !options.PreviewOnly {
 				if destFile.IsDir {
-					if err := os.RemoveAll(destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao remover diretório órfão: %v", err)
+					if err := RemoveAllFS(destFS, destFullPath); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao remover diretório órfão: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				} else {
-					if err := os.Remove(destFullPath); err != nil {
-						return nil, fmt.Errorf("erro ao remover arquivo órfão: %v", err)
+					if err := destFS.Remove(destFullPath); err != nil {
+						if err := jc.Fail(destFullPath, fmt.Errorf("erro ao remover arquivo órfão: %v", err)); err != nil {
+							return nil, err
+						}
+						continue
 					}
 				}
 			}
@@ -259,10 +314,10 @@ type fileMapEntry struct {
 }
 
 // Mapeia todos os arquivos em um diretório recursivamente
-func mapDirectoryFiles(rootDir string, includeHidden bool) (map[string]fileMapEntry, error) {
+func mapDirectoryFiles(fsys VFS, rootDir string, includeHidden bool) (map[string]fileMapEntry, error) {
 	files := make(map[string]fileMapEntry)
 
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+	err := WalkFS(fsys, rootDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// frecencyMaxRank é a soma máxima das pontuações; acima dela todas envelhecem
+// e os diretórios pouco usados são esquecidos (como no zoxide)
+const frecencyMaxRank = 10000
+
+// FrecencyEntry é um diretório visitado
+type FrecencyEntry struct {
+	Path      string    `json:"path"`
+	Rank      float64   `json:"rank"`
+	LastVisit time.Time `json:"lastVisit"`
+}
+
+// Frecency combina a frequência (Rank) com o tempo desde a última visita
+func (e FrecencyEntry) Frecency(now time.Time) float64 {
+	age := now.Sub(e.LastVisit)
+	switch {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	}
+	return e.Rank / 4
+}
+
+// FrecencyMatch é um resultado de FrecencyDB.Query
+type FrecencyMatch struct {
+	FrecencyEntry
+	Score     float64
+	Positions []int // Posições (em runas) dos caracteres encontrados no caminho
+}
+
+// FrecencyDB guarda os diretórios visitados e quanto foram usados (segura para uso concorrente)
+type FrecencyDB struct {
+	mu      sync.Mutex
+	file    string
+	entries map[string]*FrecencyEntry
+}
+
+// LoadFrecencyDB carrega a base de um arquivo JSON. Um arquivo inexistente resulta
+// em uma base vazia, que será criada ao salvar.
+func LoadFrecencyDB(file string) (*FrecencyDB, error) {
+	db := &FrecencyDB{file: file, entries: make(map[string]*FrecencyEntry)}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return db, err
+	}
+
+	var entries []FrecencyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return db, err
+	}
+	for i := range entries {
+		db.entries[entries[i].Path] = &entries[i]
+	}
+	return db, nil
+}
+
+// Visit registra uma visita ao diretório
+func (db *FrecencyDB) Visit(path string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, ok := db.entries[path]
+	if !ok {
+		entry = &FrecencyEntry{Path: path}
+		db.entries[path] = entry
+	}
+	entry.Rank++
+	entry.LastVisit = time.Now()
+
+	// Envelhecer as pontuações quando a soma passa do limite
+	total := 0.0
+	for _, e := range db.entries {
+		total += e.Rank
+	}
+	if total > frecencyMaxRank {
+		for p, e := range db.entries {
+			e.Rank *= 0.9
+			if e.Rank < 1 {
+				delete(db.entries, p)
+			}
+		}
+	}
+}
+
+// Remove esquece um diretório (ex.: quando ele deixou de existir)
+func (db *FrecencyDB) Remove(path string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.entries, path)
+}
+
+// Len retorna o número de diretórios registrados
+func (db *FrecencyDB) Len() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return len(db.entries)
+}
+
+// Query retorna os diretórios que correspondem ao padrão (busca aproximada),
+// ordenados pela combinação da pontuação da busca com a frecência. Sem padrão,
+// a ordem é só a da frecência. limit <= 0 retorna todos.
+func (db *FrecencyDB) Query(pattern string, limit int) []FrecencyMatch {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	var matches []FrecencyMatch
+	for _, entry := range db.entries {
+		score, positions, ok := FuzzyMatch(pattern, entry.Path)
+		if !ok {
+			continue
+		}
+		frecency := entry.Frecency(now)
+		matches = append(matches, FrecencyMatch{
+			FrecencyEntry: *entry,
+			Score:         float64(score) + 20*math.Log1p(frecency),
+			Positions:     positions,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Save grava a base no arquivo (substituindo-o de uma vez, sem deixá-lo pela metade)
+func (db *FrecencyDB) Save() error {
+	db.mu.Lock()
+	entries := make([]FrecencyEntry, 0, len(db.entries))
+	for _, entry := range db.entries {
+		entries = append(entries, *entry)
+	}
+	db.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(db.file), 0755); err != nil {
+		return err
+	}
+	temp := db.file + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, db.file)
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Pontuação da busca aproximada
+const (
+	fuzzyScoreMatch       = 16 // Cada caractere encontrado
+	fuzzyBonusConsecutive = 8  // Caractere logo após o anterior
+	fuzzyBonusBoundary    = 12 // Início de palavra (após /, _, -, . ou espaço)
+	fuzzyBonusCamel       = 8  // Maiúscula no meio de uma palavra (camelCase)
+	fuzzyBonusBasename    = 2  // Caractere no último componente do caminho
+	fuzzyPenaltyGapStart  = 3  // Início de um intervalo entre dois caracteres encontrados
+	fuzzyPenaltyGap       = 1  // Cada caractere pulado além do primeiro
+)
+
+// FuzzyMatch verifica se os caracteres de pattern aparecem em text, na mesma
+// ordem (sem diferenciar maiúsculas/minúsculas), como no fzf. Retorna a
+// pontuação (maior = melhor) e as posições, em runas, dos caracteres encontrados.
+// Termos separados por espaço são procurados de forma independente e todos precisam
+// ser encontrados.
+func FuzzyMatch(pattern, text string) (int, []int, bool) {
+	terms := strings.Fields(pattern)
+	if len(terms) == 0 {
+		return 0, nil, true
+	}
+
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Conversões que mudam o número de runas: comparar sem normalizar
+		lower = runes
+	}
+
+	total := 0
+	var positions []int
+	for _, term := range terms {
+		score, pos, ok := fuzzyMatchTerm([]rune(strings.ToLower(term)), runes, lower)
+		if !ok {
+			return 0, nil, false
+		}
+		total += score
+		positions = append(positions, pos...)
+	}
+	return total, positions, true
+}
+
+// fuzzyMatchTerm procura um termo. Para cada início possível encontra a primeira
+// ocorrência da subsequência e depois, de trás para frente, a ocorrência mais curta
+// que termina no mesmo ponto; fica com a de maior pontuação.
+func fuzzyMatchTerm(term, runes, lower []rune) (int, []int, bool) {
+	basename := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == '/' || runes[i] == '\\' {
+			basename = i + 1
+			break
+		}
+	}
+
+	bestScore, found := 0, false
+	var best []int
+	lastStart := -1
+	for start := range lower {
+		if lower[start] != term[0] || start <= lastStart {
+			continue
+		}
+
+		// Ida: primeira posição onde a subsequência termina
+		t, end := 0, -1
+		for i := start; i < len(lower) && t < len(term); i++ {
+			if lower[i] == term[t] {
+				t++
+				if t == len(term) {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+
+		// Volta: aproximar o início do fim
+		positions := make([]int, len(term))
+		t = len(term) - 1
+		for i := end; i >= 0 && t >= 0; i-- {
+			if lower[i] == term[t] {
+				positions[t] = i
+				t--
+			}
+		}
+		lastStart = positions[0]
+
+		if score := fuzzyScore(positions, runes, basename); !found || score > bestScore {
+			bestScore, best, found = score, positions, true
+		}
+	}
+	return bestScore, best, found
+}
+
+// fuzzyScore pontua as posições encontradas de um termo. Como no fzf, os caracteres
+// de um trecho contínuo herdam o bônus do primeiro caractere do trecho.
+func fuzzyScore(positions []int, runes []rune, basename int) int {
+	score, chunkBonus := 0, 0
+	for k, pos := range positions {
+		score += fuzzyScoreMatch
+		if pos >= basename {
+			score += fuzzyBonusBasename
+		}
+
+		bonus := 0
+		if pos == 0 || isFuzzySeparator(runes[pos-1]) {
+			bonus = fuzzyBonusBoundary
+		} else if unicode.IsUpper(runes[pos]) && unicode.IsLower(runes[pos-1]) {
+			bonus = fuzzyBonusCamel
+		}
+
+		if k > 0 && pos == positions[k-1]+1 {
+			bonus = max(bonus, chunkBonus, fuzzyBonusConsecutive)
+		} else {
+			if k > 0 {
+				score -= fuzzyPenaltyGapStart + (pos-positions[k-1]-2)*fuzzyPenaltyGap
+			}
+			chunkBonus = bonus
+		}
+		score += bonus
+	}
+	return score
+}
+
+// isFuzzySeparator indica os caracteres que separam palavras em nomes e caminhos
+func isFuzzySeparator(r rune) bool {
+	switch r {
+	case '/', '\\', '_', '-', '.', ' ':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bits das entradas do índice
+const (
+	gitIndexStageMask    = 0x3000
+	gitIndexStageShift   = 12
+	gitIndexExtended     = 0x4000
+	gitIndexNameMask     = 0x0fff
+	gitIndexIntentToAdd  = 0x2000 // Nos flags estendidos: "git add -N"
+	gitIndexSkipWorktree = 0x4000 // Nos flags estendidos: fora do checkout esparso
+)
+
+// errGitIndexUnsupported indica um índice com extensões obrigatórias que o
+// GoXTree não sabe regravar (índice dividido ou esparso)
+var errGitIndexUnsupported = errors.New("o índice do git usa recursos não suportados (split index ou sparse index); use o git para esta operação")
+
+// gitIndexEntry é uma entrada do índice (área de preparação)
+type gitIndexEntry struct {
+	ctimeSec, ctimeNsec uint32
+	mtimeSec, mtimeNsec uint32
+	dev, ino            uint32
+	mode                uint32
+	uid, gid            uint32
+	size                uint32
+	hash                []byte
+	flags               uint16 // Estágio (conflitos) e tamanho do nome
+	extFlags            uint16
+	path                string
+}
+
+// stage retorna o estágio da entrada: 0 normal, 1-3 em conflito
+func (e *gitIndexEntry) stage() int {
+	return int(e.flags&gitIndexStageMask) >> gitIndexStageShift
+}
+
+// gitIndexExtension é uma extensão do índice guardada como veio do arquivo
+type gitIndexExtension struct {
+	signature string
+	data      []byte
+}
+
+// gitIndex é o conteúdo do arquivo .git/index
+type gitIndex struct {
+	version    uint32
+	entries    []*gitIndexEntry
+	extensions []gitIndexExtension
+}
+
+// readIndex lê o índice do repositório (versões 2, 3 e 4). Um índice que ainda
+// não existe é tratado como vazio.
+func (r *GitRepo) readIndex() (*gitIndex, error) {
+	data, err := os.ReadFile(r.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return &gitIndex{version: 2}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	errCorrupt := fmt.Errorf("índice do git corrompido: %s", r.indexPath())
+	if len(data) < 12+r.hashSize || string(data[:4]) != "DIRC" {
+		return nil, errCorrupt
+	}
+	index := &gitIndex{version: binary.BigEndian.Uint32(data[4:8])}
+	if index.version < 2 || index.version > 4 {
+		return nil, fmt.Errorf("versão do índice do git não suportada: %d", index.version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+	body := data[:len(data)-r.hashSize] // Sem o hash de verificação no final
+
+	pos := 12
+	previous := ""
+	for i := 0; i < count; i++ {
+		start := pos
+		if pos+40+r.hashSize+2 > len(body) {
+			return nil, errCorrupt
+		}
+		u32 := func(k int) uint32 { return binary.BigEndian.Uint32(body[pos+k*4:]) }
+		entry := &gitIndexEntry{
+			ctimeSec: u32(0), ctimeNsec: u32(1),
+			mtimeSec: u32(2), mtimeNsec: u32(3),
+			dev: u32(4), ino: u32(5), mode: u32(6),
+			uid: u32(7), gid: u32(8), size: u32(9),
+		}
+		pos += 40
+		entry.hash = append([]byte(nil), body[pos:pos+r.hashSize]...)
+		pos += r.hashSize
+		entry.flags = binary.BigEndian.Uint16(body[pos:])
+		pos += 2
+		if entry.flags&gitIndexExtended != 0 {
+			if index.version < 3 || pos+2 > len(body) {
+				return nil, errCorrupt
+			}
+			entry.extFlags = binary.BigEndian.Uint16(body[pos:])
+			pos += 2
+		}
+
+		if index.version == 4 {
+			// O nome reaproveita o final do anterior: N bytes a remover + sufixo
+			br := bytes.NewReader(body[pos:])
+			strip, err := readGitOffset(br)
+			if err != nil || int(strip) > len(previous) {
+				return nil, errCorrupt
+			}
+			pos = len(body) - br.Len()
+			end := bytes.IndexByte(body[pos:], 0)
+			if end < 0 {
+				return nil, errCorrupt
+			}
+			entry.path = previous[:len(previous)-int(strip)] + string(body[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(body[pos:], 0)
+			if end < 0 {
+				return nil, errCorrupt
+			}
+			entry.path = string(body[pos : pos+end])
+			// Entradas completadas com NULs até múltiplo de 8 bytes
+			pos = start + (pos-start+end+8)/8*8
+		}
+		previous = entry.path
+		index.entries = append(index.entries, entry)
+	}
+
+	// Extensões: assinatura de 4 bytes, tamanho e dados
+	for pos+8 <= len(body) {
+		size := int(binary.BigEndian.Uint32(body[pos+4:]))
+		if pos+8+size > len(body) {
+			return nil, errCorrupt
+		}
+		index.extensions = append(index.extensions, gitIndexExtension{
+			signature: string(body[pos : pos+4]),
+			data:      body[pos+8 : pos+8+size],
+		})
+		pos += 8 + size
+	}
+	return index, nil
+}
+
+// writeIndex grava o índice (versão 2, ou 3 se houver flags estendidos) usando
+// index.lock, como o git. Das extensões opcionais só a REUC (resolução de
+// conflitos) é mantida; as demais são caches que o git recria.
+func (r *GitRepo) writeIndex(index *gitIndex) error {
+	for _, ext := range index.extensions {
+		if ext.signature[0] >= 'a' && ext.signature[0] <= 'z' {
+			return errGitIndexUnsupported
+		}
+	}
+
+	sort.SliceStable(index.entries, func(i, j int) bool {
+		a, b := index.entries[i], index.entries[j]
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return a.stage() < b.stage()
+	})
+
+	version := uint32(2)
+	for _, entry := range index.entries {
+		if entry.extFlags != 0 {
+			version = 3
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("DIRC")
+	binary.Write(&buf, binary.BigEndian, version)
+	binary.Write(&buf, binary.BigEndian, uint32(len(index.entries)))
+	for _, entry := range index.entries {
+		start := buf.Len()
+		for _, v := range []uint32{entry.ctimeSec, entry.ctimeNsec, entry.mtimeSec, entry.mtimeNsec,
+			entry.dev, entry.ino, entry.mode, entry.uid, entry.gid, entry.size} {
+			binary.Write(&buf, binary.BigEndian, v)
+		}
+		buf.Write(entry.hash)
+
+		flags := entry.flags&gitIndexStageMask | uint16(min(len(entry.path), gitIndexNameMask))
+		if entry.extFlags != 0 {
+			flags |= gitIndexExtended
+		}
+		binary.Write(&buf, binary.BigEndian, flags)
+		if entry.extFlags != 0 {
+			binary.Write(&buf, binary.BigEndian, entry.extFlags)
+		}
+		buf.WriteString(entry.path)
+		buf.Write(make([]byte, 8-(buf.Len()-start)%8))
+	}
+	for _, ext := range index.extensions {
+		if ext.signature != "REUC" {
+			continue
+		}
+		buf.WriteString(ext.signature)
+		binary.Write(&buf, binary.BigEndian, uint32(len(ext.data)))
+		buf.Write(ext.data)
+	}
+	h := r.newHash()
+	h.Write(buf.Bytes())
+	buf.Write(h.Sum(nil))
+
+	// O git usa index.lock para impedir gravações simultâneas
+	lockPath := r.indexPath() + ".lock"
+	lock, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("o índice está bloqueado por outro processo git (%s)", lockPath)
+		}
+		return err
+	}
+	if _, err := lock.Write(buf.Bytes()); err != nil {
+		lock.Close()
+		os.Remove(lockPath)
+		return err
+	}
+	if err := lock.Close(); err != nil {
+		os.Remove(lockPath)
+		return err
+	}
+	return os.Rename(lockPath, r.indexPath())
+}
+
+// newIndexEntry cria a entrada do índice para um arquivo do diretório de trabalho
+func newIndexEntry(path string, hash []byte, mode uint32, info os.FileInfo) *gitIndexEntry {
+	entry := &gitIndexEntry{path: path, hash: hash, mode: mode}
+	if info != nil {
+		mtime := info.ModTime()
+		entry.mtimeSec, entry.mtimeNsec = uint32(mtime.Unix()), uint32(mtime.Nanosecond())
+		entry.ctimeSec, entry.ctimeNsec = entry.mtimeSec, entry.mtimeNsec
+		entry.size = uint32(info.Size())
+	}
+	return entry
+}
+
+// gitFileMode retorna o modo usado pelo git para o arquivo: 100644, 100755 ou
+// 120000 (link simbólico); 0 para itens que o git não guarda
+func gitFileMode(info os.FileInfo) uint32 {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return 0120000
+	case !info.Mode().IsRegular():
+		return 0
+	case info.Mode()&0100 != 0:
+		return 0100755
+	}
+	return 0100644
+}
+
+// readWorktreeBlob lê o conteúdo que o git guardaria para o item: o conteúdo do
+// arquivo ou, em links simbólicos, o destino
+func readWorktreeBlob(path string, info os.FileInfo) ([]byte, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		return []byte(target), err
+	}
+	return os.ReadFile(path)
+}
+
+// statMatches indica se os dados do arquivo batem com os guardados no índice,
+// caso em que o conteúdo não precisa ser lido de novo. Arquivos alterados no
+// mesmo instante em que o índice foi gravado são sempre relidos ("racy git").
+func statMatches(entry *gitIndexEntry, info os.FileInfo, indexTime time.Time) bool {
+	mtime := info.ModTime()
+	if entry.size != uint32(info.Size()) || entry.mtimeSec != uint32(mtime.Unix()) {
+		return false
+	}
+	if entry.mtimeNsec != 0 && entry.mtimeNsec != uint32(mtime.Nanosecond()) {
+		return false
+	}
+	return mtime.Before(indexTime)
+}
+
+// hasPathPrefix indica se o caminho relativo é o próprio prefixo ou está dentro dele
+// ("" representa a raiz do repositório)
+func hasPathPrefix(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
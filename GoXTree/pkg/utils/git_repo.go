@@ -0,0 +1,931 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"container/heap"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// O repositório é lido diretamente dos arquivos em .git (refs, objetos soltos e
+// empacotados e o índice), sem depender do executável git.
+
+// ErrNotGitRepo indica que o diretório não está dentro de um repositório git
+var ErrNotGitRepo = errors.New("não é um repositório git")
+
+// errGitObjectNotFound indica que o objeto não existe no repositório
+var errGitObjectNotFound = errors.New("objeto git não encontrado")
+
+// Tipos de objeto git
+const (
+	gitObjCommit = 1
+	gitObjTree   = 2
+	gitObjBlob   = 3
+	gitObjTag    = 4
+	gitObjOfs    = 6 // Delta em relação a um objeto anterior do mesmo pacote
+	gitObjRef    = 7 // Delta em relação a um objeto identificado pelo hash
+)
+
+// GitRepo é um repositório git local
+type GitRepo struct {
+	Root      string // Diretório de trabalho
+	gitDir    string // .git (ou o diretório da worktree em .git/worktrees)
+	commonDir string // Objetos, refs e configuração compartilhados
+	hashSize  int    // 20 (SHA-1) ou 32 (SHA-256)
+	config    map[string]string
+
+	packsOnce sync.Once
+	packs     []*gitPack
+	objDirs   []string
+}
+
+// gitPack é um pacote de objetos com seu índice (.idx versão 2)
+type gitPack struct {
+	path   string // Arquivo .pack
+	idx    []byte
+	count  int
+	fanout []byte
+}
+
+// gitTreeEntry é um item de uma árvore do git
+type gitTreeEntry struct {
+	mode uint32
+	hash []byte
+}
+
+// gitCommit são os campos de um commit usados pelo GoXTree
+type gitCommit struct {
+	tree    []byte
+	parents [][]byte
+	time    int64
+}
+
+// OpenGitRepo localiza o repositório que contém o caminho, subindo pelos
+// diretórios pais. Retorna ErrNotGitRepo se não houver (ou se o caminho não for local).
+func OpenGitRepo(path string) (*GitRepo, error) {
+	if !IsLocalPath(path) {
+		return nil, ErrNotGitRepo
+	}
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if gitDir, ok := findGitDir(dir); ok {
+			return newGitRepo(dir, gitDir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotGitRepo
+		}
+		dir = parent
+	}
+}
+
+// findGitDir verifica se o diretório tem um .git: um diretório ou, em worktrees
+// e submódulos, um arquivo com "gitdir: <caminho>"
+func findGitDir(dir string) (string, bool) {
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+
+	gitDir := dotGit
+	if !info.IsDir() {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", false
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return "", false
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(dir, gitDir)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return "", false
+	}
+	return gitDir, true
+}
+
+// newGitRepo abre o repositório com o diretório de trabalho e o diretório git
+func newGitRepo(root, gitDir string) (*GitRepo, error) {
+	r := &GitRepo{Root: root, gitDir: gitDir, commonDir: gitDir, hashSize: sha1.Size}
+
+	// Worktrees adicionais compartilham objetos e refs com o repositório principal
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		r.commonDir = filepath.Clean(common)
+	}
+
+	// Configuração do sistema e do usuário, sobreposta pela do repositório
+	r.config = make(map[string]string)
+	for _, file := range globalGitConfigFiles() {
+		for key, value := range parseGitConfig(file) {
+			r.config[key] = value
+		}
+	}
+	for key, value := range parseGitConfig(filepath.Join(r.commonDir, "config")) {
+		r.config[key] = value
+	}
+	if strings.EqualFold(r.config["extensions.objectformat"], "sha256") {
+		r.hashSize = sha256.Size
+	}
+	return r, nil
+}
+
+// globalGitConfigFiles retorna os arquivos de configuração do sistema e do
+// usuário, na ordem em que o git os aplica
+func globalGitConfigFiles() []string {
+	var files []string
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		files = append(files, "/etc/gitconfig")
+	}
+	if global, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		return append(files, global)
+	}
+	files = append(files, userGitFile("config"))
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".gitconfig"))
+	}
+	return files
+}
+
+// userGitFile retorna o caminho de um arquivo do git do usuário em
+// $XDG_CONFIG_HOME/git (ou ~/.config/git); vazio se não houver diretório pessoal
+func userGitFile(name string) string {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "git", name)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "git", name)
+	}
+	return ""
+}
+
+// expandHome expande o "~/" inicial dos caminhos da configuração
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// parseGitConfig lê o arquivo de configuração do git. As chaves ficam no formato
+// "seção.subseção.nome", com seção e nome em minúsculas; vale o último valor.
+func parseGitConfig(path string) map[string]string {
+	config := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config
+	}
+
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				continue
+			}
+			header := line[1:end]
+			name, sub, hasSub := strings.Cut(header, " ")
+			section = strings.ToLower(name)
+			if hasSub {
+				section += "." + strings.Trim(strings.TrimSpace(sub), `"`)
+			}
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !hasValue {
+			value = "true"
+		}
+		config[section+"."+key] = parseGitConfigValue(value)
+	}
+	return config
+}
+
+// parseGitConfigValue remove aspas, escapes e comentários de um valor
+func parseGitConfigValue(value string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(value[i])
+			}
+		case (c == '#' || c == ';') && !quoted:
+			return strings.TrimSpace(b.String())
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// configBool interpreta uma opção booleana da configuração
+func (r *GitRepo) configBool(key string, def bool) bool {
+	value, ok := r.config[key]
+	if !ok {
+		return def
+	}
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	case "false", "no", "off", "0", "":
+		return false
+	}
+	return def
+}
+
+// newHash cria o hash usado pelo repositório (SHA-1 ou SHA-256)
+func (r *GitRepo) newHash() hash.Hash {
+	if r.hashSize == sha256.Size {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+// hashObject calcula o identificador de um objeto com o conteúdo
+func (r *GitRepo) hashObject(kind string, data []byte) []byte {
+	h := r.newHash()
+	fmt.Fprintf(h, "%s %d\x00", kind, len(data))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Head retorna o branch atual e o hash do commit do HEAD. Com o HEAD destacado o
+// branch é vazio; num repositório sem commits o hash é nil.
+func (r *GitRepo) Head() (branch string, head []byte, err error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return "", nil, err
+	}
+	content := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(content, "ref:"); ok {
+		ref = strings.TrimSpace(ref)
+		branch = strings.TrimPrefix(ref, "refs/heads/")
+		head, err = r.resolveRef(ref)
+		if errors.Is(err, os.ErrNotExist) {
+			return branch, nil, nil // Branch ainda sem commits
+		}
+		return branch, head, err
+	}
+	head, err = hex.DecodeString(content)
+	return "", head, err
+}
+
+// resolveRef retorna o hash para o qual a referência aponta, seguindo
+// referências simbólicas
+func (r *GitRepo) resolveRef(ref string) ([]byte, error) {
+	for depth := 0; depth < 5; depth++ {
+		content, err := r.readRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		target, symbolic := strings.CutPrefix(content, "ref:")
+		if !symbolic {
+			return hex.DecodeString(content)
+		}
+		ref = strings.TrimSpace(target)
+	}
+	return nil, fmt.Errorf("referência circular: %s", ref)
+}
+
+// readRef lê o conteúdo de uma referência solta ou de packed-refs
+func (r *GitRepo) readRef(ref string) (string, error) {
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if ok && name == ref && !strings.HasPrefix(hash, "#") {
+				return hash, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s: %w", ref, os.ErrNotExist)
+}
+
+// upstream retorna a referência remota seguida pelo branch (ex.: refs/remotes/origin/main)
+// e o nome curto para exibição (origin/main)
+func (r *GitRepo) upstream(branch string) (ref, name string) {
+	remote := r.config["branch."+branch+".remote"]
+	merge := r.config["branch."+branch+".merge"]
+	if remote == "" || merge == "" {
+		return "", ""
+	}
+	short := strings.TrimPrefix(merge, "refs/heads/")
+	if remote == "." {
+		return merge, short
+	}
+	return "refs/remotes/" + remote + "/" + short, remote + "/" + short
+}
+
+// objectDirs retorna o diretório de objetos e os alternativos (objects/info/alternates)
+func (r *GitRepo) objectDirs() []string {
+	r.loadPacks()
+	return r.objDirs
+}
+
+// loadPacks carrega os índices dos pacotes na primeira leitura de objeto
+func (r *GitRepo) loadPacks() {
+	r.packsOnce.Do(func() {
+		main := filepath.Join(r.commonDir, "objects")
+		r.objDirs = []string{main}
+		if data, err := os.ReadFile(filepath.Join(main, "info", "alternates")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				if !filepath.IsAbs(line) {
+					line = filepath.Join(main, line)
+				}
+				r.objDirs = append(r.objDirs, filepath.Clean(line))
+			}
+		}
+
+		for _, dir := range r.objDirs {
+			idxFiles, _ := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+			sort.Strings(idxFiles)
+			for _, idxFile := range idxFiles {
+				if pack, err := r.openPackIndex(idxFile); err == nil {
+					r.packs = append(r.packs, pack)
+				}
+			}
+		}
+	})
+}
+
+// openPackIndex lê o índice (.idx versão 2) de um pacote
+func (r *GitRepo) openPackIndex(idxFile string) (*gitPack, error) {
+	data, err := os.ReadFile(idxFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("índice de pacote não suportado: %s", idxFile)
+	}
+	fanout := data[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	if len(data) < 8+256*4+count*(r.hashSize+8) {
+		return nil, fmt.Errorf("índice de pacote truncado: %s", idxFile)
+	}
+	return &gitPack{
+		path:   strings.TrimSuffix(idxFile, ".idx") + ".pack",
+		idx:    data,
+		count:  count,
+		fanout: fanout,
+	}, nil
+}
+
+// find procura o objeto no índice do pacote e retorna sua posição no .pack
+func (p *gitPack) find(id []byte, hashSize int) (int64, bool) {
+	lo := 0
+	if id[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(p.fanout[(int(id[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(p.fanout[int(id[0])*4:]))
+
+	names := p.idx[8+256*4:]
+	i := lo + sort.Search(hi-lo, func(k int) bool {
+		return bytes.Compare(names[(lo+k)*hashSize:(lo+k+1)*hashSize], id) >= 0
+	})
+	if i >= hi || !bytes.Equal(names[i*hashSize:(i+1)*hashSize], id) {
+		return 0, false
+	}
+
+	// Tabela de posições após os nomes e os CRCs; posições acima de 2 GB ficam na tabela de 64 bits
+	offsets := names[p.count*(hashSize+4):]
+	offset := binary.BigEndian.Uint32(offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	large := offsets[p.count*4:]
+	pos := int(offset&0x7fffffff) * 8
+	if pos+8 > len(large) {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(large[pos:])), true
+}
+
+// readObject lê um objeto (solto ou empacotado) e retorna seu tipo e conteúdo
+func (r *GitRepo) readObject(id []byte) (int, []byte, error) {
+	name := hex.EncodeToString(id)
+	for _, dir := range r.objectDirs() {
+		kind, data, err := readLooseObject(filepath.Join(dir, name[:2], name[2:]))
+		if err == nil {
+			return kind, data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return 0, nil, err
+		}
+	}
+
+	for _, pack := range r.packs {
+		if offset, ok := pack.find(id, r.hashSize); ok {
+			f, err := os.Open(pack.path)
+			if err != nil {
+				return 0, nil, err
+			}
+			kind, data, err := r.readPackedObject(f, offset, 0)
+			f.Close()
+			return kind, data, err
+		}
+	}
+	return 0, nil, fmt.Errorf("%s: %w", name, errGitObjectNotFound)
+}
+
+// readLooseObject lê um objeto solto (objects/xx/yyyy, comprimido com zlib)
+func readLooseObject(path string) (int, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	kindName, _, _ := strings.Cut(string(header), " ")
+	kind := map[string]int{"commit": gitObjCommit, "tree": gitObjTree, "blob": gitObjBlob, "tag": gitObjTag}[kindName]
+	if !ok || kind == 0 {
+		return 0, nil, fmt.Errorf("objeto git inválido: %s", path)
+	}
+	return kind, content, nil
+}
+
+// readPackedObject lê o objeto na posição do pacote, aplicando os deltas
+func (r *GitRepo) readPackedObject(f *os.File, offset int64, depth int) (int, []byte, error) {
+	if depth > 64 {
+		return 0, nil, errors.New("cadeia de deltas muito longa")
+	}
+
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(c>>4) & 7
+	size := int64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(c&0x7f) << shift
+	}
+
+	var baseKind int
+	var base []byte
+	switch kind {
+	case gitObjOfs:
+		rel, err := readGitOffset(br)
+		if err != nil {
+			return 0, nil, err
+		}
+		if baseKind, base, err = r.readPackedObject(f, offset-rel, depth+1); err != nil {
+			return 0, nil, err
+		}
+	case gitObjRef:
+		id := make([]byte, r.hashSize)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return 0, nil, err
+		}
+		if baseKind, base, err = r.readObject(id); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, err
+	}
+
+	if base == nil {
+		return kind, data, nil
+	}
+	result, err := applyGitDelta(base, data)
+	return baseKind, result, err
+}
+
+// readGitOffset lê a distância até o objeto base de um delta (OFS_DELTA)
+func readGitOffset(br io.ByteReader) (int64, error) {
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	value := int64(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, err
+		}
+		value = (value+1)<<7 | int64(c&0x7f)
+	}
+	return value, nil
+}
+
+// applyGitDelta reconstrói um objeto a partir da base e das instruções do delta
+func applyGitDelta(base, delta []byte) ([]byte, error) {
+	errDelta := errors.New("delta git inválido")
+	pos := 0
+	readSize := func() (int, bool) {
+		size, shift := 0, 0
+		for pos < len(delta) {
+			c := delta[pos]
+			pos++
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+		return 0, false
+	}
+
+	srcSize, ok1 := readSize()
+	dstSize, ok2 := readSize()
+	if !ok1 || !ok2 || srcSize != len(base) {
+		return nil, errDelta
+	}
+
+	result := make([]byte, 0, dstSize)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+		switch {
+		case op&0x80 != 0:
+			// Copiar um trecho da base: bits 0-3 indicam os bytes da posição, 4-6 os do tamanho
+			var offset, size int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if pos >= len(delta) {
+					return nil, errDelta
+				}
+				if i < 4 {
+					offset |= int(delta[pos]) << (8 * i)
+				} else {
+					size |= int(delta[pos]) << (8 * (i - 4))
+				}
+				pos++
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errDelta
+			}
+			result = append(result, base[offset:offset+size]...)
+		case op > 0:
+			// Inserir os próximos op bytes
+			if pos+int(op) > len(delta) {
+				return nil, errDelta
+			}
+			result = append(result, delta[pos:pos+int(op)]...)
+			pos += int(op)
+		default:
+			return nil, errDelta
+		}
+	}
+	if len(result) != dstSize {
+		return nil, errDelta
+	}
+	return result, nil
+}
+
+// readCommit lê os campos de um commit
+func (r *GitRepo) readCommit(id []byte) (*gitCommit, error) {
+	kind, data, err := r.readObject(id)
+	if err != nil {
+		return nil, err
+	}
+	if kind == gitObjTag {
+		// Tags anotadas apontam para o commit na linha "object"
+		for _, line := range strings.Split(string(data), "\n") {
+			if target, ok := strings.CutPrefix(line, "object "); ok {
+				if id, err = hex.DecodeString(target); err != nil {
+					return nil, err
+				}
+				return r.readCommit(id)
+			}
+		}
+	}
+	if kind != gitObjCommit {
+		return nil, fmt.Errorf("%x não é um commit", id)
+	}
+
+	commit := &gitCommit{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break // Fim do cabeçalho
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			commit.tree, err = hex.DecodeString(value)
+		case "parent":
+			var parent []byte
+			if parent, err = hex.DecodeString(value); err == nil {
+				commit.parents = append(commit.parents, parent)
+			}
+		case "committer":
+			// "Nome <email> 1700000000 -0300"
+			fields := strings.Fields(value)
+			if len(fields) >= 2 {
+				commit.time, _ = strconv.ParseInt(fields[len(fields)-2], 10, 64)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return commit, nil
+}
+
+// readTree lê todos os arquivos da árvore (recursivamente), indexados pelo caminho relativo com "/"
+func (r *GitRepo) readTree(id []byte, prefix string, entries map[string]gitTreeEntry) error {
+	kind, data, err := r.readObject(id)
+	if err != nil {
+		return err
+	}
+	if kind != gitObjTree {
+		return fmt.Errorf("%x não é uma árvore", id)
+	}
+
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < r.hashSize {
+			return fmt.Errorf("árvore %x corrompida", id)
+		}
+		modeText, name, _ := strings.Cut(string(header), " ")
+		mode, _ := strconv.ParseUint(modeText, 8, 32)
+		hash := rest[:r.hashSize]
+		data = rest[r.hashSize:]
+
+		path := prefix + name
+		if mode == 040000 {
+			if err := r.readTree(hash, path+"/", entries); err != nil {
+				return err
+			}
+			continue
+		}
+		entries[path] = gitTreeEntry{mode: uint32(mode), hash: hash}
+	}
+	return nil
+}
+
+// headTree retorna os arquivos do commit do HEAD; vazio se ainda não houver commits
+func (r *GitRepo) headTree() (map[string]gitTreeEntry, error) {
+	entries := make(map[string]gitTreeEntry)
+	_, head, err := r.Head()
+	if err != nil || head == nil {
+		return entries, err
+	}
+	commit, err := r.readCommit(head)
+	if err != nil {
+		return nil, err
+	}
+	return entries, r.readTree(commit.tree, "", entries)
+}
+
+// HeadFile retorna o conteúdo do arquivo no commit do HEAD; false se o arquivo
+// não existir no HEAD
+func (r *GitRepo) HeadFile(path string) ([]byte, bool, error) {
+	rel, err := r.relPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	tree, err := r.headTree()
+	if err != nil {
+		return nil, false, err
+	}
+	entry, ok := tree[rel]
+	if !ok {
+		return nil, false, nil
+	}
+	_, data, err := r.readObject(entry.hash)
+	return data, err == nil, err
+}
+
+// relPath converte um caminho absoluto em relativo à raiz, com "/"
+func (r *GitRepo) relPath(path string) (string, error) {
+	rel, err := filepath.Rel(r.Root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s está fora do repositório %s", path, r.Root)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// aheadBehind conta os commits que só existem em local (ahead) e só em
+// upstream (behind). Percorre os dois históricos do mais recente para o mais
+// antigo e para quando só restam commits comuns, como o git rev-list --count.
+func (r *GitRepo) aheadBehind(local, upstream []byte) (ahead, behind int, err error) {
+	const (
+		fromLocal    = 1
+		fromUpstream = 2
+		both         = fromLocal | fromUpstream
+		limit        = 100000 // Proteção para históricos enormes
+	)
+
+	flags := make(map[string]int)
+	queue := &gitCommitQueue{}
+	pending := 0 // Commits na fila que ainda não são comuns aos dois lados
+
+	push := func(id []byte, f int) error {
+		key := string(id)
+		old, seen := flags[key]
+		if seen {
+			if old != both && old|f == both && queue.contains(key) {
+				pending--
+			}
+			flags[key] = old | f
+			return nil
+		}
+		commit, err := r.readCommit(id)
+		if err != nil {
+			return err
+		}
+		flags[key] = f
+		if f != both {
+			pending++
+		}
+		heap.Push(queue, gitQueuedCommit{id: key, commit: commit})
+		return nil
+	}
+	if err := push(local, fromLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := push(upstream, fromUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	for steps := 0; pending > 0 && queue.Len() > 0 && steps < limit; steps++ {
+		q := heap.Pop(queue).(gitQueuedCommit)
+		f := flags[q.id]
+		switch f {
+		case fromLocal:
+			ahead++
+			pending--
+		case fromUpstream:
+			behind++
+			pending--
+		}
+		for _, parent := range q.commit.parents {
+			if err := push(parent, f); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return ahead, behind, nil
+}
+
+// gitQueuedCommit é um commit na fila do aheadBehind
+type gitQueuedCommit struct {
+	id     string
+	commit *gitCommit
+}
+
+// gitCommitQueue é uma fila de commits ordenada do mais recente para o mais antigo
+type gitCommitQueue struct {
+	items []gitQueuedCommit
+	ids   map[string]bool
+}
+
+func (q *gitCommitQueue) Len() int           { return len(q.items) }
+func (q *gitCommitQueue) Less(i, j int) bool { return q.items[i].commit.time > q.items[j].commit.time }
+func (q *gitCommitQueue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *gitCommitQueue) Push(x any) {
+	item := x.(gitQueuedCommit)
+	if q.ids == nil {
+		q.ids = make(map[string]bool)
+	}
+	q.ids[item.id] = true
+	q.items = append(q.items, item)
+}
+
+func (q *gitCommitQueue) Pop() any {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	delete(q.ids, item.id)
+	return item
+}
+
+// contains indica se o commit ainda está na fila
+func (q *gitCommitQueue) contains(id string) bool {
+	return q.ids[id]
+}
+
+// writeObject grava um objeto solto, se ainda não existir, e retorna seu hash
+func (r *GitRepo) writeObject(kind string, data []byte) ([]byte, error) {
+	id := r.hashObject(kind, data)
+	name := hex.EncodeToString(id)
+	dir := filepath.Join(r.commonDir, "objects", name[:2])
+	path := filepath.Join(dir, name[2:])
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, "tmp_obj_")
+	if err != nil {
+		return nil, err
+	}
+	zw := zlib.NewWriter(tmp)
+	fmt.Fprintf(zw, "%s %d\x00", kind, len(data))
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	tmp.Close()
+	os.Chmod(tmp.Name(), 0444)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return id, nil
+}
+
+// indexPath retorna o caminho do índice (área de preparação)
+func (r *GitRepo) indexPath() string {
+	return filepath.Join(r.gitDir, "index")
+}
+
+// indexModTime retorna a data de modificação do índice; zero se não existir
+func (r *GitRepo) indexModTime() time.Time {
+	info, err := os.Stat(r.indexPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
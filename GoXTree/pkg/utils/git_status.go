@@ -0,0 +1,602 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitFlags resume a situação de um item ou do conteúdo de um diretório
+type GitFlags uint8
+
+const (
+	GitStaged     GitFlags = 1 << iota // Alterações na área de preparação
+	GitModified                        // Alterações ainda não preparadas
+	GitUntracked                       // Não rastreado
+	GitConflicted                      // Conflito de merge
+	GitIgnored                         // Ignorado (.gitignore)
+)
+
+// Markers retorna os marcadores exibidos na árvore e nos diretórios da lista:
+// ✗ conflito, ● alterado, ✚ preparado e ? não rastreado
+func (f GitFlags) Markers() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		flag   GitFlags
+		marker string
+	}{
+		{GitConflicted, "✗"},
+		{GitModified, "●"},
+		{GitStaged, "✚"},
+		{GitUntracked, "?"},
+	} {
+		if f&m.flag != 0 {
+			b.WriteString(m.marker)
+		}
+	}
+	return b.String()
+}
+
+// GitFileStatus é a situação de um item no git, com os códigos do
+// "git status --short": Index (X) e Worktree (Y) valem ' ', 'M', 'A', 'D' ou
+// 'U'; "??" é não rastreado e "!!" ignorado. Em diretórios, Dir resume o conteúdo.
+type GitFileStatus struct {
+	Index    byte
+	Worktree byte
+	Dir      GitFlags
+}
+
+// Flags retorna o resumo da situação
+func (s GitFileStatus) Flags() GitFlags {
+	var flags GitFlags
+	switch {
+	case s.Index == 'U' || s.Worktree == 'U':
+		flags = GitConflicted
+	case s.Index == '?':
+		flags = GitUntracked
+	case s.Index == '!':
+		flags = GitIgnored
+	default:
+		if s.Index == 'M' || s.Index == 'A' || s.Index == 'D' {
+			flags |= GitStaged
+		}
+		if s.Worktree == 'M' || s.Worktree == 'D' || s.Worktree == 'A' {
+			flags |= GitModified
+		}
+	}
+	return flags | s.Dir
+}
+
+// Label retorna o texto da coluna: o código XY em arquivos e os marcadores em
+// diretórios; vazio para itens sem alterações
+func (s GitFileStatus) Label() string {
+	if s.Index == '?' || s.Index == '!' || s.Dir == 0 {
+		code := string([]byte{max(s.Index, ' '), max(s.Worktree, ' ')})
+		if strings.TrimSpace(code) == "" {
+			return ""
+		}
+		return code
+	}
+	return s.Dir.Markers()
+}
+
+// GitStatus é a situação do diretório de trabalho de um repositório
+type GitStatus struct {
+	Root     string // Diretório de trabalho
+	Branch   string // Branch atual; vazio com o HEAD destacado
+	Head     string // Hash abreviado do HEAD; vazio antes do primeiro commit
+	Upstream string // Branch remoto seguido (ex.: origin/main); vazio se não houver
+	Ahead    int    // Commits locais que não estão no upstream
+	Behind   int    // Commits do upstream que não estão no branch local
+
+	files   map[string]GitFileStatus // Itens alterados, não rastreados ou ignorados (caminho relativo com "/")
+	dirs    map[string]GitFlags      // Resumo do conteúdo de cada diretório
+	tracked map[string]bool          // Diretórios com arquivos rastreados
+	ignored map[string]bool          // Diretórios ignorados (o conteúdo não é percorrido)
+}
+
+// Lookup retorna a situação de um item do repositório pelo caminho absoluto
+func (s *GitStatus) Lookup(path string, isDir bool) GitFileStatus {
+	rel, err := filepath.Rel(s.Root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return GitFileStatus{}
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+
+	if status, ok := s.files[rel]; ok {
+		return status
+	}
+	// Dentro de um diretório ignorado tudo é ignorado
+	for dir := rel; dir != "" && dir != "."; dir = pathDir(dir) {
+		if s.ignored[dir] {
+			return GitFileStatus{Index: '!', Worktree: '!'}
+		}
+	}
+	if !isDir {
+		return GitFileStatus{}
+	}
+
+	flags := s.dirs[rel]
+	if flags == GitUntracked && !s.tracked[rel] && rel != "" {
+		return GitFileStatus{Index: '?', Worktree: '?', Dir: flags}
+	}
+	return GitFileStatus{Dir: flags}
+}
+
+// Summary retorna o branch e a distância do upstream (ex.: "main ↑2 ↓1")
+func (s *GitStatus) Summary() string {
+	summary := s.Branch
+	if summary == "" {
+		summary = "(" + s.Head + ")"
+	}
+	if s.Ahead > 0 {
+		summary += " ↑" + strconv.Itoa(s.Ahead)
+	}
+	if s.Behind > 0 {
+		summary += " ↓" + strconv.Itoa(s.Behind)
+	}
+	return summary
+}
+
+// pathDir retorna o diretório pai de um caminho relativo com "/"; "" na raiz
+func pathDir(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// Status lê o índice, o HEAD e o diretório de trabalho e calcula a situação de
+// cada item, como o git status
+func (r *GitRepo) Status() (*GitStatus, error) {
+	status := &GitStatus{
+		Root:    r.Root,
+		files:   make(map[string]GitFileStatus),
+		dirs:    make(map[string]GitFlags),
+		tracked: make(map[string]bool),
+		ignored: make(map[string]bool),
+	}
+
+	// Branch, upstream e distância
+	branch, head, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	status.Branch = branch
+	if head != nil {
+		status.Head = hex.EncodeToString(head)[:7]
+	}
+	if ref, name := r.upstream(branch); ref != "" && head != nil {
+		if upstream, err := r.resolveRef(ref); err == nil {
+			status.Upstream = name
+			status.Ahead, status.Behind, _ = r.aheadBehind(head, upstream)
+		}
+	}
+
+	index, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := r.headTree()
+	if err != nil {
+		return nil, err
+	}
+	indexTime := r.indexModTime()
+	trustMode := r.configBool("core.filemode", true)
+
+	inIndex := make(map[string]bool, len(index.entries))
+	for _, entry := range index.entries {
+		inIndex[entry.path] = true
+		for dir := pathDir(entry.path); dir != ""; dir = pathDir(dir) {
+			if status.tracked[dir] {
+				break
+			}
+			status.tracked[dir] = true
+		}
+		status.tracked[""] = true
+
+		if entry.stage() > 0 {
+			status.files[entry.path] = GitFileStatus{Index: 'U', Worktree: 'U'}
+			continue
+		}
+
+		// Índice em relação ao HEAD
+		var x, y byte = ' ', ' '
+		if headEntry, ok := tree[entry.path]; !ok {
+			if entry.extFlags&gitIndexIntentToAdd == 0 {
+				x = 'A'
+			}
+		} else if !bytes.Equal(headEntry.hash, entry.hash) || headEntry.mode != entry.mode {
+			x = 'M'
+		}
+
+		// Diretório de trabalho em relação ao índice
+		if entry.extFlags&gitIndexIntentToAdd != 0 {
+			y = 'A'
+		} else if entry.extFlags&gitIndexSkipWorktree == 0 && entry.mode != 0160000 {
+			y = r.worktreeChange(entry, indexTime, trustMode)
+		}
+
+		if x != ' ' || y != ' ' {
+			status.files[entry.path] = GitFileStatus{Index: x, Worktree: y}
+		}
+	}
+
+	// Removidos do índice, mas presentes no HEAD
+	for path := range tree {
+		if !inIndex[path] {
+			status.files[path] = GitFileStatus{Index: 'D', Worktree: ' '}
+		}
+	}
+
+	// Itens não rastreados e ignorados
+	ignore := r.newIgnoreMatcher()
+	r.walkUntracked(status, ignore, r.Root, "", inIndex, false)
+
+	// Resumo dos diretórios
+	for path, s := range status.files {
+		flags := s.Flags() &^ GitIgnored
+		if flags == 0 {
+			continue
+		}
+		for dir := pathDir(path); ; dir = pathDir(dir) {
+			status.dirs[dir] |= flags
+			if dir == "" {
+				break
+			}
+		}
+	}
+	return status, nil
+}
+
+// worktreeChange compara o arquivo do diretório de trabalho com a entrada do
+// índice e retorna ' ', 'M' ou 'D'
+func (r *GitRepo) worktreeChange(entry *gitIndexEntry, indexTime time.Time, trustMode bool) byte {
+	path := filepath.Join(r.Root, filepath.FromSlash(entry.path))
+	info, err := os.Lstat(path)
+	if err != nil || info.IsDir() {
+		return 'D'
+	}
+
+	mode := gitFileMode(info)
+	if mode == 0 {
+		return 'M'
+	}
+	if mode != entry.mode && (trustMode || mode == 0120000 || entry.mode == 0120000) {
+		return 'M'
+	}
+	if statMatches(entry, info, indexTime) {
+		return ' '
+	}
+	// Tamanho zero no índice pode ser só a falta dos dados do arquivo (entradas
+	// restauradas do HEAD); nesse caso o conteúdo decide, como no git
+	if entry.size != 0 && int64(entry.size) != info.Size()&0xffffffff {
+		return 'M'
+	}
+
+	data, err := readWorktreeBlob(path, info)
+	if err != nil || !bytes.Equal(r.hashObject("blob", data), entry.hash) {
+		return 'M'
+	}
+	return ' '
+}
+
+// walkUntracked percorre o diretório de trabalho procurando itens que não estão
+// no índice. Diretórios ignorados sem arquivos rastreados não são percorridos.
+func (r *GitRepo) walkUntracked(status *GitStatus, ignore *IgnoreMatcher, dir, rel string, inIndex map[string]bool, ignored bool) {
+	ignore.Enter(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == ".git" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		childRel := name
+		if rel != "" {
+			childRel = rel + "/" + name
+		}
+		if inIndex[childRel] {
+			continue
+		}
+
+		isDir := entry.IsDir()
+		childIgnored := ignored || ignore.Ignored(path, isDir)
+		if isDir {
+			switch {
+			case status.tracked[childRel]:
+				r.walkUntracked(status, ignore, path, childRel, inIndex, childIgnored)
+			case childIgnored:
+				status.ignored[childRel] = true
+			case isNestedRepo(path):
+				// Outro repositório dentro deste: aparece como um item não rastreado
+				status.files[childRel] = GitFileStatus{Index: '?', Worktree: '?'}
+			default:
+				r.walkUntracked(status, ignore, path, childRel, inIndex, false)
+			}
+			continue
+		}
+
+		if childIgnored {
+			status.files[childRel] = GitFileStatus{Index: '!', Worktree: '!'}
+		} else {
+			status.files[childRel] = GitFileStatus{Index: '?', Worktree: '?'}
+		}
+	}
+}
+
+// isNestedRepo indica se o diretório é a raiz de outro repositório
+func isNestedRepo(dir string) bool {
+	_, ok := findGitDir(dir)
+	return ok
+}
+
+// newIgnoreMatcher cria o verificador de exclusões com as regras do git: os
+// .gitignore, .git/info/exclude e o arquivo global (core.excludesFile)
+func (r *GitRepo) newIgnoreMatcher() *IgnoreMatcher {
+	m := NewIgnoreMatcher(Local)
+	m.fileNames = []string{".gitignore"}
+
+	var rules []ignoreRule
+	global := expandHome(r.config["core.excludesfile"])
+	if global == "" {
+		global = userGitFile("ignore")
+	}
+	for _, file := range []string{global, filepath.Join(r.commonDir, "info", "exclude")} {
+		if data, err := os.ReadFile(file); err == nil && file != "" {
+			rules = append(rules, parseIgnoreRules(r.Root, data)...)
+		}
+	}
+	m.rules[filepath.Dir(r.Root)] = rules
+	return m
+}
+
+// Stage prepara para o commit as alterações dos caminhos (arquivos ou
+// diretórios), como o git add: arquivos novos e alterados entram no índice,
+// arquivos apagados saem e conflitos são marcados como resolvidos
+func (r *GitRepo) Stage(paths []string) error {
+	prefixes, err := r.relPaths(paths)
+	if err != nil {
+		return err
+	}
+	status, err := r.Status()
+	if err != nil {
+		return err
+	}
+	index, err := r.readIndex()
+	if err != nil {
+		return err
+	}
+
+	// Conversões de fim de linha e filtros (LFS, por exemplo) mudam o conteúdo
+	// gravado no índice; nesse caso quem prepara é o próprio git
+	if r.usesAttributes(index, status) {
+		// O git add recusa itens ignorados informados explicitamente
+		var wanted []string
+		for _, prefix := range prefixes {
+			if !status.ignored[prefix] && status.files[prefix].Index != '!' {
+				wanted = append(wanted, prefix)
+			}
+		}
+		if len(wanted) == 0 {
+			return nil
+		}
+		return r.stageWithGit(wanted)
+	}
+
+	byPath := make(map[string][]*gitIndexEntry)
+	for _, entry := range index.entries {
+		byPath[entry.path] = append(byPath[entry.path], entry)
+	}
+
+	changed := false
+	for path, s := range status.files {
+		if !matchesAnyPrefix(path, prefixes) || s.Flags()&(GitModified|GitUntracked|GitConflicted) == 0 {
+			continue
+		}
+
+		full := filepath.Join(r.Root, filepath.FromSlash(path))
+		info, err := os.Lstat(full)
+		if err != nil {
+			// Apagado no diretório de trabalho: sai do índice
+			delete(byPath, path)
+			changed = true
+			continue
+		}
+		mode := gitFileMode(info)
+		if mode == 0 {
+			continue // Diretórios (outros repositórios) e arquivos especiais
+		}
+		data, err := readWorktreeBlob(full, info)
+		if err != nil {
+			return err
+		}
+		id, err := r.writeObject("blob", data)
+		if err != nil {
+			return err
+		}
+		if !r.configBool("core.filemode", true) && len(byPath[path]) > 0 && mode != 0120000 {
+			mode = byPath[path][0].mode // Sem core.filemode o bit de execução não muda
+		}
+		byPath[path] = []*gitIndexEntry{newIndexEntry(path, id, mode, info)}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	index.entries = index.entries[:0]
+	for _, entries := range byPath {
+		index.entries = append(index.entries, entries...)
+	}
+	return r.writeIndex(index)
+}
+
+// usesAttributes indica se o conteúdo preparado pode diferir do arquivo em
+// disco: core.autocrlf ligado ou algum arquivo de atributos (.gitattributes,
+// info/attributes ou core.attributesFile), onde ficam eol, text e filter
+func (r *GitRepo) usesAttributes(index *gitIndex, status *GitStatus) bool {
+	switch strings.ToLower(r.config["core.autocrlf"]) {
+	case "true", "yes", "on", "1", "input":
+		return true
+	}
+
+	files := []string{filepath.Join(r.commonDir, "info", "attributes")}
+	if global := expandHome(r.config["core.attributesfile"]); global != "" {
+		files = append(files, global)
+	} else {
+		files = append(files, userGitFile("attributes"))
+	}
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.Size() > 0 {
+			return true
+		}
+	}
+
+	isAttributes := func(path string) bool {
+		return path == ".gitattributes" || strings.HasSuffix(path, "/.gitattributes")
+	}
+	for _, entry := range index.entries {
+		if isAttributes(entry.path) {
+			return true
+		}
+	}
+	for path := range status.files {
+		if isAttributes(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// stageWithGit prepara os caminhos com o executável git (git add -A)
+func (r *GitRepo) stageWithGit(prefixes []string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("o repositório usa .gitattributes, core.autocrlf ou filtros; instale o git para preparar arquivos")
+	}
+
+	args := []string{"add", "-A", "--"}
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			args = append(args, ":(top)")
+		} else {
+			args = append(args, ":(top,literal)"+prefix)
+		}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Unstage tira da área de preparação as alterações dos caminhos, como o
+// git restore --staged: o índice volta a ter o conteúdo do HEAD
+func (r *GitRepo) Unstage(paths []string) error {
+	prefixes, err := r.relPaths(paths)
+	if err != nil {
+		return err
+	}
+	index, err := r.readIndex()
+	if err != nil {
+		return err
+	}
+	tree, err := r.headTree()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	var entries []*gitIndexEntry
+	restored := make(map[string]bool)
+	for _, entry := range index.entries {
+		if !matchesAnyPrefix(entry.path, prefixes) {
+			entries = append(entries, entry)
+			continue
+		}
+		headEntry, inHead := tree[entry.path]
+		if inHead && entry.stage() == 0 && bytes.Equal(headEntry.hash, entry.hash) && headEntry.mode == entry.mode {
+			entries = append(entries, entry)
+			restored[entry.path] = true
+			continue
+		}
+
+		// Diferente do HEAD: volta ao conteúdo do HEAD ou sai do índice
+		changed = true
+		if inHead && !restored[entry.path] {
+			entries = append(entries, r.restoredEntry(entry.path, headEntry))
+			restored[entry.path] = true
+		}
+	}
+
+	// Remoções preparadas: o arquivo volta ao índice
+	for path, headEntry := range tree {
+		if matchesAnyPrefix(path, prefixes) && !restored[path] {
+			entries = append(entries, r.restoredEntry(path, headEntry))
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	index.entries = entries
+	return r.writeIndex(index)
+}
+
+// restoredEntry cria a entrada do índice com o conteúdo do HEAD. Se o arquivo em
+// disco for igual ao do HEAD, a entrada recebe os dados dele (tamanho e datas)
+// para que não precise ser relido.
+func (r *GitRepo) restoredEntry(path string, headEntry gitTreeEntry) *gitIndexEntry {
+	entry := &gitIndexEntry{path: path, hash: headEntry.hash, mode: headEntry.mode}
+	full := filepath.Join(r.Root, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if err != nil || gitFileMode(info) != headEntry.mode {
+		return entry
+	}
+	if data, err := readWorktreeBlob(full, info); err == nil && bytes.Equal(r.hashObject("blob", data), headEntry.hash) {
+		return newIndexEntry(path, headEntry.hash, headEntry.mode, info)
+	}
+	return entry
+}
+
+// relPaths converte os caminhos absolutos em prefixos relativos à raiz
+func (r *GitRepo) relPaths(paths []string) ([]string, error) {
+	var prefixes []string
+	for _, path := range paths {
+		rel, err := r.relPath(path)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, rel)
+	}
+	if len(prefixes) == 0 {
+		return nil, errors.New("nenhum caminho informado")
+	}
+	return prefixes, nil
+}
+
+// matchesAnyPrefix indica se o caminho está em algum dos prefixos
+func matchesAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if hasPathPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,368 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// hexSearchChunk é o tamanho dos blocos lidos nas buscas do HexFile
+const hexSearchChunk = 1024 * 1024
+
+// hexEdit é uma alteração desfazível: o byte anterior na posição
+type hexEdit struct {
+	offset   int64
+	previous byte
+	edited   bool // A posição já estava alterada antes
+}
+
+// HexFile é um arquivo aberto para edição binária por sobrescrita: o conteúdo é
+// lido sob demanda e as alterações ficam em memória até Save.
+type HexFile struct {
+	path  string
+	file  *os.File
+	size  int64
+	edits map[int64]byte
+	undo  []hexEdit
+}
+
+// OpenHexFile abre o arquivo para edição binária
+func OpenHexFile(path string) (*HexFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, fmt.Errorf("não é um arquivo regular")
+	}
+	return &HexFile{path: path, file: file, size: info.Size(), edits: make(map[int64]byte)}, nil
+}
+
+// Close fecha o arquivo (alterações não salvas são descartadas)
+func (h *HexFile) Close() error {
+	return h.file.Close()
+}
+
+// Path retorna o caminho do arquivo
+func (h *HexFile) Path() string {
+	return h.path
+}
+
+// Size retorna o tamanho do arquivo
+func (h *HexFile) Size() int64 {
+	return h.size
+}
+
+// Modified indica se há alterações não salvas
+func (h *HexFile) Modified() bool {
+	return len(h.edits) > 0
+}
+
+// IsModified indica se o byte na posição foi alterado
+func (h *HexFile) IsModified(offset int64) bool {
+	_, ok := h.edits[offset]
+	return ok
+}
+
+// ReadAt lê o conteúdo atual (com as alterações) a partir de offset
+func (h *HexFile) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= h.size {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > h.size-offset {
+		p = p[:h.size-offset]
+	}
+	n, err := h.file.ReadAt(p, offset)
+	if err == io.EOF && n == len(p) {
+		err = nil
+	}
+	for i := range p[:n] {
+		if b, ok := h.edits[offset+int64(i)]; ok {
+			p[i] = b
+		}
+	}
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// ByteAt retorna o byte atual na posição
+func (h *HexFile) ByteAt(offset int64) (byte, bool) {
+	var b [1]byte
+	if n, _ := h.ReadAt(b[:], offset); n == 0 {
+		return 0, false
+	}
+	return b[0], true
+}
+
+// Set sobrescreve o byte na posição
+func (h *HexFile) Set(offset int64, b byte) error {
+	current, ok := h.ByteAt(offset)
+	if !ok {
+		return fmt.Errorf("posição fora do arquivo: %d", offset)
+	}
+	if current == b {
+		return nil
+	}
+	_, edited := h.edits[offset]
+	h.undo = append(h.undo, hexEdit{offset: offset, previous: current, edited: edited})
+	h.setByte(offset, b)
+	return nil
+}
+
+// setByte grava a alteração, descartando-a quando volta ao conteúdo original
+func (h *HexFile) setByte(offset int64, b byte) {
+	var original [1]byte
+	if _, err := h.file.ReadAt(original[:], offset); err == nil && original[0] == b {
+		delete(h.edits, offset)
+		return
+	}
+	h.edits[offset] = b
+}
+
+// Undo desfaz a última alteração e retorna a posição afetada
+func (h *HexFile) Undo() (int64, bool) {
+	if len(h.undo) == 0 {
+		return 0, false
+	}
+	last := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	if last.edited {
+		h.edits[last.offset] = last.previous
+	} else {
+		delete(h.edits, last.offset)
+	}
+	return last.offset, true
+}
+
+// Save grava as alterações no arquivo. Com backup, uma cópia do arquivo original
+// é salva antes em BackupPath.
+func (h *HexFile) Save(backup bool) error {
+	if len(h.edits) == 0 {
+		return nil
+	}
+	if backup {
+		if err := CopyFile(h.path, h.BackupPath()); err != nil {
+			return fmt.Errorf("erro ao criar backup: %w", err)
+		}
+	}
+
+	out, err := os.OpenFile(h.path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	offsets := make([]int64, 0, len(h.edits))
+	for offset := range h.edits {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	for _, offset := range offsets {
+		if _, err := out.WriteAt([]byte{h.edits[offset]}, offset); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	h.edits = make(map[int64]byte)
+	h.undo = nil
+	return nil
+}
+
+// BackupPath retorna o caminho da cópia criada antes de salvar
+func (h *HexFile) BackupPath() string {
+	return h.path + ".bak"
+}
+
+// Find procura o padrão a partir de from (inclusive) para frente, ou antes de
+// from para trás, considerando as alterações não salvas. Retorna -1 quando não
+// encontra. ignoreCase compara letras ASCII sem diferenciar maiúsculas.
+func (h *HexFile) Find(pattern []byte, from int64, backward, ignoreCase bool) (int64, error) {
+	if len(pattern) == 0 {
+		return -1, nil
+	}
+	if ignoreCase {
+		pattern = bytes.ToLower(pattern)
+	}
+	overlap := int64(len(pattern) - 1)
+	buf := make([]byte, hexSearchChunk+overlap)
+
+	search := func(start, end int64, last bool) (int64, error) {
+		n, err := h.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+		data := buf[:n]
+		if ignoreCase {
+			data = bytes.ToLower(data)
+		}
+		var i int
+		if last {
+			i = bytes.LastIndex(data, pattern)
+		} else {
+			i = bytes.Index(data, pattern)
+		}
+		if i < 0 {
+			return -1, nil
+		}
+		return start + int64(i), nil
+	}
+
+	if backward {
+		// Ocorrências que começam antes de from
+		for end := min(from-1+int64(len(pattern)), h.size); end > 0; {
+			start := max(end-int64(len(buf)), 0)
+			if found, err := search(start, end, true); found >= 0 || err != nil {
+				return found, err
+			}
+			if start == 0 {
+				break
+			}
+			end = start + overlap
+		}
+		return -1, nil
+	}
+
+	for start := max(from, 0); start < h.size; start += hexSearchChunk {
+		end := min(start+int64(len(buf)), h.size)
+		if found, err := search(start, end, false); found >= 0 || err != nil {
+			return found, err
+		}
+	}
+	return -1, nil
+}
+
+// ParseHexBytes converte uma sequência hexadecimal ("DE AD be ef", "0xDEADBEEF")
+// em bytes
+func ParseHexBytes(text string) ([]byte, error) {
+	text = strings.NewReplacer(" ", "", "\t", "", ",", "", "0x", "", "0X", "").Replace(text)
+	if text == "" {
+		return nil, fmt.Errorf("sequência vazia")
+	}
+	if len(text)%2 != 0 {
+		return nil, fmt.Errorf("número ímpar de dígitos hexadecimais")
+	}
+	data, err := hex.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("sequência hexadecimal inválida: %s", text)
+	}
+	return data, nil
+}
+
+// ParseOffset interpreta uma posição digitada: decimal ("1024"), hexadecimal
+// ("0x400", "$400" ou "400h"), percentual ("50%") ou relativa à posição atual
+// ("+16", "-0x10"). O resultado é limitado ao arquivo.
+func ParseOffset(text string, current, size int64) (int64, error) {
+	text = strings.TrimSpace(text)
+	invalid := fmt.Errorf("posição inválida: %s", text)
+	if text == "" {
+		return 0, invalid
+	}
+
+	clamp := func(offset int64) int64 {
+		return max(0, min(offset, size-1))
+	}
+
+	if percent, ok := strings.CutSuffix(text, "%"); ok {
+		value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			return 0, invalid
+		}
+		return clamp(int64(float64(size) * value / 100)), nil
+	}
+
+	sign := 0
+	switch text[0] {
+	case '+':
+		sign, text = 1, text[1:]
+	case '-':
+		sign, text = -1, text[1:]
+	}
+
+	base := 10
+	lower := strings.ToLower(text)
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		base, text = 16, text[2:]
+	case strings.HasPrefix(lower, "$"):
+		base, text = 16, text[1:]
+	case strings.HasSuffix(lower, "h"):
+		base, text = 16, text[:len(text)-1]
+	}
+	value, err := strconv.ParseInt(text, base, 64)
+	if err != nil || value < 0 {
+		return 0, invalid
+	}
+	if sign != 0 {
+		value = current + int64(sign)*value
+	}
+	return clamp(value), nil
+}
+
+// InspectorValue é uma interpretação dos bytes na posição do cursor
+type InspectorValue struct {
+	Name         string
+	LittleEndian string
+	BigEndian    string
+}
+
+// InspectBytes interpreta os bytes a partir do início de data como inteiros,
+// números de ponto flutuante e texto, nas duas ordens de bytes. Tipos que não
+// cabem em data ficam em branco.
+func InspectBytes(data []byte) []InspectorValue {
+	values := []InspectorValue{}
+	add := func(name string, size int, format func(binary.ByteOrder, []byte) string) {
+		v := InspectorValue{Name: name}
+		if len(data) >= size {
+			v.LittleEndian = format(binary.LittleEndian, data[:size])
+			v.BigEndian = format(binary.BigEndian, data[:size])
+		}
+		values = append(values, v)
+	}
+
+	add("int8", 1, func(_ binary.ByteOrder, b []byte) string { return fmt.Sprint(int8(b[0])) })
+	add("uint8", 1, func(_ binary.ByteOrder, b []byte) string { return fmt.Sprint(b[0]) })
+	add("binário", 1, func(_ binary.ByteOrder, b []byte) string { return fmt.Sprintf("%08b", b[0]) })
+	add("int16", 2, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(int16(o.Uint16(b))) })
+	add("uint16", 2, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(o.Uint16(b)) })
+	add("int32", 4, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(int32(o.Uint32(b))) })
+	add("uint32", 4, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(o.Uint32(b)) })
+	add("int64", 8, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(int64(o.Uint64(b))) })
+	add("uint64", 8, func(o binary.ByteOrder, b []byte) string { return fmt.Sprint(o.Uint64(b)) })
+	add("float32", 4, func(o binary.ByteOrder, b []byte) string {
+		return fmt.Sprint(math.Float32frombits(o.Uint32(b)))
+	})
+	add("float64", 8, func(o binary.ByteOrder, b []byte) string {
+		return fmt.Sprint(math.Float64frombits(o.Uint64(b)))
+	})
+
+	// Caractere UTF-8 (independe da ordem de bytes)
+	utf8Value := InspectorValue{Name: "UTF-8"}
+	if r, size := utf8.DecodeRune(data); r != utf8.RuneError || size > 1 {
+		utf8Value.LittleEndian = fmt.Sprintf("%q U+%04X", r, r)
+		utf8Value.BigEndian = utf8Value.LittleEndian
+	}
+	values = append(values, utf8Value)
+	return values
+}
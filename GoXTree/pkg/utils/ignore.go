@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileNames são os arquivos de exclusão lidos em cada diretório, nesta ordem
+// (as regras de .ignore têm precedência sobre as de .gitignore)
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// ignoreRule é uma linha de um arquivo .gitignore/.ignore
+type ignoreRule struct {
+	base     string   // Diretório do arquivo que definiu a regra
+	segments []string // Padrão dividido em partes separadas por "/"
+	negate   bool     // "!padrão" volta a incluir o item
+	dirOnly  bool     // "padrão/" só vale para diretórios
+	anchored bool     // Padrões com "/" são relativos a base; os demais valem em qualquer nível
+}
+
+// IgnoreMatcher aplica as regras de .gitignore/.ignore encontradas ao percorrer
+// uma árvore. Os diretórios devem ser carregados com Enter antes do seu conteúdo
+// ser consultado com Ignored (a ordem natural de WalkFS).
+type IgnoreMatcher struct {
+	fsys      VFS
+	fileNames []string                // Arquivos de exclusão lidos em cada diretório
+	rules     map[string][]ignoreRule // Regras válidas em cada diretório (inclui as herdadas)
+}
+
+// NewIgnoreMatcher cria um verificador de exclusões para o sistema de arquivos informado
+func NewIgnoreMatcher(fsys VFS) *IgnoreMatcher {
+	return &IgnoreMatcher{fsys: fsys, fileNames: ignoreFileNames, rules: make(map[string][]ignoreRule)}
+}
+
+// Enter carrega os arquivos de exclusão de um diretório, somando suas regras às do diretório pai.
+// No primeiro diretório (a raiz da busca) valem também as regras dos diretórios
+// acima dele, até a raiz do repositório git que o contém.
+func (m *IgnoreMatcher) Enter(dir string) {
+	inherited, ok := m.rules[filepath.Dir(dir)]
+	if !ok {
+		inherited = m.enterParents(dir)
+	}
+	rules := inherited[:len(inherited):len(inherited)]
+
+	for _, name := range m.fileNames {
+		data, err := ReadFileFS(m.fsys, filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, parseIgnoreRules(dir, data)...)
+	}
+	m.rules[dir] = rules
+}
+
+// enterParents carrega as regras dos diretórios acima de dir: o .git/info/exclude,
+// o arquivo global do git e os arquivos de exclusão desde a raiz do repositório.
+// Fora de repositórios (ou de disco local) não há regras herdadas.
+func (m *IgnoreMatcher) enterParents(dir string) []ignoreRule {
+	parent := filepath.Dir(dir)
+	if _, ok := m.fsys.(LocalFS); !ok || parent == dir {
+		m.rules[parent] = nil
+		return nil
+	}
+
+	root := dir
+	gitDir, found := findGitDir(root)
+	for !found && filepath.Dir(root) != root {
+		root = filepath.Dir(root)
+		gitDir, found = findGitDir(root)
+	}
+	if !found {
+		m.rules[parent] = nil
+		return nil
+	}
+
+	repo, err := newGitRepo(root, gitDir)
+	if err != nil {
+		m.rules[parent] = nil
+		return nil
+	}
+	m.rules[filepath.Dir(root)] = repo.newIgnoreMatcher().rules[filepath.Dir(root)]
+
+	// Entrar nos diretórios entre a raiz do repositório e dir
+	var chain []string
+	for d := parent; len(d) >= len(root); d = filepath.Dir(d) {
+		chain = append(chain, d)
+		if d == root {
+			break
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		m.Enter(chain[i])
+	}
+	return m.rules[parent]
+}
+
+// Ignored indica se o item deve ser ignorado. A última regra que corresponde ao item decide.
+func (m *IgnoreMatcher) Ignored(name string, isDir bool) bool {
+	if isDir && filepath.Base(name) == ".git" {
+		return true
+	}
+
+	ignored := false
+	for _, rule := range m.rules[filepath.Dir(name)] {
+		if rule.matches(name, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreRules interpreta o conteúdo de um arquivo de exclusão
+func parseIgnoreRules(base string, data []byte) []ignoreRule {
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(line, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches verifica se a regra corresponde ao item
+func (r ignoreRule) matches(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	rel, err := filepath.Rel(r.base, name)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	if !r.anchored {
+		return matchIgnoreSegments(r.segments, parts[len(parts)-1:])
+	}
+	return matchIgnoreSegments(r.segments, parts)
+}
+
+// matchIgnoreSegments compara o padrão com o caminho parte a parte; "**" corresponde
+// a qualquer número de partes
+func matchIgnoreSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(parts); skip++ {
+				if matchIgnoreSegments(pattern[1:], parts[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobStatus representa o estado de uma operação em segundo plano
+type JobStatus int
+
+const (
+	JobQueued JobStatus = iota
+	JobRunning
+	JobPaused
+	JobDone
+	JobFailed
+	JobCanceled
+)
+
+// ErrJobCanceled é retornado por JobContext.Checkpoint quando a operação foi cancelada
+var ErrJobCanceled = errors.New("operação cancelada")
+
+// String retorna o nome do estado
+func (s JobStatus) String() string {
+	switch s {
+	case JobQueued:
+		return "Na fila"
+	case JobRunning:
+		return "Executando"
+	case JobPaused:
+		return "Pausado"
+	case JobDone:
+		return "Concluído"
+	case JobFailed:
+		return "Falhou"
+	case JobCanceled:
+		return "Cancelado"
+	default:
+		return "Desconhecido"
+	}
+}
+
+// Finished indica se o estado é final
+func (s JobStatus) Finished() bool {
+	return s == JobDone || s == JobFailed || s == JobCanceled
+}
+
+// JobError registra a falha de um arquivo específico dentro de uma operação
+type JobError struct {
+	Path string
+	Err  error
+}
+
+// JobSnapshot é uma cópia consistente do estado de uma operação, segura para exibição
+type JobSnapshot struct {
+	ID          int
+	Name        string
+	Status      JobStatus
+	TotalFiles  int
+	DoneFiles   int
+	TotalBytes  int64
+	DoneBytes   int64
+	CurrentFile string
+	Errors      []JobError
+	Err         error
+	Started     time.Time
+	Finished    time.Time
+}
+
+// Percent retorna o progresso em porcentagem (por bytes, ou por arquivos se não houver bytes)
+func (s JobSnapshot) Percent() int {
+	if s.TotalBytes > 0 {
+		return int(s.DoneBytes * 100 / s.TotalBytes)
+	}
+	if s.TotalFiles > 0 {
+		return s.DoneFiles * 100 / s.TotalFiles
+	}
+	if s.Status == JobDone {
+		return 100
+	}
+	return 0
+}
+
+// JobFunc é a função executada por uma operação em segundo plano
+type JobFunc func(jc *JobContext) error
+
+// Job representa uma operação de arquivo executada em segundo plano
+type Job struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	manager  *JobManager
+	snapshot JobSnapshot
+	run      JobFunc
+	onDone   func(*Job)
+	paused   bool
+	canceled bool
+}
+
+// Snapshot retorna o estado atual da operação
+func (j *Job) Snapshot() JobSnapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s := j.snapshot
+	s.Errors = append([]JobError(nil), j.snapshot.Errors...)
+	return s
+}
+
+// Cancel solicita o cancelamento da operação
+func (j *Job) Cancel() {
+	j.mu.Lock()
+	j.canceled = true
+	if j.snapshot.Status == JobQueued {
+		j.snapshot.Status = JobCanceled
+		j.snapshot.Finished = time.Now()
+	}
+	j.cond.Broadcast()
+	j.mu.Unlock()
+	j.manager.changed()
+}
+
+// Pause suspende a operação no próximo ponto de verificação
+func (j *Job) Pause() {
+	j.mu.Lock()
+	if !j.snapshot.Status.Finished() {
+		j.paused = true
+		if j.snapshot.Status == JobRunning {
+			j.snapshot.Status = JobPaused
+		}
+	}
+	j.mu.Unlock()
+	j.manager.changed()
+}
+
+// Resume retoma uma operação pausada
+func (j *Job) Resume() {
+	j.mu.Lock()
+	j.paused = false
+	if j.snapshot.Status == JobPaused {
+		j.snapshot.Status = JobRunning
+	}
+	j.cond.Broadcast()
+	j.mu.Unlock()
+	j.manager.changed()
+}
+
+// TogglePause alterna entre pausado e em execução
+func (j *Job) TogglePause() {
+	j.mu.Lock()
+	paused := j.paused
+	j.mu.Unlock()
+
+	if paused {
+		j.Resume()
+	} else {
+		j.Pause()
+	}
+}
+
+// JobManager executa operações de arquivo em goroutines, com fila e limite de concorrência
+type JobManager struct {
+	mu       sync.Mutex
+	jobs     []*Job
+	queue    chan *Job
+	nextID   int
+	updates  chan struct{}
+	onUpdate func()
+}
+
+// NewJobManager cria um gerenciador com o número de operações simultâneas informado.
+// onUpdate é chamado (no máximo a cada 200ms) quando o estado de alguma operação muda.
+func NewJobManager(workers int, onUpdate func()) *JobManager {
+	if workers < 1 {
+		workers = 1
+	}
+
+	m := &JobManager{
+		queue:    make(chan *Job, 1024),
+		nextID:   1,
+		updates:  make(chan struct{}, 1),
+		onUpdate: onUpdate,
+	}
+
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+	go m.notifier()
+
+	return m
+}
+
+// Submit coloca uma nova operação na fila. onDone (opcional) é chamado na goroutine
+// da operação após o término.
+func (m *JobManager) Submit(name string, run JobFunc, onDone func(*Job)) *Job {
+	m.mu.Lock()
+	job := &Job{
+		manager: m,
+		run:     run,
+		onDone:  onDone,
+		snapshot: JobSnapshot{
+			ID:     m.nextID,
+			Name:   name,
+			Status: JobQueued,
+		},
+	}
+	job.cond = sync.NewCond(&job.mu)
+	m.nextID++
+	m.jobs = append(m.jobs, job)
+	m.mu.Unlock()
+
+	m.queue <- job
+	m.changed()
+
+	return job
+}
+
+// Jobs retorna todas as operações conhecidas, da mais antiga para a mais recente
+func (m *JobManager) Jobs() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Job(nil), m.jobs...)
+}
+
+// Active retorna o número de operações na fila ou em execução
+func (m *JobManager) Active() int {
+	count := 0
+	for _, job := range m.Jobs() {
+		if !job.Snapshot().Status.Finished() {
+			count++
+		}
+	}
+	return count
+}
+
+// ClearFinished remove da lista as operações encerradas
+func (m *JobManager) ClearFinished() {
+	m.mu.Lock()
+	var active []*Job
+	for _, job := range m.jobs {
+		if !job.Snapshot().Status.Finished() {
+			active = append(active, job)
+		}
+	}
+	m.jobs = active
+	m.mu.Unlock()
+	m.changed()
+}
+
+// worker executa as operações da fila
+func (m *JobManager) worker() {
+	for job := range m.queue {
+		job.mu.Lock()
+		if job.canceled {
+			job.mu.Unlock()
+			m.finish(job, ErrJobCanceled)
+			continue
+		}
+		job.snapshot.Status = JobRunning
+		if job.paused {
+			job.snapshot.Status = JobPaused
+		}
+		job.snapshot.Started = time.Now()
+		job.mu.Unlock()
+		m.changed()
+
+		err := m.execute(job)
+		m.finish(job, err)
+	}
+}
+
+// execute roda a função da operação protegendo a aplicação contra pânicos
+func (m *JobManager) execute(job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("erro interno: %v", r)
+		}
+	}()
+	return job.run(&JobContext{job: job})
+}
+
+// finish registra o estado final da operação
+func (m *JobManager) finish(job *Job, err error) {
+	job.mu.Lock()
+	job.snapshot.Finished = time.Now()
+	job.snapshot.CurrentFile = ""
+	switch {
+	case job.canceled || errors.Is(err, ErrJobCanceled):
+		job.snapshot.Status = JobCanceled
+	case err != nil:
+		job.snapshot.Status = JobFailed
+		job.snapshot.Err = err
+	case len(job.snapshot.Errors) > 0:
+		job.snapshot.Status = JobFailed
+		job.snapshot.Err = fmt.Errorf("%d arquivo(s) com erro", len(job.snapshot.Errors))
+	default:
+		job.snapshot.Status = JobDone
+	}
+	job.mu.Unlock()
+	m.changed()
+
+	if job.onDone != nil {
+		job.onDone(job)
+	}
+}
+
+// changed sinaliza que houve mudança de estado sem bloquear
+func (m *JobManager) changed() {
+	select {
+	case m.updates <- struct{}{}:
+	default:
+	}
+}
+
+// notifier agrupa as notificações de mudança para não sobrecarregar a interface
+func (m *JobManager) notifier() {
+	for range m.updates {
+		if m.onUpdate != nil {
+			m.onUpdate()
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,450 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// largeFileIndexStep é o intervalo, em linhas, entre os pontos guardados no índice
+	largeFileIndexStep = 256
+	// largeFileChunk é o tamanho dos blocos lidos na indexação e nas buscas
+	largeFileChunk = 1024 * 1024
+	// largeFileMaxLine é quanto de cada linha é devolvido para exibição
+	largeFileMaxLine = 4096
+)
+
+// ErrSearchStopped indica que a busca foi interrompida antes de terminar
+var ErrSearchStopped = errors.New("busca interrompida")
+
+// LargeLine é uma linha lida de um LargeFile
+type LargeLine struct {
+	Offset int64  // Início da linha no arquivo
+	Next   int64  // Início da linha seguinte
+	Text   string // Conteúdo, sem o fim de linha e limitado a largeFileMaxLine bytes
+}
+
+// LargeFile dá acesso a arquivos de qualquer tamanho sem carregá-los na memória:
+// as linhas são lidas sob demanda e um índice esparso de linhas (um ponto a cada
+// largeFileIndexStep linhas) é montado em segundo plano.
+type LargeFile struct {
+	file *os.File
+	path string
+
+	mu          sync.Mutex
+	size        int64
+	checkpoints []int64 // checkpoints[k] = início da linha k*largeFileIndexStep
+	newlines    int64   // Fins de linha encontrados até indexed
+	indexed     int64   // Bytes já indexados
+	generation  int     // Incrementado quando o índice é refeito
+	indexing    bool
+	closed      bool
+}
+
+// OpenLargeFile abre o arquivo e começa a indexar as linhas
+func OpenLargeFile(path string) (*LargeFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f := &LargeFile{file: file, path: path, size: info.Size(), checkpoints: []int64{0}}
+	f.startIndex()
+	return f, nil
+}
+
+// Close encerra a indexação e fecha o arquivo
+func (f *LargeFile) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Path retorna o caminho do arquivo
+func (f *LargeFile) Path() string {
+	return f.path
+}
+
+// Size retorna o tamanho conhecido do arquivo
+func (f *LargeFile) Size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.size
+}
+
+// Refresh verifica se o arquivo cresceu (como no tail -f) ou foi truncado, e
+// atualiza o índice. Retorna true quando o tamanho mudou.
+func (f *LargeFile) Refresh() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	size := info.Size()
+	changed := size != f.size
+	if size < f.size {
+		// Arquivo truncado ou rotacionado: refazer o índice
+		f.checkpoints = []int64{0}
+		f.newlines = 0
+		f.indexed = 0
+		f.generation++
+	}
+	f.size = size
+	f.mu.Unlock()
+
+	if changed {
+		f.startIndex()
+	}
+	return changed, nil
+}
+
+// IndexProgress retorna quantas linhas e bytes já foram indexados e se o índice está completo
+func (f *LargeFile) IndexProgress() (lines int64, indexed int64, done bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lineCount(), f.indexed, f.indexed >= f.size
+}
+
+// lineCount retorna o número de linhas indexadas (a última pode não ter fim de linha)
+func (f *LargeFile) lineCount() int64 {
+	if f.indexed >= f.size && f.size > 0 {
+		last := make([]byte, 1)
+		if _, err := f.file.ReadAt(last, f.size-1); err == nil && last[0] != '\n' {
+			return f.newlines + 1
+		}
+	}
+	return f.newlines
+}
+
+// startIndex inicia a indexação do que ainda falta, se ela não estiver em andamento
+func (f *LargeFile) startIndex() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.indexing || f.closed || f.indexed >= f.size {
+		return
+	}
+	f.indexing = true
+	go f.buildIndex()
+}
+
+// buildIndex lê o arquivo em blocos registrando o início das linhas
+func (f *LargeFile) buildIndex() {
+	buf := make([]byte, largeFileChunk)
+	for {
+		f.mu.Lock()
+		offset, size, newlines, generation := f.indexed, f.size, f.newlines, f.generation
+		if f.closed || offset >= size {
+			f.indexing = false
+			f.mu.Unlock()
+			return
+		}
+		f.mu.Unlock()
+
+		n, err := f.file.ReadAt(buf[:min(int64(len(buf)), size-offset)], offset)
+		if n == 0 && err != nil {
+			f.mu.Lock()
+			f.indexing = false
+			f.mu.Unlock()
+			return
+		}
+
+		var points []int64
+		data := buf[:n]
+		for pos := 0; ; {
+			i := bytes.IndexByte(data[pos:], '\n')
+			if i < 0 {
+				break
+			}
+			pos += i + 1
+			newlines++
+			if newlines%largeFileIndexStep == 0 {
+				points = append(points, offset+int64(pos))
+			}
+		}
+
+		f.mu.Lock()
+		if f.generation != generation {
+			// O índice foi refeito (arquivo truncado) enquanto este bloco era lido
+			f.mu.Unlock()
+			continue
+		}
+		f.checkpoints = append(f.checkpoints, points...)
+		f.newlines = newlines
+		f.indexed = offset + int64(n)
+		f.mu.Unlock()
+	}
+}
+
+// findNewlineBackward retorna a posição do count-ésimo "\n" antes de from (ou -1)
+func (f *LargeFile) findNewlineBackward(from int64, count int) int64 {
+	buf := make([]byte, 64*1024)
+	for end := from; end > 0 && count > 0; {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := f.file.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return -1
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] == '\n' {
+				count--
+				if count == 0 {
+					return start + int64(i)
+				}
+			}
+		}
+		end = start
+	}
+	return -1
+}
+
+// findNewlineForward retorna a posição logo após o count-ésimo "\n" a partir de from (ou -1)
+func (f *LargeFile) findNewlineForward(from int64, count int) int64 {
+	if count == 0 {
+		return from
+	}
+	buf := make([]byte, 64*1024)
+	for offset := from; ; {
+		n, err := f.file.ReadAt(buf, offset)
+		data := buf[:n]
+		for pos := 0; ; {
+			i := bytes.IndexByte(data[pos:], '\n')
+			if i < 0 {
+				break
+			}
+			pos += i + 1
+			count--
+			if count == 0 {
+				return offset + int64(pos)
+			}
+		}
+		if err != nil {
+			return -1
+		}
+		offset += int64(n)
+	}
+}
+
+// LineStart retorna o início da linha que contém o byte offset
+func (f *LargeFile) LineStart(offset int64) int64 {
+	return f.findNewlineBackward(offset, 1) + 1
+}
+
+// LinesBefore retorna o início da linha que fica n linhas antes da linha iniciada em offset
+func (f *LargeFile) LinesBefore(offset int64, n int) int64 {
+	if offset <= 0 || n <= 0 {
+		return max(offset, 0)
+	}
+	// O byte offset-1 é o fim da linha anterior
+	return f.findNewlineBackward(offset-1, n) + 1
+}
+
+// LastPage retorna o início da página de n linhas que termina no fim do arquivo
+func (f *LargeFile) LastPage(n int) int64 {
+	size := f.Size()
+	if size == 0 {
+		return 0
+	}
+	return f.LinesBefore(f.LineStart(size-1), n-1)
+}
+
+// OffsetForPercent retorna o início da linha na posição percentual (0 a 100) do arquivo
+func (f *LargeFile) OffsetForPercent(percent float64) int64 {
+	percent = max(0, min(percent, 100))
+	size := f.Size()
+	if size == 0 {
+		return 0
+	}
+	return f.LineStart(min(int64(float64(size)*percent/100), size-1))
+}
+
+// LineOffset retorna o início da linha (a partir de 0). Retorna false quando a
+// linha ainda não foi alcançada pela indexação ou não existe.
+func (f *LargeFile) LineOffset(line int64) (int64, bool) {
+	f.mu.Lock()
+	if line < 0 || line > f.newlines {
+		f.mu.Unlock()
+		return 0, false
+	}
+	checkpoint, size := f.checkpoints[line/largeFileIndexStep], f.size
+	f.mu.Unlock()
+
+	offset := f.findNewlineForward(checkpoint, int(line%largeFileIndexStep))
+	if offset < 0 || offset >= size && line > 0 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// LineNumberAt retorna o número (a partir de 0) da linha iniciada em offset, se a
+// indexação já passou por ela
+func (f *LargeFile) LineNumberAt(offset int64) (int64, bool) {
+	f.mu.Lock()
+	if offset > f.indexed {
+		f.mu.Unlock()
+		return 0, false
+	}
+	k := sort.Search(len(f.checkpoints), func(i int) bool { return f.checkpoints[i] > offset }) - 1
+	checkpoint := f.checkpoints[k]
+	f.mu.Unlock()
+
+	// Contar os fins de linha entre o ponto do índice e offset
+	line := int64(k) * largeFileIndexStep
+	buf := make([]byte, 64*1024)
+	for pos := checkpoint; pos < offset; {
+		n, err := f.file.ReadAt(buf[:min(int64(len(buf)), offset-pos)], pos)
+		line += int64(bytes.Count(buf[:n], []byte{'\n'}))
+		pos += int64(n)
+		if err != nil {
+			break
+		}
+	}
+	return line, true
+}
+
+// ReadLines lê até n linhas a partir de offset (que deve ser o início de uma linha)
+func (f *LargeFile) ReadLines(offset int64, n int) ([]LargeLine, error) {
+	size := f.Size()
+	if offset >= size || n <= 0 {
+		return nil, nil
+	}
+
+	reader := bufio.NewReaderSize(io.NewSectionReader(f.file, offset, size-offset), 64*1024)
+	lines := make([]LargeLine, 0, n)
+	for len(lines) < n {
+		line := LargeLine{Offset: offset}
+		var text []byte
+		for {
+			chunk, err := reader.ReadSlice('\n')
+			offset += int64(len(chunk))
+			if room := largeFileMaxLine - len(text); room > 0 {
+				text = append(text, chunk[:min(len(chunk), room)]...)
+			}
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err != nil && err != io.EOF {
+				return lines, err
+			}
+			break
+		}
+		if offset == line.Offset {
+			break // Fim do arquivo
+		}
+		line.Next = offset
+		line.Text = strings.ToValidUTF8(strings.TrimRight(string(text), "\r\n"), "?")
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// Search procura a próxima linha que corresponde à expressão, a partir de from
+// (para frente) ou antes de from (para trás), lendo o arquivo em blocos. Retorna o
+// início da linha encontrada ou -1. Fechar stop interrompe a busca.
+func (f *LargeFile) Search(re *regexp.Regexp, from int64, backward bool, stop <-chan struct{}) (int64, error) {
+	if backward {
+		return f.searchBackward(re, from, stop)
+	}
+	return f.searchForward(re, from, stop)
+}
+
+// searchForward procura a partir de from, linha por linha
+func (f *LargeFile) searchForward(re *regexp.Regexp, from int64, stop <-chan struct{}) (int64, error) {
+	size := f.Size()
+	if from >= size {
+		return -1, nil
+	}
+
+	reader := bufio.NewReaderSize(io.NewSectionReader(f.file, from, size-from), largeFileChunk)
+	offset := from
+	for count := 0; ; count++ {
+		if count%4096 == 0 && stopped(stop) {
+			return -1, ErrSearchStopped
+		}
+
+		start := offset
+		chunk, err := reader.ReadSlice('\n')
+		offset += int64(len(chunk))
+		matched := re.Match(bytes.TrimRight(chunk, "\r\n"))
+		for err == bufio.ErrBufferFull {
+			// Linha maior que o buffer: continuar lendo a mesma linha
+			chunk, err = reader.ReadSlice('\n')
+			offset += int64(len(chunk))
+			matched = matched || re.Match(bytes.TrimRight(chunk, "\r\n"))
+		}
+		if matched && offset > start {
+			return start, nil
+		}
+		if err == io.EOF {
+			return -1, nil
+		}
+		if err != nil {
+			return -1, err
+		}
+	}
+}
+
+// searchBackward procura nas linhas que começam antes de from, da mais próxima para a mais distante
+func (f *LargeFile) searchBackward(re *regexp.Regexp, from int64, stop <-chan struct{}) (int64, error) {
+	var carry []byte // Começo de uma linha que continua no bloco seguinte
+	for end, first := from, true; end > 0; first = false {
+		if stopped(stop) {
+			return -1, ErrSearchStopped
+		}
+
+		start := max(end-largeFileChunk, 0)
+		chunk := make([]byte, end-start)
+		if _, err := f.file.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return -1, err
+		}
+		data := append(chunk, carry...)
+		if first {
+			// Desconsiderar o fim da linha anterior a from
+			data = bytes.TrimSuffix(data, []byte{'\n'})
+		}
+
+		// Linhas completas: as que vêm depois de um "\n"
+		for {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if re.Match(bytes.TrimRight(data[i+1:], "\r")) {
+				return start + int64(i+1), nil
+			}
+			data = data[:i]
+		}
+		if start == 0 {
+			if re.Match(bytes.TrimRight(data, "\r")) {
+				return 0, nil
+			}
+			break
+		}
+		carry = data[:min(len(data), largeFileChunk)]
+		end = start
+	}
+	return -1, nil
+}
+
+// stopped indica se o canal de interrupção foi fechado
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
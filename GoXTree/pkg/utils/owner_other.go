@@ -0,0 +1,10 @@
+//go:build !unix
+
+package utils
+
+import "os"
+
+// fileIDs não tem equivalente fora dos sistemas Unix
+func fileIDs(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,411 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+)
+
+// Bits especiais no formato do chmod
+const (
+	modeSetuid = 04000
+	modeSetgid = 02000
+	modeSticky = 01000
+)
+
+// FormatMode retorna o tipo e as permissões no formato do ls -l (ex.: drwxr-sr-t),
+// com s/S e t/T para setuid, setgid e sticky
+func FormatMode(mode os.FileMode) string {
+	var buf [10]byte
+
+	switch {
+	case mode&os.ModeDir != 0:
+		buf[0] = 'd'
+	case mode&os.ModeSymlink != 0:
+		buf[0] = 'l'
+	case mode&os.ModeNamedPipe != 0:
+		buf[0] = 'p'
+	case mode&os.ModeSocket != 0:
+		buf[0] = 's'
+	case mode&os.ModeCharDevice != 0:
+		buf[0] = 'c'
+	case mode&os.ModeDevice != 0:
+		buf[0] = 'b'
+	default:
+		buf[0] = '-'
+	}
+
+	const rwx = "rwxrwxrwx"
+	for i := 0; i < 9; i++ {
+		buf[i+1] = '-'
+		if mode&(1<<uint(8-i)) != 0 {
+			buf[i+1] = rwx[i]
+		}
+	}
+
+	// O bit especial aparece no lugar do x: minúsculo quando x também está ligado
+	special := func(pos int, set bool, char byte) {
+		if !set {
+			return
+		}
+		if buf[pos] == 'x' {
+			buf[pos] = char
+		} else {
+			buf[pos] = char - 'a' + 'A'
+		}
+	}
+	special(3, mode&os.ModeSetuid != 0, 's')
+	special(6, mode&os.ModeSetgid != 0, 's')
+	special(9, mode&os.ModeSticky != 0, 't')
+
+	return string(buf[:])
+}
+
+// FormatOctalMode retorna as permissões em octal (ex.: 0755, 4755)
+func FormatOctalMode(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", unixMode(mode))
+}
+
+// HasSpecialBits indica se setuid, setgid ou sticky estão ligados
+func HasSpecialBits(mode os.FileMode) bool {
+	return mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != 0
+}
+
+// unixMode converte as permissões para os bits do chmod
+func unixMode(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= modeSetuid
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= modeSetgid
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= modeSticky
+	}
+	return bits
+}
+
+// fileMode converte os bits do chmod para os.FileMode
+func fileMode(bits uint32) os.FileMode {
+	mode := os.FileMode(bits & 0777)
+	if bits&modeSetuid != 0 {
+		mode |= os.ModeSetuid
+	}
+	if bits&modeSetgid != 0 {
+		mode |= os.ModeSetgid
+	}
+	if bits&modeSticky != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// ParseMode aplica uma especificação do chmod às permissões atuais: octal
+// ("755", "2775") ou simbólica ("u+x", "go-w", "a=rX", "u+s,+t"). O X só liga a
+// execução em diretórios e em arquivos que já são executáveis por alguém. Como no
+// chmod do GNU, diretórios mantêm setuid e setgid a menos que sejam informados
+// explicitamente (octal com 4 dígitos ou "s" na cláusula).
+func ParseMode(spec string, current os.FileMode, isDir bool) (os.FileMode, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, errors.New("permissões não informadas")
+	}
+
+	// Octal
+	if strings.Trim(spec, "01234567") == "" {
+		if len(spec) > 4 {
+			return 0, fmt.Errorf("permissões inválidas: %s", spec)
+		}
+		bits, _ := strconv.ParseUint(spec, 8, 32)
+		mode := fileMode(uint32(bits))
+		if isDir && len(spec) < 4 {
+			mode |= current & (os.ModeSetuid | os.ModeSetgid)
+		}
+		return mode, nil
+	}
+
+	bits := unixMode(current)
+	for _, clause := range strings.Split(spec, ",") {
+		var err error
+		if bits, err = applySymbolic(clause, bits, isDir); err != nil {
+			return 0, fmt.Errorf("permissões inválidas: %s", spec)
+		}
+	}
+	return fileMode(bits), nil
+}
+
+// applySymbolic aplica uma cláusula simbólica (ex.: "ug+rw-x") aos bits
+func applySymbolic(clause string, bits uint32, isDir bool) (uint32, error) {
+	masks := map[byte]uint32{'u': 04700, 'g': 02070, 'o': 01007, 'a': 07777}
+
+	i := 0
+	var who uint32
+	for i < len(clause) && masks[clause[i]] != 0 {
+		who |= masks[clause[i]]
+		i++
+	}
+	if who == 0 {
+		who = masks['a']
+	}
+	if i == len(clause) {
+		return 0, errors.New("operador ausente")
+	}
+
+	for i < len(clause) {
+		op := clause[i]
+		if op != '+' && op != '-' && op != '=' {
+			return 0, fmt.Errorf("operador inválido: %c", op)
+		}
+		i++
+
+		var perm uint32
+		if i < len(clause) && strings.IndexByte("ugo", clause[i]) >= 0 {
+			// Copiar as permissões de outra classe (ex.: g=u)
+			shift := map[byte]uint{'u': 6, 'g': 3, 'o': 0}[clause[i]]
+			perm = (bits >> shift & 7) * 0111
+			i++
+		} else {
+			for ; i < len(clause) && strings.IndexByte("rwxXst", clause[i]) >= 0; i++ {
+				switch clause[i] {
+				case 'r':
+					perm |= 0444
+				case 'w':
+					perm |= 0222
+				case 'x':
+					perm |= 0111
+				case 'X':
+					if isDir || bits&0111 != 0 {
+						perm |= 0111
+					}
+				case 's':
+					perm |= modeSetuid | modeSetgid
+				case 't':
+					perm |= modeSticky
+				}
+			}
+		}
+		perm &= who
+
+		switch op {
+		case '+':
+			bits |= perm
+		case '-':
+			bits &^= perm
+		case '=':
+			clear := who
+			if isDir {
+				clear &^= modeSetuid | modeSetgid
+			}
+			bits = bits&^clear | perm
+		}
+	}
+	return bits, nil
+}
+
+// Nomes de usuários e grupos já consultados
+var (
+	ownerNamesMu sync.Mutex
+	userNames    = map[string]string{}
+	groupNames   = map[string]string{}
+)
+
+// lookupName retorna o nome do usuário (ou grupo) com o id, ou o próprio id
+func lookupName(cache map[string]string, id string, lookup func(string) (string, error)) string {
+	ownerNamesMu.Lock()
+	defer ownerNamesMu.Unlock()
+
+	if name, ok := cache[id]; ok {
+		return name
+	}
+	name, err := lookup(id)
+	if err != nil || name == "" {
+		name = id
+	}
+	cache[id] = name
+	return name
+}
+
+// FileOwner retorna os nomes do dono e do grupo do arquivo. Em servidores SFTP,
+// onde os nomes não podem ser consultados, retorna os números; quando o sistema
+// não informa, retorna strings vazias.
+func FileOwner(info os.FileInfo) (owner, group string) {
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		return strconv.Itoa(int(stat.UID)), strconv.Itoa(int(stat.GID))
+	}
+
+	uid, gid, ok := fileIDs(info)
+	if !ok {
+		return "", ""
+	}
+	owner = lookupName(userNames, strconv.Itoa(uid), func(id string) (string, error) {
+		u, err := user.LookupId(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	})
+	group = lookupName(groupNames, strconv.Itoa(gid), func(id string) (string, error) {
+		g, err := user.LookupGroupId(id)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	})
+	return owner, group
+}
+
+// LookupUserID converte um nome de usuário ou UID em número; vazio retorna -1
+// (mantém o dono atual)
+func LookupUserID(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return -1, fmt.Errorf("usuário desconhecido: %s", name)
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// LookupGroupID converte um nome de grupo ou GID em número; vazio retorna -1
+// (mantém o grupo atual)
+func LookupGroupID(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return -1, fmt.Errorf("grupo desconhecido: %s", name)
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// AttrChange descreve uma alteração de permissões e de dono
+type AttrChange struct {
+	Mode      string // Octal ou simbólico, como no chmod; vazio mantém
+	UID       int    // -1 mantém o dono
+	GID       int    // -1 mantém o grupo
+	Recursive bool   // Aplicar também ao conteúdo dos diretórios
+}
+
+// ChangeAttributesJob aplica a alteração aos caminhos (e, se recursiva, a tudo
+// abaixo deles). Links simbólicos têm o dono alterado, mas não as permissões.
+func ChangeAttributesJob(jc *JobContext, paths []string, change AttrChange) error {
+	if change.Mode != "" {
+		if _, err := ParseMode(change.Mode, 0, false); err != nil {
+			return err
+		}
+	}
+	if change.Mode == "" && change.UID < 0 && change.GID < 0 {
+		return nil
+	}
+
+	// Total de itens para o progresso
+	if change.Recursive && jc != nil {
+		for _, root := range paths {
+			WalkFS(FSFor(root), root, func(path string, info os.FileInfo, err error) error {
+				jc.AddTotal(1, 0)
+				return jc.Checkpoint()
+			})
+		}
+	} else {
+		jc.AddTotal(len(paths), 0)
+	}
+
+	for _, root := range paths {
+		if IsArchivePath(root) {
+			if err := jc.Fail(root, errArchiveReadOnly); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fsys := FSFor(root)
+		visit := func(path string, info os.FileInfo, err error) error {
+			if err == nil {
+				if err = jc.Checkpoint(); err != nil {
+					return err
+				}
+				jc.SetCurrent(path)
+				err = changeAttributes(fsys, path, info, change)
+				jc.FileDone()
+			}
+			return jc.Fail(path, err)
+		}
+
+		if change.Recursive {
+			if err := WalkFS(fsys, root, visit); err != nil {
+				return err
+			}
+			continue
+		}
+		info, err := LstatFS(fsys, root)
+		if err := visit(root, info, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeAttributes altera as permissões e o dono de um item
+func changeAttributes(fsys VFS, path string, info os.FileInfo, change AttrChange) error {
+	if change.UID >= 0 || change.GID >= 0 {
+		c, ok := fsys.(chowner)
+		if !ok {
+			return &fs.PathError{Op: "chown", Path: path, Err: errors.ErrUnsupported}
+		}
+		if err := c.Chown(path, change.UID, change.GID); err != nil {
+			return err
+		}
+
+		// A troca de dono pode desligar setuid e setgid
+		var err error
+		if info, err = LstatFS(fsys, path); err != nil {
+			return err
+		}
+	}
+
+	if change.Mode == "" || info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	mode, err := ParseMode(change.Mode, info.Mode(), info.IsDir())
+	if err != nil {
+		return err
+	}
+	if mode == info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky) {
+		return nil
+	}
+	c, ok := fsys.(chmoder)
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: path, Err: errors.ErrUnsupported}
+	}
+	return c.Chmod(path, mode)
+}
+
+// LinkTarget retorna o destino do link simbólico, ou vazio se não for um link
+func LinkTarget(fsys VFS, path string, info os.FileInfo) string {
+	if info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	target, err := ReadlinkFS(fsys, path)
+	if err != nil {
+		return ""
+	}
+	return target
+}
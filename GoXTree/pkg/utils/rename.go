@@ -0,0 +1,384 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CaseTransform é a conversão de maiúsculas/minúsculas aplicada ao novo nome
+type CaseTransform int
+
+const (
+	CaseKeep     CaseTransform = iota
+	CaseLower                  // tudo em minúsculas
+	CaseUpper                  // TUDO EM MAIÚSCULAS
+	CaseTitle                  // Cada Palavra Com Inicial Maiúscula
+	CaseSentence               // Só a primeira letra maiúscula
+)
+
+// CaseTransformNames são as descrições das conversões, na ordem das constantes
+var CaseTransformNames = []string{"Manter", "minúsculas", "MAIÚSCULAS", "Iniciais Maiúsculas", "Primeira maiúscula"}
+
+// DefaultRenameTemplate mantém o nome (após a substituição) e a extensão
+const DefaultRenameTemplate = "{name}"
+
+// RenameRule descreve como os novos nomes são formados. A substituição é aplicada
+// ao nome sem extensão; o resultado entra no modelo pelo token {name}.
+//
+// Tokens do modelo:
+//
+//	{name}          nome sem extensão, após a substituição
+//	{ext}           extensão original, sem o ponto
+//	{parent}        nome do diretório que contém o item
+//	{n} {n:03}      contador (completado com zeros até a largura informada)
+//	{date}          data de modificação (AAAA-MM-DD); {date:AAAAMMDD_hhmmss} define o formato
+//	{exif}          data da foto (EXIF), ou a de modificação na falta dela; aceita formato como {date}
+type RenameRule struct {
+	Find          string
+	Replace       string // Com Regexp, aceita $1, ${nome} etc.
+	Regexp        bool
+	CaseSensitive bool
+	Template      string // Vazio = DefaultRenameTemplate
+	Case          CaseTransform
+	Extension     string // Nova extensão ("" mantém a original; "." remove)
+	CounterStart  int
+	CounterStep   int // 0 = 1
+}
+
+// RenameStatus é a situação de um item no plano de renomeação
+type RenameStatus int
+
+const (
+	RenameReady RenameStatus = iota
+	RenameUnchanged
+	RenameInvalid
+	RenameCollision
+)
+
+// RenamePlan é a renomeação prevista para um item
+type RenamePlan struct {
+	Old    string
+	New    string
+	Status RenameStatus
+	Reason string // Motivo de RenameInvalid/RenameCollision
+	Cycle  bool   // O item faz parte de uma troca circular de nomes (a→b, b→a)
+}
+
+// renameTokenPattern localiza os tokens do modelo
+var renameTokenPattern = regexp.MustCompile(`\{([a-z]+)(?::([^}]*))?\}`)
+
+// PlanRenames calcula os novos nomes dos itens, na ordem informada (que define o
+// contador), e verifica nomes inválidos, colisões entre os próprios itens ou com
+// arquivos existentes e trocas circulares.
+func PlanRenames(paths []string, rule RenameRule) ([]RenamePlan, error) {
+	var find *regexp.Regexp
+	if rule.Find != "" {
+		expr := rule.Find
+		if !rule.Regexp {
+			expr = regexp.QuoteMeta(expr)
+		}
+		if !rule.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		var err error
+		if find, err = regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("expressão regular inválida: %v", err)
+		}
+	}
+
+	template := rule.Template
+	if template == "" {
+		template = DefaultRenameTemplate
+	}
+	if err := validateRenameTemplate(template); err != nil {
+		return nil, err
+	}
+	step := rule.CounterStep
+	if step == 0 {
+		step = 1
+	}
+
+	plans := make([]RenamePlan, len(paths))
+	for i, path := range paths {
+		name, err := renameOne(path, rule, find, template, rule.CounterStart+i*step)
+		plans[i] = RenamePlan{Old: path, New: filepath.Join(filepath.Dir(path), name)}
+		switch {
+		case err != nil:
+			plans[i].Status, plans[i].Reason = RenameInvalid, err.Error()
+		case name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00") || strings.ContainsRune(name, filepath.Separator):
+			plans[i].Status, plans[i].Reason = RenameInvalid, "nome inválido"
+		case plans[i].New == path:
+			plans[i].Status = RenameUnchanged
+		}
+	}
+
+	checkRenameCollisions(plans)
+	markRenameCycles(plans)
+	return plans, nil
+}
+
+// validateRenameTemplate rejeita tokens desconhecidos
+func validateRenameTemplate(template string) error {
+	for _, m := range renameTokenPattern.FindAllStringSubmatch(template, -1) {
+		switch m[1] {
+		case "name", "ext", "parent", "n", "date", "exif":
+		default:
+			return fmt.Errorf("token desconhecido: %s", m[0])
+		}
+		if m[1] == "n" && m[2] != "" {
+			if _, err := strconv.Atoi(m[2]); err != nil {
+				return fmt.Errorf("largura inválida no contador: %s", m[0])
+			}
+		}
+	}
+	return nil
+}
+
+// renameOne forma o novo nome de um item
+func renameOne(path string, rule RenameRule, find *regexp.Regexp, template string, counter int) (string, error) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Arquivos como ".bashrc" não têm extensão
+		stem, ext = base, ""
+	}
+
+	if find != nil {
+		if rule.Regexp {
+			stem = find.ReplaceAllString(stem, rule.Replace)
+		} else {
+			stem = find.ReplaceAllLiteralString(stem, rule.Replace)
+		}
+	}
+
+	var info os.FileInfo
+	var tokenErr error
+	name := renameTokenPattern.ReplaceAllStringFunc(template, func(token string) string {
+		m := renameTokenPattern.FindStringSubmatch(token)
+		switch m[1] {
+		case "name":
+			return stem
+		case "ext":
+			return strings.TrimPrefix(ext, ".")
+		case "parent":
+			return filepath.Base(filepath.Dir(path))
+		case "n":
+			width, _ := strconv.Atoi(m[2])
+			return fmt.Sprintf("%0*d", width, counter)
+		}
+
+		// Tokens de data
+		if info == nil {
+			var err error
+			if info, err = LstatFS(FSFor(path), path); err != nil {
+				tokenErr = err
+				return ""
+			}
+		}
+		date := info.ModTime()
+		if m[1] == "exif" {
+			if exif, err := ReadEXIFDate(FSFor(path), path); err == nil {
+				date = exif
+			}
+		}
+		format := m[2]
+		if format == "" {
+			format = "AAAA-MM-DD"
+		}
+		return date.Format(renameDateLayout(format))
+	})
+	if tokenErr != nil {
+		return "", tokenErr
+	}
+
+	name = applyCaseTransform(name, rule.Case)
+
+	switch rule.Extension {
+	case "":
+		name += ext
+	case ".":
+	default:
+		name += "." + strings.TrimPrefix(rule.Extension, ".")
+	}
+	return name, nil
+}
+
+// renameDateLayout converte um formato como "AAAAMMDD_hhmmss" para o layout do Go
+func renameDateLayout(format string) string {
+	return strings.NewReplacer(
+		"AAAA", "2006", "YYYY", "2006",
+		"AA", "06", "YY", "06",
+		"MM", "01", "DD", "02",
+		"hh", "15", "mm", "04", "ss", "05",
+	).Replace(format)
+}
+
+// applyCaseTransform converte maiúsculas/minúsculas
+func applyCaseTransform(name string, transform CaseTransform) string {
+	switch transform {
+	case CaseLower:
+		return strings.ToLower(name)
+	case CaseUpper:
+		return strings.ToUpper(name)
+	case CaseTitle:
+		runes := []rune(strings.ToLower(name))
+		for i, r := range runes {
+			if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) && runes[i-1] != '\'' {
+				runes[i] = unicode.ToUpper(r)
+			}
+		}
+		return string(runes)
+	case CaseSentence:
+		lower := strings.ToLower(name)
+		if lower == "" {
+			return lower
+		}
+		r, size := utf8.DecodeRuneInString(lower)
+		return string(unicode.ToUpper(r)) + lower[size:]
+	}
+	return name
+}
+
+// checkRenameCollisions marca itens cujo novo nome é repetido ou já está ocupado
+func checkRenameCollisions(plans []RenamePlan) {
+	byNew := make(map[string][]int)
+	moving := make(map[string]bool) // Caminhos que serão liberados
+	for i, plan := range plans {
+		if plan.Status == RenameReady {
+			byNew[plan.New] = append(byNew[plan.New], i)
+			moving[plan.Old] = true
+		}
+	}
+
+	for target, indexes := range byNew {
+		if len(indexes) > 1 {
+			for _, i := range indexes {
+				plans[i].Status = RenameCollision
+				plans[i].Reason = fmt.Sprintf("%d itens teriam o mesmo nome", len(indexes))
+			}
+			continue
+		}
+		if moving[target] {
+			continue
+		}
+
+		// O destino existe e não será liberado (a menos que seja o próprio item,
+		// como ao trocar só maiúsculas/minúsculas em um sistema que não as diferencia)
+		i := indexes[0]
+		existing, err := LstatFS(FSFor(target), target)
+		if err != nil {
+			continue
+		}
+		if own, err := LstatFS(FSFor(plans[i].Old), plans[i].Old); err == nil && os.SameFile(own, existing) {
+			continue
+		}
+		plans[i].Status = RenameCollision
+		plans[i].Reason = "já existe um item com este nome"
+	}
+}
+
+// markRenameCycles marca os itens que trocam de nome entre si em círculo
+func markRenameCycles(plans []RenamePlan) {
+	byOld := make(map[string]int)
+	for i, plan := range plans {
+		if plan.Status == RenameReady {
+			byOld[plan.Old] = i
+		}
+	}
+
+	for i, plan := range plans {
+		if plan.Status != RenameReady {
+			continue
+		}
+		// Seguir a cadeia de destinos até sair do lote ou voltar ao início
+		next, steps := plan.New, 0
+		for steps <= len(plans) {
+			j, ok := byOld[next]
+			if !ok {
+				break
+			}
+			if j == i {
+				plans[i].Cycle = true
+				break
+			}
+			next = plans[j].New
+			steps++
+		}
+	}
+}
+
+// ApplyRenames executa o plano de uma só vez: se algum item tiver colisão ou nome
+// inválido, nada é alterado. Todos os itens passam primeiro por um nome temporário,
+// o que resolve cadeias e trocas circulares; se uma renomeação falhar, as já feitas
+// são revertidas. Retorna os passos que desfazem a operação.
+func ApplyRenames(plans []RenamePlan) ([]UndoStep, error) {
+	var ready []RenamePlan
+	for _, plan := range plans {
+		switch plan.Status {
+		case RenameReady:
+			ready = append(ready, plan)
+		case RenameInvalid, RenameCollision:
+			return nil, fmt.Errorf("'%s': %s", filepath.Base(plan.Old), plan.Reason)
+		}
+	}
+
+	type renameDone struct{ from, to string }
+	var done []renameDone
+	rename := func(from, to string) error {
+		if err := FSFor(from).Rename(from, to); err != nil {
+			return err
+		}
+		done = append(done, renameDone{from, to})
+		return nil
+	}
+	rollback := func(cause error) error {
+		var failed []string
+		for i := len(done) - 1; i >= 0; i-- {
+			if err := FSFor(done[i].to).Rename(done[i].to, done[i].from); err != nil {
+				failed = append(failed, done[i].from)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%v; não foi possível reverter: %s", cause, strings.Join(failed, ", "))
+		}
+		return cause
+	}
+
+	// Fase 1: nomes temporários exclusivos no mesmo diretório
+	temps := make([]string, len(ready))
+	stamp := time.Now().UnixNano()
+	for i, plan := range ready {
+		temps[i] = filepath.Join(filepath.Dir(plan.Old), fmt.Sprintf(".gxtree-rename-%d-%d", stamp, i))
+		if err := rename(plan.Old, temps[i]); err != nil {
+			return nil, rollback(fmt.Errorf("erro ao renomear '%s': %v", filepath.Base(plan.Old), err))
+		}
+	}
+
+	// Fase 2: nomes definitivos, sem sobrescrever o que tenha surgido nesse meio tempo
+	for i, plan := range ready {
+		if _, err := LstatFS(FSFor(plan.New), plan.New); err == nil {
+			return nil, rollback(fmt.Errorf("'%s' já existe", filepath.Base(plan.New)))
+		}
+		if err := rename(temps[i], plan.New); err != nil {
+			return nil, rollback(fmt.Errorf("erro ao renomear '%s' para '%s': %v", filepath.Base(plan.Old), filepath.Base(plan.New), err))
+		}
+	}
+
+	// Desfeitos na ordem inversa: primeiro todos voltam ao nome temporário, depois ao original
+	var steps []UndoStep
+	for i, plan := range ready {
+		steps = append(steps, UndoStep{Kind: UndoMoveBack, Path: temps[i], OriginalPath: plan.Old})
+	}
+	for i, plan := range ready {
+		steps = append(steps, UndoStep{Kind: UndoMoveBack, Path: plan.New, OriginalPath: temps[i]})
+	}
+	return steps, nil
+}
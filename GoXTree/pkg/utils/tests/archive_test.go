@@ -0,0 +1,319 @@
+package utils_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// archiveFiles é o conteúdo usado nos arquivos compactados de teste
+var archiveFiles = map[string]string{
+	"./docs/leia.txt":    "olá",
+	"./docs/sub/a.txt":   "a",
+	"./raiz.txt":         "raiz",
+	"../fora/escape.txt": "não deve sair do destino",
+}
+
+func writeTestTarGz(t *testing.T, path string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for name, content := range archiveFiles {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func writeTestZip(t *testing.T, path string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for name, content := range archiveFiles {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+}
+
+func TestArchiveBrowseAndExtract(t *testing.T) {
+	for _, name := range []string{"teste.tar.gz", "teste.zip"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, name)
+			if filepath.Ext(name) == ".zip" {
+				writeTestZip(t, archivePath)
+			} else {
+				writeTestTarGz(t, archivePath)
+			}
+
+			// O arquivo compactado é tratado como diretório
+			info, err := utils.Stat(archivePath)
+			if err != nil || !info.IsDir() {
+				t.Fatalf("Stat(%s) = %v, %v", name, info, err)
+			}
+
+			// Diretórios implícitos aparecem na listagem
+			files, err := utils.ListDirectory(filepath.Join(archivePath, "docs"), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 2 || files[0].Name != "leia.txt" || !files[1].IsDir {
+				t.Fatalf("ListDirectory(docs) = %+v", files)
+			}
+			if files[0].Path != filepath.Join(archivePath, "docs", "leia.txt") {
+				t.Errorf("Path = %s", files[0].Path)
+			}
+
+			archive, inner, ok := utils.SplitArchivePath(filepath.Join(archivePath, "docs", "sub", "a.txt"))
+			if !ok || archive != archivePath || inner != "docs/sub/a.txt" {
+				t.Fatalf("SplitArchivePath() = %s, %s, %v", archive, inner, ok)
+			}
+			if _, _, ok := utils.SplitArchivePath(dir); ok {
+				t.Errorf("SplitArchivePath(%s) deveria ser um caminho real", dir)
+			}
+
+			a, err := utils.OpenArchive(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := a.ReadFile("docs/leia.txt", 0)
+			if err != nil || string(data) != "olá" {
+				t.Fatalf("ReadFile() = %q, %v", data, err)
+			}
+
+			// Extrair "docs" cria o diretório no destino com o conteúdo
+			dest := filepath.Join(dir, "destino")
+			os.MkdirAll(dest, 0755)
+			if err := a.Extract(nil, []string{"docs"}, dest); err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dest, "docs", "sub", "a.txt")); string(data) != "a" {
+				t.Errorf("docs/sub/a.txt = %q", data)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "raiz.txt")); !os.IsNotExist(err) {
+				t.Errorf("raiz.txt não deveria ser extraído")
+			}
+
+			// Extrair tudo não pode escrever fora do destino
+			all := filepath.Join(dir, "tudo")
+			os.MkdirAll(all, 0755)
+			if err := a.Extract(nil, []string{""}, all); err != nil {
+				t.Fatalf("Extract(tudo) error = %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "fora")); !os.IsNotExist(err) {
+				t.Errorf("entrada com ../ foi extraída fora do destino")
+			}
+			if data, _ := os.ReadFile(filepath.Join(all, "raiz.txt")); string(data) != "raiz" {
+				t.Errorf("raiz.txt = %q", data)
+			}
+		})
+	}
+}
+
+func TestCreateArchiveFormats(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "projeto")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(src, "debug.log"), []byte("log"), 0644)
+	os.WriteFile(filepath.Join(src, "sub", "run.sh"), []byte("#!/bin/sh"), 0755)
+	os.Symlink("main.go", filepath.Join(src, "link.go"))
+
+	for _, format := range utils.WritableArchiveFormats {
+		t.Run(format, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "saida"+utils.ArchiveExtension(format))
+			opts := utils.ArchiveOptions{
+				Format:              format,
+				Level:               9,
+				Exclude:             utils.ParseExcludePatterns("*.log"),
+				PreservePermissions: true,
+				PreserveSymlinks:    true,
+			}
+			if err := utils.CreateArchive(nil, []string{src}, dst, opts); err != nil {
+				t.Fatalf("CreateArchive() error = %v", err)
+			}
+
+			a, err := utils.OpenArchive(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, err := a.ReadFile("projeto/main.go", 0); err != nil || string(data) != "package main" {
+				t.Errorf("projeto/main.go = %q, %v", data, err)
+			}
+			if _, err := a.Stat("projeto/debug.log"); err == nil {
+				t.Errorf("debug.log deveria ter sido excluído")
+			}
+			if info, err := a.Stat("projeto/sub/run.sh"); err != nil || info.Mode().Perm() != 0755 {
+				t.Errorf("run.sh = %v, %v", info, err)
+			}
+			if info, err := a.Stat("projeto/link.go"); err != nil || info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("link.go deveria ser um link simbólico: %v, %v", info, err)
+			}
+		})
+	}
+
+	// tar.bz2 é somente leitura
+	err := utils.CreateArchive(nil, []string{src}, filepath.Join(t.TempDir(), "x.tar.bz2"), utils.ArchiveOptions{Format: utils.ArchiveTarBz2})
+	if err == nil {
+		t.Errorf("criar tar.bz2 deveria falhar")
+	}
+}
+
+func TestCreateArchiveVolumes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "dados.bin")
+	data := make([]byte, 5000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	os.WriteFile(src, data, 0644)
+
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "dados.tar")
+	err := utils.CreateArchive(nil, []string{src}, dst, utils.ArchiveOptions{Format: utils.ArchiveTar, VolumeSize: 2048})
+	if err != nil {
+		t.Fatalf("CreateArchive() error = %v", err)
+	}
+
+	// Juntar os volumes deve produzir um tar válido
+	var joined []byte
+	for n := 1; ; n++ {
+		part, err := os.ReadFile(utils.VolumePath(dst, n))
+		if err != nil {
+			break
+		}
+		if len(part) > 2048 {
+			t.Errorf("volume %d tem %d bytes", n, len(part))
+		}
+		joined = append(joined, part...)
+	}
+	if len(joined) <= 2048 {
+		t.Fatalf("esperava mais de um volume, total %d bytes", len(joined))
+	}
+	os.WriteFile(dst, joined, 0644)
+
+	a, err := utils.OpenArchive(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := a.ReadFile("dados.bin", 0); err != nil || string(got) != string(data) {
+		t.Errorf("conteúdo dos volumes difere do original: %v", err)
+	}
+}
+
+func TestArchiveExtractSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "fora")
+	os.MkdirAll(outside, 0755)
+
+	// Link para fora do destino seguido de uma entrada "através" dele
+	archivePath := filepath.Join(dir, "malicioso.tar")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(file)
+	tw.WriteHeader(&tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside, Mode: 0777})
+	tw.WriteHeader(&tar.Header{Name: "a/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+	tw.Write([]byte("x"))
+	tw.WriteHeader(&tar.Header{Name: "b/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+	tw.Write([]byte("x"))
+	tw.Close()
+	file.Close()
+
+	// Um link já existente no destino também não pode ser atravessado
+	dest := filepath.Join(dir, "destino")
+	os.MkdirAll(dest, 0755)
+	if err := os.Symlink(outside, filepath.Join(dest, "b")); err != nil {
+		t.Skip("links simbólicos não suportados:", err)
+	}
+
+	a, err := utils.OpenArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := a.Extract(nil, []string{name}, dest); err == nil {
+			t.Errorf("Extract(%s) deveria falhar", name)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "pwned")); !os.IsNotExist(err) {
+		t.Errorf("a extração gravou fora do destino")
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "a")); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("link para fora do destino foi criado")
+	}
+}
+
+func TestArchiveExtractHardLinks(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "links.tar")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(file)
+	tw.WriteHeader(&tar.Header{Name: "a/dados.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 8})
+	tw.Write([]byte("conteudo"))
+	tw.WriteHeader(&tar.Header{Name: "a/link.txt", Typeflag: tar.TypeLink, Linkname: "a/dados.txt", Mode: 0644})
+	tw.WriteHeader(&tar.Header{Name: "a/fora.txt", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd", Mode: 0644})
+	tw.WriteHeader(&tar.Header{Name: "b/fila", Typeflag: tar.TypeFifo, Mode: 0644})
+	tw.Close()
+	file.Close()
+
+	a, err := utils.OpenArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// O link físico aponta para o mesmo arquivo extraído; o link para uma
+	// entrada que não existe é recusado
+	dest := filepath.Join(dir, "destino")
+	if err := a.Extract(nil, []string{"a"}, dest); err == nil || !strings.Contains(err.Error(), "fora.txt") {
+		t.Errorf("Extract(a) error = %v, want erro em fora.txt", err)
+	}
+	source, err1 := os.Stat(filepath.Join(dest, "a", "dados.txt"))
+	link, err2 := os.Stat(filepath.Join(dest, "a", "link.txt"))
+	if err1 != nil || err2 != nil || !os.SameFile(source, link) {
+		t.Errorf("link.txt não é um link físico de dados.txt: %v, %v", err1, err2)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "a", "fora.txt")); !os.IsNotExist(err) {
+		t.Errorf("fora.txt foi criado: %v", err)
+	}
+
+	// Extraído sozinho, o link recebe o conteúdo do arquivo ligado
+	only := filepath.Join(dir, "so-o-link")
+	if err := a.Extract(nil, []string{"a/link.txt"}, only); err != nil {
+		t.Fatalf("Extract(a/link.txt) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(only, "link.txt")); string(data) != "conteudo" {
+		t.Errorf("link.txt = %q", data)
+	}
+
+	// FIFOs e dispositivos não viram arquivos vazios
+	if err := a.Extract(nil, []string{"b"}, dest); err == nil {
+		t.Error("Extract(b) deveria recusar a FIFO")
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "b", "fila")); !os.IsNotExist(err) {
+		t.Errorf("a FIFO foi extraída como arquivo: %v", err)
+	}
+}
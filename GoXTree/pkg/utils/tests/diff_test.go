@@ -0,0 +1,149 @@
+package utils_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// lcsLength calcula o tamanho da maior subsequência comum (referência para o teste)
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else {
+				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d", "e"}
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for n := 0; n < 500; n++ {
+		a, b := randomLines(), randomLines()
+		lines := utils.DiffLines(a, b, utils.DiffOptions{})
+
+		// O resultado deve reconstruir os dois lados
+		var oldSide, newSide []string
+		equal := 0
+		for _, line := range lines {
+			switch line.Op {
+			case utils.DiffEqual:
+				oldSide, newSide = append(oldSide, line.Old), append(newSide, line.New)
+				equal++
+			case utils.DiffDelete:
+				oldSide = append(oldSide, line.Old)
+			case utils.DiffInsert:
+				newSide = append(newSide, line.New)
+			}
+		}
+		if strings.Join(oldSide, ",") != strings.Join(a, ",") || strings.Join(newSide, ",") != strings.Join(b, ",") {
+			t.Fatalf("resultado não reconstrói as entradas: %v / %v", a, b)
+		}
+
+		// E ser mínimo (linhas iguais = maior subsequência comum)
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("DiffLines(%v, %v) manteve %d linhas, esperava %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestDiffLinesLargeFile(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 50000; i++ {
+		a = append(a, fmt.Sprintf("linha %d", i))
+		if i%1000 == 0 {
+			b = append(b, fmt.Sprintf("alterada %d", i))
+		} else {
+			b = append(b, a[i])
+		}
+	}
+
+	start := time.Now()
+	diff := utils.DiffText(strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n", utils.DiffOptions{})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("DiffText() levou %v", elapsed)
+	}
+	if inserted, deleted := diff.Stats(); inserted != 50 || deleted != 50 || len(diff.Hunks) != 50 {
+		t.Errorf("Stats() = +%d -%d, %d blocos", inserted, deleted, len(diff.Hunks))
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldFile, newFile := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	os.WriteFile(oldFile, []byte("um\ndois\ntrês\nquatro\ncinco\nseis\nsete\noito\nnove\n"), 0644)
+	os.WriteFile(newFile, []byte("um\nDois\ntrês\nquatro\ncinco\nseis\nsete\noito\nnove\ndez"), 0644)
+
+	diff, err := utils.DiffFiles(oldFile, newFile, utils.DiffOptions{Context: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--- " + oldFile + "\n+++ " + newFile + "\n" +
+		"@@ -1,3 +1,3 @@\n um\n-dois\n+Dois\n três\n" +
+		"@@ -9 +9,2 @@\n nove\n+dez\n\\ No newline at end of file\n"
+	if got := diff.Unified(); got != want {
+		t.Errorf("Unified() =\n%s\nesperava\n%s", got, want)
+	}
+
+	// Ignorando maiúsculas só resta a linha adicionada no fim
+	diff, _ = utils.DiffFiles(oldFile, newFile, utils.DiffOptions{IgnoreCase: true})
+	if inserted, deleted := diff.Stats(); inserted != 1 || deleted != 0 {
+		t.Errorf("IgnoreCase: Stats() = +%d -%d", inserted, deleted)
+	}
+
+	// Espaços ignorados
+	diff = utils.DiffText("if (x)  {\n", "if(x) {\n", utils.DiffOptions{IgnoreWhitespace: true})
+	if !diff.Identical() {
+		t.Errorf("IgnoreWhitespace: esperava arquivos iguais, obteve %s", diff.Unified())
+	}
+
+	// Inserção em arquivo vazio
+	diff = utils.DiffText("", "novo\n", utils.DiffOptions{})
+	if got := diff.Unified(); got != "--- \n+++ \n@@ -0,0 +1 @@\n+novo\n" {
+		t.Errorf("Unified(vazio) = %q", got)
+	}
+
+	// Só a quebra de linha final muda: a última linha sai e volta com ela
+	diff = utils.DiffText("a\nx", "a\nx\n", utils.DiffOptions{})
+	if diff.Identical() {
+		t.Error("Identical() ignorou a falta da quebra de linha final")
+	}
+	want = "--- \n+++ \n@@ -1,2 +1,2 @@\n a\n-x\n\\ No newline at end of file\n+x\n"
+	if got := diff.Unified(); got != want {
+		t.Errorf("Unified(sem quebra final) = %q, want %q", got, want)
+	}
+	if inserted, deleted := diff.Stats(); inserted != 1 || deleted != 1 {
+		t.Errorf("Stats(sem quebra final) = +%d -%d", inserted, deleted)
+	}
+}
+
+func TestIntraLineDiff(t *testing.T) {
+	oldSpans, newSpans := utils.IntraLineDiff("Return .T.", "Return .F.")
+	if len(oldSpans) != 1 || oldSpans[0] != [2]int{8, 9} || len(newSpans) != 1 || newSpans[0] != [2]int{8, 9} {
+		t.Errorf("IntraLineDiff() = %v, %v", oldSpans, newSpans)
+	}
+}
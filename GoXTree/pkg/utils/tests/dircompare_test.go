@@ -0,0 +1,86 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestCompareDirectoryTrees(t *testing.T) {
+	left, right := t.TempDir(), t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	write := func(root, name, content string, mtime time.Time) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	// Conteúdo igual com datas diferentes continua idêntico
+	write(left, "igual.txt", "abc", now)
+	write(right, "igual.txt", "abc", now.Add(-time.Hour))
+	// Mesmo tamanho e mesma data, conteúdo diferente
+	write(left, "sub/diferente.txt", "abc", now)
+	write(right, "sub/diferente.txt", "abd", now)
+	write(left, "novo_esq.txt", "versão nova", now)
+	write(right, "novo_esq.txt", "antiga", now.Add(-time.Hour))
+	write(left, "novo_dir.txt", "antiga", now.Add(-time.Hour))
+	write(right, "novo_dir.txt", "versão nova", now)
+	write(left, "tipo", "arquivo", now)
+	os.MkdirAll(filepath.Join(right, "tipo"), 0755)
+	write(left, "so_esq/a.txt", "a", now)
+	write(left, "so_esq/b.txt", "b", now)
+	write(right, "so_dir.txt", "x", now)
+	write(left, ".oculto", "1", now)
+	write(right, ".oculto", "2", now)
+
+	want := map[string]utils.CompareStatus{
+		"igual.txt":                           utils.CompareIdentical,
+		filepath.Join("sub", "diferente.txt"): utils.CompareContentDiffers,
+		"novo_esq.txt":                        utils.CompareNewerLeft,
+		"novo_dir.txt":                        utils.CompareNewerRight,
+		"tipo":                                utils.CompareTypeMismatch,
+		"so_esq":                              utils.CompareOrphanLeft,
+		"so_dir.txt":                          utils.CompareOrphanRight,
+	}
+
+	for _, byteCompare := range []bool{false, true} {
+		entries, err := utils.CompareDirectoryTrees(nil, left, right, utils.DirCompareOptions{ByteCompare: byteCompare})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make(map[string]utils.CompareStatus)
+		for _, entry := range entries {
+			got[entry.RelPath] = entry.Status
+		}
+		if len(got) != len(want) {
+			t.Errorf("ByteCompare=%v: %d itens, esperava %d: %v", byteCompare, len(got), len(want), got)
+		}
+		for path, status := range want {
+			if got[path] != status {
+				t.Errorf("ByteCompare=%v: %s = %v, esperava %v", byteCompare, path, got[path], status)
+			}
+		}
+	}
+
+	// Arquivos ocultos só entram quando solicitados
+	entries, err := utils.CompareDirectoryTrees(nil, left, right, utils.DirCompareOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, entry := range entries {
+		if entry.RelPath == ".oculto" {
+			found = entry.Status == utils.CompareContentDiffers
+		}
+	}
+	if !found {
+		t.Error("IncludeHidden: .oculto deveria aparecer com conteúdo diferente")
+	}
+}
@@ -0,0 +1,116 @@
+package utils_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestFindDuplicateFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Arquivos grandes que só diferem no meio passam pela triagem dos blocos
+	big := bytes.Repeat([]byte("0123456789"), 10000)
+	changed := append([]byte(nil), big...)
+	changed[len(changed)/2] = 'x'
+
+	write("a.txt", []byte("conteúdo igual"))
+	write("sub/b.txt", []byte("conteúdo igual"))
+	write("c.txt", []byte("conteúdo IGUAL"))
+	write("grande1.bin", big)
+	write("sub/grande2.bin", big)
+	write("grande3.bin", changed)
+	write("vazio1", nil)
+	write("vazio2", nil)
+	write(".oculto.txt", []byte("conteúdo igual"))
+	if err := os.Link(filepath.Join(dir, "a.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := utils.FindDuplicatesJob(nil, dir, utils.DuplicateOptions{Recursive: true, Workers: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("esperava 2 grupos, obteve %d: %+v", len(groups), groups)
+	}
+
+	// Grupos ordenados pelo espaço desperdiçado
+	names := func(g utils.DuplicateGroup) []string {
+		var result []string
+		for _, f := range g.Files {
+			rel, _ := filepath.Rel(dir, f.Path)
+			result = append(result, rel)
+		}
+		return result
+	}
+	if got := names(groups[0]); len(got) != 2 || got[0] != "grande1.bin" || got[1] != filepath.Join("sub", "grande2.bin") {
+		t.Errorf("grupo 0 = %v", got)
+	}
+	// O link físico para a.txt não conta como cópia
+	if got := names(groups[1]); len(got) != 2 || got[1] != filepath.Join("sub", "b.txt") {
+		t.Errorf("grupo 1 = %v", got)
+	}
+
+	// Sem recursão e com ocultos
+	groups, err = utils.FindDuplicatesJob(nil, dir, utils.DuplicateOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || len(groups[0].Files) != 2 || groups[0].Files[0].Name != ".oculto.txt" {
+		t.Errorf("sem recursão: %+v", groups)
+	}
+}
+
+func TestDedupFile(t *testing.T) {
+	dir := t.TempDir()
+	old, recent := filepath.Join(dir, "antigo.txt"), filepath.Join(dir, "recente.txt")
+	os.WriteFile(old, []byte("mesmo conteúdo"), 0644)
+	os.WriteFile(recent, []byte("mesmo conteúdo"), 0644)
+	os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+
+	groups, err := utils.FindDuplicateFiles(dir, false)
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("FindDuplicateFiles() = %v, %v", groups, err)
+	}
+	group := groups[0]
+	keep := group.Keeper(utils.KeepOldest)
+	if group.Files[keep].Path != old || group.Files[group.Keeper(utils.KeepNewest)].Path != recent {
+		t.Fatalf("Keeper() escolheu o arquivo errado")
+	}
+
+	var removed []string
+	remove := func(path string) error {
+		removed = append(removed, path)
+		return os.Remove(path)
+	}
+
+	if err := utils.DedupFile(group.Files[keep], group.Files[1-keep], utils.DedupHardlink, remove); err != nil {
+		t.Fatal(err)
+	}
+	oldInfo, _ := os.Stat(old)
+	recentInfo, _ := os.Stat(recent)
+	if !os.SameFile(oldInfo, recentInfo) || len(removed) != 1 {
+		t.Errorf("recente.txt deveria ser um link físico para antigo.txt")
+	}
+
+	// Arquivos alterados desde a busca não são tocados
+	os.WriteFile(old, []byte("alterado"), 0644)
+	if err := utils.DedupFile(group.Files[1-keep], group.Files[keep], utils.DedupDelete, remove); err == nil {
+		t.Error("DedupFile() deveria recusar arquivos alterados")
+	}
+	if len(removed) != 1 {
+		t.Errorf("nada deveria ter sido removido")
+	}
+}
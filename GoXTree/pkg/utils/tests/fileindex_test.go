@@ -0,0 +1,122 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestFileIndex(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"pkg/ui/app_core.go",
+		"pkg/ui/app_finder.go",
+		"pkg/utils/fuzzy.go",
+		"build/saida.bin",
+		".oculto/segredo.txt",
+		"README.md",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := utils.NewFileIndex(root, utils.IndexOptions{})
+	if err := ix.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if !ix.Done() {
+		t.Error("Done() = false after Wait()")
+	}
+
+	// Ocultos e ignorados ficam de fora: pkg, pkg/ui, 2 arquivos, pkg/utils, 1 arquivo, README.md
+	if count, truncated := ix.Status(); count != 7 || truncated {
+		t.Errorf("Status() = %d, %v; want 7, false", count, truncated)
+	}
+
+	matches := ix.Query("uifind", 0)
+	if len(matches) != 1 || matches[0].Path != filepath.Join("pkg", "ui", "app_finder.go") {
+		t.Fatalf("Query(uifind) = %+v", matches)
+	}
+	if len(matches[0].Positions) != 6 {
+		t.Errorf("Positions = %v", matches[0].Positions)
+	}
+
+	// Diretórios também são indexados; caminhos mais curtos ganham nos empates
+	matches = ix.Query("ui", 0)
+	if len(matches) == 0 || matches[0].Path != filepath.Join("pkg", "ui") || !matches[0].IsDir {
+		t.Errorf("Query(ui) = %+v", matches)
+	}
+
+	if got := ix.Query("", 3); len(got) != 3 {
+		t.Errorf("Query(\"\", 3) returned %d items", len(got))
+	}
+	if got := ix.Query("saida", 0); len(got) != 0 {
+		t.Errorf("ignored file was indexed: %+v", got)
+	}
+
+	// Limite de itens e inclusão de ocultos
+	limited := utils.NewFileIndex(root, utils.IndexOptions{MaxEntries: 2, IncludeHidden: true, NoIgnore: true})
+	limited.Wait()
+	if count, truncated := limited.Status(); count != 2 || !truncated {
+		t.Errorf("limited Status() = %d, %v; want 2, true", count, truncated)
+	}
+	all := utils.NewFileIndex(root, utils.IndexOptions{IncludeHidden: true, NoIgnore: true})
+	all.Wait()
+	if got := all.Query("segredo", 0); len(got) != 1 {
+		t.Errorf("hidden file not indexed: %+v", got)
+	}
+	all.Stop()
+}
+
+func TestFileIndexRepoIgnores(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Índice de um subdiretório: valem os .gitignore acima dele e o info/exclude
+	repo := t.TempDir()
+	for name, content := range map[string]string{
+		".git/HEAD":             "ref: refs/heads/main\n",
+		".git/info/exclude":     "*.tmp\n",
+		".gitignore":            "*.log\ngerado/\n",
+		"sub/.gitignore":        "local.txt\n",
+		"sub/pasta/a.go":        "a",
+		"sub/pasta/x.log":       "x",
+		"sub/pasta/y.tmp":       "y",
+		"sub/pasta/local.txt":   "l",
+		"sub/pasta/gerado/z.go": "z",
+		"outro/pasta/local.txt": "o",
+	} {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := utils.NewFileIndex(filepath.Join(repo, "sub", "pasta"), utils.IndexOptions{})
+	if err := ix.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ix.Query("", 0); len(got) != 1 || got[0].Path != "a.go" {
+		t.Errorf("Query(\"\") = %+v, want só a.go", got)
+	}
+
+	// As regras de sub/ não valem para os irmãos
+	ix = utils.NewFileIndex(filepath.Join(repo, "outro", "pasta"), utils.IndexOptions{})
+	ix.Wait()
+	if got := ix.Query("local", 0); len(got) != 1 {
+		t.Errorf("Query(local) = %+v, want outro/pasta/local.txt", got)
+	}
+}
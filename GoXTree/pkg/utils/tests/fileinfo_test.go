@@ -0,0 +1,97 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestListBranch(t *testing.T) {
+	mem := utils.NewMemFS()
+	mem.MkdirAll("/ramo/src/pkg", 0755)
+	mem.MkdirAll("/ramo/.git", 0755)
+	mem.MkdirAll("/ramo/vazio", 0755)
+	utils.WriteFileFS(mem, "/ramo/leia.txt", []byte("a"), 0644)
+	utils.WriteFileFS(mem, "/ramo/src/main.go", []byte("b"), 0644)
+	utils.WriteFileFS(mem, "/ramo/src/pkg/util.go", []byte("c"), 0644)
+	utils.WriteFileFS(mem, "/ramo/src/.env", []byte("d"), 0644)
+	utils.WriteFileFS(mem, "/ramo/.git/HEAD", []byte("e"), 0644)
+
+	names := func(files []utils.FileInfo) []string {
+		var result []string
+		for _, f := range files {
+			result = append(result, filepath.ToSlash(f.Name))
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	// Só arquivos, com o caminho relativo, sem os ocultos
+	files, truncated, err := utils.ListBranchFS(mem, "/ramo", false, 0)
+	if err != nil || truncated {
+		t.Fatalf("ListBranchFS() = %v, %v", truncated, err)
+	}
+	want := []string{"leia.txt", "src/main.go", "src/pkg/util.go"}
+	if got := names(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListBranchFS() = %v, want %v", got, want)
+	}
+	for _, f := range files {
+		if f.Path != filepath.Join("/ramo", f.Name) {
+			t.Errorf("Path = %q para %q", f.Path, f.Name)
+		}
+	}
+
+	files, _, _ = utils.ListBranchFS(mem, "/ramo", true, 0)
+	if len(files) != 5 {
+		t.Errorf("ListBranchFS(ocultos) = %v", names(files))
+	}
+
+	files, truncated, _ = utils.ListBranchFS(mem, "/ramo", true, 2)
+	if len(files) != 2 || !truncated {
+		t.Errorf("ListBranchFS(limite 2) = %v, %v", names(files), truncated)
+	}
+
+	if _, _, err := utils.ListBranchFS(mem, "/inexistente", false, 0); err == nil {
+		t.Error("ListBranchFS() em diretório inexistente não falhou")
+	}
+}
+
+func TestFileInfoDetails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dados.json")
+	os.WriteFile(path, []byte("{}"), 0644)
+	os.Link(path, filepath.Join(dir, "copia.json"))
+
+	files, err := utils.ListFiles(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file utils.FileInfo
+	for _, f := range files {
+		if f.Name == "dados.json" {
+			file = f
+		}
+	}
+
+	if got := utils.FileMIMEType(file); got != "application/json" {
+		t.Errorf("FileMIMEType() = %q", got)
+	}
+	if got := utils.FileMIMEType(utils.FileInfo{Name: "docs", IsDir: true}); got != "inode/directory" {
+		t.Errorf("FileMIMEType(dir) = %q", got)
+	}
+	if got := utils.FileMIMEType(utils.FileInfo{Name: "sem-extensao"}); got != "application/octet-stream" {
+		t.Errorf("FileMIMEType(sem extensão) = %q", got)
+	}
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return
+	}
+	if file.Inode == 0 || file.Links != 2 || file.AccessTime.IsZero() {
+		t.Errorf("inode %d, links %d, acesso %v", file.Inode, file.Links, file.AccessTime)
+	}
+}
@@ -0,0 +1,94 @@
+package utils_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestFilespec(t *testing.T) {
+	now := time.Now()
+	dir := utils.FileInfo{Name: "src", IsDir: true, Mode: os.ModeDir | 0755, ModTime: now.AddDate(-1, 0, 0)}
+	main := utils.FileInfo{Name: "main.go", Size: 2 << 20, ModTime: now.Add(-time.Hour)}
+	test := utils.FileInfo{Name: "main_test.go", Size: 100, ModTime: now.AddDate(0, 0, -30)}
+	prw := utils.FileInfo{Name: "src/Rotina.PRW", Size: 512 << 10, ModTime: now.AddDate(0, 0, -3)}
+	link := utils.FileInfo{Name: "atalho", Mode: os.ModeSymlink | 0777, ModTime: now}
+
+	tests := []struct {
+		spec string
+		want []bool // dir, main, test, prw, link
+	}{
+		{"", []bool{true, true, true, true, true}},
+		{"*.go", []bool{true, true, true, false, false}},
+		{"*.go;*.prw;!*_test.go", []bool{true, true, false, true, false}},
+		{"!src", []bool{false, true, true, true, true}},
+		{"size>1MB", []bool{true, true, false, false, false}},
+		{"size <= 512k", []bool{true, false, true, true, true}},
+		{"modified<7d", []bool{true, true, false, true, true}},
+		{"modified>2w", []bool{true, false, true, false, false}},
+		{"modified<" + now.AddDate(0, 0, -7).Format("2006-01-02"), []bool{true, false, true, false, false}},
+		{"type=dir", []bool{true, false, false, false, false}},
+		{"type!=dir;*.go", []bool{false, true, true, false, false}},
+		{"type=file,link", []bool{false, true, true, true, true}},
+		{"TYPE=f; Size>1K ; *.go", []bool{false, true, false, false, false}},
+	}
+	for _, tt := range tests {
+		filter, err := utils.ParseFilespec(tt.spec)
+		if err != nil {
+			t.Errorf("ParseFilespec(%q) error = %v", tt.spec, err)
+			continue
+		}
+		for i, file := range []utils.FileInfo{dir, main, test, prw, link} {
+			if got := filter.Match(file); got != tt.want[i] {
+				t.Errorf("ParseFilespec(%q).Match(%s) = %v, want %v", tt.spec, file.Name, got, tt.want[i])
+			}
+		}
+	}
+
+	if filter, _ := utils.ParseFilespec(" *.go ;; size>1M "); filter.String() != "*.go;size>1M" {
+		t.Errorf("String() = %q", filter.String())
+	}
+	if filter, err := utils.ParseFilespec(" ; "); filter != nil || err != nil {
+		t.Errorf("ParseFilespec(vazio) = %v, %v; want nil, nil", filter, err)
+	}
+
+	// Datas comparam o dia inteiro, não só o seu início
+	noon := utils.FileInfo{Name: "dia.txt", ModTime: time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local)}
+	late := utils.FileInfo{Name: "noite.txt", ModTime: time.Date(2024, 1, 31, 23, 59, 59, 0, time.Local)}
+	next := utils.FileInfo{Name: "depois.txt", ModTime: time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)}
+	for _, tt := range []struct {
+		spec string
+		want []bool // noon, late, next
+	}{
+		{"modified=2024-01-31", []bool{true, true, false}},
+		{"modified!=2024-01-31", []bool{false, false, true}},
+		{"modified<=2024-01-31", []bool{true, true, false}},
+		{"modified>2024-01-31", []bool{false, false, true}},
+		{"modified<2024-01-31", []bool{false, false, false}},
+		{"modified>=2024-01-31", []bool{true, true, true}},
+	} {
+		filter, err := utils.ParseFilespec(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, file := range []utils.FileInfo{noon, late, next} {
+			if got := filter.Match(file); got != tt.want[i] {
+				t.Errorf("ParseFilespec(%q).Match(%s) = %v, want %v", tt.spec, file.Name, got, tt.want[i])
+			}
+		}
+	}
+
+	for _, spec := range []string{"[a-", "!", "size>muito", "size>1XB", "modified<7x", "type=pasta", "type>dir"} {
+		if _, err := utils.ParseFilespec(spec); err == nil {
+			t.Errorf("ParseFilespec(%q) sem erro", spec)
+		}
+	}
+
+	filter, _ := utils.ParseFilespec("!*_test.go")
+	files := utils.FilterFiles([]utils.FileInfo{dir, main, test}, filter)
+	if len(files) != 2 || files[1].Name != "main.go" {
+		t.Errorf("FilterFiles() = %v", files)
+	}
+}
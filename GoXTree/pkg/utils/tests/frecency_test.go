@@ -0,0 +1,118 @@
+package utils_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"", "/qualquer/coisa", true},
+		{"gxt", "/home/user/GoXTree", true},
+		{"GXT", "/home/user/goxtree", true},
+		{"pkg ui", "/src/GoXTree/pkg/ui", true},
+		{"tgx", "/home/user/GoXTree", false},
+		{"pkg zz", "/src/GoXTree/pkg/ui", false},
+	}
+	for _, tt := range tests {
+		_, positions, ok := utils.FuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, ok, tt.want)
+		}
+		if ok && len(positions) != len([]rune(stripSpaces(tt.pattern))) {
+			t.Errorf("FuzzyMatch(%q, %q) positions = %v", tt.pattern, tt.text, positions)
+		}
+	}
+
+	// As posições são as mais próximas entre si
+	_, positions, _ := utils.FuzzyMatch("ui", "/usr/include/ui")
+	if positions[0] != 13 || positions[1] != 14 {
+		t.Errorf("positions = %v, want [13 14]", positions)
+	}
+
+	// Caracteres consecutivos e no início de palavras pontuam mais
+	contiguous, _, _ := utils.FuzzyMatch("proj", "/home/projects")
+	scattered, _, _ := utils.FuzzyMatch("proj", "/home/p/r/o/j")
+	if contiguous <= scattered {
+		t.Errorf("contiguous score %d <= scattered score %d", contiguous, scattered)
+	}
+	boundary, _, _ := utils.FuzzyMatch("api", "/srv/app/api")
+	middle, _, _ := utils.FuzzyMatch("api", "/srv/app/rapid")
+	if boundary <= middle {
+		t.Errorf("boundary score %d <= middle score %d", boundary, middle)
+	}
+}
+
+func stripSpaces(s string) string {
+	var out []rune
+	for _, r := range s {
+		if r != ' ' {
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+func TestFrecencyDB(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "frecency.json")
+	db, err := utils.LoadFrecencyDB(file)
+	if err != nil {
+		t.Fatalf("LoadFrecencyDB() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		db.Visit("/src/projeto/api")
+	}
+	db.Visit("/src/projeto/apidocs")
+	db.Visit("/tmp")
+
+	// Mais visitado primeiro
+	matches := db.Query("api", 0)
+	if len(matches) != 2 || matches[0].Path != "/src/projeto/api" {
+		t.Fatalf("Query(api) = %+v", matches)
+	}
+	if matches[0].Rank != 5 {
+		t.Errorf("Rank = %v, want 5", matches[0].Rank)
+	}
+	if got := db.Query("", 1); len(got) != 1 || got[0].Path != "/src/projeto/api" {
+		t.Errorf("Query(\"\", 1) = %+v", got)
+	}
+
+	// Persistência
+	if err := db.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := utils.LoadFrecencyDB(file)
+	if err != nil {
+		t.Fatalf("LoadFrecencyDB() error = %v", err)
+	}
+	if loaded.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", loaded.Len())
+	}
+	loaded.Remove("/tmp")
+	if got := loaded.Query("tmp", 0); len(got) != 0 {
+		t.Errorf("Query(tmp) after Remove = %+v", got)
+	}
+}
+
+func TestFrecencyDBAging(t *testing.T) {
+	db, _ := utils.LoadFrecencyDB(filepath.Join(t.TempDir(), "frecency.json"))
+
+	db.Visit("/raro")
+	for i := 0; i < 10000; i++ {
+		db.Visit("/frequente")
+	}
+
+	// Passado o limite, as pontuações envelhecem e o diretório raro é esquecido
+	if got := db.Query("raro", 0); len(got) != 0 {
+		t.Errorf("rarely visited directory was kept: %+v", got)
+	}
+	if got := db.Query("frequente", 0); len(got) != 1 || got[0].Rank >= 10000 {
+		t.Errorf("Query(frequente) = %+v", got)
+	}
+}
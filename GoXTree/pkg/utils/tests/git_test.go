@@ -0,0 +1,243 @@
+package utils_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// gitRun executa o git no repositório de teste, sem a configuração global e do
+// sistema, e retorna a saída
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_NAME=Teste", "GIT_AUTHOR_EMAIL=teste@example.com",
+		"GIT_COMMITTER_NAME=Teste", "GIT_COMMITTER_EMAIL=teste@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitPorcelain retorna as linhas do git status --porcelain em ordem
+func gitPorcelain(t *testing.T, dir string) []string {
+	t.Helper()
+	lines := strings.Split(gitRun(t, dir, "status", "--porcelain", "--untracked-files=all"), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func writeGitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	if _, err := utils.OpenGitRepo(dir); !errors.Is(err, utils.ErrNotGitRepo) {
+		t.Fatalf("OpenGitRepo(fora) error = %v, want ErrNotGitRepo", err)
+	}
+
+	// Histórico: um commit em comum, um só local e um só no upstream
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	writeGitFile(t, dir, "a.txt", "um\n")
+	writeGitFile(t, dir, "b.txt", "b\n")
+	writeGitFile(t, dir, "sub/c.txt", "c\n")
+	writeGitFile(t, dir, ".gitignore", "*.log\nbuild/\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "inicial")
+	base := gitRun(t, dir, "rev-parse", "HEAD")
+	remote := gitRun(t, dir, "commit-tree", "HEAD^{tree}", "-p", base, "-m", "remoto")
+	gitRun(t, dir, "update-ref", "refs/remotes/origin/main", remote)
+	gitRun(t, dir, "config", "branch.main.remote", "origin")
+	gitRun(t, dir, "config", "branch.main.merge", "refs/heads/main")
+	writeGitFile(t, dir, "a.txt", "dois\n")
+	gitRun(t, dir, "commit", "-q", "-am", "local")
+
+	// Alterações de todo tipo
+	writeGitFile(t, dir, "a.txt", "três\n")
+	os.Remove(filepath.Join(dir, "b.txt"))
+	writeGitFile(t, dir, "novo.txt", "novo\n")
+	writeGitFile(t, dir, "x.log", "log\n")
+	writeGitFile(t, dir, "build/out.bin", "bin\n")
+	writeGitFile(t, dir, "sub/c.txt", "c2\n")
+	writeGitFile(t, dir, "sub/d.txt", "d\n")
+	gitRun(t, dir, "add", "sub")
+
+	repo, err := utils.OpenGitRepo(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("OpenGitRepo() error = %v", err)
+	}
+	status, err := repo.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Branch != "main" || len(status.Head) != 7 || status.Upstream != "origin/main" {
+		t.Errorf("Status() = branch %q, head %q, upstream %q", status.Branch, status.Head, status.Upstream)
+	}
+	if status.Ahead != 1 || status.Behind != 1 || status.Summary() != "main ↑1 ↓1" {
+		t.Errorf("Summary() = %q (ahead %d, behind %d)", status.Summary(), status.Ahead, status.Behind)
+	}
+
+	labels := map[string]string{
+		"a.txt":         " M",
+		"b.txt":         " D",
+		"novo.txt":      "??",
+		"x.log":         "!!",
+		"build/out.bin": "!!",
+		"sub/c.txt":     "M ",
+		"sub/d.txt":     "A ",
+		".gitignore":    "",
+	}
+	for name, want := range labels {
+		if got := status.Lookup(filepath.Join(dir, name), false).Label(); got != want {
+			t.Errorf("Lookup(%s).Label() = %q, want %q", name, got, want)
+		}
+	}
+	if got := status.Lookup(filepath.Join(dir, "build"), true).Label(); got != "!!" {
+		t.Errorf("Lookup(build).Label() = %q, want !!", got)
+	}
+	if got := status.Lookup(filepath.Join(dir, "sub"), true).Flags(); got != utils.GitStaged {
+		t.Errorf("Lookup(sub).Flags() = %v, want GitStaged", got)
+	}
+	want := utils.GitStaged | utils.GitModified | utils.GitUntracked
+	if got := status.Lookup(dir, true).Flags(); got != want || got.Markers() != "●✚?" {
+		t.Errorf("Lookup(raiz).Flags() = %v (%q), want %v", got, got.Markers(), want)
+	}
+
+	// Conteúdo no HEAD
+	if data, ok, err := repo.HeadFile(filepath.Join(dir, "a.txt")); err != nil || !ok || string(data) != "dois\n" {
+		t.Errorf("HeadFile(a.txt) = %q, %v, %v", data, ok, err)
+	}
+	if _, ok, err := repo.HeadFile(filepath.Join(dir, "novo.txt")); err != nil || ok {
+		t.Errorf("HeadFile(novo.txt) = %v, %v; want false", ok, err)
+	}
+
+	// Stage e Unstage devem deixar o índice como o git deixaria
+	if err := repo.Stage([]string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "novo.txt")}); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	wantLines := []string{"A  novo.txt", "A  sub/d.txt", "D  b.txt", "M  a.txt", "M  sub/c.txt"}
+	if got := gitPorcelain(t, dir); !equalLines(got, wantLines) {
+		t.Errorf("após Stage(): git status = %q, want %q", got, wantLines)
+	}
+
+	if err := repo.Unstage([]string{filepath.Join(dir, "sub"), filepath.Join(dir, "b.txt")}); err != nil {
+		t.Fatalf("Unstage() error = %v", err)
+	}
+	wantLines = []string{" D b.txt", " M sub/c.txt", "?? sub/d.txt", "A  novo.txt", "M  a.txt"}
+	if got := gitPorcelain(t, dir); !equalLines(got, wantLines) {
+		t.Errorf("após Unstage(): git status = %q, want %q", got, wantLines)
+	}
+	gitRun(t, dir, "fsck", "--no-progress")
+
+	// A leitura própria deve concordar com o git
+	status, err = repo.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status.Lookup(filepath.Join(dir, "sub", "c.txt"), false).Label(); got != " M" {
+		t.Errorf("Lookup(sub/c.txt) após Unstage() = %q, want \" M\"", got)
+	}
+	if got := status.Lookup(filepath.Join(dir, "novo.txt"), false).Label(); got != "A " {
+		t.Errorf("Lookup(novo.txt) após Stage() = %q, want \"A \"", got)
+	}
+}
+
+func equalLines(a, b []string) bool {
+	return strings.Join(a, "\n") == strings.Join(b, "\n")
+}
+
+func TestGitStageAttributes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	// Com eol=lf o git grava o arquivo no índice sem o CR
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	writeGitFile(t, dir, ".gitattributes", "*.txt text eol=lf\n")
+	writeGitFile(t, dir, "crlf.txt", "x\r\ny\r\n")
+
+	repo, err := utils.OpenGitRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Stage([]string{filepath.Join(dir, "crlf.txt")}); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if got := gitRun(t, dir, "cat-file", "-p", ":crlf.txt"); got != "x\ny" {
+		t.Errorf("conteúdo preparado = %q, want %q", got, "x\ny")
+	}
+	if got := gitPorcelain(t, dir); !equalLines(got, []string{"?? .gitattributes", "A  crlf.txt"}) {
+		t.Errorf("git status = %q", got)
+	}
+}
+
+func TestGitUnstageRemovedFromIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	writeGitFile(t, dir, "f.txt", "conteúdo\n")
+	writeGitFile(t, dir, "g.txt", "outro\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "inicial")
+
+	// git rm --cached seguido de Unstage deve deixar tudo limpo
+	gitRun(t, dir, "rm", "-q", "--cached", "f.txt")
+	repo, err := utils.OpenGitRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Unstage([]string{filepath.Join(dir, "f.txt")}); err != nil {
+		t.Fatalf("Unstage() error = %v", err)
+	}
+	if got := gitRun(t, dir, "status", "--porcelain"); got != "" {
+		t.Errorf("git status após Unstage() = %q, want vazio", got)
+	}
+
+	// Entradas sem os dados do arquivo (tamanho zero) são comparadas pelo conteúdo
+	hash := gitRun(t, dir, "rev-parse", "HEAD:g.txt")
+	gitRun(t, dir, "update-index", "--cacheinfo", "100644,"+hash+",g.txt")
+
+	status, err := repo.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"f.txt", "g.txt"} {
+		if got := status.Lookup(filepath.Join(dir, name), false).Label(); got != "" {
+			t.Errorf("Lookup(%s).Label() = %q, want vazio", name, got)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package utils_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestHexFile(t *testing.T) {
+	content := bytes.Repeat([]byte{0}, 3*1024*1024)
+	copy(content[100:], "Agulha")
+	copy(content[2*1024*1024-2:], []byte{0xDE, 0xAD, 0xBE, 0xEF}) // Na divisa entre blocos
+	path := filepath.Join(t.TempDir(), "dados.bin")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := utils.OpenHexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// Buscas de texto e de bytes, para frente e para trás
+	if got, _ := h.Find([]byte("agulha"), 0, false, true); got != 100 {
+		t.Errorf("Find(texto) = %d; want 100", got)
+	}
+	if got, _ := h.Find([]byte("agulha"), 0, false, false); got != -1 {
+		t.Errorf("Find(texto, com maiúsculas) = %d; want -1", got)
+	}
+	pattern, err := utils.ParseHexBytes("de ad 0xBE EF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := int64(2*1024*1024 - 2)
+	if got, _ := h.Find(pattern, 101, false, false); got != want {
+		t.Errorf("Find(bytes) = %d; want %d", got, want)
+	}
+	if got, _ := h.Find(pattern, h.Size(), true, false); got != want {
+		t.Errorf("Find(bytes, para trás) = %d; want %d", got, want)
+	}
+	if got, _ := h.Find(pattern, want, true, false); got != -1 {
+		t.Errorf("Find(bytes, antes) = %d; want -1", got)
+	}
+
+	// Alterações ficam em memória e podem ser desfeitas
+	h.Set(0, 'X')
+	h.Set(1, 'Y')
+	h.Set(1, 0) // Voltar ao original descarta a alteração
+	if !h.Modified() || h.IsModified(1) {
+		t.Errorf("Modified() = %v, IsModified(1) = %v", h.Modified(), h.IsModified(1))
+	}
+	if offset, ok := h.Undo(); !ok || offset != 1 {
+		t.Errorf("Undo() = %d, %v", offset, ok)
+	}
+	if b, _ := h.ByteAt(1); b != 'Y' {
+		t.Errorf("ByteAt(1) após desfazer = %q; want 'Y'", b)
+	}
+	if got, _ := h.Find([]byte("XY"), 0, false, false); got != 0 {
+		t.Errorf("Find() com alterações = %d; want 0", got)
+	}
+	if disk, _ := os.ReadFile(path); disk[0] != 0 {
+		t.Error("alteração gravada antes de salvar")
+	}
+
+	// Salvar cria a cópia de segurança antes
+	if err := h.Save(true); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	disk, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(disk, []byte("XY")) || len(disk) != len(content) {
+		t.Errorf("arquivo salvo começa com %q", disk[:2])
+	}
+	backup, _ := os.ReadFile(h.BackupPath())
+	if !bytes.Equal(backup, content) {
+		t.Error("backup diferente do original")
+	}
+	if h.Modified() {
+		t.Error("Modified() = true após salvar")
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"1024", 1024},
+		{"0x400", 1024},
+		{"$400", 1024},
+		{"400h", 1024},
+		{"50%", 2048},
+		{"+16", 116},
+		{"-0x10", 84},
+		{"99999", 4095},
+	}
+	for _, tt := range tests {
+		got, err := utils.ParseOffset(tt.text, 100, 4096)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseOffset(%q) = %d, %v; want %d", tt.text, got, err, tt.want)
+		}
+	}
+	if _, err := utils.ParseOffset("xyz", 0, 4096); err == nil {
+		t.Error("ParseOffset(xyz) sem erro")
+	}
+}
+
+func TestInspectBytes(t *testing.T) {
+	values := utils.InspectBytes([]byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F})
+	found := map[string]utils.InspectorValue{}
+	for _, v := range values {
+		found[v.Name] = v
+	}
+	if v := found["uint16"]; v.LittleEndian != "513" || v.BigEndian != "258" {
+		t.Errorf("uint16 = %+v", v)
+	}
+	if v := found["int8"]; v.LittleEndian != "1" {
+		t.Errorf("int8 = %+v", v)
+	}
+	if v := found["float64"]; v.BigEndian == "" || v.LittleEndian == "" {
+		t.Errorf("float64 = %+v", v)
+	}
+
+	// Tipos que não cabem nos bytes disponíveis ficam em branco
+	for _, v := range utils.InspectBytes([]byte{0xFF, 0xFF}) {
+		if v.Name == "uint32" && v.LittleEndian != "" {
+			t.Errorf("uint32 com 2 bytes = %+v", v)
+		}
+		if v.Name == "int16" && v.LittleEndian != "-1" {
+			t.Errorf("int16 = %+v", v)
+		}
+	}
+}
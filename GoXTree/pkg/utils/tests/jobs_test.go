@@ -0,0 +1,176 @@
+package utils_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// blockSize é o tamanho do bloco das cópias com progresso (jobBufferSize)
+const blockSize = 256 * 1024
+
+// submitAndWait executa a operação e espera o término
+func submitAndWait(t *testing.T, m *utils.JobManager, run utils.JobFunc) utils.JobSnapshot {
+	t.Helper()
+	done := make(chan struct{})
+	job := m.Submit("teste", run, func(*utils.Job) { close(done) })
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("a operação não terminou")
+	}
+	return job.Snapshot()
+}
+
+func TestJobProgressTotals(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("abc"), 0644)
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "b.bin"), make([]byte, blockSize+10), 0644)
+	dst := filepath.Join(t.TempDir(), "copia")
+
+	m := utils.NewJobManager(1, nil)
+	s := submitAndWait(t, m, func(jc *utils.JobContext) error {
+		jc.Scan(src)
+		return utils.CopyPathJob(jc, src, dst)
+	})
+
+	wantBytes := int64(3 + blockSize + 10)
+	if s.Status != utils.JobDone || s.Err != nil {
+		t.Fatalf("Status = %v, Err = %v", s.Status, s.Err)
+	}
+	if s.TotalFiles != 2 || s.DoneFiles != 2 || s.TotalBytes != wantBytes || s.DoneBytes != wantBytes {
+		t.Errorf("progresso = %d/%d arquivos, %d/%d bytes; want 2/2, %d/%d",
+			s.DoneFiles, s.TotalFiles, s.DoneBytes, s.TotalBytes, wantBytes, wantBytes)
+	}
+	if s.Percent() != 100 || s.CurrentFile != "" {
+		t.Errorf("Percent() = %d, CurrentFile = %q", s.Percent(), s.CurrentFile)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "sub", "b.bin")); err != nil || info.Size() != blockSize+10 {
+		t.Errorf("cópia incompleta: %v", err)
+	}
+}
+
+func TestJobFailAggregatesErrors(t *testing.T) {
+	// Sem operação em segundo plano o erro é devolvido a quem chamou
+	var jc *utils.JobContext
+	if err := jc.Fail("x", os.ErrNotExist); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Fail() sem contexto = %v, want o próprio erro", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "ok.txt"), []byte("ok"), 0644)
+	sources := []string{filepath.Join(dir, "falta1"), filepath.Join(dir, "ok.txt"), filepath.Join(dir, "falta2")}
+	dst := t.TempDir()
+
+	m := utils.NewJobManager(1, nil)
+	s := submitAndWait(t, m, func(jc *utils.JobContext) error {
+		for _, src := range sources {
+			if err := utils.CopyPathJob(jc, src, filepath.Join(dst, filepath.Base(src))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Os erros são guardados e a operação segue com os demais arquivos
+	if s.Status != utils.JobFailed || s.Err == nil || !strings.Contains(s.Err.Error(), "2 arquivo(s)") {
+		t.Errorf("Status = %v, Err = %v", s.Status, s.Err)
+	}
+	if len(s.Errors) != 2 || s.Errors[0].Path != sources[0] || s.Errors[1].Path != sources[2] {
+		t.Errorf("Errors = %+v", s.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "ok.txt")); err != nil {
+		t.Errorf("o arquivo sem erro não foi copiado: %v", err)
+	}
+}
+
+// gatedFS entrega o primeiro bloco de cada arquivo e segura a leitura seguinte
+// até release ser fechado; reading é fechado quando a segunda leitura começa
+type gatedFS struct {
+	utils.VFS
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedFS) Open(name string) (io.ReadCloser, error) {
+	data, err := utils.ReadFileFS(g.VFS, name)
+	if err != nil {
+		return nil, err
+	}
+	return &gatedReader{r: bytes.NewReader(data), fs: g}, nil
+}
+
+type gatedReader struct {
+	r     *bytes.Reader
+	fs    *gatedFS
+	reads int
+	once  sync.Once
+}
+
+func (g *gatedReader) Read(p []byte) (int, error) {
+	g.reads++
+	if g.reads > 1 {
+		g.once.Do(func() { close(g.fs.reading) })
+		<-g.fs.release
+	}
+	return g.r.Read(p)
+}
+
+func (g *gatedReader) Close() error { return nil }
+
+func TestJobPauseAndCancelMidCopy(t *testing.T) {
+	mem := utils.NewMemFS()
+	mem.MkdirAll("/memfs-jobs", 0755)
+	utils.WriteFileFS(mem, "/memfs-jobs/grande.bin", make([]byte, 4*blockSize), 0644)
+	src := &gatedFS{VFS: mem, reading: make(chan struct{}), release: make(chan struct{})}
+	dst := filepath.Join(t.TempDir(), "grande.bin")
+
+	m := utils.NewJobManager(1, nil)
+	done := make(chan struct{})
+	job := m.Submit("copiar", func(jc *utils.JobContext) error {
+		jc.AddTotal(1, 4*blockSize)
+		return utils.CopyPathJobFS(jc, src, "/memfs-jobs/grande.bin", utils.Local, dst)
+	}, func(*utils.Job) { close(done) })
+
+	// O primeiro bloco já foi gravado quando a segunda leitura começa
+	select {
+	case <-src.reading:
+	case <-time.After(10 * time.Second):
+		t.Fatal("a cópia não começou")
+	}
+	job.Pause()
+	close(src.release)
+
+	// Pausada, a cópia para no próximo ponto de verificação (antes do 2º bloco)
+	time.Sleep(100 * time.Millisecond)
+	s := job.Snapshot()
+	if s.Status != utils.JobPaused || s.DoneBytes != blockSize {
+		t.Errorf("pausada: Status = %v, DoneBytes = %d; want Pausado, %d", s.Status, s.DoneBytes, blockSize)
+	}
+	if info, err := os.Stat(dst); err != nil || info.Size() != blockSize {
+		t.Fatalf("esperava o arquivo parcial durante a pausa: %v", err)
+	}
+
+	// Cancelada, a cópia termina sem deixar o arquivo parcial
+	job.Cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("a operação cancelada não terminou")
+	}
+	if s := job.Snapshot(); s.Status != utils.JobCanceled {
+		t.Errorf("Status = %v, want Cancelado", s.Status)
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("o arquivo parcial ficou no destino: %v", err)
+	}
+}
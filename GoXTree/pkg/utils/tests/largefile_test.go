@@ -0,0 +1,155 @@
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// waitIndex aguarda o fim da indexação do arquivo
+func waitIndex(t *testing.T, f *utils.LargeFile) int64 {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lines, _, done := f.IndexProgress()
+		if done {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("indexação não terminou")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLargeFile(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 3000; i++ {
+		switch i {
+		case 1500:
+			sb.WriteString("linha 1500 com a agulha\r\n")
+		case 2000:
+			sb.WriteString(strings.Repeat("x", 10000) + "\n")
+		default:
+			fmt.Fprintf(&sb, "linha %d\n", i)
+		}
+	}
+	sb.WriteString("última sem fim de linha")
+	path := filepath.Join(t.TempDir(), "grande.log")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := utils.OpenLargeFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if lines := waitIndex(t, f); lines != 3001 {
+		t.Errorf("linhas = %d; want 3001", lines)
+	}
+
+	// Ir para uma linha e voltar a partir dela
+	offset, ok := f.LineOffset(1000)
+	if !ok {
+		t.Fatal("LineOffset(1000) não encontrou a linha")
+	}
+	lines, err := f.ReadLines(offset, 2)
+	if err != nil || len(lines) != 2 || lines[0].Text != "linha 1000" || lines[1].Text != "linha 1001" {
+		t.Fatalf("ReadLines() = %+v, %v", lines, err)
+	}
+	if line, ok := f.LineNumberAt(lines[1].Offset); !ok || line != 1001 {
+		t.Errorf("LineNumberAt() = %d, %v; want 1001", line, ok)
+	}
+	if got := f.LinesBefore(offset, 3); got != mustLineOffset(t, f, 997) {
+		t.Errorf("LinesBefore(3) = %d", got)
+	}
+	if got := f.LineStart(offset + 4); got != offset {
+		t.Errorf("LineStart() = %d; want %d", got, offset)
+	}
+	if _, ok := f.LineOffset(5000); ok {
+		t.Error("LineOffset(5000) encontrou uma linha inexistente")
+	}
+
+	// Linhas longas são cortadas para exibição; CR é removido
+	lines, _ = f.ReadLines(mustLineOffset(t, f, 2000), 1)
+	if len(lines) != 1 || len(lines[0].Text) != 4096 {
+		t.Errorf("linha longa com %d bytes", len(lines[0].Text))
+	}
+	lines, _ = f.ReadLines(mustLineOffset(t, f, 1500), 1)
+	if lines[0].Text != "linha 1500 com a agulha" {
+		t.Errorf("linha com CRLF = %q", lines[0].Text)
+	}
+
+	// Última página e percentual
+	lines, _ = f.ReadLines(f.LastPage(3), 5)
+	if len(lines) != 3 || lines[2].Text != "última sem fim de linha" {
+		t.Errorf("LastPage() = %+v", lines)
+	}
+	if line, _ := f.LineNumberAt(f.OffsetForPercent(0)); line != 0 {
+		t.Errorf("OffsetForPercent(0) na linha %d", line)
+	}
+
+	// Busca para frente e para trás
+	re := regexp.MustCompile("(?i)AGULHA")
+	found, err := f.Search(re, 0, false, nil)
+	if err != nil || found != mustLineOffset(t, f, 1500) {
+		t.Errorf("Search(frente) = %d, %v", found, err)
+	}
+	if found, _ := f.Search(re, mustLineOffset(t, f, 1501), false, nil); found != -1 {
+		t.Errorf("Search(frente, depois) = %d; want -1", found)
+	}
+	if found, _ := f.Search(re, f.Size(), true, nil); found != mustLineOffset(t, f, 1500) {
+		t.Errorf("Search(trás) = %d", found)
+	}
+	if found, _ := f.Search(re, mustLineOffset(t, f, 1500), true, nil); found != -1 {
+		t.Errorf("Search(trás, antes) = %d; want -1", found)
+	}
+	if found, _ := f.Search(regexp.MustCompile("^linha 0$"), f.Size(), true, nil); found != 0 {
+		t.Errorf("Search(trás, primeira linha) = %d; want 0", found)
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if _, err := f.Search(re, 0, false, stop); err != utils.ErrSearchStopped {
+		t.Errorf("Search(interrompida) error = %v", err)
+	}
+
+	// Modo seguir: o índice acompanha o crescimento e o truncamento do arquivo
+	out, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.WriteString("\nnova linha\n")
+	out.Close()
+	if changed, err := f.Refresh(); !changed || err != nil {
+		t.Fatalf("Refresh() = %v, %v", changed, err)
+	}
+	if lines := waitIndex(t, f); lines != 3002 {
+		t.Errorf("linhas após crescer = %d; want 3002", lines)
+	}
+
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.Refresh()
+	if lines := waitIndex(t, f); lines != 2 {
+		t.Errorf("linhas após truncar = %d; want 2", lines)
+	}
+}
+
+func mustLineOffset(t *testing.T, f *utils.LargeFile, line int64) int64 {
+	t.Helper()
+	offset, ok := f.LineOffset(line)
+	if !ok {
+		t.Fatalf("LineOffset(%d) não encontrou a linha", line)
+	}
+	return offset
+}
@@ -0,0 +1,153 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestFormatMode(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want string
+	}{
+		{0644, "-rw-r--r--"},
+		{os.ModeDir | 0755, "drwxr-xr-x"},
+		{os.ModeSymlink | 0777, "lrwxrwxrwx"},
+		{os.ModeSetuid | 0755, "-rwsr-xr-x"},
+		{os.ModeSetuid | 0644, "-rwSr--r--"},
+		{os.ModeDir | os.ModeSetgid | 0775, "drwxrwsr-x"},
+		{os.ModeDir | os.ModeSticky | 0777, "drwxrwxrwt"},
+		{os.ModeDir | os.ModeSticky | 0770, "drwxrwx--T"},
+		{os.ModeNamedPipe | 0600, "prw-------"},
+	}
+	for _, tt := range tests {
+		if got := utils.FormatMode(tt.mode); got != tt.want {
+			t.Errorf("FormatMode(%v) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+	if got := utils.FormatOctalMode(os.ModeSetgid | os.ModeSticky | 0750); got != "3750" {
+		t.Errorf("FormatOctalMode() = %q", got)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		spec    string
+		current os.FileMode
+		isDir   bool
+		want    os.FileMode
+	}{
+		{"755", 0600, false, 0755},
+		{"0640", 0777, false, 0640},
+		{"4755", 0, false, os.ModeSetuid | 0755},
+		{"u+x", 0644, false, 0744},
+		{"go-w", 0666, false, 0644},
+		{"+x", 0644, false, 0755},
+		{"a=r", 0755, false, 0444},
+		{"u=rwx,go=rx", 0, false, 0755},
+		{"a+X", 0644, false, 0644},
+		{"a+X", 0644, true, 0755},
+		{"a+X", 0744, false, 0755},
+		{"g=u", 0700, false, 0770},
+		{"u+s", 0755, false, os.ModeSetuid | 0755},
+		{"g+s,+t", 0775, true, os.ModeSetgid | os.ModeSticky | 0775},
+		{"o-rwx+t", 0777, true, os.ModeSticky | 0770},
+		{"u-s", os.ModeSetuid | 0755, false, 0755},
+		{"go=", os.ModeSetgid | 0775, false, 0700},
+		{"go=", os.ModeSetgid | 0775, true, os.ModeSetgid | 0700},
+		{"g-s", os.ModeSetgid | 0775, true, 0775},
+		{"755", os.ModeSetgid | 0775, true, os.ModeSetgid | 0755},
+		{"0755", os.ModeSetgid | 0775, true, 0755},
+	}
+	for _, tt := range tests {
+		got, err := utils.ParseMode(tt.spec, tt.current, tt.isDir)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMode(%q, %v) = %v, %v; want %v", tt.spec, tt.current, got, err, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "8", "77777", "u", "u*x", "z+x", "u+x,"} {
+		if _, err := utils.ParseMode(spec, 0644, false); err == nil {
+			t.Errorf("ParseMode(%q) sem erro", spec)
+		}
+	}
+}
+
+func TestChangeAttributes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissões Unix")
+	}
+
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	file := filepath.Join(sub, "a.sh")
+	os.WriteFile(file, []byte("#!/bin/sh\n"), 0644)
+	other := filepath.Join(dir, "b.txt")
+	os.WriteFile(other, []byte("b"), 0644)
+	link := filepath.Join(dir, "link")
+	os.Symlink("b.txt", link)
+
+	mode := func(path string) os.FileMode {
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode() & (os.ModePerm | os.ModeSetgid)
+	}
+
+	// Sem recursão, só o diretório muda
+	change := utils.AttrChange{Mode: "g+s,o-rx", UID: -1, GID: -1}
+	if err := utils.ChangeAttributesJob(nil, []string{sub}, change); err != nil {
+		t.Fatal(err)
+	}
+	if mode(sub) != os.ModeSetgid|0750 || mode(file) != 0644 {
+		t.Errorf("modos = %v, %v", mode(sub), mode(file))
+	}
+
+	// Recursivo: X liga a execução só no diretório
+	change = utils.AttrChange{Mode: "u=rwX,go=", UID: -1, GID: -1, Recursive: true}
+	if err := utils.ChangeAttributesJob(nil, []string{dir}, change); err != nil {
+		t.Fatal(err)
+	}
+	if mode(sub) != os.ModeSetgid|0700 || mode(file) != 0600 || mode(other) != 0600 {
+		t.Errorf("modos = %v, %v, %v", mode(sub), mode(file), mode(other))
+	}
+
+	// O dono atual pode ser reaplicado sem privilégios
+	uid, gid := os.Getuid(), os.Getgid()
+	change = utils.AttrChange{UID: uid, GID: gid, Recursive: true}
+	if err := utils.ChangeAttributesJob(nil, []string{dir}, change); err != nil {
+		t.Errorf("ChangeAttributesJob(chown) = %v", err)
+	}
+
+	if err := utils.ChangeAttributesJob(nil, []string{dir}, utils.AttrChange{Mode: "u+q", UID: -1, GID: -1}); err == nil {
+		t.Error("ChangeAttributesJob() aceitou modo inválido")
+	}
+
+	// A listagem traz modo, dono e destino dos links
+	files, err := utils.ListFiles(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]utils.FileInfo{}
+	for _, f := range files {
+		byName[f.Name] = f
+	}
+	if got := utils.FormatMode(byName["sub"].Mode); got != "drwx--S---" {
+		t.Errorf("modo de sub = %q", got)
+	}
+	if byName["link"].LinkTarget != "b.txt" || byName["b.txt"].LinkTarget != "" {
+		t.Errorf("destinos = %q, %q", byName["link"].LinkTarget, byName["b.txt"].LinkTarget)
+	}
+	if byName["b.txt"].Owner == "" || byName["b.txt"].Group == "" {
+		t.Error("dono e grupo não informados")
+	}
+	if id, err := utils.LookupUserID(byName["b.txt"].Owner); err != nil || id != uid {
+		t.Errorf("LookupUserID(%q) = %d, %v", byName["b.txt"].Owner, id, err)
+	}
+}
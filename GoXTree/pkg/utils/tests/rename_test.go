@@ -0,0 +1,202 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// renameFixture cria os arquivos informados e retorna seus caminhos
+func renameFixture(t *testing.T, names ...string) (string, []string) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return dir, paths
+}
+
+func TestPlanRenames(t *testing.T) {
+	_, paths := renameFixture(t, "IMG_0001.JPG", "IMG_0002.JPG", "notas da reunião.txt")
+	date := time.Date(2024, 3, 15, 10, 30, 0, 0, time.Local)
+	os.Chtimes(paths[0], date, date)
+
+	tests := []struct {
+		rule utils.RenameRule
+		want []string
+	}{
+		{utils.RenameRule{Find: `IMG_(\d+)`, Replace: "foto-$1", Regexp: true}, []string{"foto-0001.JPG", "foto-0002.JPG", "notas da reunião.txt"}},
+		{utils.RenameRule{Template: "{n:03} {name}", CounterStart: 1, CounterStep: 5}, []string{"001 IMG_0001.JPG", "006 IMG_0002.JPG", "011 notas da reunião.txt"}},
+		{utils.RenameRule{Case: utils.CaseTitle, Extension: "md"}, []string{"Img_0001.md", "Img_0002.md", "Notas Da Reunião.md"}},
+		{utils.RenameRule{Case: utils.CaseLower, Extension: "."}, []string{"img_0001", "img_0002", "notas da reunião"}},
+		{utils.RenameRule{Template: "{date:AAAAMMDD_hhmm}-{ext}"}, []string{"20240315_1030-JPG.JPG"}},
+	}
+
+	for i, tt := range tests {
+		plans, err := utils.PlanRenames(paths, tt.rule)
+		if err != nil {
+			t.Fatalf("caso %d: %v", i, err)
+		}
+		for j, want := range tt.want {
+			if got := filepath.Base(plans[j].New); got != want {
+				t.Errorf("caso %d: %s -> %q, esperava %q", i, filepath.Base(paths[j]), got, want)
+			}
+		}
+	}
+
+	if _, err := utils.PlanRenames(paths, utils.RenameRule{Template: "{xyz}"}); err == nil {
+		t.Error("token desconhecido deveria ser rejeitado")
+	}
+	if _, err := utils.PlanRenames(paths, utils.RenameRule{Find: "(", Regexp: true}); err == nil {
+		t.Error("expressão regular inválida deveria ser rejeitada")
+	}
+}
+
+func TestPlanRenamesCollisions(t *testing.T) {
+	dir, paths := renameFixture(t, "a.txt", "b.txt", "c.txt", "ocupado.txt")
+
+	// Todos com o mesmo nome
+	plans, _ := utils.PlanRenames(paths[:3], utils.RenameRule{Template: "igual"})
+	for _, plan := range plans {
+		if plan.Status != utils.RenameCollision {
+			t.Errorf("%s: status %v, esperava colisão", plan.Old, plan.Status)
+		}
+	}
+
+	// Nome já ocupado por um arquivo fora do lote
+	plans, _ = utils.PlanRenames(paths[:1], utils.RenameRule{Template: "ocupado"})
+	if plans[0].Status != utils.RenameCollision {
+		t.Errorf("esperava colisão com ocupado.txt")
+	}
+	if _, err := utils.ApplyRenames(plans); err == nil {
+		t.Error("ApplyRenames() deveria recusar um plano com colisões")
+	}
+
+	// Nome com separador
+	plans, _ = utils.PlanRenames(paths[:1], utils.RenameRule{Template: "x/y"})
+	if plans[0].Status != utils.RenameInvalid {
+		t.Errorf("esperava nome inválido")
+	}
+
+	// a -> b com b.txt fora do lote de renomeação
+	plans, _ = utils.PlanRenames(paths[:1], utils.RenameRule{Find: "a", Replace: "b"})
+	if plans[0].Status != utils.RenameCollision {
+		t.Errorf("a->b com b.txt inalterado deveria colidir")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyRenamesCycleAndUndo(t *testing.T) {
+	_, paths := renameFixture(t, "1.txt", "2.txt")
+
+	// Contador decrescente troca os nomes: 1 -> 2 e 2 -> 1
+	plans, err := utils.PlanRenames(paths, utils.RenameRule{Template: "{n}", CounterStart: 2, CounterStep: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plan := range plans {
+		if plan.Status != utils.RenameReady || !plan.Cycle {
+			t.Fatalf("%s: status %v, ciclo %v", plan.Old, plan.Status, plan.Cycle)
+		}
+	}
+
+	steps, err := utils.ApplyRenames(plans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != "2.txt" {
+		t.Errorf("1.txt contém %q após a troca", data)
+	}
+
+	// Desfazer devolve os nomes originais
+	if err := (utils.UndoOperation{Steps: steps}).Undo(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if data, _ := os.ReadFile(path); string(data) != filepath.Base(path) {
+			t.Errorf("%s contém %q após desfazer", filepath.Base(path), data)
+		}
+	}
+}
+
+func TestApplyRenamesRollback(t *testing.T) {
+	dir, paths := renameFixture(t, "1.txt", "2.txt", "3.txt")
+
+	// Cadeia 1 -> 2 -> 3 -> 4, liberada pelos próprios itens
+	plans, err := utils.PlanRenames(paths, utils.RenameRule{Template: "{n}", CounterStart: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plan := range plans {
+		if plan.Status != utils.RenameReady || plan.Cycle {
+			t.Fatalf("%s: status %v, ciclo %v", plan.Old, plan.Status, plan.Cycle)
+		}
+	}
+
+	// O último item some antes de aplicar: nada deve mudar
+	os.Remove(paths[2])
+	if _, err := utils.ApplyRenames(plans); err == nil {
+		t.Fatal("ApplyRenames() deveria falhar")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("%d itens no diretório após reverter", len(entries))
+	}
+	for _, path := range paths[:2] {
+		if data, _ := os.ReadFile(path); string(data) != filepath.Base(path) {
+			t.Errorf("%s contém %q após reverter", filepath.Base(path), data)
+		}
+	}
+}
+
+func TestReadEXIFDate(t *testing.T) {
+	// TIFF mínimo: IFD0 apontando para o IFD EXIF com DateTimeOriginal
+	tiff := new(bytes.Buffer)
+	le := binary.LittleEndian
+	tiff.WriteString("II*\x00")
+	binary.Write(tiff, le, uint32(8))
+	binary.Write(tiff, le, uint16(1))
+	binary.Write(tiff, le, []uint16{0x8769, 4})
+	binary.Write(tiff, le, []uint32{1, 26})
+	binary.Write(tiff, le, uint32(0))
+	binary.Write(tiff, le, uint16(1))
+	binary.Write(tiff, le, []uint16{0x9003, 2})
+	binary.Write(tiff, le, []uint32{20, 44})
+	binary.Write(tiff, le, uint32(0))
+	tiff.WriteString("2023:07:01 12:34:56\x00")
+
+	jpeg := new(bytes.Buffer)
+	jpeg.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(jpeg, binary.BigEndian, uint16(2+6+tiff.Len()))
+	jpeg.WriteString("Exif\x00\x00")
+	jpeg.Write(tiff.Bytes())
+	jpeg.Write([]byte{0xFF, 0xD9})
+
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "foto.jpg")
+	os.WriteFile(photo, jpeg.Bytes(), 0644)
+
+	date, err := utils.ReadEXIFDate(utils.Local, photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2023, 7, 1, 12, 34, 56, 0, time.Local); !date.Equal(want) {
+		t.Errorf("ReadEXIFDate() = %v, esperava %v", date, want)
+	}
+
+	plans, _ := utils.PlanRenames([]string{photo}, utils.RenameRule{Template: "{exif:AAAA-MM-DD_hhmmss}"})
+	if got := filepath.Base(plans[0].New); got != "2023-07-01_123456.jpg" {
+		t.Errorf("{exif} = %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestSearchFilesContent(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".gitignore", "build/\n*.log\n!keep.log\n/raiz.txt\n")
+	write("src/.ignore", "gerado_*.go\n")
+	write("src/main.go", "package main\n\n// TODO: primeiro\nfunc main() {\n\t// TODO: segundo\n}\n")
+	write("src/gerado_x.go", "// TODO: ignorado pelo .ignore\n")
+	write("src/raiz.txt", "TODO: não ancorado na raiz, deve aparecer\n")
+	write("raiz.txt", "TODO: ignorado\n")
+	write("build/saida.txt", "TODO: ignorado\n")
+	write("app.log", "TODO: ignorado\n")
+	write("keep.log", "TODO: reincluído\n")
+	write("binario.bin", "TODO\x00\x01\x02")
+	write(".oculto/a.txt", "TODO: oculto\n")
+
+	results, err := utils.AdvancedSearchFiles(utils.SearchOptions{
+		Pattern:      "todo",
+		Directory:    dir,
+		Recursive:    true,
+		MatchContent: true,
+		Literal:      true,
+		Context:      1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	for _, r := range results {
+		rel, _ := filepath.Rel(dir, r.Path)
+		found = append(found, rel)
+	}
+	want := []string{"keep.log", filepath.Join("src", "main.go"), filepath.Join("src", "raiz.txt")}
+	if strings.Join(found, ",") != strings.Join(want, ",") {
+		t.Fatalf("resultados = %v, esperava %v", found, want)
+	}
+
+	// Várias ocorrências por arquivo, com contexto
+	main := results[1]
+	if len(main.Matches) != 2 || main.LineNum != 3 {
+		t.Fatalf("main.go: %+v", main.Matches)
+	}
+	second := main.Matches[1]
+	if second.LineNum != 5 || second.Before[0] != "func main() {" || second.After[0] != "}" {
+		t.Errorf("segunda ocorrência = %+v", second)
+	}
+	if span := second.Spans[0]; second.Line[span[0]:span[1]] != "TODO" {
+		t.Errorf("Spans = %v", second.Spans)
+	}
+
+	// Sem as regras de exclusão e com ocultos
+	results, err = utils.AdvancedSearchFiles(utils.SearchOptions{
+		Pattern:       "TODO",
+		Directory:     dir,
+		Recursive:     true,
+		MatchContent:  true,
+		CaseSensitive: true,
+		NoIgnore:      true,
+		IncludeHidden: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 8 {
+		t.Errorf("NoIgnore: %d resultados, esperava 8", len(results))
+	}
+}
+
+func TestSearchFilesManyFiles(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 2000; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("d%02d", i%50))
+		os.MkdirAll(sub, 0755)
+		content := fmt.Sprintf("arquivo %d\n", i)
+		if i%100 == 0 {
+			content += "agulha\n"
+		}
+		os.WriteFile(filepath.Join(sub, fmt.Sprintf("f%d.txt", i)), []byte(content), 0644)
+	}
+
+	count := 0
+	err := utils.SearchFiles(nil, utils.SearchOptions{
+		Pattern:      "agulha",
+		Directory:    dir,
+		Recursive:    true,
+		MatchContent: true,
+		Workers:      8,
+	}, func(r utils.SearchResult) {
+		count++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 20 {
+		t.Errorf("SearchFiles() encontrou %d arquivos, esperava 20", count)
+	}
+}
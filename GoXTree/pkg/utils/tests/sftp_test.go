@@ -0,0 +1,211 @@
+package utils_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startTestSFTPServer inicia um servidor SSH com o subsistema SFTP que aceita apenas
+// a chave clientKey. O servidor atende o sistema de arquivos local.
+func startTestSFTPServer(t *testing.T, clientKey ssh.PublicKey) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "teste" && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSH(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), hostSigner.PublicKey()
+}
+
+// serveTestSSH atende uma conexão, abrindo o servidor SFTP no pedido de subsistema
+func serveTestSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "tipo de canal não suportado")
+			continue
+		}
+		channel, reqs, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range reqs {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+// testSFTPConfig gera a chave do cliente, inicia o servidor e monta a configuração
+func testSFTPConfig(t *testing.T) utils.SFTPConfig {
+	t.Helper()
+
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	sshPub, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, hostKey := startTestSFTPServer(t, sshPub)
+
+	dir := t.TempDir()
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "id_ed25519")
+	os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey)+"\n"), 0600)
+
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	return utils.SFTPConfig{
+		User:            "teste",
+		Host:            host,
+		Port:            portNum,
+		KeyFiles:        []string{keyFile},
+		KnownHostsFiles: []string{knownHosts},
+	}
+}
+
+func TestParseSFTPURL(t *testing.T) {
+	cfg, remotePath, err := utils.ParseSFTPURL("sftp://maria@servidor:2222/var/www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.User != "maria" || cfg.Host != "servidor" || cfg.Port != 2222 || remotePath != "/var/www" {
+		t.Errorf("ParseSFTPURL() = %+v, %q", cfg, remotePath)
+	}
+	if got := cfg.MountPoint(); got != "/sftp:maria@servidor:2222" {
+		t.Errorf("MountPoint() = %s", got)
+	}
+
+	if _, remotePath, _ := utils.ParseSFTPURL("sftp://maria@servidor"); remotePath != "" {
+		t.Errorf("sem caminho deveria usar o diretório inicial, obteve %q", remotePath)
+	}
+	if _, _, err := utils.ParseSFTPURL("http://servidor/x"); err == nil {
+		t.Errorf("esquema http deveria ser rejeitado")
+	}
+}
+
+func TestSFTPBrowseCopyAndSync(t *testing.T) {
+	cfg := testSFTPConfig(t)
+	defer utils.CloseSFTPConnections()
+
+	remoteDir := t.TempDir()
+	os.WriteFile(filepath.Join(remoteDir, "remoto.txt"), []byte("do servidor"), 0644)
+
+	dir, err := utils.ConnectSFTP(cfg, remoteDir)
+	if err != nil {
+		t.Fatalf("ConnectSFTP() error = %v", err)
+	}
+	if dir != cfg.MountPoint()+remoteDir {
+		t.Errorf("ConnectSFTP() = %s", dir)
+	}
+	if !utils.IsSFTPPath(dir) {
+		t.Errorf("%s deveria ser um caminho SFTP", dir)
+	}
+
+	// Navegar como em um diretório local
+	files, err := utils.ListDirectory(dir, false)
+	if err != nil || len(files) != 1 || files[0].Name != "remoto.txt" {
+		t.Fatalf("ListDirectory() = %+v, %v", files, err)
+	}
+
+	// Copiar do servidor para o disco e de volta
+	local := t.TempDir()
+	if err := utils.CopyFile(filepath.Join(dir, "remoto.txt"), filepath.Join(local, "remoto.txt")); err != nil {
+		t.Fatalf("CopyFile(remoto -> local) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(local, "remoto.txt")); string(data) != "do servidor" {
+		t.Errorf("cópia local = %q", data)
+	}
+
+	os.MkdirAll(filepath.Join(local, "src", "sub"), 0755)
+	os.WriteFile(filepath.Join(local, "src", "sub", "a.go"), []byte("package a"), 0644)
+	if _, err := utils.SyncDirectories(utils.SyncOptions{SourceDir: filepath.Join(local, "src"), DestDir: filepath.Join(dir, "destino")}); err != nil {
+		t.Fatalf("SyncDirectories(local -> remoto) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(remoteDir, "destino", "sub", "a.go")); string(data) != "package a" {
+		t.Errorf("arquivo sincronizado = %q", data)
+	}
+
+	// Renomear e excluir no servidor
+	if err := utils.MoveFile(filepath.Join(dir, "remoto.txt"), filepath.Join(dir, "novo.txt")); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	if err := utils.DeleteFile(filepath.Join(dir, "destino")); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	files, _ = utils.ListDirectory(dir, false)
+	if len(files) != 1 || files[0].Name != "novo.txt" {
+		t.Errorf("ListDirectory() após renomear/excluir = %+v", files)
+	}
+}
+
+func TestSFTPUnknownHost(t *testing.T) {
+	cfg := testSFTPConfig(t)
+
+	// known_hosts sem o servidor
+	cfg.KnownHostsFiles = []string{filepath.Join(t.TempDir(), "vazio")}
+	os.WriteFile(cfg.KnownHostsFiles[0], nil, 0600)
+
+	if _, err := utils.DialSFTP(cfg); err == nil {
+		t.Fatal("DialSFTP() deveria rejeitar um servidor desconhecido")
+	}
+}
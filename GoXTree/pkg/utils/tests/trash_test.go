@@ -0,0 +1,262 @@
+package utils_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestMoveToTrashAndRestore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nota com espaço.txt")
+	if err := os.WriteFile(path, []byte("conteúdo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := utils.MoveToTrash(path)
+	if err != nil {
+		t.Fatalf("MoveToTrash() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("arquivo ainda existe após ir para a lixeira")
+	}
+
+	// O .trashinfo deve seguir a especificação (caminho escapado)
+	trashDir, _ := utils.TrashDir()
+	info, err := os.ReadFile(filepath.Join(trashDir, "info", entry.Name+".trashinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), "nota%20com%20espa%C3%A7o.txt") {
+		t.Errorf(".trashinfo inválido:\n%s", info)
+	}
+
+	entries, err := utils.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].OriginalPath != path {
+		t.Fatalf("ListTrash() = %+v", entries)
+	}
+
+	if err := utils.RestoreFromTrash(entries[0]); err != nil {
+		t.Fatalf("RestoreFromTrash() error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "conteúdo" {
+		t.Fatalf("arquivo não restaurado: %v", err)
+	}
+	if entries, _ := utils.ListTrash(); len(entries) != 0 {
+		t.Errorf("lixeira deveria estar vazia, tem %d item(ns)", len(entries))
+	}
+}
+
+func TestMoveToTrashUniqueNames(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(t.TempDir(), "a.txt")
+		os.WriteFile(path, []byte{byte(i)}, 0644)
+
+		entry, err := utils.MoveToTrash(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, entry.Name)
+	}
+
+	if names[0] != "a.txt" || names[1] != "a.2.txt" {
+		t.Errorf("nomes na lixeira = %v", names)
+	}
+
+	// Um resto em files/ sem o .trashinfo também ocupa o nome
+	trashDir, _ := utils.TrashDir()
+	orphan := filepath.Join(trashDir, "files", "b.txt")
+	os.WriteFile(orphan, []byte("resto"), 0644)
+	path := filepath.Join(t.TempDir(), "b.txt")
+	os.WriteFile(path, []byte("novo"), 0644)
+	entry, err := utils.MoveToTrash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != "b.2.txt" {
+		t.Errorf("nome na lixeira = %q, want b.2.txt", entry.Name)
+	}
+	if data, _ := os.ReadFile(orphan); string(data) != "resto" {
+		t.Errorf("o resto em files/ foi sobrescrito: %q", data)
+	}
+}
+
+// failRename faz TrashRename falhar com o erro informado até o fim do teste
+func failRename(t *testing.T, errno syscall.Errno) {
+	t.Helper()
+	rename := utils.TrashRename
+	utils.TrashRename = func(oldPath, newPath string) error {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: errno}
+	}
+	t.Cleanup(func() { utils.TrashRename = rename })
+}
+
+// trashContents retorna os nomes em files/ e info/ da lixeira
+func trashContents(t *testing.T) []string {
+	t.Helper()
+	trashDir, _ := utils.TrashDir()
+	var names []string
+	for _, sub := range []string{"files", "info"} {
+		entries, _ := os.ReadDir(filepath.Join(trashDir, sub))
+		for _, entry := range entries {
+			names = append(names, sub+"/"+entry.Name())
+		}
+	}
+	return names
+}
+
+func TestMoveToTrashRenameFailure(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pasta", "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "pasta", "sub", "a.txt"), []byte("a"), 0644)
+	path := filepath.Join(dir, "pasta")
+
+	// Sem permissão não há cópia: nada fica na lixeira e a origem continua lá
+	failRename(t, syscall.EACCES)
+	if _, err := utils.MoveToTrash(path); !errors.Is(err, syscall.EACCES) {
+		t.Fatalf("MoveToTrash() error = %v, want EACCES", err)
+	}
+	if got := trashContents(t); len(got) != 0 {
+		t.Errorf("restos na lixeira: %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(path, "sub", "a.txt")); err != nil {
+		t.Errorf("a origem foi alterada: %v", err)
+	}
+
+	// Em outro sistema de arquivos o item é copiado e a origem removida
+	failRename(t, syscall.EXDEV)
+	entry, err := utils.MoveToTrash(path)
+	if err != nil {
+		t.Fatalf("MoveToTrash(EXDEV) error = %v", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("a origem continua após a cópia: %v", err)
+	}
+
+	// Uma restauração que falha deixa o item na lixeira, sem nada no destino
+	failRename(t, syscall.EPERM)
+	if err := utils.RestoreFromTrash(entry); !errors.Is(err, syscall.EPERM) {
+		t.Fatalf("RestoreFromTrash() error = %v, want EPERM", err)
+	}
+	if entries, _ := utils.ListTrash(); len(entries) != 1 {
+		t.Errorf("ListTrash() = %+v, want o item", entries)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("restauração parcial em %s: %v", path, err)
+	}
+
+	failRename(t, syscall.EXDEV)
+	if err := utils.RestoreFromTrash(entry); err != nil {
+		t.Fatalf("RestoreFromTrash(EXDEV) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(path, "sub", "a.txt")); string(data) != "a" {
+		t.Errorf("a.txt restaurado = %q", data)
+	}
+	if got := trashContents(t); len(got) != 0 {
+		t.Errorf("restos na lixeira após restaurar: %v", got)
+	}
+}
+
+func TestListTrashDirSizesCache(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "pasta com espaço")
+	os.MkdirAll(filepath.Join(path, "sub"), 0755)
+	os.WriteFile(filepath.Join(path, "sub", "a.txt"), []byte("12345"), 0644)
+	entry, err := utils.MoveToTrash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := utils.ListTrash()
+	if err != nil || len(entries) != 1 || entries[0].Size != 5 {
+		t.Fatalf("ListTrash() = %+v, %v; want um item de 5 bytes", entries, err)
+	}
+	trashDir, _ := utils.TrashDir()
+	sizesFile := filepath.Join(trashDir, "directorysizes")
+	if data, _ := os.ReadFile(sizesFile); !strings.HasSuffix(string(data), " pasta%20com%20espa%C3%A7o\n") {
+		t.Errorf("directorysizes = %q", data)
+	}
+
+	// O tamanho vem do cache enquanto o .trashinfo não mudar
+	os.WriteFile(filepath.Join(trashDir, "files", entry.Name, "b.txt"), []byte("123"), 0644)
+	if entries, _ := utils.ListTrash(); len(entries) != 1 || entries[0].Size != 5 {
+		t.Errorf("ListTrash() não usou o cache: %+v", entries)
+	}
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(trashDir, "info", entry.Name+".trashinfo"), later, later)
+	if entries, _ := utils.ListTrash(); len(entries) != 1 || entries[0].Size != 8 {
+		t.Errorf("ListTrash() não recalculou o tamanho: %+v", entries)
+	}
+
+	// Itens que saíram da lixeira saem do cache
+	if err := utils.PurgeTrashEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+	utils.ListTrash()
+	if data, _ := os.ReadFile(sizesFile); len(data) != 0 {
+		t.Errorf("directorysizes após esvaziar = %q", data)
+	}
+}
+
+func TestUndoOperation(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	os.WriteFile(oldPath, []byte("antigo"), 0644)
+	os.WriteFile(newPath, []byte("substituído"), 0644)
+
+	// Renomear substituindo um arquivo existente, como faz a interface
+	entry, err := utils.MoveToTrash(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatal(err)
+	}
+
+	stack := utils.NewUndoStack(utils.DefaultUndoLimit)
+	stack.Push(utils.UndoOperation{
+		Description: "Renomear",
+		Steps: []utils.UndoStep{
+			{Kind: utils.UndoRestoreTrash, Path: newPath, OriginalPath: newPath, Trash: entry},
+			{Kind: utils.UndoMoveBack, Path: newPath, OriginalPath: oldPath},
+		},
+	})
+
+	op, ok := stack.Pop()
+	if !ok {
+		t.Fatal("pilha vazia")
+	}
+	if err := op.Undo(nil); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(oldPath); string(data) != "antigo" {
+		t.Errorf("old.txt = %q", data)
+	}
+	if data, _ := os.ReadFile(newPath); string(data) != "substituído" {
+		t.Errorf("new.txt = %q", data)
+	}
+	if stack.Len() != 0 {
+		t.Errorf("Len() = %d", stack.Len())
+	}
+}
@@ -0,0 +1,152 @@
+package utils_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+// newTestMemFS cria um MemFS com uma pequena árvore de arquivos
+func newTestMemFS(t *testing.T) *utils.MemFS {
+	t.Helper()
+
+	mem := utils.NewMemFS()
+	if err := mem.MkdirAll("/projeto/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"/projeto/main.go":    "package main\n\nfunc main() {}\n",
+		"/projeto/leia.txt":   "olá",
+		"/projeto/sub/a.txt":  "conteúdo a",
+		"/projeto/.oculto":    "x",
+		"/projeto/sub/b.prw":  "User Function Teste()",
+		"/projeto/binario.db": "\x00\x01\x02",
+	} {
+		if err := utils.WriteFileFS(mem, name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mem
+}
+
+func TestMemFS(t *testing.T) {
+	mem := newTestMemFS(t)
+
+	files, err := utils.ListFilesFS(mem, "/projeto", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if len(names) != 4 || names[0] != "binario.db" || names[3] != "sub" {
+		t.Fatalf("ListFilesFS() = %v", names)
+	}
+
+	if _, err := mem.Stat("/projeto/nada"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(inexistente) error = %v", err)
+	}
+	if err := mem.Remove("/projeto/sub"); err == nil {
+		t.Errorf("Remove() de diretório não vazio deveria falhar")
+	}
+
+	// Renomear um diretório move o conteúdo
+	if err := mem.Rename("/projeto/sub", "/projeto/outro"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := utils.ReadFileFS(mem, "/projeto/outro/a.txt"); err != nil || string(data) != "conteúdo a" {
+		t.Errorf("outro/a.txt = %q, %v", data, err)
+	}
+	if _, err := mem.Stat("/projeto/sub/a.txt"); err == nil {
+		t.Errorf("sub/a.txt ainda existe após o Rename")
+	}
+
+	if err := utils.RemoveAllFS(mem, "/projeto/outro"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mem.Stat("/projeto/outro"); err == nil {
+		t.Errorf("outro ainda existe após RemoveAllFS")
+	}
+}
+
+func TestVFSCopyMoveBetweenFileSystems(t *testing.T) {
+	mem := newTestMemFS(t)
+	dir := t.TempDir()
+
+	// Memória -> disco
+	dst := filepath.Join(dir, "copia")
+	if err := utils.CopyDirectoryFS(mem, "/projeto", utils.Local, dst); err != nil {
+		t.Fatalf("CopyDirectoryFS() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "sub", "b.prw")); err != nil || string(data) != "User Function Teste()" {
+		t.Errorf("copia/sub/b.prw = %q, %v", data, err)
+	}
+
+	// Disco -> memória, removendo a origem
+	if err := utils.MoveFileFS(utils.Local, filepath.Join(dst, "leia.txt"), mem, "/movido.txt"); err != nil {
+		t.Fatalf("MoveFileFS() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "leia.txt")); !os.IsNotExist(err) {
+		t.Errorf("a origem deveria ter sido removida")
+	}
+	if data, _ := utils.ReadFileFS(mem, "/movido.txt"); string(data) != "olá" {
+		t.Errorf("/movido.txt = %q", data)
+	}
+
+	// Caminhos montados são atendidos pelo MemFS
+	utils.Mount("/memfs-teste", mem)
+	defer utils.Unmount("/memfs-teste")
+	if err := mem.MkdirAll("/memfs-teste", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.CopyFile(filepath.Join(dst, "main.go"), "/memfs-teste/main.go"); err != nil {
+		t.Fatalf("CopyFile() para a montagem error = %v", err)
+	}
+	if info, err := utils.Stat("/memfs-teste/main.go"); err != nil || info.Size() == 0 {
+		t.Errorf("Stat(/memfs-teste/main.go) = %v, %v", info, err)
+	}
+}
+
+func TestSyncAndSearchOnMemFS(t *testing.T) {
+	mem := newTestMemFS(t)
+	dir := t.TempDir()
+
+	actions, err := utils.SyncDirectories(utils.SyncOptions{
+		SourceDir: "/projeto",
+		DestDir:   dir,
+		SourceFS:  mem,
+	})
+	if err != nil {
+		t.Fatalf("SyncDirectories() error = %v", err)
+	}
+	if len(actions) != 6 {
+		t.Errorf("SyncDirectories() = %d ações, esperava 6: %+v", len(actions), actions)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "a.txt")); string(data) != "conteúdo a" {
+		t.Errorf("sub/a.txt = %q", data)
+	}
+
+	results, err := utils.AdvancedSearchFiles(utils.SearchOptions{
+		Pattern:      "func",
+		Directory:    "/projeto",
+		Recursive:    true,
+		MatchContent: true,
+		FS:           mem,
+	})
+	if err != nil {
+		t.Fatalf("AdvancedSearchFiles() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("AdvancedSearchFiles() = %+v", results)
+	}
+	for _, r := range results {
+		if r.Name != "main.go" && r.Name != "b.prw" {
+			t.Errorf("resultado inesperado: %s", r.Path)
+		}
+	}
+}
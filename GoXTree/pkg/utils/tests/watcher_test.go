@@ -0,0 +1,93 @@
+//go:build linux
+
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+)
+
+func TestDirWatcherDebounce(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+
+	changes := make(chan []string, 10)
+	w, err := utils.NewDirWatcher(100*time.Millisecond, func(dirs []string) { changes <- dirs })
+	if err != nil {
+		t.Fatalf("NewDirWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	w.SetDirs([]string{dir + "/", "", other})
+	if got, want := w.Dirs(), []string{dir, other}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dirs() = %v, want %v", got, want)
+	}
+
+	// Vários arquivos criados de uma vez geram uma só notificação
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d.txt", i)), []byte("x"), 0644)
+	}
+	select {
+	case got := <-changes:
+		if !reflect.DeepEqual(got, []string{dir}) {
+			t.Errorf("onChange(%v), want [%s]", got, dir)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("onChange não foi chamado")
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("notificação repetida: %v", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Depois de Close nada mais é notificado
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() repetido error = %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "depois.txt"), []byte("x"), 0644)
+	w.SetDirs([]string{t.TempDir()})
+	select {
+	case got := <-changes:
+		t.Errorf("notificação após Close(): %v", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestDirWatcherMaxDirs(t *testing.T) {
+	root := t.TempDir()
+	var dirs []string
+	for i := 0; i < utils.MaxWatchedDirs+10; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("d%03d", i))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	w, err := utils.NewDirWatcher(0, nil)
+	if err != nil {
+		t.Fatalf("NewDirWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	w.SetDirs(dirs)
+	if got := len(w.Dirs()); got != utils.MaxWatchedDirs {
+		t.Errorf("len(Dirs()) = %d, want %d", got, utils.MaxWatchedDirs)
+	}
+
+	// Diretórios inexistentes são ignorados e os que saíram da lista deixam de ser observados
+	w.SetDirs([]string{dirs[0], filepath.Join(root, "nao-existe")})
+	if got := w.Dirs(); !reflect.DeepEqual(got, []string{dirs[0]}) {
+		t.Errorf("Dirs() = %v, want [%s]", got, dirs[0])
+	}
+}
@@ -0,0 +1,472 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Lixeira segundo a especificação freedesktop.org Trash
+// (https://specifications.freedesktop.org/trash-spec/trashspec-latest.html)
+
+// trashInfoExt é a extensão dos arquivos de informação da lixeira
+const trashInfoExt = ".trashinfo"
+
+// trashDateFormat é o formato do campo DeletionDate
+const trashDateFormat = "2006-01-02T15:04:05"
+
+// trashDirSizesName é o cache de tamanhos dos diretórios na raiz da lixeira
+const trashDirSizesName = "directorysizes"
+
+// TrashRename move itens para a lixeira e de volta; os testes a substituem
+// para simular falhas
+var TrashRename = os.Rename
+
+// TrashEntry representa um item na lixeira
+type TrashEntry struct {
+	Name         string // Nome do item em files/ (e do .trashinfo em info/)
+	OriginalPath string
+	DeletionDate time.Time
+	IsDir        bool
+	Size         int64
+}
+
+// TrashDir retorna o diretório da lixeira do usuário ($XDG_DATA_HOME/Trash)
+func TrashDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// trashPaths retorna os diretórios files/ e info/ da lixeira, criando-os se necessário
+func trashPaths() (string, string, error) {
+	trashDir, err := TrashDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", "", err
+		}
+	}
+	return filesDir, infoDir, nil
+}
+
+// MoveToTrash move um arquivo ou diretório para a lixeira
+func MoveToTrash(path string) (TrashEntry, error) {
+	return MoveToTrashJob(nil, path)
+}
+
+// MoveToTrashJob move um arquivo ou diretório para a lixeira reportando o progresso.
+// Quando a lixeira está em outro sistema de arquivos, o item é copiado e depois removido.
+func MoveToTrashJob(jc *JobContext, path string) (TrashEntry, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return TrashEntry{}, fmt.Errorf("erro ao acessar a lixeira: %v", err)
+	}
+
+	if err := jc.Checkpoint(); err != nil {
+		return TrashEntry{}, err
+	}
+	jc.SetCurrent(absPath)
+
+	// Reservar um nome único criando o .trashinfo de forma exclusiva
+	deletionDate := time.Now()
+	name, infoPath, err := reserveTrashName(filesDir, infoDir, filepath.Base(absPath), absPath, deletionDate)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	files, bytes := countTree(Local, absPath)
+	target := filepath.Join(filesDir, name)
+
+	if err := TrashRename(absPath, target); err != nil {
+		// Só em outro sistema de arquivos vale copiar e remover a origem; sem
+		// permissão, por exemplo, a cópia ficaria na lixeira sem a origem sair
+		if !errors.Is(err, syscall.EXDEV) {
+			os.Remove(infoPath)
+			return TrashEntry{}, err
+		}
+		if err := copyTree(absPath, target); err != nil {
+			os.RemoveAll(target)
+			os.Remove(infoPath)
+			return TrashEntry{}, err
+		}
+		if err := os.RemoveAll(absPath); err != nil {
+			os.RemoveAll(target)
+			os.Remove(infoPath)
+			return TrashEntry{}, err
+		}
+	}
+
+	if jc != nil {
+		jc.update(func(s *JobSnapshot) {
+			s.DoneFiles += files
+			s.DoneBytes += bytes
+		})
+	}
+
+	return TrashEntry{
+		Name:         name,
+		OriginalPath: absPath,
+		DeletionDate: deletionDate,
+		IsDir:        info.IsDir(),
+		Size:         bytes,
+	}, nil
+}
+
+// reserveTrashName cria o .trashinfo com um nome ainda não usado na lixeira,
+// nem em info/ nem em files/ (que pode ter restos sem o .trashinfo)
+func reserveTrashName(filesDir, infoDir, base, originalPath string, deletionDate time.Time) (string, string, error) {
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: originalPath}).EscapedPath(), deletionDate.Format(trashDateFormat))
+
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; i < 10000; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+
+		infoPath := filepath.Join(infoDir, name+trashInfoExt)
+		file, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := os.Lstat(filepath.Join(filesDir, name)); !os.IsNotExist(err) {
+			file.Close()
+			os.Remove(infoPath)
+			if err != nil {
+				return "", "", err
+			}
+			continue
+		}
+
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", "", err
+		}
+		return name, infoPath, nil
+	}
+
+	return "", "", fmt.Errorf("não foi possível gerar um nome único na lixeira para %s", base)
+}
+
+// copyTree copia um arquivo, link simbólico ou diretório preservando links
+func copyTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return CopyFile(src, dst)
+	}
+}
+
+// ListTrash lista os itens da lixeira, dos mais recentes para os mais antigos
+func ListTrash() ([]TrashEntry, error) {
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := os.ReadDir(infoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sizesPath := filepath.Join(filepath.Dir(infoDir), trashDirSizesName)
+	sizes := readTrashDirSizes(sizesPath)
+	seen := make(map[string]trashDirSize)
+	changed := false
+
+	var entries []TrashEntry
+	for _, infoFile := range infos {
+		if infoFile.IsDir() || !strings.HasSuffix(infoFile.Name(), trashInfoExt) {
+			continue
+		}
+
+		entry, err := readTrashInfo(filepath.Join(infoDir, infoFile.Name()))
+		if err != nil {
+			continue
+		}
+		entry.Name = strings.TrimSuffix(infoFile.Name(), trashInfoExt)
+
+		// Ignorar informações órfãs (sem o item correspondente)
+		info, err := os.Lstat(filepath.Join(filesDir, entry.Name))
+		if err != nil {
+			continue
+		}
+		entry.IsDir = info.IsDir()
+		entry.Size = info.Size()
+		if entry.IsDir {
+			// O tamanho de diretórios vem do cache enquanto o .trashinfo não mudar
+			var mtime int64
+			if info, err := infoFile.Info(); err == nil {
+				mtime = info.ModTime().Unix()
+			}
+			cached, ok := sizes[entry.Name]
+			if !ok || cached.mtime != mtime {
+				_, size := countTree(Local, filepath.Join(filesDir, entry.Name))
+				cached = trashDirSize{size: size, mtime: mtime}
+				changed = true
+			}
+			entry.Size = cached.size
+			seen[entry.Name] = cached
+		}
+
+		entries = append(entries, entry)
+	}
+	if changed || len(seen) != len(sizes) {
+		writeTrashDirSizes(sizesPath, seen)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletionDate.After(entries[j].DeletionDate)
+	})
+
+	return entries, nil
+}
+
+// trashDirSize é uma linha do cache de tamanhos: o tamanho do diretório e o
+// horário de modificação do .trashinfo quando ele foi calculado
+type trashDirSize struct {
+	size  int64
+	mtime int64
+}
+
+// readTrashDirSizes lê o cache de tamanhos ("tamanho mtime nome-escapado" por
+// linha, como na especificação); linhas inválidas são ignoradas
+func readTrashDirSizes(path string) map[string]trashDirSize {
+	sizes := make(map[string]trashDirSize)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sizes
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[0], 10, 64)
+		mtime, err2 := strconv.ParseInt(fields[1], 10, 64)
+		name, err3 := url.PathUnescape(fields[2])
+		if err1 == nil && err2 == nil && err3 == nil {
+			sizes[name] = trashDirSize{size: size, mtime: mtime}
+		}
+	}
+	return sizes
+}
+
+// writeTrashDirSizes grava o cache de tamanhos de uma vez (arquivo temporário
+// renomeado), para que outros programas nunca leiam um arquivo pela metade
+func writeTrashDirSizes(path string, sizes map[string]trashDirSize) error {
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%d %d %s\n", sizes[name].size, sizes[name].mtime, url.PathEscape(name))
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), trashDirSizesName+".*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.WriteString(sb.String())
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+	}
+	return err
+}
+
+// readTrashInfo lê um arquivo .trashinfo
+func readTrashInfo(path string) (TrashEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	defer file.Close()
+
+	var entry TrashEntry
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[Trash Info]"
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Path":
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				value = unescaped
+			}
+			entry.OriginalPath = value
+		case "DeletionDate":
+			if date, err := time.ParseInLocation(trashDateFormat, value, time.Local); err == nil {
+				entry.DeletionDate = date
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashEntry{}, err
+	}
+
+	if entry.OriginalPath == "" {
+		return TrashEntry{}, errors.New("arquivo .trashinfo sem o campo Path")
+	}
+
+	// Caminhos relativos são relativos ao diretório pai da lixeira
+	if !filepath.IsAbs(entry.OriginalPath) {
+		entry.OriginalPath = filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(path))), entry.OriginalPath)
+	}
+
+	return entry, nil
+}
+
+// RestoreFromTrash devolve um item da lixeira ao caminho original
+func RestoreFromTrash(entry TrashEntry) error {
+	return RestoreFromTrashTo(entry, entry.OriginalPath)
+}
+
+// RestoreFromTrashTo devolve um item da lixeira para o caminho informado
+func RestoreFromTrashTo(entry TrashEntry, dest string) error {
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("já existe um item em %s", dest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	src := filepath.Join(filesDir, entry.Name)
+	if err := TrashRename(src, dest); err != nil {
+		// Outro sistema de arquivos: copiar e remover da lixeira
+		if !errors.Is(err, syscall.EXDEV) {
+			return err
+		}
+		if err := copyTree(src, dest); err != nil {
+			os.RemoveAll(dest)
+			return err
+		}
+		if err := os.RemoveAll(src); err != nil {
+			os.RemoveAll(dest)
+			return err
+		}
+	}
+
+	return os.Remove(filepath.Join(infoDir, entry.Name+trashInfoExt))
+}
+
+// PurgeTrashEntry exclui definitivamente um item da lixeira
+func PurgeTrashEntry(entry TrashEntry) error {
+	filesDir, infoDir, err := trashPaths()
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(filepath.Join(filesDir, entry.Name)); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(infoDir, entry.Name+trashInfoExt))
+}
+
+// EmptyTrash exclui definitivamente todos os itens da lixeira
+func EmptyTrash() error {
+	entries, err := ListTrash()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, entry := range entries {
+		if err := PurgeTrashEntry(entry); err != nil {
+			failed = append(failed, entry.Name+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUndoLimit é o número máximo de operações guardadas na pilha de desfazer
+const DefaultUndoLimit = 50
+
+// UndoKind identifica o tipo de passo necessário para desfazer uma alteração
+type UndoKind int
+
+const (
+	// UndoRestoreTrash devolve um item da lixeira ao caminho original
+	UndoRestoreTrash UndoKind = iota
+	// UndoMoveBack move Path de volta para OriginalPath
+	UndoMoveBack
+	// UndoRemove envia para a lixeira um item criado pela operação (ex.: uma cópia)
+	UndoRemove
+)
+
+// UndoStep é um passo reversível de uma operação
+type UndoStep struct {
+	Kind         UndoKind
+	Path         string     // Caminho atual do item
+	OriginalPath string     // Caminho antes da operação
+	Trash        TrashEntry // Item da lixeira (UndoRestoreTrash)
+}
+
+// UndoOperation agrupa os passos de uma operação do usuário (excluir, renomear, mover, colar)
+type UndoOperation struct {
+	Description string
+	Time        time.Time
+	Steps       []UndoStep
+}
+
+// Undo desfaz a operação executando os passos na ordem inversa.
+// Passos que falham são informados no erro retornado, mas não interrompem os demais.
+func (op UndoOperation) Undo(jc *JobContext) error {
+	var failed []string
+
+	jc.AddTotal(len(op.Steps), 0)
+	for i := len(op.Steps) - 1; i >= 0; i-- {
+		if err := jc.Checkpoint(); err != nil {
+			return err
+		}
+
+		step := op.Steps[i]
+		jc.SetCurrent(step.Path)
+
+		// Em segundo plano o erro fica registrado na operação; sem contexto é acumulado
+		if err := jc.Fail(step.Path, step.undo()); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", step.Path, err))
+		}
+		jc.FileDone()
+	}
+
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// undo desfaz um único passo
+func (s UndoStep) undo() error {
+	switch s.Kind {
+	case UndoRestoreTrash:
+		return RestoreFromTrashTo(s.Trash, s.OriginalPath)
+	case UndoMoveBack:
+		if _, err := os.Lstat(s.OriginalPath); err == nil {
+			return fmt.Errorf("já existe um item em %s", s.OriginalPath)
+		}
+		return MovePathJob(nil, s.Path, s.OriginalPath)
+	case UndoRemove:
+		_, err := MoveToTrash(s.Path)
+		return err
+	default:
+		return fmt.Errorf("passo de desfazer desconhecido: %d", s.Kind)
+	}
+}
+
+// UndoStack é a pilha de operações que podem ser desfeitas (segura para uso concorrente)
+type UndoStack struct {
+	mu    sync.Mutex
+	ops   []UndoOperation
+	limit int
+}
+
+// NewUndoStack cria uma pilha que guarda no máximo limit operações
+func NewUndoStack(limit int) *UndoStack {
+	if limit < 1 {
+		limit = DefaultUndoLimit
+	}
+	return &UndoStack{limit: limit}
+}
+
+// Push empilha uma operação (operações sem passos são ignoradas)
+func (u *UndoStack) Push(op UndoOperation) {
+	if len(op.Steps) == 0 {
+		return
+	}
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.ops = append(u.ops, op)
+	if len(u.ops) > u.limit {
+		u.ops = u.ops[len(u.ops)-u.limit:]
+	}
+}
+
+// Pop desempilha a operação mais recente
+func (u *UndoStack) Pop() (UndoOperation, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.ops) == 0 {
+		return UndoOperation{}, false
+	}
+	op := u.ops[len(u.ops)-1]
+	u.ops = u.ops[:len(u.ops)-1]
+	return op, true
+}
+
+// Peek retorna a operação mais recente sem removê-la
+func (u *UndoStack) Peek() (UndoOperation, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.ops) == 0 {
+		return UndoOperation{}, false
+	}
+	return u.ops[len(u.ops)-1], true
+}
+
+// Len retorna o número de operações na pilha
+func (u *UndoStack) Len() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.ops)
+}
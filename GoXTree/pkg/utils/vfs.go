@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// VFS é um sistema de arquivos navegável pelo GoXTree. Os caminhos recebidos são
+// sempre completos (como exibidos na interface), inclusive o prefixo de montagem.
+type VFS interface {
+	// List retorna as entradas de um diretório ordenadas pelo nome
+	List(dir string) ([]os.FileInfo, error)
+	// Stat retorna as informações de um arquivo ou diretório
+	Stat(name string) (os.FileInfo, error)
+	// Open abre um arquivo para leitura
+	Open(name string) (io.ReadCloser, error)
+	// Create cria (ou trunca) um arquivo para escrita
+	Create(name string) (io.WriteCloser, error)
+	// Rename renomeia ou move um item dentro do mesmo sistema de arquivos
+	Rename(oldPath, newPath string) error
+	// Remove exclui um arquivo ou um diretório vazio
+	Remove(name string) error
+	// MkdirAll cria um diretório e os diretórios pais necessários
+	MkdirAll(dir string, perm os.FileMode) error
+}
+
+// Interfaces opcionais que um VFS pode implementar
+type (
+	// lstater retorna informações sem seguir links simbólicos
+	lstater interface {
+		Lstat(name string) (os.FileInfo, error)
+	}
+	// chmoder altera as permissões de um item
+	chmoder interface {
+		Chmod(name string, mode os.FileMode) error
+	}
+	// chowner altera o dono e o grupo de um item (-1 mantém), sem seguir links
+	chowner interface {
+		Chown(name string, uid, gid int) error
+	}
+	// readlinker retorna o destino de um link simbólico
+	readlinker interface {
+		Readlink(name string) (string, error)
+	}
+	// removeAller exclui um diretório recursivamente de forma otimizada
+	removeAller interface {
+		RemoveAll(name string) error
+	}
+)
+
+// LocalFS é o sistema de arquivos local (pacote os)
+type LocalFS struct{}
+
+// Local é a instância padrão do sistema de arquivos local
+var Local VFS = LocalFS{}
+
+func (LocalFS) List(dir string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		// Itens removidos durante a listagem são ignorados
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (LocalFS) Stat(name string) (os.FileInfo, error)  { return os.Stat(name) }
+func (LocalFS) Lstat(name string) (os.FileInfo, error) { return os.Lstat(name) }
+func (LocalFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+func (LocalFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+func (LocalFS) Rename(oldPath, newPath string) error        { return os.Rename(oldPath, newPath) }
+func (LocalFS) Remove(name string) error                    { return os.Remove(name) }
+func (LocalFS) RemoveAll(name string) error                 { return os.RemoveAll(name) }
+func (LocalFS) MkdirAll(dir string, perm os.FileMode) error { return os.MkdirAll(dir, perm) }
+func (LocalFS) Chmod(name string, mode os.FileMode) error   { return os.Chmod(name, mode) }
+func (LocalFS) Chown(name string, uid, gid int) error       { return os.Lchown(name, uid, gid) }
+func (LocalFS) Readlink(name string) (string, error)        { return os.Readlink(name) }
+
+// mount associa um prefixo de caminho a um sistema de arquivos
+type mount struct {
+	prefix string
+	fsys   VFS
+}
+
+var (
+	mountsMu sync.RWMutex
+	mounts   []mount
+)
+
+// Mount faz com que os caminhos sob prefix sejam atendidos por fsys
+// (ex.: um servidor SFTP ou um sistema de arquivos em memória nos testes)
+func Mount(prefix string, fsys VFS) {
+	prefix = filepath.Clean(prefix)
+
+	mountsMu.Lock()
+	defer mountsMu.Unlock()
+
+	for i, m := range mounts {
+		if m.prefix == prefix {
+			mounts[i].fsys = fsys
+			return
+		}
+	}
+	mounts = append(mounts, mount{prefix: prefix, fsys: fsys})
+
+	// Prefixos mais longos têm precedência
+	sort.Slice(mounts, func(i, j int) bool {
+		return len(mounts[i].prefix) > len(mounts[j].prefix)
+	})
+}
+
+// Unmount remove uma montagem feita com Mount
+func Unmount(prefix string) {
+	prefix = filepath.Clean(prefix)
+
+	mountsMu.Lock()
+	defer mountsMu.Unlock()
+
+	for i, m := range mounts {
+		if m.prefix == prefix {
+			mounts = append(mounts[:i], mounts[i+1:]...)
+			return
+		}
+	}
+}
+
+// mountFor retorna o sistema de arquivos montado que atende o caminho, se houver
+func mountFor(name string) (VFS, bool) {
+	mountsMu.RLock()
+	defer mountsMu.RUnlock()
+
+	for _, m := range mounts {
+		if name == m.prefix || strings.HasPrefix(name, m.prefix+string(os.PathSeparator)) {
+			return m.fsys, true
+		}
+	}
+	return nil, false
+}
+
+// FSFor retorna o sistema de arquivos responsável pelo caminho: uma montagem,
+// o conteúdo de um arquivo compactado ou o sistema de arquivos local
+func FSFor(name string) VFS {
+	if fsys, ok := mountFor(filepath.Clean(name)); ok {
+		return fsys
+	}
+	if IsArchivePath(name) {
+		return archiveFS{}
+	}
+	return Local
+}
+
+// IsLocalPath indica se o caminho pertence ao sistema de arquivos local
+func IsLocalPath(name string) bool {
+	_, ok := FSFor(name).(LocalFS)
+	return ok
+}
+
+// LstatFS retorna as informações sem seguir links, quando o sistema de arquivos suporta
+func LstatFS(fsys VFS, name string) (os.FileInfo, error) {
+	if l, ok := fsys.(lstater); ok {
+		return l.Lstat(name)
+	}
+	return fsys.Stat(name)
+}
+
+// chmodFS altera as permissões, quando o sistema de arquivos suporta
+func chmodFS(fsys VFS, name string, mode os.FileMode) error {
+	if c, ok := fsys.(chmoder); ok {
+		return c.Chmod(name, mode)
+	}
+	return nil
+}
+
+// ReadlinkFS retorna o destino de um link simbólico, quando o sistema de arquivos suporta
+func ReadlinkFS(fsys VFS, name string) (string, error) {
+	if r, ok := fsys.(readlinker); ok {
+		return r.Readlink(name)
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: errors.ErrUnsupported}
+}
+
+// RemoveAllFS exclui um arquivo ou diretório recursivamente
+func RemoveAllFS(fsys VFS, name string) error {
+	if r, ok := fsys.(removeAller); ok {
+		return r.RemoveAll(name)
+	}
+
+	info, err := LstatFS(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if info.IsDir() {
+		entries, err := fsys.List(name)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := RemoveAllFS(fsys, filepath.Join(name, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return fsys.Remove(name)
+}
+
+// WalkFS percorre a árvore a partir de root, como filepath.Walk (fn pode retornar filepath.SkipDir)
+func WalkFS(fsys VFS, root string, fn filepath.WalkFunc) error {
+	info, err := LstatFS(fsys, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkFS(fsys, root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkFS visita um item e, se for um diretório, o seu conteúdo
+func walkFS(fsys VFS, name string, info os.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(name, info, nil)
+	}
+
+	entries, err := fsys.List(name)
+	if err := fn(name, info, err); err != nil || entries == nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := walkFS(fsys, filepath.Join(name, entry.Name()), entry, fn); err != nil {
+			if err == filepath.SkipDir && !entry.IsDir() {
+				return nil
+			}
+			if err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadFileFS lê o conteúdo de um arquivo
+func ReadFileFS(fsys VFS, name string) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// WriteFileFS grava o conteúdo de um arquivo, criando-o se necessário
+func WriteFileFS(fsys VFS, name string, data []byte, perm os.FileMode) error {
+	file, err := fsys.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return chmodFS(fsys, name, perm)
+}
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS é um sistema de arquivos em memória, usado principalmente em testes
+type MemFS struct {
+	mu    sync.RWMutex
+	nodes map[string]*memNode
+}
+
+// memNode é um arquivo ou diretório do MemFS
+type memNode struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// memFileInfo adapta memNode para os.FileInfo
+type memFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+	time time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.time }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+// NewMemFS cria um sistema de arquivos em memória vazio (apenas a raiz "/")
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: map[string]*memNode{
+		string(os.PathSeparator): {mode: os.ModeDir | 0755, modTime: time.Now()},
+	}}
+}
+
+// info monta o os.FileInfo de um nó (o chamador deve segurar o lock)
+func (m *MemFS) info(name string, node *memNode) os.FileInfo {
+	return memFileInfo{name: filepath.Base(name), size: int64(len(node.data)), mode: node.mode, time: node.modTime}
+}
+
+// lookup retorna o nó de um caminho (o chamador deve segurar o lock)
+func (m *MemFS) lookup(op, name string) (string, *memNode, error) {
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return name, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return name, node, nil
+}
+
+// parentDir verifica se o diretório pai existe (o chamador deve segurar o lock)
+func (m *MemFS) parentDir(op, name string) error {
+	parent, ok := m.nodes[filepath.Dir(name)]
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: fmt.Errorf("%s não é um diretório", filepath.Dir(name))}
+	}
+	return nil
+}
+
+// List retorna as entradas de um diretório ordenadas pelo nome
+func (m *MemFS) List(dir string) ([]os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dir, node, err := m.lookup("open", dir)
+	if err != nil {
+		return nil, err
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fmt.Errorf("não é um diretório")}
+	}
+
+	var infos []os.FileInfo
+	for name, child := range m.nodes {
+		if name != dir && filepath.Dir(name) == dir {
+			infos = append(infos, m.info(name, child))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	return infos, nil
+}
+
+// Stat retorna as informações de um arquivo ou diretório
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name, node, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return m.info(name, node), nil
+}
+
+// Open abre um arquivo para leitura (o conteúdo é o do momento da abertura)
+func (m *MemFS) Open(name string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name, node, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("é um diretório")}
+	}
+	return io.NopCloser(bytes.NewReader(node.data)), nil
+}
+
+// Create cria (ou trunca) um arquivo; o conteúdo é gravado ao fechar
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.parentDir("open", name); err != nil {
+		return nil, err
+	}
+	if node, ok := m.nodes[name]; ok && node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("é um diretório")}
+	}
+
+	mode := os.FileMode(0644)
+	if node, ok := m.nodes[name]; ok {
+		mode = node.mode
+	}
+	m.nodes[name] = &memNode{mode: mode, modTime: time.Now()}
+	return &memWriter{fs: m, name: name}, nil
+}
+
+// Rename renomeia um arquivo ou diretório (com todo o conteúdo)
+func (m *MemFS) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldPath, node, err := m.lookup("rename", oldPath)
+	if err != nil {
+		return err
+	}
+	newPath = filepath.Clean(newPath)
+	if err := m.parentDir("rename", newPath); err != nil {
+		return err
+	}
+	if node.mode.IsDir() && strings.HasPrefix(newPath, oldPath+string(os.PathSeparator)) {
+		return &fs.PathError{Op: "rename", Path: newPath, Err: fmt.Errorf("destino dentro da origem")}
+	}
+	if existing, ok := m.nodes[newPath]; ok && existing.mode.IsDir() {
+		if !node.mode.IsDir() || m.hasChildren(newPath) {
+			return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrExist}
+		}
+	}
+
+	// Mover o nó e os descendentes
+	prefix := oldPath + string(os.PathSeparator)
+	for name, child := range m.nodes {
+		if strings.HasPrefix(name, prefix) {
+			delete(m.nodes, name)
+			m.nodes[newPath+string(os.PathSeparator)+strings.TrimPrefix(name, prefix)] = child
+		}
+	}
+	delete(m.nodes, oldPath)
+	m.nodes[newPath] = node
+	return nil
+}
+
+// hasChildren indica se o diretório tem conteúdo (o chamador deve segurar o lock)
+func (m *MemFS) hasChildren(dir string) bool {
+	for name := range m.nodes {
+		if name != dir && filepath.Dir(name) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove exclui um arquivo ou um diretório vazio
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, node, err := m.lookup("remove", name)
+	if err != nil {
+		return err
+	}
+	if node.mode.IsDir() && m.hasChildren(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fmt.Errorf("diretório não está vazio")}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// MkdirAll cria um diretório e os diretórios pais necessários
+func (m *MemFS) MkdirAll(dir string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	var missing []string
+	for p := dir; ; p = filepath.Dir(p) {
+		if node, ok := m.nodes[p]; ok {
+			if !node.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: p, Err: fmt.Errorf("não é um diretório")}
+			}
+			break
+		}
+		missing = append(missing, p)
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+
+	for _, p := range missing {
+		m.nodes[p] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+// Chmod altera as permissões de um item
+func (m *MemFS) Chmod(name string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, node, err := m.lookup("chmod", name)
+	if err != nil {
+		return err
+	}
+	node.mode = node.mode&os.ModeType | mode.Perm()
+	return nil
+}
+
+// memWriter acumula o conteúdo de um arquivo até o Close
+type memWriter struct {
+	fs   *MemFS
+	name string
+	buf  bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+
+	node, ok := w.fs.nodes[w.name]
+	if !ok {
+		return &fs.PathError{Op: "close", Path: w.name, Err: fs.ErrNotExist}
+	}
+	node.data = append([]byte(nil), w.buf.Bytes()...)
+	node.modTime = time.Now()
+	return nil
+}
@@ -0,0 +1,343 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPMountPrefix é o início dos caminhos locais onde as conexões SFTP são montadas:
+// sftp://usuario@host/caminho é exibido como /sftp:usuario@host/caminho
+const SFTPMountPrefix = "/sftp:"
+
+// SFTPConfig define os parâmetros de uma conexão SFTP
+type SFTPConfig struct {
+	User string
+	Host string
+	Port int
+	// KeyFiles são as chaves privadas tentadas além do ssh-agent (padrão: ~/.ssh/id_*)
+	KeyFiles []string
+	// KnownHostsFiles são usados para validar a chave do servidor (padrão: ~/.ssh/known_hosts)
+	KnownHostsFiles []string
+	// UseAgent habilita a autenticação pelo ssh-agent (SSH_AUTH_SOCK)
+	UseAgent bool
+	Timeout  time.Duration
+}
+
+// ParseSFTPURL interpreta um endereço sftp://usuario@host:porta/caminho e retorna a
+// configuração padrão da conexão e o caminho remoto ("" = diretório inicial do usuário)
+func ParseSFTPURL(rawURL string) (SFTPConfig, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return SFTPConfig{}, "", err
+	}
+	if u.Scheme != "sftp" || u.Hostname() == "" {
+		return SFTPConfig{}, "", fmt.Errorf("endereço SFTP inválido: %s", rawURL)
+	}
+
+	cfg := defaultSFTPConfig()
+	cfg.Host = u.Hostname()
+	if u.User != nil {
+		cfg.User = u.User.Username()
+	}
+	if port := u.Port(); port != "" {
+		if cfg.Port, err = strconv.Atoi(port); err != nil {
+			return SFTPConfig{}, "", fmt.Errorf("porta inválida: %s", port)
+		}
+	}
+
+	remotePath := u.Path
+	if remotePath == "/" {
+		remotePath = ""
+	}
+	return cfg, remotePath, nil
+}
+
+// defaultSFTPConfig usa o usuário atual, a porta 22 e os arquivos de ~/.ssh
+func defaultSFTPConfig() SFTPConfig {
+	cfg := SFTPConfig{Port: 22, UseAgent: true, Timeout: 15 * time.Second}
+	if user := os.Getenv("USER"); user != "" {
+		cfg.User = user
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		sshDir := filepath.Join(home, ".ssh")
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			cfg.KeyFiles = append(cfg.KeyFiles, filepath.Join(sshDir, name))
+		}
+		cfg.KnownHostsFiles = []string{filepath.Join(sshDir, "known_hosts")}
+	}
+	return cfg
+}
+
+// Address retorna host:porta
+func (c SFTPConfig) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// MountPoint retorna o caminho local onde a conexão é montada
+func (c SFTPConfig) MountPoint() string {
+	target := c.Host
+	if c.Port != 22 {
+		target = c.Address()
+	}
+	if c.User != "" {
+		target = c.User + "@" + target
+	}
+	return SFTPMountPrefix + target
+}
+
+// authMethods reúne os métodos de autenticação disponíveis (ssh-agent e chaves)
+func (c SFTPConfig) authMethods() ([]ssh.AuthMethod, func()) {
+	var methods []ssh.AuthMethod
+	cleanup := func() {}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); c.UseAgent && sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			cleanup = func() { conn.Close() }
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, keyFile := range c.KeyFiles {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			continue
+		}
+		// Chaves protegidas por senha só são usadas pelo ssh-agent
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	return methods, cleanup
+}
+
+// hostKeyCallback valida a chave do servidor com os arquivos known_hosts
+func (c SFTPConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	var files []string
+	for _, file := range c.KnownHostsFiles {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo known_hosts encontrado; conecte-se uma vez com o ssh para registrar o servidor")
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler known_hosts: %v", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("servidor %s não está em known_hosts", hostname)
+			}
+			return fmt.Errorf("a chave do servidor %s mudou (possível ataque man-in-the-middle)", hostname)
+		}
+		return err
+	}, nil
+}
+
+// SFTPFS é um sistema de arquivos remoto acessado por SFTP. Os caminhos recebidos
+// começam pelo ponto de montagem (ex.: /sftp:usuario@host/home/usuario).
+type SFTPFS struct {
+	prefix string
+	home   string
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+// DialSFTP abre uma conexão SFTP autenticada pelo ssh-agent ou pelas chaves configuradas
+func DialSFTP(cfg SFTPConfig) (*SFTPFS, error) {
+	hostKeyCallback, err := cfg.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	methods, cleanup := cfg.authMethods()
+	defer cleanup()
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("nenhuma chave SSH disponível (ssh-agent ou ~/.ssh/id_*)")
+	}
+
+	conn, err := ssh.Dial("tcp", cfg.Address(), &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            methods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         cfg.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar a %s: %v", cfg.Address(), err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao iniciar SFTP em %s: %v", cfg.Address(), err)
+	}
+
+	home, err := client.Getwd()
+	if err != nil || home == "" {
+		home = "/"
+	}
+
+	return &SFTPFS{prefix: cfg.MountPoint(), home: home, conn: conn, client: client}, nil
+}
+
+var (
+	sftpMu    sync.Mutex
+	sftpConns = make(map[string]*SFTPFS)
+)
+
+// OpenSFTP conecta (ou reaproveita a conexão) ao endereço sftp:// e monta o servidor.
+// Retorna o caminho local correspondente ao caminho remoto do endereço.
+func OpenSFTP(rawURL string) (string, error) {
+	cfg, remotePath, err := ParseSFTPURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return ConnectSFTP(cfg, remotePath)
+}
+
+// ConnectSFTP conecta com a configuração informada e monta o servidor em cfg.MountPoint()
+func ConnectSFTP(cfg SFTPConfig, remotePath string) (string, error) {
+	sftpMu.Lock()
+	defer sftpMu.Unlock()
+
+	fsys, ok := sftpConns[cfg.MountPoint()]
+	if !ok {
+		var err error
+		if fsys, err = DialSFTP(cfg); err != nil {
+			return "", err
+		}
+		sftpConns[fsys.prefix] = fsys
+		Mount(fsys.prefix, fsys)
+	}
+
+	if remotePath == "" {
+		remotePath = fsys.home
+	}
+	return fsys.LocalPath(remotePath), nil
+}
+
+// CloseSFTPConnections encerra e desmonta todas as conexões SFTP
+func CloseSFTPConnections() {
+	sftpMu.Lock()
+	defer sftpMu.Unlock()
+
+	for prefix, fsys := range sftpConns {
+		Unmount(prefix)
+		fsys.Close()
+		delete(sftpConns, prefix)
+	}
+}
+
+// IsSFTPPath indica se o caminho está em uma montagem SFTP
+func IsSFTPPath(name string) bool {
+	_, ok := FSFor(name).(*SFTPFS)
+	return ok
+}
+
+// LocalPath converte um caminho remoto no caminho exibido na interface
+func (s *SFTPFS) LocalPath(remotePath string) string {
+	return filepath.Join(s.prefix, filepath.FromSlash(path.Clean("/"+remotePath)))
+}
+
+// remote converte um caminho da interface no caminho do servidor
+func (s *SFTPFS) remote(name string) string {
+	rel := strings.TrimPrefix(filepath.Clean(name), s.prefix)
+	return path.Clean("/" + filepath.ToSlash(rel))
+}
+
+// Close encerra a conexão
+func (s *SFTPFS) Close() error {
+	s.client.Close()
+	return s.conn.Close()
+}
+
+// List retorna as entradas de um diretório remoto ordenadas pelo nome
+func (s *SFTPFS) List(dir string) ([]os.FileInfo, error) {
+	infos, err := s.client.ReadDir(s.remote(dir))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	return infos, nil
+}
+
+func (s *SFTPFS) Stat(name string) (os.FileInfo, error)  { return s.client.Stat(s.remote(name)) }
+func (s *SFTPFS) Lstat(name string) (os.FileInfo, error) { return s.client.Lstat(s.remote(name)) }
+func (s *SFTPFS) Open(name string) (io.ReadCloser, error) {
+	return s.client.Open(s.remote(name))
+}
+func (s *SFTPFS) Create(name string) (io.WriteCloser, error) {
+	return s.client.Create(s.remote(name))
+}
+func (s *SFTPFS) Remove(name string) error { return s.client.Remove(s.remote(name)) }
+func (s *SFTPFS) Chmod(name string, mode os.FileMode) error {
+	return s.client.Chmod(s.remote(name), mode)
+}
+func (s *SFTPFS) Readlink(name string) (string, error) {
+	return s.client.ReadLink(s.remote(name))
+}
+
+// Chown altera o dono e o grupo; o SFTP exige os dois, então -1 mantém o atual
+func (s *SFTPFS) Chown(name string, uid, gid int) error {
+	if uid < 0 || gid < 0 {
+		info, err := s.client.Lstat(s.remote(name))
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if uid < 0 {
+				uid = int(stat.UID)
+			}
+			if gid < 0 {
+				gid = int(stat.GID)
+			}
+		}
+	}
+	return s.client.Chown(s.remote(name), uid, gid)
+}
+
+// Rename renomeia um item, substituindo o destino quando o servidor suporta
+func (s *SFTPFS) Rename(oldPath, newPath string) error {
+	oldPath, newPath = s.remote(oldPath), s.remote(newPath)
+	if err := s.client.PosixRename(oldPath, newPath); err == nil {
+		return nil
+	}
+	return s.client.Rename(oldPath, newPath)
+}
+
+// MkdirAll cria um diretório remoto e os diretórios pais necessários
+func (s *SFTPFS) MkdirAll(dir string, perm os.FileMode) error {
+	return s.client.MkdirAll(s.remote(dir))
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Constantes do observador de diretórios
+const (
+	DefaultWatchDebounce = 300 * time.Millisecond // Intervalo de agrupamento de eventos
+	MaxWatchedDirs       = 256                    // Limite de diretórios observados simultaneamente
+)
+
+// watchBackend é a implementação específica de plataforma do observador
+type watchBackend interface {
+	add(dir string) error
+	remove(dir string) error
+	close() error
+}
+
+// DirWatcher observa diretórios e notifica alterações agrupadas, evitando
+// atualizações repetidas quando muitos arquivos mudam de uma só vez
+type DirWatcher struct {
+	mu       sync.Mutex
+	backend  watchBackend
+	dirs     map[string]bool
+	pending  map[string]bool
+	timer    *time.Timer
+	delay    time.Duration
+	onChange func(dirs []string)
+	closed   bool
+}
+
+// NewDirWatcher cria um observador que chama onChange com os diretórios alterados
+// após delay sem novos eventos
+func NewDirWatcher(delay time.Duration, onChange func(dirs []string)) (*DirWatcher, error) {
+	if delay <= 0 {
+		delay = DefaultWatchDebounce
+	}
+
+	w := &DirWatcher{
+		dirs:     make(map[string]bool),
+		pending:  make(map[string]bool),
+		delay:    delay,
+		onChange: onChange,
+	}
+
+	backend, err := newWatchBackend(w.notify)
+	if err != nil {
+		return nil, err
+	}
+	w.backend = backend
+
+	return w, nil
+}
+
+// SetDirs substitui o conjunto de diretórios observados
+func (w *DirWatcher) SetDirs(dirs []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	// Normalizar e limitar a lista
+	wanted := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" || len(wanted) >= MaxWatchedDirs {
+			continue
+		}
+		wanted[filepath.Clean(dir)] = true
+	}
+
+	// Remover diretórios que não são mais necessários
+	for dir := range w.dirs {
+		if !wanted[dir] {
+			w.backend.remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+
+	// Adicionar novos diretórios
+	for dir := range wanted {
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.backend.add(dir); err == nil {
+			w.dirs[dir] = true
+		}
+	}
+}
+
+// Dirs retorna os diretórios atualmente observados
+func (w *DirWatcher) Dirs() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	dirs := make([]string, 0, len(w.dirs))
+	for dir := range w.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Close encerra o observador
+func (w *DirWatcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	return w.backend.close()
+}
+
+// notify registra uma alteração em um diretório e reinicia o temporizador de agrupamento
+func (w *DirWatcher) notify(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	w.pending[dir] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.delay, w.flush)
+}
+
+// flush entrega os diretórios pendentes ao callback
+func (w *DirWatcher) flush() {
+	w.mu.Lock()
+	if w.closed || len(w.pending) == 0 {
+		w.mu.Unlock()
+		return
+	}
+
+	dirs := make([]string, 0, len(w.pending))
+	for dir := range w.pending {
+		dirs = append(dirs, dir)
+	}
+	w.pending = make(map[string]bool)
+	w.mu.Unlock()
+
+	sort.Strings(dirs)
+	if w.onChange != nil {
+		w.onChange(dirs)
+	}
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Eventos do inotify que indicam mudança no conteúdo de um diretório
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_CLOSE_WRITE | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotifyBackend observa diretórios usando o inotify do Linux
+type inotifyBackend struct {
+	mu     sync.Mutex
+	file   *os.File
+	fd     int
+	wds    map[string]int
+	paths  map[int]string
+	notify func(dir string)
+}
+
+// newWatchBackend cria o backend baseado em inotify
+func newWatchBackend(notify func(dir string)) (watchBackend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	// Descritor não bloqueante: o runtime do Go usa o poller e Close interrompe a leitura
+	b := &inotifyBackend{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		wds:    make(map[string]int),
+		paths:  make(map[int]string),
+		notify: notify,
+	}
+
+	go b.readEvents()
+
+	return b, nil
+}
+
+// add começa a observar um diretório
+func (b *inotifyBackend) add(dir string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wd, err := unix.InotifyAddWatch(b.fd, dir, inotifyMask)
+	if err != nil {
+		return err
+	}
+	b.wds[dir] = wd
+	b.paths[wd] = dir
+	return nil
+}
+
+// remove deixa de observar um diretório
+func (b *inotifyBackend) remove(dir string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wd, ok := b.wds[dir]
+	if !ok {
+		return nil
+	}
+	delete(b.wds, dir)
+	delete(b.paths, wd)
+
+	_, err := unix.InotifyRmWatch(b.fd, uint32(wd))
+	return err
+}
+
+// close encerra o descritor do inotify
+func (b *inotifyBackend) close() error {
+	return b.file.Close()
+}
+
+// readEvents lê eventos do inotify até o descritor ser fechado
+func (b *inotifyBackend) readEvents() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+
+			b.mu.Lock()
+			dir, ok := b.paths[int(event.Wd)]
+			b.mu.Unlock()
+
+			if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+				// Fila estourou: considerar todos os diretórios alterados
+				b.notifyAll()
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			// Se o próprio diretório sumiu, avisar também o diretório pai
+			if event.Mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0 {
+				b.notify(filepath.Dir(dir))
+			}
+			b.notify(dir)
+		}
+	}
+}
+
+// notifyAll marca todos os diretórios observados como alterados
+func (b *inotifyBackend) notifyAll() {
+	b.mu.Lock()
+	dirs := make([]string, 0, len(b.wds))
+	for dir := range b.wds {
+		dirs = append(dirs, dir)
+	}
+	b.mu.Unlock()
+
+	for _, dir := range dirs {
+		b.notify(dir)
+	}
+}
@@ -0,0 +1,93 @@
+//go:build !linux
+
+package utils
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// pollInterval é o intervalo de verificação do observador por varredura
+const pollInterval = time.Second
+
+// pollBackend observa diretórios verificando periodicamente a data de modificação
+// (usado em sistemas sem inotify)
+type pollBackend struct {
+	mu     sync.Mutex
+	dirs   map[string]time.Time
+	notify func(dir string)
+	done   chan struct{}
+}
+
+// newWatchBackend cria o backend baseado em varredura periódica
+func newWatchBackend(notify func(dir string)) (watchBackend, error) {
+	b := &pollBackend{
+		dirs:   make(map[string]time.Time),
+		notify: notify,
+		done:   make(chan struct{}),
+	}
+
+	go b.poll()
+
+	return b, nil
+}
+
+// add começa a observar um diretório
+func (b *pollBackend) add(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.dirs[dir] = info.ModTime()
+	b.mu.Unlock()
+	return nil
+}
+
+// remove deixa de observar um diretório
+func (b *pollBackend) remove(dir string) error {
+	b.mu.Lock()
+	delete(b.dirs, dir)
+	b.mu.Unlock()
+	return nil
+}
+
+// close encerra a varredura
+func (b *pollBackend) close() error {
+	close(b.done)
+	return nil
+}
+
+// poll verifica os diretórios até o observador ser fechado
+func (b *pollBackend) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		var changed []string
+
+		b.mu.Lock()
+		for dir, modTime := range b.dirs {
+			info, err := os.Stat(dir)
+			if err != nil || !info.ModTime().Equal(modTime) {
+				changed = append(changed, dir)
+				if err == nil {
+					b.dirs[dir] = info.ModTime()
+				}
+			}
+		}
+		b.mu.Unlock()
+
+		for _, dir := range changed {
+			b.notify(dir)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package viewer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// diffRow é uma linha da visualização lado a lado; left/right são nil quando
+// a linha não existe naquele lado
+type diffRow struct {
+	left  *utils.DiffLine
+	right *utils.DiffLine
+}
+
+// DiffViewer exibe a comparação de dois arquivos lado a lado
+type DiffViewer struct {
+	app       *tview.Application
+	left      *tview.TextView
+	right     *tview.TextView
+	statusBar *tview.TextView
+	layout    *tview.Flex
+	file1     string
+	file2     string
+	texts     *[2]string // Conteúdos já lidos (LoadText); nil compara os arquivos
+	titles    [2]string  // Títulos dos lados; vazios usam o nome dos arquivos
+	options   utils.DiffOptions
+	diff      *utils.FileDiff
+	rows      []diffRow
+	hunkRows  []int
+	hunk      int
+	row       int
+	col       int
+	onExport  func(unified string)
+	onClose   func()
+}
+
+// NewDiffViewer cria um novo visualizador de diferenças
+func NewDiffViewer(app *tview.Application) *DiffViewer {
+	dv := &DiffViewer{
+		app:       app,
+		left:      tview.NewTextView(),
+		right:     tview.NewTextView(),
+		statusBar: tview.NewTextView(),
+		layout:    tview.NewFlex(),
+	}
+
+	// Os dois lados rolam juntos, sem quebra de linha, para manter as linhas alinhadas
+	for _, tv := range []*tview.TextView{dv.left, dv.right} {
+		tv.SetDynamicColors(true)
+		tv.SetScrollable(true)
+		tv.SetWrap(false)
+		tv.SetBorder(true)
+		tv.SetTitleAlign(tview.AlignLeft)
+	}
+
+	// Configurar a barra de status
+	dv.statusBar.SetTextColor(utils.ColorStatusText)
+	dv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
+	dv.statusBar.SetDynamicColors(true)
+
+	// Configurar o layout
+	panes := tview.NewFlex().
+		AddItem(dv.left, 0, 1, true).
+		AddItem(dv.right, 0, 1, false)
+	dv.layout.SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(dv.statusBar, 1, 1, false)
+
+	// Configurar manipuladores de eventos
+	dv.layout.SetInputCapture(dv.handleKeyEvents)
+
+	return dv
+}
+
+// SetExportFunc define a função chamada com o diff unificado ao pressionar U
+func (dv *DiffViewer) SetExportFunc(fn func(unified string)) {
+	dv.onExport = fn
+}
+
+// SetCloseFunc define a função chamada ao fechar o visualizador (ESC)
+func (dv *DiffViewer) SetCloseFunc(fn func()) {
+	dv.onClose = fn
+}
+
+// LoadFiles compara dois arquivos e exibe o resultado
+func (dv *DiffViewer) LoadFiles(file1, file2 string) error {
+	dv.file1, dv.file2 = file1, file2
+	dv.texts, dv.titles = nil, [2]string{}
+	return dv.reload()
+}
+
+// LoadText compara dois textos já lidos, como uma versão guardada no git e o
+// arquivo em disco. Os nomes vão para o diff unificado e os títulos para os
+// painéis.
+func (dv *DiffViewer) LoadText(oldName, oldTitle, oldText, newName, newTitle, newText string) {
+	dv.file1, dv.file2 = oldName, newName
+	dv.texts = &[2]string{oldText, newText}
+	dv.titles = [2]string{oldTitle, newTitle}
+	dv.reload()
+}
+
+// reload refaz a comparação com as opções atuais
+func (dv *DiffViewer) reload() error {
+	var diff *utils.FileDiff
+	if dv.texts != nil {
+		diff = utils.DiffText(dv.texts[0], dv.texts[1], dv.options)
+		diff.OldName, diff.NewName = dv.file1, dv.file2
+	} else {
+		var err error
+		if diff, err = utils.DiffFiles(dv.file1, dv.file2, dv.options); err != nil {
+			return err
+		}
+	}
+	dv.diff = diff
+	dv.buildRows()
+	dv.render()
+
+	dv.hunk = -1
+	dv.scrollTo(0)
+	if len(dv.hunkRows) > 0 {
+		dv.gotoHunk(0)
+	}
+	return nil
+}
+
+// buildRows alinha as linhas dos dois lados: em cada bloco alterado, as linhas
+// removidas são emparelhadas com as inseridas na mesma ordem
+func (dv *DiffViewer) buildRows() {
+	dv.rows = dv.rows[:0]
+	dv.hunkRows = dv.hunkRows[:0]
+
+	lines := dv.diff.Lines
+	for i := 0; i < len(lines); {
+		if lines[i].Op == utils.DiffEqual {
+			dv.rows = append(dv.rows, diffRow{left: &lines[i], right: &lines[i]})
+			i++
+			continue
+		}
+
+		// Bloco alterado: remoções seguidas de inserções
+		var deleted, inserted []*utils.DiffLine
+		for ; i < len(lines) && lines[i].Op == utils.DiffDelete; i++ {
+			deleted = append(deleted, &lines[i])
+		}
+		for ; i < len(lines) && lines[i].Op == utils.DiffInsert; i++ {
+			inserted = append(inserted, &lines[i])
+		}
+
+		dv.hunkRows = append(dv.hunkRows, len(dv.rows))
+		for k := 0; k < max(len(deleted), len(inserted)); k++ {
+			var row diffRow
+			if k < len(deleted) {
+				row.left = deleted[k]
+			}
+			if k < len(inserted) {
+				row.right = inserted[k]
+			}
+			dv.rows = append(dv.rows, row)
+		}
+	}
+}
+
+// render monta o texto dos dois lados
+func (dv *DiffViewer) render() {
+	width := len(fmt.Sprint(len(dv.rows)))
+
+	var left, right strings.Builder
+	for _, row := range dv.rows {
+		var leftSpans, rightSpans [][2]int
+		if row.left != nil && row.right != nil && row.left.Op == utils.DiffDelete {
+			leftSpans, rightSpans = utils.IntraLineDiff(row.left.Old, row.right.New)
+		}
+
+		writeDiffSide(&left, row.left, true, leftSpans, width)
+		writeDiffSide(&right, row.right, false, rightSpans, width)
+	}
+
+	dv.left.SetText(left.String())
+	dv.right.SetText(right.String())
+
+	inserted, deleted := dv.diff.Stats()
+	dv.left.SetTitle(fmt.Sprintf(" %s [red](-%d)[-] ", dv.title(0), deleted))
+	dv.right.SetTitle(fmt.Sprintf(" %s [green](+%d)[-] ", dv.title(1), inserted))
+}
+
+// title retorna o título de um dos lados (0 antigo, 1 novo)
+func (dv *DiffViewer) title(side int) string {
+	if dv.titles[side] != "" {
+		return tview.Escape(dv.titles[side])
+	}
+	return filepath.Base([]string{dv.file1, dv.file2}[side])
+}
+
+// writeDiffSide escreve uma linha de um dos lados, destacando os trechos alterados
+func writeDiffSide(sb *strings.Builder, line *utils.DiffLine, old bool, spans [][2]int, width int) {
+	if line == nil {
+		sb.WriteString(fmt.Sprintf("[gray]%*s[-] \n", width, "~"))
+		return
+	}
+
+	number, text := line.NewLine, line.New
+	if old {
+		number, text = line.OldLine, line.Old
+	}
+	text = strings.TrimSuffix(text, "\r")
+
+	color, highlight := "white", ""
+	switch line.Op {
+	case utils.DiffDelete:
+		color, highlight = "red", "white:darkred"
+	case utils.DiffInsert:
+		color, highlight = "green", "white:darkgreen"
+	}
+
+	sb.WriteString(fmt.Sprintf("[gray]%*d[-] [%s]", width, number, color))
+	if len(spans) == 0 {
+		sb.WriteString(tview.Escape(text))
+	} else {
+		runes := []rune(text)
+		pos := 0
+		for _, span := range spans {
+			end := min(span[1], len(runes))
+			if span[0] >= end {
+				continue
+			}
+			sb.WriteString(tview.Escape(string(runes[pos:span[0]])))
+			sb.WriteString(fmt.Sprintf("[%s]%s[%s:-]", highlight, tview.Escape(string(runes[span[0]:end])), color))
+			pos = end
+		}
+		sb.WriteString(tview.Escape(string(runes[min(pos, len(runes)):])))
+	}
+	sb.WriteString("[-]\n")
+}
+
+// scrollTo posiciona os dois lados na mesma linha e coluna
+func (dv *DiffViewer) scrollTo(row int) {
+	dv.row = max(0, min(row, len(dv.rows)-1))
+	dv.left.ScrollTo(dv.row, dv.col)
+	dv.right.ScrollTo(dv.row, dv.col)
+	dv.updateStatus()
+}
+
+// gotoHunk exibe o bloco alterado informado com algumas linhas de contexto acima
+func (dv *DiffViewer) gotoHunk(index int) {
+	if len(dv.hunkRows) == 0 {
+		return
+	}
+	dv.hunk = max(0, min(index, len(dv.hunkRows)-1))
+	dv.scrollTo(dv.hunkRows[dv.hunk] - utils.DefaultDiffContext)
+}
+
+// NextHunk vai para o próximo bloco alterado
+func (dv *DiffViewer) NextHunk() {
+	dv.gotoHunk(dv.hunk + 1)
+}
+
+// PreviousHunk vai para o bloco alterado anterior
+func (dv *DiffViewer) PreviousHunk() {
+	dv.gotoHunk(dv.hunk - 1)
+}
+
+// updateStatus atualiza a barra de status com a posição e as opções
+func (dv *DiffViewer) updateStatus() {
+	onOff := func(b bool) string {
+		if b {
+			return "sim"
+		}
+		return "não"
+	}
+
+	position := "arquivos iguais"
+	if len(dv.hunkRows) > 0 {
+		position = fmt.Sprintf("alteração %d/%d", dv.hunk+1, len(dv.hunkRows))
+	}
+
+	dv.statusBar.SetText(fmt.Sprintf(" %s  [::b]N/P[-:-:-] Próxima/Anterior  [::b]W[-:-:-] Ignorar espaços: %s  [::b]I[-:-:-] Ignorar maiúsculas: %s  [::b]U[-:-:-] Exportar diff  [::b]ESC[-:-:-] Fechar",
+		position, onOff(dv.options.IgnoreWhitespace), onOff(dv.options.IgnoreCase)))
+}
+
+// handleKeyEvents manipula eventos de teclado
+func (dv *DiffViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
+	_, _, _, height := dv.left.GetInnerRect()
+	page := max(height-1, 1)
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		if dv.onClose != nil {
+			dv.onClose()
+		}
+		return nil
+	case tcell.KeyUp:
+		dv.scrollTo(dv.row - 1)
+	case tcell.KeyDown:
+		dv.scrollTo(dv.row + 1)
+	case tcell.KeyPgUp:
+		dv.scrollTo(dv.row - page)
+	case tcell.KeyPgDn:
+		dv.scrollTo(dv.row + page)
+	case tcell.KeyHome:
+		dv.col = 0
+		dv.scrollTo(0)
+	case tcell.KeyEnd:
+		dv.scrollTo(len(dv.rows) - page)
+	case tcell.KeyLeft:
+		dv.col = max(dv.col-8, 0)
+		dv.scrollTo(dv.row)
+	case tcell.KeyRight:
+		dv.col += 8
+		dv.scrollTo(dv.row)
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'n', 'N', ']':
+			dv.NextHunk()
+		case 'p', 'P', '[':
+			dv.PreviousHunk()
+		case 'w', 'W':
+			dv.options.IgnoreWhitespace = !dv.options.IgnoreWhitespace
+			dv.reload()
+		case 'i', 'I':
+			dv.options.IgnoreCase = !dv.options.IgnoreCase
+			dv.reload()
+		case 'u', 'U':
+			if dv.onExport != nil {
+				dv.onExport(dv.diff.Unified())
+			}
+		}
+	}
+
+	// Os eventos são tratados aqui para que os dois lados rolem juntos
+	return nil
+}
+
+// Show exibe o visualizador
+func (dv *DiffViewer) Show() *tview.Flex {
+	return dv.layout
+}
@@ -0,0 +1,590 @@
+package viewer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// hexBytesPerRow é o número de bytes exibidos em cada linha
+	hexBytesPerRow = 16
+	// hexMinDigits é o número mínimo de dígitos da coluna de posição
+	hexMinDigits = 8
+)
+
+// hexPage desenha apenas as linhas visíveis do arquivo
+type hexPage struct {
+	*tview.Box
+	viewer *HexViewer
+}
+
+// HexViewer é um visualizador e editor hexadecimal paginado: só as linhas
+// visíveis são lidas do arquivo. No modo de edição os bytes são sobrescritos pela
+// coluna hexadecimal ou pela coluna de texto; as alterações ficam em memória até
+// serem salvas, com uma cópia de segurança do original.
+type HexViewer struct {
+	app       *tview.Application
+	page      *hexPage
+	inspector *tview.TextView
+	body      *tview.Flex
+	statusBar *tview.TextView
+	prompt    *tview.InputField
+	layout    *tview.Flex
+	file      *utils.HexFile
+
+	cursor        int64
+	top           int64 // Primeira linha visível
+	rows          int
+	asciiColumn   bool // Cursor na coluna de texto
+	nibble        int  // Metade do byte sendo digitada na coluna hexadecimal
+	editing       bool
+	showInspector bool
+
+	promptMode     rune // ':' posição, '/' texto, 'x' bytes
+	pattern        []byte
+	patternText    string
+	ignoreCase     bool
+	message        string
+	confirmDiscard bool
+	onClose        func()
+}
+
+// NewHexViewer cria um novo visualizador hexadecimal
+func NewHexViewer(app *tview.Application) *HexViewer {
+	hv := &HexViewer{
+		app:           app,
+		inspector:     tview.NewTextView(),
+		body:          tview.NewFlex(),
+		statusBar:     tview.NewTextView(),
+		prompt:        tview.NewInputField(),
+		layout:        tview.NewFlex(),
+		rows:          1,
+		showInspector: true,
+	}
+	hv.page = &hexPage{Box: tview.NewBox(), viewer: hv}
+
+	// Configurar a área hexadecimal
+	hv.page.SetBorder(true)
+	hv.page.SetTitle(" Visualizador Hexadecimal ")
+	hv.page.SetTitleAlign(tview.AlignLeft)
+	hv.page.SetInputCapture(hv.handleKeyEvents)
+	hv.page.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseScrollUp:
+			hv.moveCursor(-3 * hexBytesPerRow)
+			return action, nil
+		case tview.MouseScrollDown:
+			hv.moveCursor(3 * hexBytesPerRow)
+			return action, nil
+		}
+		return action, event
+	})
+
+	// Configurar o painel de interpretação
+	hv.inspector.SetDynamicColors(true)
+	hv.inspector.SetBorder(true)
+	hv.inspector.SetTitle(" Dados no Cursor ")
+	hv.inspector.SetTitleAlign(tview.AlignLeft)
+
+	// Configurar a barra de status
+	hv.statusBar.SetTextColor(utils.ColorStatusText)
+	hv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
+	hv.statusBar.SetDynamicColors(true)
+
+	// Campo de posição e de busca, exibido no lugar da barra de status
+	hv.prompt.SetDoneFunc(func(key tcell.Key) {
+		text := hv.prompt.GetText()
+		hv.closePrompt()
+		if key != tcell.KeyEnter {
+			return
+		}
+		switch hv.promptMode {
+		case ':':
+			hv.Goto(text)
+		case '/':
+			hv.SearchText(text)
+		case 'x':
+			hv.SearchBytes(text)
+		}
+	})
+
+	// Configurar o layout
+	hv.body.AddItem(hv.page, hexPageWidth(0), 0, true).
+		AddItem(hv.inspector, 0, 1, false)
+	hv.layout.SetDirection(tview.FlexRow).
+		AddItem(hv.body, 0, 1, true).
+		AddItem(hv.statusBar, 1, 1, false)
+
+	return hv
+}
+
+// SetCloseFunc define a função chamada ao fechar o visualizador
+func (hv *HexViewer) SetCloseFunc(fn func()) {
+	hv.onClose = fn
+}
+
+// LoadFile abre um arquivo para visualização e edição hexadecimal
+func (hv *HexViewer) LoadFile(filePath string) error {
+	file, err := utils.OpenHexFile(filePath)
+	if err != nil {
+		return err
+	}
+	if hv.file != nil {
+		hv.file.Close()
+	}
+	hv.file = file
+	hv.cursor, hv.top = 0, 0
+	if hv.showInspector {
+		hv.body.ResizeItem(hv.page, hexPageWidth(file.Size()), 0)
+	}
+
+	// Atualizar o título
+	hv.page.SetTitle(fmt.Sprintf(" %s (%s) - Hex ", filepath.Base(filePath), utils.FormatFileSize(file.Size())))
+	hv.update()
+	return nil
+}
+
+// Close fecha o arquivo, descartando alterações não salvas
+func (hv *HexViewer) Close() {
+	if hv.file != nil {
+		hv.file.Close()
+	}
+}
+
+// hexDigits retorna o número de dígitos da coluna de posição para o tamanho
+func hexDigits(size int64) int {
+	return max(hexMinDigits, len(strconv.FormatInt(max(size-1, 0), 16)))
+}
+
+// hexPageWidth retorna a largura da área hexadecimal, com as bordas
+func hexPageWidth(size int64) int {
+	return hexDigits(size) + 2 + hexBytesPerRow*3 + 2 + hexBytesPerRow + 2
+}
+
+// Draw desenha as linhas visíveis
+func (p *hexPage) Draw(screen tcell.Screen) {
+	p.DrawForSubclass(screen, p)
+	x, y, width, height := p.GetInnerRect()
+	hv := p.viewer
+	hv.rows = max(height-1, 1)
+	if hv.file == nil {
+		return
+	}
+	if hv.file.Size() == 0 {
+		tview.Print(screen, "Arquivo vazio", x, y, width, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+		return
+	}
+	hv.ensureVisible()
+
+	digits := hexDigits(hv.file.Size())
+	hexX := x + digits + 2
+	asciiX := hexX + hexBytesPerRow*3 + 2
+
+	base := tcell.StyleDefault.Foreground(tview.Styles.PrimaryTextColor).Background(tview.Styles.PrimitiveBackgroundColor)
+	dim := base.Foreground(tcell.ColorGray)
+	modified := base.Foreground(tcell.ColorRed).Bold(true)
+	active := base.Reverse(true)
+	passive := base.Underline(true)
+	if hv.editing {
+		active = active.Foreground(tcell.ColorYellow)
+	}
+
+	put := func(px, py int, text string, style tcell.Style) {
+		for i, r := range text {
+			if px+i < x+width {
+				screen.SetContent(px+i, py, r, nil, style)
+			}
+		}
+	}
+
+	// Cabeçalho com as colunas
+	header := strings.Repeat(" ", digits+2)
+	for i := 0; i < hexBytesPerRow; i++ {
+		if i == hexBytesPerRow/2 {
+			header += " "
+		}
+		header += fmt.Sprintf("%02X ", i)
+	}
+	put(x, y, header, dim)
+
+	buf := make([]byte, hv.rows*hexBytesPerRow)
+	start := hv.top * hexBytesPerRow
+	n, _ := hv.file.ReadAt(buf, start)
+	for row := 0; row*hexBytesPerRow < n; row++ {
+		py := y + 1 + row
+		rowStart := start + int64(row*hexBytesPerRow)
+		put(x, py, fmt.Sprintf("%0*X", digits, rowStart), dim)
+
+		for i := 0; i < hexBytesPerRow && row*hexBytesPerRow+i < n; i++ {
+			offset := rowStart + int64(i)
+			b := buf[row*hexBytesPerRow+i]
+
+			style := base
+			if hv.file.IsModified(offset) {
+				style = modified
+			}
+			hexStyle, asciiStyle := style, style
+			if offset == hv.cursor {
+				hexStyle, asciiStyle = active, passive
+				if hv.asciiColumn {
+					hexStyle, asciiStyle = passive, active
+				}
+			}
+
+			px := hexX + i*3
+			if i >= hexBytesPerRow/2 {
+				px++
+			}
+			put(px, py, fmt.Sprintf("%02X", b), hexStyle)
+
+			char := "."
+			if b >= 32 && b <= 126 {
+				char = string(rune(b))
+			}
+			put(asciiX+i, py, char, asciiStyle)
+		}
+	}
+}
+
+// ensureVisible rola a página para que o cursor fique visível
+func (hv *HexViewer) ensureVisible() {
+	row := hv.cursor / hexBytesPerRow
+	if row < hv.top {
+		hv.top = row
+	}
+	if row >= hv.top+int64(hv.rows) {
+		hv.top = row - int64(hv.rows) + 1
+	}
+}
+
+// moveCursor move o cursor delta bytes, sem sair do arquivo
+func (hv *HexViewer) moveCursor(delta int64) {
+	hv.setCursor(hv.cursor + delta)
+}
+
+// setCursor posiciona o cursor e atualiza o painel e a barra de status
+func (hv *HexViewer) setCursor(offset int64) {
+	if hv.file == nil {
+		return
+	}
+	hv.cursor = max(0, min(offset, hv.file.Size()-1))
+	hv.nibble = 0
+	hv.confirmDiscard = false
+	hv.ensureVisible()
+	hv.update()
+}
+
+// Goto vai para uma posição (decimal, 0x hexadecimal, percentual ou relativa)
+func (hv *HexViewer) Goto(target string) {
+	offset, err := utils.ParseOffset(target, hv.cursor, hv.file.Size())
+	if err != nil {
+		hv.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+		hv.update()
+		return
+	}
+	hv.message = ""
+	hv.setCursor(offset)
+}
+
+// SearchText procura o texto (sem diferenciar maiúsculas) a partir do cursor
+func (hv *HexViewer) SearchText(text string) {
+	if text == "" {
+		return
+	}
+	hv.pattern, hv.patternText, hv.ignoreCase = []byte(text), strconv.Quote(text), true
+	hv.find(hv.cursor, false)
+}
+
+// SearchBytes procura uma sequência de bytes em hexadecimal ("DE AD BE EF")
+func (hv *HexViewer) SearchBytes(text string) {
+	pattern, err := utils.ParseHexBytes(text)
+	if err != nil {
+		hv.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+		hv.update()
+		return
+	}
+	hv.pattern, hv.patternText, hv.ignoreCase = pattern, fmt.Sprintf("% X", pattern), false
+	hv.find(hv.cursor, false)
+}
+
+// find procura o último padrão buscado e posiciona o cursor na ocorrência
+func (hv *HexViewer) find(from int64, backward bool) {
+	if len(hv.pattern) == 0 {
+		return
+	}
+	offset, err := hv.file.Find(hv.pattern, from, backward, hv.ignoreCase)
+	switch {
+	case err != nil:
+		hv.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+	case offset < 0:
+		hv.message = fmt.Sprintf("[red]%s não encontrado[-]", tview.Escape(hv.patternText))
+	default:
+		hv.message = fmt.Sprintf("%s em 0x%X", tview.Escape(hv.patternText), offset)
+		hv.setCursor(offset)
+		return
+	}
+	hv.update()
+}
+
+// edit trata a digitação no modo de edição; retorna false para teclas que não editam
+func (hv *HexViewer) edit(r rune) bool {
+	current, ok := hv.file.ByteAt(hv.cursor)
+	if !ok {
+		return false
+	}
+
+	if hv.asciiColumn {
+		if r < 32 || r > 255 || r == 127 {
+			return false
+		}
+		hv.apply(byte(r))
+		hv.moveCursor(1)
+		return true
+	}
+
+	value, err := strconv.ParseUint(string(r), 16, 8)
+	if err != nil {
+		return false
+	}
+	if hv.nibble == 0 {
+		hv.apply(byte(value)<<4 | current&0x0F)
+		hv.nibble = 1
+		hv.update()
+		return true
+	}
+	hv.apply(current&0xF0 | byte(value))
+	hv.moveCursor(1)
+	return true
+}
+
+// apply sobrescreve o byte no cursor
+func (hv *HexViewer) apply(b byte) {
+	if err := hv.file.Set(hv.cursor, b); err != nil {
+		hv.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+	}
+}
+
+// Save grava as alterações, criando antes uma cópia de segurança do original
+func (hv *HexViewer) Save() error {
+	if !hv.file.Modified() {
+		hv.message = "Nenhuma alteração para salvar"
+		hv.update()
+		return nil
+	}
+	if err := hv.file.Save(true); err != nil {
+		hv.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+		hv.update()
+		return err
+	}
+	hv.message = fmt.Sprintf("Salvo (backup em %s)", tview.Escape(filepath.Base(hv.file.BackupPath())))
+	hv.update()
+	return nil
+}
+
+// undo desfaz a última alteração
+func (hv *HexViewer) undo() {
+	if offset, ok := hv.file.Undo(); ok {
+		hv.message = ""
+		hv.setCursor(offset)
+		return
+	}
+	hv.message = "Nada para desfazer"
+	hv.update()
+}
+
+// openPrompt exibe o campo de posição ou de busca no lugar da barra de status
+func (hv *HexViewer) openPrompt(mode rune) {
+	hv.promptMode = mode
+	switch mode {
+	case ':':
+		hv.prompt.SetLabel(" Ir para (0x hex, decimal, %, +/-): ")
+	case '/':
+		hv.prompt.SetLabel(" Buscar texto: ")
+	case 'x':
+		hv.prompt.SetLabel(" Buscar bytes (hex): ")
+	}
+	hv.prompt.SetText("")
+	hv.layout.RemoveItem(hv.statusBar)
+	hv.layout.AddItem(hv.prompt, 1, 1, true)
+	hv.app.SetFocus(hv.prompt)
+}
+
+// closePrompt volta a exibir a barra de status
+func (hv *HexViewer) closePrompt() {
+	hv.layout.RemoveItem(hv.prompt)
+	hv.layout.AddItem(hv.statusBar, 1, 1, false)
+	hv.app.SetFocus(hv.page)
+}
+
+// toggleInspector exibe ou oculta o painel de interpretação dos dados
+func (hv *HexViewer) toggleInspector() {
+	hv.showInspector = !hv.showInspector
+	if hv.showInspector {
+		hv.body.ResizeItem(hv.page, hexPageWidth(hv.file.Size()), 0)
+		hv.body.ResizeItem(hv.inspector, 0, 1)
+	} else {
+		hv.body.ResizeItem(hv.page, 0, 1)
+		hv.body.ResizeItem(hv.inspector, 0, 0)
+	}
+}
+
+// update atualiza o painel de interpretação e a barra de status
+func (hv *HexViewer) update() {
+	if hv.file == nil {
+		return
+	}
+
+	// Interpretação dos bytes no cursor
+	data := make([]byte, 8)
+	n, _ := hv.file.ReadAt(data, hv.cursor)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " Posição [::b]0x%X[-:-:-] (%d)\n\n", hv.cursor, hv.cursor)
+	fmt.Fprintf(&sb, " [gray]%-8s %-22s %s[-]\n", "Tipo", "Little-endian", "Big-endian")
+	for _, v := range utils.InspectBytes(data[:n]) {
+		fmt.Fprintf(&sb, " %-8s %-22s %s\n", v.Name, tview.Escape(v.LittleEndian), tview.Escape(v.BigEndian))
+	}
+	hv.inspector.SetText(sb.String())
+
+	// Barra de status
+	mode := ""
+	if hv.editing {
+		column := "HEX"
+		if hv.asciiColumn {
+			column = "TEXTO"
+		}
+		mode = fmt.Sprintf("  [black:yellow] EDITANDO %s [-:-]", column)
+	}
+	if hv.file.Modified() {
+		mode += "  [red]modificado[-]"
+	}
+	message := ""
+	if hv.message != "" {
+		message = "  " + hv.message
+	}
+
+	keys := "[::b]E[-:-:-] Editar  [::b]Tab[-:-:-] Coluna  [::b]:[-:-:-] Ir para  [::b]/[-:-:-] Texto  [::b]X[-:-:-] Bytes  [::b]n/N[-:-:-] Próxima/Anterior  [::b]I[-:-:-] Dados  [::b]ESC[-:-:-] Fechar"
+	if hv.editing {
+		keys = "[::b]Tab[-:-:-] Coluna  [::b]Ctrl+S[-:-:-] Salvar  [::b]Ctrl+Z[-:-:-] Desfazer  [::b]Ctrl+G[-:-:-] Ir para  [::b]Ctrl+F[-:-:-] Buscar  [::b]ESC[-:-:-] Parar de editar"
+	}
+	hv.statusBar.SetText(fmt.Sprintf(" 0x%X/0x%X%s%s  %s", hv.cursor, max(hv.file.Size()-1, 0), mode, message, keys))
+}
+
+// handleKeyEvents manipula eventos de teclado
+func (hv *HexViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
+	if hv.file == nil {
+		return event
+	}
+	hv.message = ""
+
+	// Navegação e comandos comuns aos dois modos
+	switch event.Key() {
+	case tcell.KeyEscape:
+		switch {
+		case hv.editing:
+			hv.editing = false
+			hv.nibble = 0
+			hv.update()
+		case hv.file.Modified() && !hv.confirmDiscard:
+			hv.confirmDiscard = true
+			hv.message = "[red]Alterações não salvas:[-] [::b]Ctrl+S[-:-:-] salva, [::b]ESC[-:-:-] descarta"
+			hv.update()
+		default:
+			hv.Close()
+			if hv.onClose != nil {
+				hv.onClose()
+			}
+		}
+		return nil
+	case tcell.KeyLeft:
+		hv.moveCursor(-1)
+	case tcell.KeyRight:
+		hv.moveCursor(1)
+	case tcell.KeyUp:
+		hv.moveCursor(-hexBytesPerRow)
+	case tcell.KeyDown:
+		hv.moveCursor(hexBytesPerRow)
+	case tcell.KeyPgUp:
+		hv.moveCursor(-int64(hv.rows * hexBytesPerRow))
+	case tcell.KeyPgDn:
+		hv.moveCursor(int64(hv.rows * hexBytesPerRow))
+	case tcell.KeyHome:
+		if event.Modifiers()&tcell.ModCtrl != 0 {
+			hv.setCursor(0)
+		} else {
+			hv.setCursor(hv.cursor - hv.cursor%hexBytesPerRow)
+		}
+	case tcell.KeyEnd:
+		if event.Modifiers()&tcell.ModCtrl != 0 {
+			hv.setCursor(hv.file.Size() - 1)
+		} else {
+			hv.setCursor(hv.cursor - hv.cursor%hexBytesPerRow + hexBytesPerRow - 1)
+		}
+	case tcell.KeyTab, tcell.KeyBacktab:
+		hv.asciiColumn = !hv.asciiColumn
+		hv.nibble = 0
+		hv.update()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		hv.moveCursor(-1)
+	case tcell.KeyCtrlG:
+		hv.openPrompt(':')
+	case tcell.KeyCtrlF:
+		hv.openPrompt('/')
+	case tcell.KeyCtrlB:
+		hv.openPrompt('x')
+	case tcell.KeyCtrlN:
+		hv.find(hv.cursor+1, false)
+	case tcell.KeyCtrlP:
+		hv.find(hv.cursor, true)
+	case tcell.KeyCtrlS:
+		hv.Save()
+	case tcell.KeyCtrlZ:
+		hv.undo()
+	case tcell.KeyEnter, tcell.KeyInsert:
+		hv.editing = !hv.editing
+		hv.nibble = 0
+		hv.update()
+	case tcell.KeyRune:
+		if hv.editing {
+			hv.edit(event.Rune())
+			return nil
+		}
+		switch event.Rune() {
+		case 'e', 'E':
+			hv.editing = true
+			hv.update()
+		case ':', 'g':
+			hv.openPrompt(':')
+		case '/':
+			hv.openPrompt('/')
+		case 'x', 'X':
+			hv.openPrompt('x')
+		case 'n':
+			hv.find(hv.cursor+1, false)
+		case 'N':
+			hv.find(hv.cursor, true)
+		case 'i', 'I':
+			hv.toggleInspector()
+		case 's':
+			hv.Save()
+		case 'u':
+			hv.undo()
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+// Show exibe o visualizador
+func (hv *HexViewer) Show() *tview.Flex {
+	return hv.layout
+}
@@ -0,0 +1,434 @@
+package viewer
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TokenKind é a categoria de um trecho de código, usada para escolher a cor
+type TokenKind int
+
+const (
+	TokenText     TokenKind = iota // Texto sem destaque
+	TokenKeyword                   // Palavras reservadas (ênfase no Markdown)
+	TokenType                      // Tipos embutidos (tags no YAML)
+	TokenLiteral                   // true, false, nil, .T. ...
+	TokenString                    // Textos entre aspas (código no Markdown)
+	TokenNumber                    // Números
+	TokenComment                   // Comentários (citações no Markdown)
+	TokenOperator                  // Operadores e pontuação
+	TokenFunction                  // Nome seguido de "(" (links no Markdown)
+	TokenPreproc                   // Diretivas (#include, #define, cercas de código)
+	TokenKey                       // Chaves de JSON e YAML
+	TokenVariable                  // Variáveis do shell ($HOME) e âncoras do YAML
+	TokenHeading                   // Títulos do Markdown
+)
+
+// TokenKinds lista todas as categorias, na ordem das constantes
+var TokenKinds = []TokenKind{
+	TokenText, TokenKeyword, TokenType, TokenLiteral, TokenString, TokenNumber, TokenComment,
+	TokenOperator, TokenFunction, TokenPreproc, TokenKey, TokenVariable, TokenHeading,
+}
+
+// tokenKindNames são os nomes das categorias nos esquemas de cores ("syntax.<nome>")
+var tokenKindNames = []string{
+	"text", "keyword", "type", "literal", "string", "number", "comment",
+	"operator", "function", "preproc", "key", "variable", "heading",
+}
+
+// String retorna o nome da categoria
+func (k TokenKind) String() string {
+	if int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return "text"
+}
+
+// SyntaxColors associa cada categoria a uma cor
+type SyntaxColors map[TokenKind]tcell.Color
+
+// DefaultSyntaxColors são as cores usadas quando o tema não define outras (fundo escuro)
+var DefaultSyntaxColors = SyntaxColors{
+	TokenText:     tcell.ColorWhite,
+	TokenKeyword:  tcell.ColorYellow,
+	TokenType:     tcell.NewRGBColor(0, 255, 255),
+	TokenLiteral:  tcell.NewRGBColor(255, 0, 255),
+	TokenString:   tcell.ColorLime,
+	TokenNumber:   tcell.NewRGBColor(255, 0, 255),
+	TokenComment:  tcell.ColorGray,
+	TokenOperator: tcell.ColorWhite,
+	TokenFunction: tcell.NewRGBColor(0, 255, 255),
+	TokenPreproc:  tcell.ColorRed,
+	TokenKey:      tcell.NewRGBColor(0, 255, 255),
+	TokenVariable: tcell.ColorYellow,
+	TokenHeading:  tcell.ColorYellow,
+}
+
+// Token é um trecho de uma linha com a sua categoria
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// lexState guarda o que continua de uma linha para a seguinte
+type lexState struct {
+	blockComment bool // Dentro de um comentário de bloco
+	rawString    bool // Dentro de uma string de várias linhas
+	fence        bool // Dentro de um bloco de código do Markdown
+}
+
+// Language descreve como destacar uma linguagem. As regras genéricas (comentários,
+// strings, números, palavras) atendem as linguagens no estilo C; lineLexer trata
+// as que são organizadas por linha, como Markdown e YAML.
+type Language struct {
+	Name       string
+	Extensions []string // Extensões, com ponto e em minúsculas
+	FileNames  []string // Nomes completos de arquivo (ex.: .bashrc)
+	Shebangs   []string // Interpretadores reconhecidos na linha #!
+
+	lineComments      []string
+	commentNeedsSpace bool      // Comentário de linha só no início ou após espaço (shell, YAML)
+	blockComment      [2]string // Início e fim do comentário de bloco
+	starComment       bool      // "*" no início da linha é comentário (xBase)
+	stringDelims      string
+	rawStringDelim    byte // Delimitador de string de várias linhas, sem escapes (` no Go)
+	escapes           bool // "\" escapa o caractere seguinte nas strings
+	ignoreCase        bool
+	preprocessor      string // Prefixo de diretiva no início da linha
+	variables         bool   // $nome, ${nome}, $1 (shell)
+	dotWords          bool   // .T., .F., .AND. (xBase)
+	keyStrings        bool   // String seguida de ":" é uma chave (JSON)
+	keywords          map[string]bool
+	types             map[string]bool
+	literals          map[string]bool
+
+	lineLexer func(l *Language, line string, st *lexState) []Token
+}
+
+// DetectLanguage escolhe a linguagem pelo nome do arquivo ou, se não houver
+// correspondência, pelo interpretador da linha #! (shebang). Retorna nil para
+// arquivos sem destaque.
+func DetectLanguage(fileName, firstLine string) *Language {
+	base := strings.ToLower(filepath.Base(fileName))
+	ext := filepath.Ext(base)
+	for _, lang := range Languages {
+		for _, name := range lang.FileNames {
+			if base == name {
+				return lang
+			}
+		}
+		for _, e := range lang.Extensions {
+			if ext == e {
+				return lang
+			}
+		}
+	}
+
+	if interpreter := shebangInterpreter(firstLine); interpreter != "" {
+		for _, lang := range Languages {
+			for _, sh := range lang.Shebangs {
+				if interpreter == sh {
+					return lang
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// shebangInterpreter extrai o interpretador de "#!/bin/sh" ou "#!/usr/bin/env bash -e"
+func shebangInterpreter(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(line[2:])
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				return filepath.Base(f)
+			}
+		}
+		return ""
+	}
+	return interpreter
+}
+
+// Highlight divide cada linha em trechos com categoria. A concatenação dos
+// trechos de uma linha é sempre igual à linha original.
+func Highlight(lang *Language, lines []string) [][]Token {
+	result := make([][]Token, len(lines))
+	var st lexState
+	for i, line := range lines {
+		if lang == nil {
+			result[i] = []Token{{Kind: TokenText, Text: line}}
+			continue
+		}
+		if lang.lineLexer != nil {
+			result[i] = lang.lineLexer(lang, line, &st)
+		} else {
+			result[i] = lang.tokenize(line, &st)
+		}
+	}
+	return result
+}
+
+// tokenWriter acumula os trechos de uma linha, juntando os vizinhos da mesma categoria
+type tokenWriter struct {
+	line   string
+	pos    int
+	tokens []Token
+}
+
+// emit registra line[pos:end] com a categoria informada
+func (w *tokenWriter) emit(kind TokenKind, end int) {
+	if end <= w.pos {
+		return
+	}
+	text := w.line[w.pos:end]
+	w.pos = end
+	if n := len(w.tokens); n > 0 && w.tokens[n-1].Kind == kind {
+		w.tokens[n-1].Text += text
+		return
+	}
+	w.tokens = append(w.tokens, Token{Kind: kind, Text: text})
+}
+
+// tokenize aplica as regras genéricas a uma linha
+func (l *Language) tokenize(line string, st *lexState) []Token {
+	w := &tokenWriter{line: line}
+	l.tokenizeFrom(w, st)
+	return w.tokens
+}
+
+// tokenizeFrom continua a análise da linha a partir de w.pos
+func (l *Language) tokenizeFrom(w *tokenWriter, st *lexState) {
+	line := w.line
+
+	// Continuações de linhas anteriores
+	if st.blockComment {
+		end := strings.Index(line[w.pos:], l.blockComment[1])
+		if end < 0 {
+			w.emit(TokenComment, len(line))
+			return
+		}
+		st.blockComment = false
+		w.emit(TokenComment, w.pos+end+len(l.blockComment[1]))
+	}
+	if st.rawString {
+		end := strings.IndexByte(line[w.pos:], l.rawStringDelim)
+		if end < 0 {
+			w.emit(TokenString, len(line))
+			return
+		}
+		st.rawString = false
+		w.emit(TokenString, w.pos+end+1)
+	}
+
+	// Regras que valem para a linha inteira
+	if w.pos == 0 {
+		trimmed := strings.TrimLeft(line, " \t")
+		if l.preprocessor != "" && strings.HasPrefix(trimmed, l.preprocessor) {
+			w.emit(TokenPreproc, len(line))
+			return
+		}
+		if l.starComment && strings.HasPrefix(trimmed, "*") {
+			w.emit(TokenComment, len(line))
+			return
+		}
+	}
+
+	for w.pos < len(line) {
+		i := w.pos
+		rest := line[i:]
+		c := line[i]
+
+		// Comentários de linha
+		if l.startsLineComment(line, i) {
+			w.emit(TokenComment, len(line))
+			return
+		}
+
+		// Comentários de bloco
+		if l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]) {
+			end := strings.Index(rest[len(l.blockComment[0]):], l.blockComment[1])
+			if end < 0 {
+				st.blockComment = true
+				w.emit(TokenComment, len(line))
+				return
+			}
+			w.emit(TokenComment, i+len(l.blockComment[0])+end+len(l.blockComment[1]))
+			continue
+		}
+
+		// Strings de várias linhas
+		if l.rawStringDelim != 0 && c == l.rawStringDelim {
+			end := strings.IndexByte(rest[1:], l.rawStringDelim)
+			if end < 0 {
+				st.rawString = true
+				w.emit(TokenString, len(line))
+				return
+			}
+			w.emit(TokenString, i+end+2)
+			continue
+		}
+
+		// Strings
+		if strings.IndexByte(l.stringDelims, c) >= 0 {
+			end := l.stringEnd(line, i)
+			kind := TokenString
+			if l.keyStrings && strings.HasPrefix(strings.TrimLeft(line[end:], " \t"), ":") {
+				kind = TokenKey
+			}
+			w.emit(kind, end)
+			continue
+		}
+
+		// Variáveis do shell
+		if l.variables && c == '$' {
+			w.emit(TokenVariable, shellVariableEnd(line, i))
+			continue
+		}
+
+		// Palavras entre pontos do xBase
+		if l.dotWords && c == '.' {
+			if end := strings.IndexByte(rest[1:], '.'); end > 0 {
+				word := strings.ToLower(rest[:end+2])
+				if l.literals[word] {
+					w.emit(TokenLiteral, i+end+2)
+					continue
+				}
+				if l.keywords[word] {
+					w.emit(TokenKeyword, i+end+2)
+					continue
+				}
+			}
+		}
+
+		// Números
+		if isDigit(c) || c == '.' && i+1 < len(line) && isDigit(line[i+1]) {
+			end := i + 1
+			for end < len(line) && (isWordByte(line[end]) || line[end] == '.' && end+1 < len(line) && isDigit(line[end+1])) {
+				end++
+			}
+			w.emit(TokenNumber, end)
+			continue
+		}
+
+		// Palavras
+		if r, size := utf8.DecodeRuneInString(rest); r == '_' || unicode.IsLetter(r) {
+			end := i + size
+			for end < len(line) {
+				r, size := utf8.DecodeRuneInString(line[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			w.emit(l.wordKind(line[i:end], line[end:]), end)
+			continue
+		}
+
+		// Operadores e o restante
+		if strings.IndexByte("+-*/%=<>!&|^~?:;,.(){}[]@", c) >= 0 {
+			w.emit(TokenOperator, i+1)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		w.emit(TokenText, i+size)
+	}
+}
+
+// startsLineComment indica se um comentário de linha começa na posição i
+func (l *Language) startsLineComment(line string, i int) bool {
+	for _, prefix := range l.lineComments {
+		if !strings.HasPrefix(line[i:], prefix) {
+			continue
+		}
+		if l.commentNeedsSpace && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// stringEnd retorna a posição logo após o fim da string iniciada em start (ou o fim da linha)
+func (l *Language) stringEnd(line string, start int) int {
+	delim := line[start]
+	for i := start + 1; i < len(line); i++ {
+		switch {
+		case l.escapes && line[i] == '\\':
+			i++
+		case line[i] == delim:
+			return i + 1
+		}
+	}
+	return len(line)
+}
+
+// wordKind classifica uma palavra; after é o texto que vem depois dela
+func (l *Language) wordKind(word, after string) TokenKind {
+	key := word
+	if l.ignoreCase {
+		key = strings.ToLower(word)
+	}
+	switch {
+	case l.keywords[key]:
+		return TokenKeyword
+	case l.types[key]:
+		return TokenType
+	case l.literals[key]:
+		return TokenLiteral
+	case strings.HasPrefix(strings.TrimLeft(after, " \t"), "("):
+		return TokenFunction
+	}
+	return TokenText
+}
+
+// shellVariableEnd retorna o fim de $nome, ${...}, $1, $@ etc.
+func shellVariableEnd(line string, start int) int {
+	i := start + 1
+	if i >= len(line) {
+		return i
+	}
+	switch c := line[i]; {
+	case c == '{':
+		if end := strings.IndexByte(line[i:], '}'); end >= 0 {
+			return i + end + 1
+		}
+		return len(line)
+	case strings.IndexByte("@*#?$!-0123456789", c) >= 0:
+		return i + 1
+	}
+	for i < len(line) && isWordByte(line[i]) {
+		i++
+	}
+	return i
+}
+
+// isDigit indica se o byte é um dígito decimal
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordByte indica se o byte pode fazer parte de um identificador ASCII
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// wordSet cria um conjunto de palavras
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
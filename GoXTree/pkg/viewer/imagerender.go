@@ -0,0 +1,266 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// GraphicsMode é a forma de desenhar imagens no terminal
+type GraphicsMode int
+
+const (
+	GraphicsASCII  GraphicsMode = iota // Caracteres em tons de cinza
+	GraphicsBlocks                     // Meios-blocos coloridos (dois pixels por célula)
+	GraphicsSixel                      // Protocolo sixel (xterm, foot, mlterm, WezTerm...)
+	GraphicsKitty                      // Protocolo gráfico do kitty (kitty, WezTerm, Ghostty)
+)
+
+// String retorna o nome do modo
+func (m GraphicsMode) String() string {
+	switch m {
+	case GraphicsBlocks:
+		return "blocos"
+	case GraphicsSixel:
+		return "sixel"
+	case GraphicsKitty:
+		return "kitty"
+	}
+	return "ascii"
+}
+
+// ParseGraphicsMode converte o nome do modo (como em GOXTREE_IMAGE)
+func ParseGraphicsMode(name string) (GraphicsMode, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ascii":
+		return GraphicsASCII, true
+	case "blocos", "blocks", "truecolor":
+		return GraphicsBlocks, true
+	case "sixel":
+		return GraphicsSixel, true
+	case "kitty":
+		return GraphicsKitty, true
+	}
+	return GraphicsASCII, false
+}
+
+// DetectGraphics escolhe o melhor modo suportado pelo terminal, a partir das
+// variáveis de ambiente e do número de cores. GOXTREE_IMAGE força um modo.
+func DetectGraphics(colors int, getenv func(string) string) GraphicsMode {
+	if mode, ok := ParseGraphicsMode(getenv("GOXTREE_IMAGE")); ok {
+		return mode
+	}
+
+	// Dentro do tmux/screen as sequências gráficas não chegam ao terminal
+	if getenv("TMUX") == "" && !strings.HasPrefix(getenv("TERM"), "screen") {
+		term := getenv("TERM")
+		program := getenv("TERM_PROGRAM")
+		switch {
+		case term == "xterm-kitty", term == "xterm-ghostty", getenv("KITTY_WINDOW_ID") != "",
+			program == "WezTerm", program == "ghostty":
+			return GraphicsKitty
+		case strings.Contains(term, "sixel"), term == "foot", term == "foot-extra",
+			strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "yaft"), term == "contour",
+			program == "mintty", program == "iTerm.app":
+			return GraphicsSixel
+		}
+	}
+
+	if colors >= 256 {
+		return GraphicsBlocks
+	}
+	return GraphicsASCII
+}
+
+// ImageViewport é o trecho da imagem exibido: Zoom 1 mostra a imagem inteira na
+// área disponível; o centro é dado em pixels da imagem
+type ImageViewport struct {
+	Zoom    float64
+	CenterX float64
+	CenterY float64
+}
+
+// Fit mostra a imagem inteira, centralizada
+func (v *ImageViewport) Fit(bounds image.Rectangle) {
+	v.Zoom = 1
+	v.CenterX = float64(bounds.Dx()) / 2
+	v.CenterY = float64(bounds.Dy()) / 2
+}
+
+// Scale retorna quantos pixels de saída correspondem a um pixel da imagem
+func (v *ImageViewport) Scale(bounds image.Rectangle, outW, outH int) float64 {
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return 1
+	}
+	fit := math.Min(float64(outW)/float64(bounds.Dx()), float64(outH)/float64(bounds.Dy()))
+	return fit * v.Zoom
+}
+
+// Visible calcula o trecho da imagem (src) que aparece numa área de outW x outH
+// pixels e o tamanho que ele ocupa nela (dstW x dstH). O centro é ajustado para
+// que o trecho não saia da imagem.
+func (v *ImageViewport) Visible(bounds image.Rectangle, outW, outH int) (src image.Rectangle, dstW, dstH int) {
+	scale := v.Scale(bounds, outW, outH)
+	if scale <= 0 {
+		return image.Rectangle{}, 0, 0
+	}
+
+	axis := func(size, out int, center *float64) (lo, hi, dst int) {
+		visible := float64(out) / scale
+		if visible >= float64(size) {
+			*center = float64(size) / 2
+			return 0, size, max(1, min(out, int(math.Round(float64(size)*scale))))
+		}
+		*center = math.Max(visible/2, math.Min(*center, float64(size)-visible/2))
+		lo = int(math.Floor(*center - visible/2))
+		hi = min(size, max(lo+1, int(math.Ceil(*center+visible/2))))
+		return lo, hi, out
+	}
+
+	x0, x1, dstW := axis(bounds.Dx(), outW, &v.CenterX)
+	y0, y1, dstH := axis(bounds.Dy(), outH, &v.CenterY)
+	src = image.Rect(x0, y0, x1, y1).Add(bounds.Min)
+	return src, dstW, dstH
+}
+
+// ScaleImage redimensiona o trecho src da imagem para w x h pixels, compondo a
+// transparência sobre a cor de fundo
+func ScaleImage(img image.Image, src image.Rectangle, w, h int, background color.Color) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	if !src.Empty() {
+		draw.BiLinear.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+	}
+	return dst
+}
+
+// EncodeSixel converte a imagem para o protocolo sixel, com até 256 cores
+func EncodeSixel(img image.Image) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Reduzir para a paleta com difusão de erro
+	paletted := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&buf, "\"1;1;%d;%d", width, height)
+
+	// Registrar apenas as cores usadas (componentes em percentual)
+	used := make([]bool, len(paletted.Palette))
+	for _, index := range paletted.Pix {
+		used[index] = true
+	}
+	for i, c := range paletted.Palette {
+		if !used[i] {
+			continue
+		}
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	// Cada faixa tem 6 linhas de pixels; cada cor da faixa é uma passada
+	bands := make(map[uint8][]byte)
+	for y := 0; y < height; y += 6 {
+		clear(bands)
+		for dy := 0; dy < 6 && y+dy < height; dy++ {
+			row := paletted.Pix[(y+dy)*paletted.Stride:]
+			for x := 0; x < width; x++ {
+				bits, ok := bands[row[x]]
+				if !ok {
+					bits = make([]byte, width)
+					bands[row[x]] = bits
+				}
+				bits[x] |= 1 << dy
+			}
+		}
+
+		colors := make([]int, 0, len(bands))
+		for index := range bands {
+			colors = append(colors, int(index))
+		}
+		sort.Ints(colors)
+
+		for n, index := range colors {
+			if n > 0 {
+				buf.WriteByte('$')
+			}
+			buf.WriteString("#" + strconv.Itoa(index))
+			writeSixelRow(&buf, bands[uint8(index)])
+		}
+		buf.WriteByte('-')
+	}
+
+	buf.WriteString("\x1b\\")
+	return buf.Bytes()
+}
+
+// writeSixelRow escreve uma passada da faixa, compactando repetições
+func writeSixelRow(buf *bytes.Buffer, bits []byte) {
+	// Colunas vazias no fim não precisam ser enviadas
+	end := len(bits)
+	for end > 0 && bits[end-1] == 0 {
+		end--
+	}
+	for x := 0; x < end; {
+		run := 1
+		for x+run < end && bits[x+run] == bits[x] {
+			run++
+		}
+		char := byte('?' + bits[x])
+		if run > 3 {
+			buf.WriteString("!" + strconv.Itoa(run))
+			buf.WriteByte(char)
+		} else {
+			for i := 0; i < run; i++ {
+				buf.WriteByte(char)
+			}
+		}
+		x += run
+	}
+}
+
+// kittyChunk é o tamanho máximo de cada bloco em base64 do protocolo do kitty
+const kittyChunk = 4096
+
+// EncodeKitty converte a imagem (em PNG) para o protocolo gráfico do kitty,
+// ocupando cols x rows células, sem mover o cursor
+func EncodeKitty(img image.Image, id, cols, rows int) ([]byte, error) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data.Bytes())
+
+	var buf bytes.Buffer
+	for first := true; first || encoded != ""; first = false {
+		chunk := encoded[:min(kittyChunk, len(encoded))]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if encoded != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&buf, "\x1b_Ga=T,f=100,q=2,C=1,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&buf, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// KittyDelete remove a imagem da tela e libera seus dados no terminal
+func KittyDelete(id int) []byte {
+	return []byte(fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id))
+}
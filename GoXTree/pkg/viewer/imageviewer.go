@@ -1,71 +1,162 @@
 package viewer
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
 	_ "image/gif"  // Registro de decodificadores de imagem
 	_ "image/jpeg" // Registro de decodificadores de imagem
 	_ "image/png"  // Registro de decodificadores de imagem
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
+	"golang.org/x/image/draw"
+
+	_ "golang.org/x/image/bmp"  // Registro de decodificadores de imagem
+	_ "golang.org/x/image/tiff" // Registro de decodificadores de imagem
+	_ "golang.org/x/image/webp" // Registro de decodificadores de imagem
 
 	"github.com/gdamore/tcell/v2"
 )
 
-// ImageViewer representa um visualizador de imagens em ASCII art
+const (
+	imageMaxFileSize = 64 * 1024 * 1024 // Arquivos maiores não são abertos
+	imageMaxPixels   = 50_000_000       // Nem imagens com mais pixels que isso
+	imageZoomStep    = 1.25
+	imageKittyID     = 4711 // Identificador da imagem no protocolo do kitty
+)
+
+// Tamanho de célula assumido quando o terminal não informa o tamanho em pixels
+const (
+	defaultCellWidth  = 8
+	defaultCellHeight = 16
+)
+
+// asciiChars são os tons de cinza do modo ASCII, do mais escuro ao mais claro
+const asciiChars = " .:-=+*#%@"
+
+// imagePage desenha a imagem na área do visualizador
+type imagePage struct {
+	*tview.Box
+	viewer *ImageViewer
+}
+
+// imageKey identifica o que foi desenhado, para reaproveitar o desenho anterior
+type imageKey struct {
+	mode         GraphicsMode
+	area         image.Rectangle // Área interna, em células
+	screenW      int
+	screenH      int
+	view         ImageViewport
+	cellW, cellH int
+	outW, outH   int
+	background   color.RGBA
+}
+
+// ImageViewer exibe imagens PNG, JPEG, GIF, WebP, BMP e TIFF com zoom e
+// deslocamento. Conforme o terminal, desenha com os protocolos gráficos sixel
+// ou kitty, com meios-blocos coloridos ou em ASCII.
 type ImageViewer struct {
 	app       *tview.Application
-	textView  *tview.TextView
+	page      *imagePage
 	statusBar *tview.TextView
 	layout    *tview.Flex
 	filePath  string
 	fileInfo  os.FileInfo
 	image     image.Image
+	format    string
 	width     int
 	height    int
+
+	view     ImageViewport
+	mode     GraphicsMode
+	detected bool // Modo definido (detectado ou escolhido com SetMode)
+	probed   bool // Terminal já consultado
+	hasTty   bool
+	visible  image.Rectangle // Trecho da imagem exibido no último desenho
+	outputW  int             // Área de saída do último desenho, em pixels
+	outputH  int
+
+	// Desenho em células (ASCII e blocos)
+	cells    *image.RGBA
+	cellsKey imageKey
+
+	// Imagem enviada diretamente ao terminal (sixel e kitty): as células da área
+	// ficam bloqueadas para que o tcell não as redesenhe por cima
+	screen  tcell.Screen
+	pending imageKey
+	emitted imageKey
+	locked  image.Rectangle
+
+	message string
+	closed  bool
+	onClose func()
 }
 
 // NewImageViewer cria um novo visualizador de imagens
 func NewImageViewer(app *tview.Application) *ImageViewer {
 	iv := &ImageViewer{
 		app:       app,
-		textView:  tview.NewTextView(),
 		statusBar: tview.NewTextView(),
 		layout:    tview.NewFlex(),
 	}
-
-	// Configurar o visualizador de texto
-	iv.textView.SetDynamicColors(true)
-	iv.textView.SetRegions(true)
-	iv.textView.SetScrollable(true)
-	iv.textView.SetBorder(true)
-	iv.textView.SetTitle(" Visualizador de Imagem ")
-	iv.textView.SetTitleAlign(tview.AlignLeft)
-	iv.textView.SetBorderColor(utils.ColorViewerBorder)
-	iv.textView.SetTitleColor(utils.ColorViewerTitle)
-	iv.textView.SetTextColor(utils.ColorViewerText)
+	iv.page = &imagePage{Box: tview.NewBox(), viewer: iv}
+
+	// Configurar a área da imagem
+	iv.page.SetBorder(true)
+	iv.page.SetTitle(" Visualizador de Imagem ")
+	iv.page.SetTitleAlign(tview.AlignLeft)
+	iv.page.SetBorderColor(utils.ColorViewerBorder)
+	iv.page.SetTitleColor(utils.ColorViewerTitle)
+	iv.page.SetInputCapture(iv.handleKeyEvents)
+	iv.page.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseScrollUp:
+			iv.zoom(imageZoomStep)
+			return action, nil
+		case tview.MouseScrollDown:
+			iv.zoom(1 / imageZoomStep)
+			return action, nil
+		}
+		return action, event
+	})
 
 	// Configurar a barra de status
 	iv.statusBar.SetTextColor(utils.ColorStatusText)
 	iv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
 	iv.statusBar.SetDynamicColors(true)
-	iv.statusBar.SetText("[::b]ESC[-:-:-] Fechar  [::b]↑/↓[-:-:-] Rolar  [::b]PgUp/PgDn[-:-:-] Página  [::b]Home/End[-:-:-] Início/Fim")
 
 	// Configurar o layout
 	iv.layout.SetDirection(tview.FlexRow).
-		AddItem(iv.textView, 0, 1, true).
+		AddItem(iv.page, 0, 1, true).
 		AddItem(iv.statusBar, 1, 1, false)
 
-	// Configurar manipuladores de eventos
-	iv.textView.SetInputCapture(iv.handleKeyEvents)
-
 	return iv
 }
 
+// SetCloseFunc define a função chamada ao fechar o visualizador (ESC)
+func (iv *ImageViewer) SetCloseFunc(fn func()) {
+	iv.onClose = fn
+}
+
+// SetMode define como a imagem é desenhada, em vez de detectar pelo terminal
+func (iv *ImageViewer) SetMode(mode GraphicsMode) {
+	iv.mode = mode
+	iv.detected = true
+	iv.updateStatus()
+}
+
+// Mode retorna como a imagem está sendo desenhada
+func (iv *ImageViewer) Mode() GraphicsMode {
+	return iv.mode
+}
+
 // LoadFile carrega uma imagem
 func (iv *ImageViewer) LoadFile(filePath string) error {
 	// Obter informações do arquivo
@@ -80,8 +171,8 @@ func (iv *ImageViewer) LoadFile(filePath string) error {
 	}
 
 	// Verificar o tamanho do arquivo
-	if info.Size() > 10*1024*1024 { // 10MB
-		return fmt.Errorf("arquivo muito grande para visualização (> 10MB)")
+	if info.Size() > imageMaxFileSize {
+		return fmt.Errorf("arquivo muito grande para visualização (> %s)", utils.FormatFileSize(imageMaxFileSize))
 	}
 
 	// Abrir o arquivo
@@ -91,8 +182,20 @@ func (iv *ImageViewer) LoadFile(filePath string) error {
 	}
 	defer file.Close()
 
-	// Decodificar a imagem
-	img, _, err := image.Decode(file)
+	// Recusar imagens grandes demais antes de decodificá-las
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return fmt.Errorf("erro ao decodificar imagem: %w", err)
+	}
+	if config.Width*config.Height > imageMaxPixels {
+		return fmt.Errorf("imagem muito grande para visualização (%dx%d)", config.Width, config.Height)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Decodificar a imagem (GIFs animados mostram o primeiro quadro)
+	img, format, err := image.Decode(file)
 	if err != nil {
 		return fmt.Errorf("erro ao decodificar imagem: %w", err)
 	}
@@ -101,96 +204,338 @@ func (iv *ImageViewer) LoadFile(filePath string) error {
 	iv.filePath = filePath
 	iv.fileInfo = info
 	iv.image = img
+	iv.format = strings.ToUpper(format)
 	iv.width = img.Bounds().Dx()
 	iv.height = img.Bounds().Dy()
+	iv.view.Fit(img.Bounds())
 
 	// Atualizar o título
 	fileName := filepath.Base(filePath)
-	iv.textView.SetTitle(fmt.Sprintf(" %s (%dx%d) - %s ", fileName, iv.width, iv.height, utils.FormatFileSize(info.Size())))
-
-	// Exibir a imagem
-	iv.displayImageAsAscii()
+	iv.page.SetTitle(fmt.Sprintf(" %s (%dx%d %s) - %s ", fileName, iv.width, iv.height, iv.format, utils.FormatFileSize(info.Size())))
+	iv.updateStatus()
 
 	return nil
 }
 
-// displayImageAsAscii exibe a imagem como ASCII art
-func (iv *ImageViewer) displayImageAsAscii() {
-	if iv.image == nil {
-		return
+// Close remove a imagem enviada ao terminal
+func (iv *ImageViewer) Close() {
+	iv.release()
+	iv.closed = true
+}
+
+// detect escolhe o modo de desenho conforme o terminal
+func (iv *ImageViewer) detect(screen tcell.Screen) {
+	_, iv.hasTty = screen.Tty()
+	iv.probed = true
+	if !iv.detected {
+		iv.mode = DetectGraphics(screen.Colors(), os.Getenv)
+		iv.detected = true
+	}
+	if !iv.hasTty && (iv.mode == GraphicsSixel || iv.mode == GraphicsKitty) {
+		iv.mode = GraphicsBlocks
 	}
+	iv.updateStatus()
+}
 
-	// Obter dimensões da imagem
-	bounds := iv.image.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
+// cellSize retorna o tamanho de uma célula do terminal em pixels
+func (iv *ImageViewer) cellSize(screen tcell.Screen) (int, int) {
+	if tty, ok := screen.Tty(); ok {
+		if size, err := tty.WindowSize(); err == nil {
+			if w, h := size.CellDimensions(); w > 0 && h > 0 {
+				return w, h
+			}
+		}
+	}
+	return defaultCellWidth, defaultCellHeight
+}
 
-	// Calcular fator de escala para ajustar ao terminal
-	// Assumindo que cada caractere tem proporção 2:1 (altura:largura)
-	maxWidth := 80
-	maxHeight := 40
+// backgroundColor retorna a cor de fundo da área da imagem
+func (iv *ImageViewer) backgroundColor() color.RGBA {
+	r, g, b := iv.page.GetBackgroundColor().RGB()
+	if r < 0 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xff}
+}
 
-	scaleX := float64(width) / float64(maxWidth)
-	scaleY := float64(height) / float64(maxHeight) * 2 // Compensar pela proporção do caractere
+// Draw desenha a imagem no modo atual
+func (p *imagePage) Draw(screen tcell.Screen) {
+	p.DrawForSubclass(screen, p)
+	iv := p.viewer
+	iv.screen = screen
+	x, y, width, height := p.GetInnerRect()
+	if iv.image == nil || width <= 0 || height <= 0 {
+		iv.release()
+		return
+	}
+	if !iv.probed {
+		iv.detect(screen)
+	}
 
-	scale := scaleX
-	if scaleY > scale {
-		scale = scaleY
+	// Nos modos em células cada célula tem dois pixels (um em cima do outro);
+	// nos protocolos gráficos a saída tem a resolução real do terminal
+	key := imageKey{mode: iv.mode, area: image.Rect(x, y, x+width, y+height), background: iv.backgroundColor()}
+	key.screenW, key.screenH = screen.Size()
+	key.cellW, key.cellH = 1, 2
+	if iv.mode == GraphicsSixel || iv.mode == GraphicsKitty {
+		key.cellW, key.cellH = iv.cellSize(screen)
 	}
+	iv.outputW, iv.outputH = width*key.cellW, height*key.cellH
+	key.outW, key.outH = iv.outputW, iv.outputH
+
+	src, dstW, dstH := iv.view.Visible(iv.image.Bounds(), iv.outputW, iv.outputH)
+	iv.visible = src
+	key.view = iv.view
+	iv.updateStatus()
 
-	if scale < 1 {
-		scale = 1
+	if iv.mode == GraphicsSixel || iv.mode == GraphicsKitty {
+		iv.drawGraphics(screen, key, src, dstW, dstH)
+		return
 	}
+	iv.pending = imageKey{}
+	iv.release()
+	iv.drawCells(screen, key, src, dstW, dstH)
+}
 
-	// Calcular dimensões escaladas
-	scaledWidth := int(float64(width) / scale)
-	scaledHeight := int(float64(height) / scale)
+// drawCells desenha a imagem com meios-blocos coloridos ou em ASCII
+func (iv *ImageViewer) drawCells(screen tcell.Screen, key imageKey, src image.Rectangle, dstW, dstH int) {
+	if iv.cells == nil || key != iv.cellsKey {
+		scaled := ScaleImage(iv.image, src, dstW, dstH, key.background)
+		iv.cells = image.NewRGBA(image.Rect(0, 0, key.outW, key.outH))
+		draw.Draw(iv.cells, iv.cells.Bounds(), image.NewUniform(key.background), image.Point{}, draw.Src)
+		offset := image.Pt((key.outW-dstW)/2, (key.outH-dstH)/2)
+		draw.Draw(iv.cells, scaled.Bounds().Add(offset), scaled, image.Point{}, draw.Src)
+		iv.cellsKey = key
+	}
 
-	// Caracteres para representar diferentes níveis de brilho
-	// Do mais escuro para o mais claro
-	asciiChars := " .:-=+*#%@"
+	rgb := func(c color.RGBA) tcell.Color {
+		return tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+	}
+	x, y := key.area.Min.X, key.area.Min.Y
+	for row := 0; row < key.area.Dy(); row++ {
+		for col := 0; col < key.area.Dx(); col++ {
+			top := iv.cells.RGBAAt(col, row*2)
+			bottom := iv.cells.RGBAAt(col, row*2+1)
+			if iv.mode == GraphicsBlocks {
+				style := tcell.StyleDefault.Foreground(rgb(top)).Background(rgb(bottom))
+				screen.SetContent(x+col, y+row, '▀', nil, style)
+				continue
+			}
+			gray := (int(top.R) + int(top.G) + int(top.B) + int(bottom.R) + int(bottom.G) + int(bottom.B)) / 6
+			char := asciiChars[gray*(len(asciiChars)-1)/255]
+			screen.SetContent(x+col, y+row, rune(char), nil, tcell.StyleDefault.Foreground(utils.ColorViewerText).Background(iv.page.GetBackgroundColor()))
+		}
+	}
+}
+
+// drawGraphics limpa a área e agenda o envio da imagem ao terminal, que precisa
+// acontecer depois de o tcell atualizar a tela
+func (iv *ImageViewer) drawGraphics(screen tcell.Screen, key imageKey, src image.Rectangle, dstW, dstH int) {
+	if key == iv.emitted && !iv.locked.Empty() {
+		return
+	}
+	iv.release()
 
-	// Construir a representação ASCII
-	var content string
+	style := tcell.StyleDefault.Background(iv.page.GetBackgroundColor())
+	for row := key.area.Min.Y; row < key.area.Max.Y; row++ {
+		for col := key.area.Min.X; col < key.area.Max.X; col++ {
+			screen.SetContent(col, row, ' ', nil, style)
+		}
+	}
 
-	for y := 0; y < scaledHeight; y++ {
-		for x := 0; x < scaledWidth; x++ {
-			// Calcular coordenadas na imagem original
-			origX := int(float64(x) * scale)
-			origY := int(float64(y) * scale)
+	if key == iv.pending {
+		return
+	}
+	iv.pending = key
+	iv.app.QueueUpdate(func() {
+		iv.emit(key, src, dstW, dstH)
+	})
+}
 
-			// Obter cor do pixel
-			pixel := iv.image.At(origX+bounds.Min.X, origY+bounds.Min.Y)
+// emit envia a imagem ao terminal com o protocolo do modo atual e bloqueia as
+// células da área
+func (iv *ImageViewer) emit(key imageKey, src image.Rectangle, dstW, dstH int) {
+	if iv.closed || key != iv.pending || key == iv.emitted || iv.screen == nil {
+		return
+	}
+	iv.pending = imageKey{}
+	tty, ok := iv.screen.Tty()
+	if !ok {
+		return
+	}
 
-			// Converter para escala de cinza
-			r, g, b, _ := color.RGBAModel.Convert(pixel).RGBA()
-			gray := (r + g + b) / 3
+	// Centralizar na área, alinhando às células
+	cols := min(key.area.Dx(), (dstW+key.cellW-1)/key.cellW)
+	rows := min(key.area.Dy(), (dstH+key.cellH-1)/key.cellH)
+	x := key.area.Min.X + (key.area.Dx()-cols)/2
+	y := key.area.Min.Y + (key.area.Dy()-rows)/2
+
+	scaled := ScaleImage(iv.image, src, dstW, dstH, key.background)
+	var data []byte
+	if key.mode == GraphicsKitty {
+		var err error
+		if data, err = EncodeKitty(scaled, imageKittyID, cols, rows); err != nil {
+			iv.message = err.Error()
+			iv.updateStatus()
+			return
+		}
+	} else {
+		data = EncodeSixel(scaled)
+	}
 
-			// Normalizar para 0-255
-			gray = gray / 257
+	var buf bytes.Buffer
+	buf.WriteString("\x1b7")
+	fmt.Fprintf(&buf, "\x1b[%d;%dH", y+1, x+1)
+	buf.Write(data)
+	buf.WriteString("\x1b8")
+	if _, err := tty.Write(buf.Bytes()); err != nil {
+		iv.message = err.Error()
+		iv.updateStatus()
+		return
+	}
 
-			// Mapear para um caractere ASCII
-			charIndex := int(float64(gray) * float64(len(asciiChars)-1) / 255.0)
-			if charIndex >= len(asciiChars) {
-				charIndex = len(asciiChars) - 1
-			}
+	iv.screen.LockRegion(key.area.Min.X, key.area.Min.Y, key.area.Dx(), key.area.Dy(), true)
+	iv.locked = key.area
+	iv.emitted = key
+}
 
-			content += string(asciiChars[charIndex])
+// release remove a imagem enviada ao terminal e libera as células da área,
+// que voltam a ser desenhadas pelo tcell
+func (iv *ImageViewer) release() {
+	if iv.locked.Empty() || iv.screen == nil {
+		return
+	}
+	if iv.emitted.mode == GraphicsKitty {
+		if tty, ok := iv.screen.Tty(); ok {
+			tty.Write(KittyDelete(imageKittyID))
 		}
-		content += "\n"
 	}
+	iv.screen.LockRegion(iv.locked.Min.X, iv.locked.Min.Y, iv.locked.Dx(), iv.locked.Dy(), false)
+	iv.locked = image.Rectangle{}
+	iv.emitted = imageKey{}
+}
+
+// realZoom retorna o zoom em que cada pixel da imagem ocupa um pixel da saída
+func (iv *ImageViewer) realZoom() float64 {
+	fit := ImageViewport{Zoom: 1}
+	scale := fit.Scale(iv.image.Bounds(), max(iv.outputW, 1), max(iv.outputH, 1))
+	return 1 / scale
+}
+
+// zoom multiplica o zoom por factor, entre a imagem inteira (ou o tamanho
+// real, se menor) e 16 vezes o tamanho real
+func (iv *ImageViewer) zoom(factor float64) {
+	if iv.image == nil {
+		return
+	}
+	actual := iv.realZoom()
+	iv.view.Zoom = math.Max(math.Min(1, actual), math.Min(iv.view.Zoom*factor, math.Max(1, actual*16)))
+	iv.message = ""
+	iv.updateStatus()
+}
 
-	// Exibir no visualizador
-	iv.textView.SetText(content)
-	iv.textView.ScrollToBeginning()
+// pan desloca o trecho visível em frações da área exibida
+func (iv *ImageViewer) pan(dx, dy float64) {
+	if iv.image == nil {
+		return
+	}
+	iv.view.CenterX += dx * float64(iv.visible.Dx())
+	iv.view.CenterY += dy * float64(iv.visible.Dy())
+}
+
+// cycleMode alterna entre os modos de desenho disponíveis no terminal
+func (iv *ImageViewer) cycleMode() {
+	last := GraphicsBlocks
+	if iv.hasTty {
+		last = GraphicsKitty
+	}
+	iv.mode++
+	if iv.mode > last {
+		iv.mode = GraphicsASCII
+	}
+	iv.message = fmt.Sprintf("Modo %s", iv.mode)
+	iv.updateStatus()
+}
+
+// updateStatus atualiza a barra de status
+func (iv *ImageViewer) updateStatus() {
+	status := ""
+	if iv.image != nil {
+		scale := iv.view.Scale(iv.image.Bounds(), max(iv.outputW, 1), max(iv.outputH, 1))
+		status = fmt.Sprintf(" %dx%d %s  %.0f%%  %s  ", iv.width, iv.height, iv.format, scale*100, iv.mode)
+	}
+	if iv.message != "" {
+		status += "[yellow]" + tview.Escape(iv.message) + "[-]  "
+	}
+	status += "[::b]ESC[-:-:-] Fechar  [::b]+/-[-:-:-] Zoom  [::b]0[-:-:-] Ajustar  [::b]1[-:-:-] Tamanho real  [::b]Setas[-:-:-] Mover  [::b]M[-:-:-] Modo"
+	iv.statusBar.SetText(status)
 }
 
 // handleKeyEvents manipula eventos de teclado
 func (iv *ImageViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
 	switch event.Key() {
 	case tcell.KeyEscape:
-		// O fechamento é tratado pelo FileViewer
-		return event
+		iv.Close()
+		if iv.onClose != nil {
+			iv.onClose()
+		}
+		return nil
+	case tcell.KeyUp:
+		iv.pan(0, -0.125)
+		return nil
+	case tcell.KeyDown:
+		iv.pan(0, 0.125)
+		return nil
+	case tcell.KeyLeft:
+		iv.pan(-0.125, 0)
+		return nil
+	case tcell.KeyRight:
+		iv.pan(0.125, 0)
+		return nil
+	case tcell.KeyPgUp:
+		iv.pan(0, -1)
+		return nil
+	case tcell.KeyPgDn:
+		iv.pan(0, 1)
+		return nil
+	case tcell.KeyHome:
+		iv.view.CenterX, iv.view.CenterY = 0, 0
+		return nil
+	case tcell.KeyEnd:
+		iv.view.CenterX, iv.view.CenterY = float64(iv.width), float64(iv.height)
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case '+', '=':
+			iv.zoom(imageZoomStep)
+		case '-', '_':
+			iv.zoom(1 / imageZoomStep)
+		case '0':
+			if iv.image != nil {
+				iv.view.Fit(iv.image.Bounds())
+				iv.message = ""
+				iv.updateStatus()
+			}
+		case '1':
+			if iv.image != nil {
+				iv.view.Zoom = iv.realZoom()
+				iv.message = ""
+				iv.updateStatus()
+			}
+		case 'k':
+			iv.pan(0, -0.125)
+		case 'j':
+			iv.pan(0, 0.125)
+		case 'h':
+			iv.pan(-0.125, 0)
+		case 'l':
+			iv.pan(0.125, 0)
+		case 'm', 'M':
+			iv.cycleMode()
+		default:
+			return event
+		}
+		return nil
 	}
 
 	return event
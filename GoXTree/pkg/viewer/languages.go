@@ -0,0 +1,247 @@
+package viewer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Languages são as linguagens com destaque de sintaxe, na ordem em que são testadas
+var Languages = []*Language{
+	langGo,
+	langAdvPL,
+	langJSON,
+	langYAML,
+	langMarkdown,
+	langShell,
+	langSQL,
+}
+
+var langGo = &Language{
+	Name:           "Go",
+	Extensions:     []string{".go"},
+	lineComments:   []string{"//"},
+	blockComment:   [2]string{"/*", "*/"},
+	stringDelims:   `"'`,
+	rawStringDelim: '`',
+	escapes:        true,
+	keywords: wordSet(`break case chan const continue default defer else fallthrough for func
+		go goto if import interface map package range return select struct switch type var`),
+	types: wordSet(`any bool byte comparable complex64 complex128 error float32 float64 int int8
+		int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr`),
+	literals: wordSet(`true false nil iota`),
+}
+
+// langAdvPL cobre o AdvPL e o TLPP (Protheus), que não diferenciam maiúsculas
+var langAdvPL = &Language{
+	Name:         "AdvPL/TLPP",
+	Extensions:   []string{".prw", ".prx", ".prg", ".tlpp", ".ch", ".aph"},
+	lineComments: []string{"//", "&&"},
+	blockComment: [2]string{"/*", "*/"},
+	starComment:  true,
+	stringDelims: `"'`,
+	ignoreCase:   true,
+	preprocessor: "#",
+	dotWords:     true,
+	keywords: wordSet(`function static user main return local private public default if else
+		elseif endif while enddo do for to step next each in case otherwise endcase begin sequence
+		end recover using exit loop class method data endclass from inherit self super new
+		namespace property constructor destructor public protected private try catch finally
+		endtry throw wsservice wsmethod wsdata wsstruct endwsservice wsrestful endwsrestful
+		.and. .or. .not.`),
+	types: wordSet(`character numeric logical date array object codeblock variant json
+		as integer decimal double long`),
+	literals: wordSet(`nil .t. .f.`),
+}
+
+var langJSON = &Language{
+	Name:         "JSON",
+	Extensions:   []string{".json", ".jsonc", ".geojson"},
+	FileNames:    []string{".babelrc", ".eslintrc"},
+	lineComments: []string{"//"},
+	blockComment: [2]string{"/*", "*/"},
+	stringDelims: `"`,
+	escapes:      true,
+	keyStrings:   true,
+	literals:     wordSet(`true false null`),
+}
+
+var langShell = &Language{
+	Name:              "Shell",
+	Extensions:        []string{".sh", ".bash", ".zsh", ".ksh"},
+	FileNames:         []string{".bashrc", ".bash_profile", ".bash_aliases", ".profile", ".zshrc", ".zprofile"},
+	Shebangs:          []string{"sh", "bash", "zsh", "ksh", "dash", "ash"},
+	lineComments:      []string{"#"},
+	commentNeedsSpace: true,
+	stringDelims:      `"'`,
+	escapes:           true,
+	variables:         true,
+	keywords: wordSet(`if then else elif fi for while until do done case esac function in
+		select return break continue local export readonly declare typeset unset shift
+		source exit trap eval exec time`),
+	types: wordSet(`echo printf cd pwd read test set alias cat grep sed awk find xargs
+		mkdir rm cp mv ls chmod chown sudo`),
+	literals: wordSet(`true false`),
+}
+
+var langSQL = &Language{
+	Name:         "SQL",
+	Extensions:   []string{".sql", ".ddl", ".dml"},
+	lineComments: []string{"--"},
+	blockComment: [2]string{"/*", "*/"},
+	stringDelims: `'"`,
+	ignoreCase:   true,
+	keywords: wordSet(`select from where and or not in is like between exists insert into values
+		update set delete create alter drop table view index sequence trigger procedure function
+		begin end declare as on join inner left right full outer cross union all distinct group
+		by order having limit offset top case when then else primary key foreign references
+		constraint unique default check grant revoke commit rollback transaction with returns
+		return if while asc desc nolock merge using matched truncate execute exec cursor fetch
+		open close deallocate database schema over partition`),
+	types: wordSet(`int integer smallint bigint tinyint decimal numeric float real double
+		char varchar nchar nvarchar text ntext date datetime datetime2 timestamp time boolean
+		bit blob clob binary varbinary money uniqueidentifier serial`),
+	literals: wordSet(`null true false`),
+}
+
+// langYAML destaca chaves, valores, âncoras e comentários linha a linha
+var langYAML = &Language{
+	Name:              "YAML",
+	Extensions:        []string{".yaml", ".yml"},
+	lineComments:      []string{"#"},
+	commentNeedsSpace: true,
+	stringDelims:      `"'`,
+	escapes:           true,
+	literals:          wordSet(`true false null yes no on off True False Null Yes No TRUE FALSE NULL ~`),
+	lineLexer:         lexYAMLLine,
+}
+
+// yamlKey reconhece "  - chave:" no início da linha
+var yamlKey = regexp.MustCompile(`^(\s*(?:-\s+)*)("[^"]*"|'[^']*'|[^\s#'"{\[][^:#]*?)(\s*:)(?:\s|$)`)
+
+// lexYAMLLine destaca uma linha de YAML
+func lexYAMLLine(l *Language, line string, st *lexState) []Token {
+	w := &tokenWriter{line: line}
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case strings.HasPrefix(trimmed, "#"):
+		w.emit(TokenComment, len(line))
+		return w.tokens
+	case trimmed == "---" || trimmed == "...":
+		w.emit(TokenPreproc, len(line))
+		return w.tokens
+	}
+
+	// Indentação, marcadores de lista e chave
+	if m := yamlKey.FindStringSubmatchIndex(line); m != nil {
+		w.emit(TokenOperator, m[3])
+		w.emit(TokenKey, m[5])
+		w.emit(TokenOperator, m[7])
+	} else {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		w.emit(TokenText, indent)
+		if strings.HasPrefix(line[indent:], "- ") || line[indent:] == "-" {
+			w.emit(TokenOperator, indent+1)
+		}
+	}
+
+	// Valor: âncoras, tags e marcadores de bloco, depois as regras genéricas
+	for {
+		rest := line[w.pos:]
+		value := strings.TrimLeft(rest, " \t")
+		w.emit(TokenText, w.pos+len(rest)-len(value))
+		if strings.HasPrefix(value, "&") || strings.HasPrefix(value, "*") {
+			w.emit(TokenVariable, w.pos+wordEnd(value))
+			continue
+		}
+		if strings.HasPrefix(value, "!") {
+			w.emit(TokenType, w.pos+wordEnd(value))
+			continue
+		}
+		if value == "|" || value == ">" || strings.HasPrefix(value, "|-") || strings.HasPrefix(value, ">-") {
+			w.emit(TokenOperator, len(line))
+		}
+		break
+	}
+	l.tokenizeFrom(w, st)
+	return w.tokens
+}
+
+// wordEnd retorna o fim da primeira palavra (até um espaço)
+func wordEnd(s string) int {
+	if end := strings.IndexAny(s, " \t"); end >= 0 {
+		return end
+	}
+	return len(s)
+}
+
+// langMarkdown destaca títulos, citações, listas, blocos e trechos de código, ênfase e links
+var langMarkdown = &Language{
+	Name:       "Markdown",
+	Extensions: []string{".md", ".markdown", ".mdown"},
+	lineLexer:  lexMarkdownLine,
+}
+
+var (
+	markdownList   = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s`)
+	markdownRule   = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	markdownInline = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*|!?\\[[^\\]]*\\]\\([^)]*\\)|<https?://[^>]+>")
+)
+
+// lexMarkdownLine destaca uma linha de Markdown
+func lexMarkdownLine(l *Language, line string, st *lexState) []Token {
+	w := &tokenWriter{line: line}
+	trimmed := strings.TrimSpace(line)
+
+	// Blocos de código cercados por ``` ou ~~~
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		st.fence = !st.fence
+		w.emit(TokenPreproc, len(line))
+		return w.tokens
+	}
+	if st.fence {
+		w.emit(TokenString, len(line))
+		return w.tokens
+	}
+
+	switch {
+	case strings.HasPrefix(trimmed, "#"):
+		w.emit(TokenHeading, len(line))
+		return w.tokens
+	case strings.HasPrefix(trimmed, ">"):
+		w.emit(TokenComment, len(line))
+		return w.tokens
+	case markdownRule.MatchString(line):
+		w.emit(TokenOperator, len(line))
+		return w.tokens
+	case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+		w.emit(TokenString, len(line))
+		return w.tokens
+	}
+
+	if m := markdownList.FindStringIndex(line); m != nil {
+		w.emit(TokenOperator, m[1])
+	}
+
+	// Elementos dentro da linha
+	base := w.pos
+	for _, m := range markdownInline.FindAllStringIndex(line[base:], -1) {
+		start, end := base+m[0], base+m[1]
+		w.emit(TokenText, start)
+		switch text := line[start:end]; {
+		case strings.HasPrefix(text, "`"):
+			w.emit(TokenString, end)
+		case strings.HasPrefix(text, "*"), strings.HasPrefix(text, "_"):
+			w.emit(TokenKeyword, end)
+		case strings.HasPrefix(text, "<"):
+			w.emit(TokenType, end)
+		default:
+			// [texto](endereço): o texto como função, o endereço como tipo
+			split := start + strings.Index(text, "](") + 1
+			w.emit(TokenFunction, split)
+			w.emit(TokenType, end)
+		}
+	}
+	w.emit(TokenText, len(line))
+	return w.tokens
+}
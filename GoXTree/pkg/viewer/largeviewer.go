@@ -0,0 +1,542 @@
+package viewer
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peder1981/GoXTree/pkg/utils"
+	"github.com/rivo/tview"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// largeRefreshInterval é o intervalo de atualização do índice e do modo seguir
+const largeRefreshInterval = 500 * time.Millisecond
+
+// largePage desenha apenas as linhas visíveis do arquivo
+type largePage struct {
+	*tview.Box
+	viewer *LargeViewer
+}
+
+// LargeViewer exibe arquivos de qualquer tamanho lendo só a página visível. O
+// índice de linhas é montado em segundo plano; permite ir para uma linha ou
+// percentual, buscar para frente e para trás e acompanhar o fim do arquivo
+// (como no tail -f).
+type LargeViewer struct {
+	app       *tview.Application
+	page      *largePage
+	statusBar *tview.TextView
+	prompt    *tview.InputField
+	layout    *tview.Flex
+	file      *utils.LargeFile
+
+	top         int64 // Início da primeira linha visível
+	column      int   // Deslocamento horizontal, em runas
+	height      int
+	lineNumbers bool
+	following   bool
+
+	query      string
+	re         *regexp.Regexp
+	match      int64 // Início da linha encontrada (-1 = nenhuma)
+	stopSearch chan struct{}
+	promptMode rune // '/', '?' ou ':'
+	message    string
+
+	closed  chan struct{}
+	onClose func()
+}
+
+// NewLargeViewer cria um novo visualizador de arquivos grandes
+func NewLargeViewer(app *tview.Application) *LargeViewer {
+	lv := &LargeViewer{
+		app:         app,
+		statusBar:   tview.NewTextView(),
+		prompt:      tview.NewInputField(),
+		layout:      tview.NewFlex(),
+		lineNumbers: true,
+		match:       -1,
+		height:      1,
+		closed:      make(chan struct{}),
+	}
+	lv.page = &largePage{Box: tview.NewBox(), viewer: lv}
+
+	// Configurar a área de texto
+	lv.page.SetBorder(true)
+	lv.page.SetTitle(" Visualizador de Arquivos Grandes ")
+	lv.page.SetTitleAlign(tview.AlignLeft)
+	lv.page.SetInputCapture(lv.handleKeyEvents)
+	lv.page.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseScrollUp:
+			lv.scroll(-3)
+			return action, nil
+		case tview.MouseScrollDown:
+			lv.scroll(3)
+			return action, nil
+		}
+		return action, event
+	})
+
+	// Configurar a barra de status
+	lv.statusBar.SetTextColor(utils.ColorStatusText)
+	lv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
+	lv.statusBar.SetDynamicColors(true)
+
+	// Campo de busca e de posição, exibido no lugar da barra de status
+	lv.prompt.SetDoneFunc(func(key tcell.Key) {
+		text := lv.prompt.GetText()
+		lv.closePrompt()
+		if key != tcell.KeyEnter {
+			return
+		}
+		if lv.promptMode == ':' {
+			lv.Goto(text)
+			return
+		}
+		lv.Search(text, lv.promptMode == '?')
+	})
+
+	// Configurar o layout
+	lv.layout.SetDirection(tview.FlexRow).
+		AddItem(lv.page, 0, 1, true).
+		AddItem(lv.statusBar, 1, 1, false)
+
+	return lv
+}
+
+// SetCloseFunc define a função chamada ao fechar o visualizador (ESC)
+func (lv *LargeViewer) SetCloseFunc(fn func()) {
+	lv.onClose = fn
+}
+
+// LoadFile abre o arquivo e começa a indexá-lo
+func (lv *LargeViewer) LoadFile(filePath string) error {
+	file, err := utils.OpenLargeFile(filePath)
+	if err != nil {
+		return err
+	}
+	lv.file = file
+	lv.page.SetTitle(fmt.Sprintf(" %s (%s) ", filepath.Base(filePath), utils.FormatFileSize(file.Size())))
+	lv.updateStatus()
+
+	// Atualizar o progresso da indexação e acompanhar o arquivo no modo seguir
+	go func() {
+		ticker := time.NewTicker(largeRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lv.closed:
+				return
+			case <-ticker.C:
+				lv.app.QueueUpdate(func() {
+					if lv.refresh() {
+						lv.app.Draw()
+					}
+				})
+			}
+		}
+	}()
+
+	return nil
+}
+
+// refresh acompanha o fim do arquivo no modo seguir e atualiza a barra de
+// status. Retorna true quando a tela precisa ser redesenhada.
+func (lv *LargeViewer) refresh() bool {
+	select {
+	case <-lv.closed:
+		return false
+	default:
+	}
+
+	_, _, done := lv.file.IndexProgress()
+	if !lv.following {
+		if !done {
+			lv.updateStatus()
+		}
+		return !done
+	}
+
+	changed, err := lv.file.Refresh()
+	if err != nil {
+		lv.message = err.Error()
+	}
+	if changed {
+		lv.page.SetTitle(fmt.Sprintf(" %s (%s) ", filepath.Base(lv.file.Path()), utils.FormatFileSize(lv.file.Size())))
+		lv.top = lv.file.LastPage(lv.height)
+	}
+	lv.updateStatus()
+	return true
+}
+
+// Close interrompe a indexação e a busca e fecha o arquivo
+func (lv *LargeViewer) Close() {
+	select {
+	case <-lv.closed:
+		return
+	default:
+	}
+	close(lv.closed)
+	lv.cancelSearch()
+	if lv.file != nil {
+		lv.file.Close()
+	}
+}
+
+// Draw desenha as linhas visíveis
+func (p *largePage) Draw(screen tcell.Screen) {
+	p.DrawForSubclass(screen, p)
+	x, y, width, height := p.GetInnerRect()
+	lv := p.viewer
+	lv.height = max(height, 1)
+	if lv.file == nil {
+		return
+	}
+
+	lines, _ := lv.file.ReadLines(lv.top, height)
+	first, known := lv.file.LineNumberAt(lv.top)
+	gutter := 0
+	if lv.lineNumbers && known {
+		gutter = len(strconv.FormatInt(first+int64(height), 10)) + 1
+	}
+
+	for i, line := range lines {
+		if gutter > 0 {
+			tview.Print(screen, strconv.FormatInt(first+int64(i)+1, 10), x, y+i, gutter-1, tview.AlignRight, tcell.ColorGray)
+		}
+		text := []rune(strings.ReplaceAll(line.Text, "\t", "    "))
+		text = text[min(lv.column, len(text)):]
+		tview.Print(screen, lv.markMatches(string(text), line.Offset == lv.match), x+gutter, y+i, width-gutter, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+	}
+}
+
+// markMatches escapa o texto e destaca as ocorrências da busca; a linha
+// encontrada tem destaque mais forte
+func (lv *LargeViewer) markMatches(text string, current bool) string {
+	if lv.re == nil {
+		return tview.Escape(text)
+	}
+	tag := "[black:yellow]"
+	if current {
+		tag = "[black:orange]"
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for _, m := range lv.re.FindAllStringIndex(text, -1) {
+		if m[1] == m[0] {
+			continue
+		}
+		sb.WriteString(tview.Escape(text[pos:m[0]]))
+		sb.WriteString(tag + tview.Escape(text[m[0]:m[1]]) + "[-:-]")
+		pos = m[1]
+	}
+	sb.WriteString(tview.Escape(text[pos:]))
+	return sb.String()
+}
+
+// scroll rola n linhas (negativo para cima), sem passar da última página
+func (lv *LargeViewer) scroll(n int) {
+	if lv.file == nil || n == 0 {
+		return
+	}
+	if n < 0 {
+		lv.top = lv.file.LinesBefore(lv.top, -n)
+	} else {
+		lines, _ := lv.file.ReadLines(lv.top, n+1)
+		if len(lines) == 0 {
+			return
+		}
+		top := lines[len(lines)-1].Offset
+		if len(lines) <= n {
+			top = lv.top
+		}
+		lv.top = max(lv.top, min(top, lv.file.LastPage(lv.height)))
+	}
+	lv.updateStatus()
+}
+
+// Goto vai para uma linha ("1500") ou para uma posição percentual ("50%")
+func (lv *LargeViewer) Goto(target string) {
+	target = strings.TrimSpace(target)
+	lv.message = ""
+
+	if percent, ok := strings.CutSuffix(target, "%"); ok {
+		value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil {
+			lv.message = fmt.Sprintf("Percentual inválido: %s", target)
+		} else {
+			lv.following = false
+			lv.top = lv.file.OffsetForPercent(value)
+		}
+		lv.updateStatus()
+		return
+	}
+
+	line, err := strconv.ParseInt(target, 10, 64)
+	if err != nil || line < 1 {
+		lv.message = fmt.Sprintf("Linha inválida: %s", target)
+		lv.updateStatus()
+		return
+	}
+	offset, ok := lv.file.LineOffset(line - 1)
+	if !ok {
+		lines, _, done := lv.file.IndexProgress()
+		if done {
+			lv.message = fmt.Sprintf("O arquivo tem %d linhas", lines)
+		} else {
+			lv.message = fmt.Sprintf("Linha %d ainda não indexada (%d linhas até agora)", line, lines)
+		}
+		lv.updateStatus()
+		return
+	}
+	lv.following = false
+	lv.top = offset
+	lv.updateStatus()
+}
+
+// Search busca o texto (sem diferenciar maiúsculas) a partir da primeira linha
+// visível, para frente ou para trás, em segundo plano
+func (lv *LargeViewer) Search(query string, backward bool) {
+	lv.query = query
+	lv.re = nil
+	lv.match = -1
+	if query == "" {
+		lv.message = ""
+		lv.updateStatus()
+		return
+	}
+	lv.re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+
+	// A busca para frente inclui a linha do topo; para trás, começa na anterior
+	lv.startSearch(lv.top, backward)
+}
+
+// searchNext repete a busca a partir da ocorrência atual
+func (lv *LargeViewer) searchNext(backward bool) {
+	if lv.re == nil {
+		return
+	}
+	from := lv.top
+	if lv.match >= 0 {
+		from = lv.match
+	}
+	if !backward {
+		lines, _ := lv.file.ReadLines(from, 1)
+		if len(lines) == 0 {
+			return
+		}
+		from = lines[0].Next
+	}
+	lv.startSearch(from, backward)
+}
+
+// startSearch executa a busca fora da interface; ESC a interrompe
+func (lv *LargeViewer) startSearch(from int64, backward bool) {
+	lv.cancelSearch()
+	stop := make(chan struct{})
+	lv.stopSearch = stop
+	lv.message = "Buscando... (ESC interrompe)"
+	lv.updateStatus()
+
+	re := lv.re
+	go func() {
+		offset, err := lv.file.Search(re, from, backward, stop)
+		lv.app.QueueUpdateDraw(func() {
+			if lv.stopSearch != stop {
+				return // Busca interrompida ou substituída
+			}
+			lv.stopSearch = nil
+			switch {
+			case errors.Is(err, utils.ErrSearchStopped):
+				lv.message = "Busca interrompida"
+			case err != nil:
+				lv.message = err.Error()
+			case offset < 0:
+				lv.message = fmt.Sprintf("[red]%s não encontrado[-]", tview.Escape(strconv.Quote(lv.query)))
+			default:
+				lv.message = ""
+				lv.following = false
+				lv.match = offset
+				lv.top = offset
+			}
+			lv.updateStatus()
+		})
+	}()
+}
+
+// cancelSearch interrompe a busca em andamento
+func (lv *LargeViewer) cancelSearch() bool {
+	if lv.stopSearch == nil {
+		return false
+	}
+	close(lv.stopSearch)
+	lv.stopSearch = nil
+	return true
+}
+
+// ToggleFollow liga ou desliga o acompanhamento do fim do arquivo
+func (lv *LargeViewer) ToggleFollow() {
+	lv.following = !lv.following
+	if lv.following {
+		lv.file.Refresh()
+		lv.top = lv.file.LastPage(lv.height)
+	}
+	lv.updateStatus()
+}
+
+// openPrompt exibe o campo de busca ou de posição no lugar da barra de status
+func (lv *LargeViewer) openPrompt(mode rune) {
+	lv.promptMode = mode
+	switch mode {
+	case '/':
+		lv.prompt.SetLabel(" Buscar: ").SetText(lv.query)
+	case '?':
+		lv.prompt.SetLabel(" Buscar para trás: ").SetText(lv.query)
+	default:
+		lv.prompt.SetLabel(" Ir para (linha ou %): ").SetText("")
+	}
+	lv.layout.RemoveItem(lv.statusBar)
+	lv.layout.AddItem(lv.prompt, 1, 1, true)
+	lv.app.SetFocus(lv.prompt)
+}
+
+// closePrompt volta a exibir a barra de status
+func (lv *LargeViewer) closePrompt() {
+	lv.layout.RemoveItem(lv.prompt)
+	lv.layout.AddItem(lv.statusBar, 1, 1, false)
+	lv.app.SetFocus(lv.page)
+}
+
+// updateStatus atualiza a barra de status com a posição, a indexação e a busca
+func (lv *LargeViewer) updateStatus() {
+	if lv.file == nil {
+		return
+	}
+
+	position := "linha ?"
+	if line, ok := lv.file.LineNumberAt(lv.top); ok {
+		position = fmt.Sprintf("linha %d", line+1)
+	}
+	lines, indexed, done := lv.file.IndexProgress()
+	size := lv.file.Size()
+	total := fmt.Sprintf("%d linhas", lines)
+	if !done {
+		total = fmt.Sprintf("indexando %d%%, %d linhas", indexed*100/max(size, 1), lines)
+	}
+	percent := 100
+	if size > 0 {
+		percent = int(lv.top * 100 / size)
+	}
+
+	state := ""
+	if lv.following {
+		state = "  [black:green] SEGUINDO [-:-]"
+	}
+	if lv.message != "" {
+		state += "  " + lv.message
+	} else if lv.query != "" {
+		state += "  " + tview.Escape(strconv.Quote(lv.query))
+	}
+
+	lv.statusBar.SetText(fmt.Sprintf(" %s (%d%%) de %s%s  [::b]/ ?[-:-:-] Buscar  [::b]n/N[-:-:-] Próxima/Anterior  [::b]:[-:-:-] Linha/%%  [::b]F[-:-:-] Seguir  [::b]L[-:-:-] Números  [::b]ESC[-:-:-] Fechar",
+		position, percent, total, state))
+}
+
+// handleKeyEvents manipula eventos de teclado
+func (lv *LargeViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
+	if lv.file == nil {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		// ESC interrompe a busca, depois limpa o destaque e por fim fecha
+		if lv.cancelSearch() {
+			lv.message = "Busca interrompida"
+			lv.updateStatus()
+			return nil
+		}
+		if lv.query != "" {
+			lv.Search("", false)
+			return nil
+		}
+		lv.Close()
+		if lv.onClose != nil {
+			lv.onClose()
+		}
+		return nil
+	case tcell.KeyUp:
+		lv.following = false
+		lv.scroll(-1)
+	case tcell.KeyDown:
+		lv.scroll(1)
+	case tcell.KeyPgUp:
+		lv.following = false
+		lv.scroll(-max(lv.height-1, 1))
+	case tcell.KeyPgDn:
+		lv.scroll(max(lv.height-1, 1))
+	case tcell.KeyHome:
+		lv.following = false
+		lv.top = 0
+		lv.updateStatus()
+	case tcell.KeyEnd:
+		lv.top = lv.file.LastPage(lv.height)
+		lv.updateStatus()
+	case tcell.KeyLeft:
+		lv.column = max(lv.column-8, 0)
+	case tcell.KeyRight:
+		lv.column += 8
+	case tcell.KeyCtrlF:
+		lv.openPrompt('/')
+	case tcell.KeyCtrlG:
+		lv.openPrompt(':')
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'k':
+			lv.following = false
+			lv.scroll(-1)
+		case 'j':
+			lv.scroll(1)
+		case ' ':
+			lv.scroll(max(lv.height-1, 1))
+		case 'b':
+			lv.following = false
+			lv.scroll(-max(lv.height-1, 1))
+		case 'g':
+			lv.following = false
+			lv.top = 0
+			lv.updateStatus()
+		case 'G':
+			lv.top = lv.file.LastPage(lv.height)
+			lv.updateStatus()
+		case '/', '?', ':':
+			lv.openPrompt(event.Rune())
+		case 'n':
+			lv.searchNext(false)
+		case 'N':
+			lv.searchNext(true)
+		case 'F', 'f':
+			lv.ToggleFollow()
+		case 'L':
+			lv.lineNumbers = !lv.lineNumbers
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+// Show exibe o visualizador
+func (lv *LargeViewer) Show() *tview.Flex {
+	return lv.layout
+}
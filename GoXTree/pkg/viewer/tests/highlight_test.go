@@ -0,0 +1,136 @@
+package viewer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peder1981/GoXTree/pkg/viewer"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name, firstLine, want string
+	}{
+		{"main.go", "", "Go"},
+		{"MATA010.PRW", "", "AdvPL/TLPP"},
+		{"classe.tlpp", "", "AdvPL/TLPP"},
+		{"package.json", "", "JSON"},
+		{"docker-compose.yml", "", "YAML"},
+		{"README.md", "", "Markdown"},
+		{"consulta.sql", "", "SQL"},
+		{".bashrc", "", "Shell"},
+		{"deploy", "#!/usr/bin/env bash", "Shell"},
+		{"build", "#!/bin/sh -e", "Shell"},
+		{"script", "#!/usr/bin/python3", ""},
+		{"notas.txt", "", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if lang := viewer.DetectLanguage(tt.name, tt.firstLine); lang != nil {
+			got = lang.Name
+		}
+		if got != tt.want {
+			t.Errorf("DetectLanguage(%q, %q) = %q, want %q", tt.name, tt.firstLine, got, tt.want)
+		}
+	}
+}
+
+// kinds retorna a categoria de cada trecho com texto não vazio (sem espaços), no formato "texto:categoria"
+func kinds(tokens []viewer.Token) []string {
+	var out []string
+	for _, tok := range tokens {
+		if text := strings.TrimSpace(tok.Text); text != "" {
+			out = append(out, text+":"+tok.Kind.String())
+		}
+	}
+	return out
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		file  string
+		lines []string
+		want  [][]string
+	}{
+		{
+			file:  "x.go",
+			lines: []string{`func main() { return nil } // fim`, "s := `várias", "linhas` /* bloco"},
+			want: [][]string{
+				{"func:keyword", "main:function", "():operator", "{:operator", "return:keyword", "nil:literal", "}:operator", "// fim:comment"},
+				{"s:text", ":=:operator", "`várias:string"},
+				{"linhas`:string", "/* bloco:comment"},
+			},
+		},
+		{
+			file:  "x.prw",
+			lines: []string{`#include "protheus.ch"`, `User Function Teste()`, `If lOk .And. .T. // ok`},
+			want: [][]string{
+				{`#include "protheus.ch":preproc`},
+				{"User:keyword", "Function:keyword", "Teste:function", "():operator"},
+				{"If:keyword", "lOk:text", ".And.:keyword", ".T.:literal", "// ok:comment"},
+			},
+		},
+		{
+			file:  "x.json",
+			lines: []string{`{"nome": "valor", "n": 12.5, "ok": true}`},
+			want: [][]string{
+				{"{:operator", `"nome":key`, "::operator", `"valor":string`, ",:operator", `"n":key`, "::operator",
+					"12.5:number", ",:operator", `"ok":key`, "::operator", "true:literal", "}:operator"},
+			},
+		},
+		{
+			file:  "x.yaml",
+			lines: []string{"# config", "- nome: &base 'app' # fim", "ativo: true"},
+			want: [][]string{
+				{"# config:comment"},
+				{"-:operator", "nome:key", "::operator", "&base:variable", "'app':string", "# fim:comment"},
+				{"ativo:key", "::operator", "true:literal"},
+			},
+		},
+		{
+			file:  "x.md",
+			lines: []string{"# Título", "Use `go build` e **leia** [aqui](http://x)", "```", "# não é título", "```"},
+			want: [][]string{
+				{"# Título:heading"},
+				{"Use:text", "`go build`:string", "e:text", "**leia**:keyword", "[aqui]:function", "(http://x):type"},
+				{"```:preproc"},
+				{"# não é título:string"},
+				{"```:preproc"},
+			},
+		},
+		{
+			file:  "x.sh",
+			lines: []string{`if [ -n "$HOME" ]; then echo ${USER}#x; fi # comentário`},
+			want: [][]string{
+				{"if:keyword", "[:operator", "-:operator", "n:text", `"$HOME":string`, "];:operator", "then:keyword",
+					"echo:type", "${USER}:variable", "#x:text", ";:operator", "fi:keyword", "# comentário:comment"},
+			},
+		},
+		{
+			file:  "x.sql",
+			lines: []string{"SELECT nome FROM clientes WHERE id = 10 -- fim"},
+			want: [][]string{
+				{"SELECT:keyword", "nome:text", "FROM:keyword", "clientes:text", "WHERE:keyword", "id:text", "=:operator", "10:number", "-- fim:comment"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		lang := viewer.DetectLanguage(tt.file, "")
+		result := viewer.Highlight(lang, tt.lines)
+		for i, tokens := range result {
+			// Os trechos sempre reconstroem a linha
+			var joined strings.Builder
+			for _, tok := range tokens {
+				joined.WriteString(tok.Text)
+			}
+			if joined.String() != tt.lines[i] {
+				t.Errorf("%s line %d: tokens rebuild %q, want %q", tt.file, i, joined.String(), tt.lines[i])
+			}
+
+			if got := kinds(tokens); strings.Join(got, " | ") != strings.Join(tt.want[i], " | ") {
+				t.Errorf("%s line %d:\n got %v\nwant %v", tt.file, i, got, tt.want[i])
+			}
+		}
+	}
+}
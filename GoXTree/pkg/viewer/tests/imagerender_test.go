@@ -0,0 +1,197 @@
+package viewer_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/peder1981/GoXTree/pkg/viewer"
+	"github.com/rivo/tview"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+func TestDetectGraphics(t *testing.T) {
+	tests := []struct {
+		env    map[string]string
+		colors int
+		want   viewer.GraphicsMode
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, 256, viewer.GraphicsKitty},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, 1 << 24, viewer.GraphicsKitty},
+		{map[string]string{"TERM": "foot"}, 1 << 24, viewer.GraphicsSixel},
+		{map[string]string{"TERM": "mlterm"}, 256, viewer.GraphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-0/default"}, 256, viewer.GraphicsBlocks},
+		{map[string]string{"TERM": "xterm-256color"}, 1 << 24, viewer.GraphicsBlocks},
+		{map[string]string{"TERM": "vt100"}, 8, viewer.GraphicsASCII},
+		{map[string]string{"TERM": "xterm-kitty", "GOXTREE_IMAGE": "blocos"}, 256, viewer.GraphicsBlocks},
+		{map[string]string{"TERM": "xterm", "GOXTREE_IMAGE": "sixel"}, 8, viewer.GraphicsSixel},
+	}
+	for _, tt := range tests {
+		got := viewer.DetectGraphics(tt.colors, func(name string) string { return tt.env[name] })
+		if got != tt.want {
+			t.Errorf("DetectGraphics(%d, %v) = %s, want %s", tt.colors, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestImageViewport(t *testing.T) {
+	bounds := image.Rect(0, 0, 400, 200)
+	var view viewer.ImageViewport
+	view.Fit(bounds)
+
+	// Imagem inteira numa área quadrada: ocupa toda a largura e metade da altura
+	src, w, h := view.Visible(bounds, 100, 100)
+	if src != bounds || w != 100 || h != 50 {
+		t.Errorf("Visible(ajustar) = %v, %d, %d", src, w, h)
+	}
+
+	// Com zoom 4 aparece um quarto da largura, centralizado
+	view.Zoom = 4
+	src, w, h = view.Visible(bounds, 100, 100)
+	if src != image.Rect(150, 50, 250, 150) || w != 100 || h != 100 {
+		t.Errorf("Visible(zoom 4) = %v, %d, %d", src, w, h)
+	}
+
+	// O centro não deixa o trecho sair da imagem
+	view.CenterX, view.CenterY = 1000, -50
+	src, _, _ = view.Visible(bounds, 100, 100)
+	if src != image.Rect(300, 0, 400, 100) || view.CenterX != 350 || view.CenterY != 50 {
+		t.Errorf("Visible(fora da imagem) = %v, centro %v,%v", src, view.CenterX, view.CenterY)
+	}
+}
+
+// testImage cria uma imagem com a metade de cima vermelha e a de baixo azul
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 0xff, A: 0xff}
+			if y >= h/2 {
+				c = color.RGBA{B: 0xff, A: 0xff}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncodeSixel(t *testing.T) {
+	data := string(viewer.EncodeSixel(testImage(20, 12)))
+	if !strings.HasPrefix(data, "\x1bP0;1;0q\"1;1;20;12") || !strings.HasSuffix(data, "\x1b\\") {
+		t.Fatalf("EncodeSixel() = %q", data)
+	}
+	// Duas cores (vermelho e azul) e duas faixas de 6 linhas
+	if colors := regexp.MustCompile(`#\d+;2;`).FindAllString(data, -1); len(colors) != 2 {
+		t.Errorf("EncodeSixel() definiu %d cores", len(colors))
+	}
+	if bands := strings.Count(data, "-"); bands != 2 {
+		t.Errorf("EncodeSixel() gerou %d faixas", bands)
+	}
+	// Faixa inteira da mesma cor: 20 colunas com os 6 bits ligados, compactadas
+	if !strings.Contains(data, "!20~") {
+		t.Errorf("EncodeSixel() sem compactação: %q", data)
+	}
+}
+
+func TestEncodeKitty(t *testing.T) {
+	// Ruído não comprime bem no PNG e gera vários blocos
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	rand.New(rand.NewSource(1)).Read(img.Pix)
+	data, err := viewer.EncodeKitty(img, 7, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(string(data), -1)
+	if len(chunks) < 2 {
+		t.Fatalf("EncodeKitty() gerou %d blocos", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0][1], "a=T,f=100") || !strings.Contains(chunks[0][1], "i=7,c=10,r=5,m=1") {
+		t.Errorf("EncodeKitty() cabeçalho = %q", chunks[0][1])
+	}
+	if chunks[len(chunks)-1][1] != "m=0" {
+		t.Errorf("EncodeKitty() último bloco = %q", chunks[len(chunks)-1][1])
+	}
+
+	var encoded strings.Builder
+	for _, chunk := range chunks {
+		if len(chunk[2]) > 4096 {
+			t.Errorf("bloco com %d bytes", len(chunk[2]))
+		}
+		encoded.WriteString(chunk[2])
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(raw))
+	if err != nil || decoded.Bounds() != img.Bounds() {
+		t.Errorf("PNG enviado inválido: %v", err)
+	}
+}
+
+func TestImageViewerFormats(t *testing.T) {
+	dir := t.TempDir()
+	img := testImage(40, 40)
+	encoders := map[string]func(*bytes.Buffer) error{
+		"imagem.bmp":  func(b *bytes.Buffer) error { return bmp.Encode(b, img) },
+		"imagem.tiff": func(b *bytes.Buffer) error { return tiff.Encode(b, img, nil) },
+		"imagem.png":  func(b *bytes.Buffer) error { return png.Encode(b, img) },
+	}
+
+	for name, encode := range encoders {
+		var buf bytes.Buffer
+		if err := encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		iv := viewer.NewImageViewer(tview.NewApplication())
+		if err := iv.LoadFile(path); err != nil {
+			t.Errorf("LoadFile(%s): %v", name, err)
+			continue
+		}
+		iv.SetMode(viewer.GraphicsBlocks)
+
+		// Área interna de 10x5 células (10x10 pixels): metade vermelha, metade azul
+		screen := tcell.NewSimulationScreen("UTF-8")
+		if err := screen.Init(); err != nil {
+			t.Fatal(err)
+		}
+		screen.SetSize(12, 8)
+		layout := iv.Show()
+		layout.SetRect(0, 0, 12, 8)
+		layout.Draw(screen)
+
+		char, _, style, _ := screen.GetContent(5, 1)
+		fg, bg, _ := style.Decompose()
+		if char != '▀' || fg != tcell.NewRGBColor(0xff, 0, 0) || bg != tcell.NewRGBColor(0xff, 0, 0) {
+			t.Errorf("%s: célula do topo = %q %v/%v", name, char, fg, bg)
+		}
+		_, _, style, _ = screen.GetContent(5, 5)
+		if fg, bg, _ := style.Decompose(); fg != tcell.NewRGBColor(0, 0, 0xff) || bg != tcell.NewRGBColor(0, 0, 0xff) {
+			t.Errorf("%s: célula de baixo = %v/%v", name, fg, bg)
+		}
+		screen.Fini()
+	}
+
+	// Formatos que não são imagem
+	path := filepath.Join(dir, "falso.webp")
+	os.WriteFile(path, []byte("não é uma imagem"), 0644)
+	if err := viewer.NewImageViewer(tview.NewApplication()).LoadFile(path); err == nil {
+		t.Error("LoadFile() aceitou arquivo inválido")
+	}
+}
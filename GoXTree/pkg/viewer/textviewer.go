@@ -5,7 +5,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/peder1981/GoXTree/pkg/utils"
 	"github.com/rivo/tview"
@@ -13,31 +15,58 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-// TextViewer representa um visualizador de texto
+// highlightLimit é o tamanho máximo de arquivo exibido com destaque de sintaxe
+const highlightLimit = 2 * 1024 * 1024
+
+// TextViewerLimit é o tamanho máximo de arquivo carregado pelo TextViewer; acima
+// dele, use o LargeViewer
+const TextViewerLimit = 10 * 1024 * 1024
+
+// textMatch é uma ocorrência da busca: linha e intervalo em runas
+type textMatch struct {
+	line, start, end int
+}
+
+// TextViewer representa um visualizador de texto com destaque de sintaxe,
+// números de linha, quebra de linha opcional e busca
 type TextViewer struct {
-	app       *tview.Application
-	textView  *tview.TextView
-	statusBar *tview.TextView
-	layout    *tview.Flex
-	filePath  string
-	fileInfo  os.FileInfo
-	lines     []string
-	lineCount int
+	app         *tview.Application
+	textView    *tview.TextView
+	statusBar   *tview.TextView
+	search      *tview.InputField
+	layout      *tview.Flex
+	filePath    string
+	fileInfo    os.FileInfo
+	lines       []string
+	lineCount   int
+	language    *Language
+	tokens      [][]Token
+	colors      SyntaxColors
+	lineNumbers bool
+	wrap        bool
+	query       string
+	matches     []textMatch
+	current     int
+	onClose     func()
 }
 
 // NewTextViewer cria um novo visualizador de texto
 func NewTextViewer(app *tview.Application) *TextViewer {
 	tv := &TextViewer{
-		app:       app,
-		textView:  tview.NewTextView(),
-		statusBar: tview.NewTextView(),
-		layout:    tview.NewFlex(),
+		app:         app,
+		textView:    tview.NewTextView(),
+		statusBar:   tview.NewTextView(),
+		search:      tview.NewInputField(),
+		layout:      tview.NewFlex(),
+		colors:      DefaultSyntaxColors,
+		lineNumbers: true,
 	}
 
 	// Configurar o visualizador de texto
 	tv.textView.SetDynamicColors(true)
 	tv.textView.SetRegions(true)
 	tv.textView.SetScrollable(true)
+	tv.textView.SetWrap(false)
 	tv.textView.SetBorder(true)
 	tv.textView.SetTitle(" Visualizador de Texto ")
 	tv.textView.SetTitleAlign(tview.AlignLeft)
@@ -46,7 +75,15 @@ func NewTextViewer(app *tview.Application) *TextViewer {
 	tv.statusBar.SetTextColor(utils.ColorStatusText)
 	tv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
 	tv.statusBar.SetDynamicColors(true)
-	tv.statusBar.SetText("[::b]ESC[-:-:-] Fechar  [::b]↑/↓[-:-:-] Rolar  [::b]PgUp/PgDn[-:-:-] Página  [::b]Home/End[-:-:-] Início/Fim")
+
+	// Campo de busca, exibido no lugar da barra de status
+	tv.search.SetLabel(" Buscar: ")
+	tv.search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			tv.Search(tv.search.GetText())
+		}
+		tv.closeSearch()
+	})
 
 	// Configurar o layout
 	tv.layout.SetDirection(tview.FlexRow).
@@ -55,10 +92,30 @@ func NewTextViewer(app *tview.Application) *TextViewer {
 
 	// Configurar manipuladores de eventos
 	tv.textView.SetInputCapture(tv.handleKeyEvents)
+	tv.updateStatus()
 
 	return tv
 }
 
+// SetSyntaxColors define as cores do destaque; categorias ausentes usam DefaultSyntaxColors
+func (tv *TextViewer) SetSyntaxColors(colors SyntaxColors) {
+	tv.colors = make(SyntaxColors, len(TokenKinds))
+	for kind, color := range DefaultSyntaxColors {
+		tv.colors[kind] = color
+	}
+	for kind, color := range colors {
+		tv.colors[kind] = color
+	}
+	if tv.tokens != nil {
+		tv.render()
+	}
+}
+
+// SetCloseFunc define a função chamada ao fechar o visualizador (ESC)
+func (tv *TextViewer) SetCloseFunc(fn func()) {
+	tv.onClose = fn
+}
+
 // LoadFile carrega um arquivo de texto
 func (tv *TextViewer) LoadFile(filePath string) error {
 	// Obter informações do arquivo
@@ -73,7 +130,7 @@ func (tv *TextViewer) LoadFile(filePath string) error {
 	}
 
 	// Verificar o tamanho do arquivo
-	if info.Size() > 10*1024*1024 { // 10MB
+	if info.Size() > TextViewerLimit {
 		return fmt.Errorf("arquivo muito grande para visualização (> 10MB)")
 	}
 
@@ -94,194 +151,278 @@ func (tv *TextViewer) LoadFile(filePath string) error {
 	tv.filePath = filePath
 	tv.fileInfo = info
 
-	// Processar o conteúdo
-	tv.lines = strings.Split(string(content), "\n")
+	// Processar o conteúdo (finais de linha CRLF são exibidos como LF)
+	tv.lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	for i, line := range tv.lines {
+		tv.lines[i] = strings.TrimSuffix(line, "\r")
+	}
 	tv.lineCount = len(tv.lines)
 
+	// Escolher a linguagem pela extensão ou pela linha #!
+	tv.language = nil
+	if info.Size() <= highlightLimit {
+		tv.language = DetectLanguage(filePath, tv.lines[0])
+	}
+	tv.tokens = Highlight(tv.language, tv.lines)
+
 	// Atualizar o título
 	fileName := filepath.Base(filePath)
 	tv.textView.SetTitle(fmt.Sprintf(" %s (%s) ", fileName, utils.FormatFileSize(info.Size())))
 
 	// Exibir o conteúdo
-	tv.displayContent(string(content))
+	tv.query, tv.matches = "", nil
+	tv.render()
+	tv.textView.ScrollToBeginning()
 
 	return nil
 }
 
-// displayContent exibe o conteúdo no visualizador
-func (tv *TextViewer) displayContent(content string) {
-	tv.textView.SetText(content)
-	tv.textView.ScrollToBeginning()
+// Language retorna a linguagem detectada (nil para texto sem destaque)
+func (tv *TextViewer) Language() *Language {
+	return tv.language
 }
 
-// handleKeyEvents manipula eventos de teclado
-func (tv *TextViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Key() {
-	case tcell.KeyEscape:
-		// Fechar o visualizador
-		return nil
-	}
+// render monta o texto com cores, números de linha e ocorrências da busca
+func (tv *TextViewer) render() {
+	var sb strings.Builder
+	width := len(strconv.Itoa(tv.lineCount))
+	next := 0
+	for i, tokens := range tv.tokens {
+		if tv.lineNumbers {
+			fmt.Fprintf(&sb, "%s%*d[-] ", colorTag(tv.colors[TokenComment]), width, i+1)
+		}
 
-	return event
+		first := next
+		for next < len(tv.matches) && tv.matches[next].line == i {
+			next++
+		}
+		writeHighlightedLine(&sb, tokens, tv.colors, tv.matches[first:next], first)
+		if i < len(tv.tokens)-1 {
+			sb.WriteByte('\n')
+		}
+	}
+	tv.textView.SetText(sb.String())
+	tv.updateStatus()
 }
 
-// Show exibe o visualizador
-func (tv *TextViewer) Show() *tview.Flex {
-	return tv.layout
-}
+// writeHighlightedLine escreve uma linha com as cores de cada trecho; as ocorrências
+// da busca viram regiões "m<n>", numeradas a partir de firstID
+func writeHighlightedLine(sb *strings.Builder, tokens []Token, colors SyntaxColors, matches []textMatch, firstID int) {
+	var chunk strings.Builder
+	flush := func() {
+		sb.WriteString(tview.Escape(chunk.String()))
+		chunk.Reset()
+	}
 
-// HexViewer representa um visualizador hexadecimal
-type HexViewer struct {
-	app       *tview.Application
-	textView  *tview.TextView
-	statusBar *tview.TextView
-	layout    *tview.Flex
-	filePath  string
-	fileInfo  os.FileInfo
+	color, region := "", -1
+	r, k := 0, 0
+	for _, token := range tokens {
+		tag := colorTag(colors[token.Kind])
+		for _, ch := range token.Text {
+			for k < len(matches) && r >= matches[k].end {
+				k++
+			}
+			current := -1
+			if k < len(matches) && r >= matches[k].start {
+				current = firstID + k
+			}
+			if current != region {
+				flush()
+				if region >= 0 {
+					sb.WriteString(`[""]`)
+				}
+				if current >= 0 {
+					fmt.Fprintf(sb, `["m%d"]`, current)
+				}
+				region = current
+			}
+			if tag != color {
+				flush()
+				sb.WriteString(tag)
+				color = tag
+			}
+			chunk.WriteRune(ch)
+			r++
+		}
+	}
+	flush()
+	if region >= 0 {
+		sb.WriteString(`[""]`)
+	}
+	if color != "" {
+		sb.WriteString("[-]")
+	}
 }
 
-// NewHexViewer cria um novo visualizador hexadecimal
-func NewHexViewer(app *tview.Application) *HexViewer {
-	hv := &HexViewer{
-		app:       app,
-		textView:  tview.NewTextView(),
-		statusBar: tview.NewTextView(),
-		layout:    tview.NewFlex(),
+// colorTag retorna a marcação de cor do tview para a cor informada
+func colorTag(color tcell.Color) string {
+	if color == tcell.ColorDefault || color.Hex() < 0 {
+		return "[-]"
 	}
-
-	// Configurar o visualizador hexadecimal
-	hv.textView.SetDynamicColors(true)
-	hv.textView.SetRegions(true)
-	hv.textView.SetScrollable(true)
-	hv.textView.SetBorder(true)
-	hv.textView.SetTitle(" Visualizador Hexadecimal ")
-	hv.textView.SetTitleAlign(tview.AlignLeft)
-
-	// Configurar a barra de status
-	hv.statusBar.SetTextColor(utils.ColorStatusText)
-	hv.statusBar.SetBackgroundColor(utils.ColorStatusBar)
-	hv.statusBar.SetDynamicColors(true)
-	hv.statusBar.SetText("[::b]ESC[-:-:-] Fechar  [::b]↑/↓[-:-:-] Rolar  [::b]PgUp/PgDn[-:-:-] Página  [::b]Home/End[-:-:-] Início/Fim")
-
-	// Configurar o layout
-	hv.layout.SetDirection(tview.FlexRow).
-		AddItem(hv.textView, 0, 1, true).
-		AddItem(hv.statusBar, 1, 1, false)
-
-	// Configurar manipuladores de eventos
-	hv.textView.SetInputCapture(hv.handleKeyEvents)
-
-	return hv
+	return fmt.Sprintf("[#%06x]", color.Hex())
 }
 
-// LoadFile carrega um arquivo para visualização hexadecimal
-func (hv *HexViewer) LoadFile(filePath string) error {
-	// Obter informações do arquivo
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return err
+// Search destaca todas as ocorrências do texto (sem diferenciar maiúsculas) e vai
+// para a primeira a partir da linha visível. Texto vazio limpa a busca.
+func (tv *TextViewer) Search(query string) int {
+	tv.query = query
+	tv.matches = nil
+	tv.current = 0
+
+	if query != "" {
+		needle := []rune(query)
+		for j := range needle {
+			needle[j] = unicode.ToLower(needle[j])
+		}
+		for i, line := range tv.lines {
+			runes := []rune(line)
+			for j := range runes {
+				runes[j] = unicode.ToLower(runes[j])
+			}
+			for start := 0; start+len(needle) <= len(runes); start++ {
+				if runesEqual(runes[start:start+len(needle)], needle) {
+					tv.matches = append(tv.matches, textMatch{line: i, start: start, end: start + len(needle)})
+					start += len(needle) - 1
+				}
+			}
+		}
 	}
 
-	// Verificar se é um arquivo regular
-	if !info.Mode().IsRegular() {
-		return fmt.Errorf("não é um arquivo regular")
+	tv.render()
+	if len(tv.matches) == 0 {
+		tv.textView.Highlight()
+		return 0
 	}
 
-	// Verificar o tamanho do arquivo
-	if info.Size() > 1*1024*1024 { // 1MB
-		return fmt.Errorf("arquivo muito grande para visualização hexadecimal (> 1MB)")
+	row, _ := tv.textView.GetScrollOffset()
+	for i, m := range tv.matches {
+		if m.line >= row {
+			tv.current = i
+			break
+		}
 	}
+	tv.gotoMatch(tv.current)
+	return len(tv.matches)
+}
 
-	// Abrir o arquivo
-	file, err := os.Open(filePath)
-	if err != nil {
-		return err
+// runesEqual compara duas sequências de runas do mesmo tamanho
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
-	defer file.Close()
+	return true
+}
 
-	// Ler o conteúdo do arquivo
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return err
+// gotoMatch destaca a ocorrência informada e rola até ela
+func (tv *TextViewer) gotoMatch(index int) {
+	if len(tv.matches) == 0 {
+		return
 	}
+	tv.current = (index + len(tv.matches)) % len(tv.matches)
+	tv.textView.Highlight(fmt.Sprintf("m%d", tv.current)).ScrollToHighlight()
+	tv.updateStatus()
+}
 
-	// Armazenar informações do arquivo
-	hv.filePath = filePath
-	hv.fileInfo = info
-
-	// Atualizar o título
-	fileName := filepath.Base(filePath)
-	hv.textView.SetTitle(fmt.Sprintf(" %s (%s) - Hex ", fileName, utils.FormatFileSize(info.Size())))
+// openSearch exibe o campo de busca no lugar da barra de status
+func (tv *TextViewer) openSearch() {
+	tv.search.SetText(tv.query)
+	tv.layout.RemoveItem(tv.statusBar)
+	tv.layout.AddItem(tv.search, 1, 1, true)
+	tv.app.SetFocus(tv.search)
+}
 
-	// Exibir o conteúdo hexadecimal
-	hv.displayHexContent(content)
+// closeSearch volta a exibir a barra de status
+func (tv *TextViewer) closeSearch() {
+	tv.layout.RemoveItem(tv.search)
+	tv.layout.AddItem(tv.statusBar, 1, 1, false)
+	tv.app.SetFocus(tv.textView)
+}
 
-	return nil
+// ToggleWrap liga ou desliga a quebra das linhas longas
+func (tv *TextViewer) ToggleWrap() {
+	tv.wrap = !tv.wrap
+	tv.textView.SetWrap(tv.wrap)
+	tv.textView.SetWordWrap(tv.wrap)
+	tv.updateStatus()
 }
 
-// displayHexContent exibe o conteúdo em formato hexadecimal
-func (hv *HexViewer) displayHexContent(content []byte) {
-	if len(content) == 0 {
-		hv.textView.SetText("Arquivo vazio")
-		return
+// ToggleLineNumbers exibe ou oculta os números de linha
+func (tv *TextViewer) ToggleLineNumbers() {
+	tv.lineNumbers = !tv.lineNumbers
+	tv.render()
+	if len(tv.matches) > 0 {
+		tv.textView.Highlight(fmt.Sprintf("m%d", tv.current))
 	}
+}
 
-	var hexOutput strings.Builder
-
-	// Cabeçalho
-	hexOutput.WriteString("       00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F |0123456789ABCDEF|\n")
-	hexOutput.WriteString("------------------------------------------------------|-----------------|\n")
-
-	// Conteúdo
-	for i := 0; i < len(content); i += 16 {
-		// Endereço
-		hexOutput.WriteString(fmt.Sprintf("%06X ", i))
-
-		// Bytes em hexadecimal
-		for j := 0; j < 16; j++ {
-			if i+j < len(content) {
-				hexOutput.WriteString(fmt.Sprintf("%02X ", content[i+j]))
-			} else {
-				hexOutput.WriteString("   ")
-			}
+// updateStatus atualiza a barra de status com a linguagem, a busca e as opções
+func (tv *TextViewer) updateStatus() {
+	onOff := func(b bool) string {
+		if b {
+			return "sim"
 		}
+		return "não"
+	}
 
-		// Separador
-		hexOutput.WriteString(" |")
-
-		// Caracteres ASCII
-		for j := 0; j < 16; j++ {
-			if i+j < len(content) {
-				b := content[i+j]
-				if b >= 32 && b <= 126 { // Caracteres imprimíveis
-					hexOutput.WriteString(string(b))
-				} else {
-					hexOutput.WriteString(".")
-				}
-			} else {
-				hexOutput.WriteString(" ")
-			}
+	language := "Texto"
+	if tv.language != nil {
+		language = tv.language.Name
+	}
+	found := ""
+	if tv.query != "" {
+		if len(tv.matches) == 0 {
+			found = fmt.Sprintf("  [red]%s não encontrado[-]", tview.Escape(strconv.Quote(tv.query)))
+		} else {
+			found = fmt.Sprintf("  %s %d/%d", tview.Escape(strconv.Quote(tv.query)), tv.current+1, len(tv.matches))
 		}
-
-		hexOutput.WriteString("|\n")
 	}
 
-	hv.textView.SetText(hexOutput.String())
-	hv.textView.ScrollToBeginning()
+	tv.statusBar.SetText(fmt.Sprintf(" %s, %d linhas%s  [::b]/[-:-:-] Buscar  [::b]n/N[-:-:-] Próxima/Anterior  [::b]W[-:-:-] Quebrar linhas: %s  [::b]L[-:-:-] Números: %s  [::b]ESC[-:-:-] Fechar",
+		tview.Escape(language), tv.lineCount, found, onOff(tv.wrap), onOff(tv.lineNumbers)))
 }
 
 // handleKeyEvents manipula eventos de teclado
-func (hv *HexViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
+func (tv *TextViewer) handleKeyEvents(event *tcell.EventKey) *tcell.EventKey {
 	switch event.Key() {
 	case tcell.KeyEscape:
-		// Fechar o visualizador
+		// ESC limpa a busca; sem busca, fecha o visualizador
+		if tv.query != "" {
+			tv.Search("")
+			return nil
+		}
+		if tv.onClose != nil {
+			tv.onClose()
+		}
+		return nil
+	case tcell.KeyCtrlF:
+		tv.openSearch()
 		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case '/':
+			tv.openSearch()
+			return nil
+		case 'n':
+			tv.gotoMatch(tv.current + 1)
+			return nil
+		case 'N':
+			tv.gotoMatch(tv.current - 1)
+			return nil
+		case 'w', 'W':
+			tv.ToggleWrap()
+			return nil
+		case 'L':
+			tv.ToggleLineNumbers()
+			return nil
+		}
 	}
 
 	return event
 }
 
 // Show exibe o visualizador
-func (hv *HexViewer) Show() *tview.Flex {
-	return hv.layout
+func (tv *TextViewer) Show() *tview.Flex {
+	return tv.layout
 }